@@ -1,6 +1,7 @@
 package clipboard
 
 import (
+	"encoding/base64"
 	"io"
 	"os"
 	"os/exec"
@@ -31,17 +32,23 @@ type Clipboard struct {
 	tool ClipboardTool
 	// Whether we've warned about missing clipboard tools
 	warned bool
+	// OSC52 selection targets, e.g. "c" (clipboard), "p" (primary) or "pc"
+	osc52Selections string
 }
 
+// defaultOSC52Selections targets the system clipboard only
+const defaultOSC52Selections = "c"
+
 // New creates a new Clipboard instance.
 func New(output io.Writer) *Clipboard {
 	if output == nil {
 		output = os.Stdout
 	}
 	return &Clipboard{
-		isSSH:  isSSHSession(),
-		output: output,
-		tool:   detectClipboardTool(),
+		isSSH:           isSSHSession(),
+		output:          output,
+		tool:            detectClipboardTool(),
+		osc52Selections: defaultOSC52Selections,
 	}
 }
 
@@ -125,10 +132,50 @@ func (c *Clipboard) copyNative(text string) error {
 	return cmd.Run()
 }
 
+// SetOSC52Selections sets which selections OSC52 copies write to, e.g. "c"
+// for the clipboard, "p" for primary, or "pc" for both. Invalid values fall
+// back to "c".
+func (c *Clipboard) SetOSC52Selections(selections string) {
+	if !validOSC52Selections(selections) {
+		selections = defaultOSC52Selections
+	}
+	c.osc52Selections = selections
+}
+
+// OSC52Selections returns the selections OSC52 copies write to.
+func (c *Clipboard) OSC52Selections() string {
+	if c.osc52Selections == "" {
+		return defaultOSC52Selections
+	}
+	return c.osc52Selections
+}
+
+// validOSC52Selections checks selections against the targets xterm accepts:
+// c (clipboard), p (primary), q (secondary), s (select) and cut buffers 0-7.
+func validOSC52Selections(selections string) bool {
+	if selections == "" {
+		return false
+	}
+	for _, r := range selections {
+		if !strings.ContainsRune("cpqs01234567", r) {
+			return false
+		}
+	}
+	return true
+}
+
 // copyOSC52 copies text using OSC52 escape sequence.
 func (c *Clipboard) copyOSC52(text string) error {
-	seq := osc52.New(text)
-	_, err := io.WriteString(c.output, seq.String())
+	selections := c.OSC52Selections()
+	var seq string
+	if len(selections) == 1 {
+		seq = osc52.New(text).Clipboard(osc52.Clipboard(selections[0])).String()
+	} else {
+		// The osc52 package only models a single target, so build multi-target
+		// sequences directly
+		seq = "\x1b]52;" + selections + ";" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	}
+	_, err := io.WriteString(c.output, seq)
 	return err
 }
 
@@ -0,0 +1,38 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestCopyOSC52Selections(t *testing.T) {
+	payload := base64.StdEncoding.EncodeToString([]byte("hello"))
+
+	tests := []struct {
+		name       string
+		selections string
+		want       string
+	}{
+		{"default clipboard", "", "\x1b]52;c;" + payload + "\x07"},
+		{"clipboard", "c", "\x1b]52;c;" + payload + "\x07"},
+		{"primary", "p", "\x1b]52;p;" + payload + "\x07"},
+		{"primary and clipboard", "pc", "\x1b]52;pc;" + payload + "\x07"},
+		{"invalid falls back to clipboard", "x", "\x1b]52;c;" + payload + "\x07"},
+		{"partly invalid falls back to clipboard", "pz", "\x1b]52;c;" + payload + "\x07"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		c := New(&out)
+		if tt.selections != "" {
+			c.SetOSC52Selections(tt.selections)
+		}
+		if err := c.copyOSC52("hello"); err != nil {
+			t.Fatalf("%s: copyOSC52() error: %v", tt.name, err)
+		}
+		if got := out.String(); got != tt.want {
+			t.Errorf("%s: copyOSC52() wrote %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
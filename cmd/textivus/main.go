@@ -6,6 +6,7 @@ import (
 
 	"github.com/cornish/textivus-editor/config"
 	"github.com/cornish/textivus-editor/editor"
+	"github.com/cornish/textivus-editor/ui"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -17,6 +18,7 @@ func main() {
 	args := os.Args[1:]
 	var filename string
 	asciiMode := false
+	readOnly := false
 
 	// Handle flags
 	for _, arg := range args {
@@ -29,6 +31,8 @@ func main() {
 			os.Exit(0)
 		case "--ascii":
 			asciiMode = true
+		case "--readonly":
+			readOnly = true
 		default:
 			if filename == "" && !isFlag(arg) {
 				filename = arg
@@ -51,6 +55,12 @@ func main() {
 		cfg.Editor.AsciiMode = &t
 	}
 
+	// Honor NO_COLOR and plain output when not writing to a terminal; styles
+	// are built from this, so it comes before the editor
+	if config.GetCapabilities().NoColor {
+		ui.SetColorEnabled(false)
+	}
+
 	// Create editor with config
 	e := editor.NewWithConfig(cfg)
 
@@ -61,6 +71,9 @@ func main() {
 		}
 	}
 
+	// Command-line --readonly blocks edits to every opened file
+	e.SetReadOnly(readOnly)
+
 	// Load file if provided
 	if filename != "" {
 		// Check if file exists
@@ -80,7 +93,14 @@ func main() {
 
 	// Create and run the Bubbletea program
 	p := tea.NewProgram(e, tea.WithAltScreen(), tea.WithMouseAllMotion())
-	if _, err := p.Run(); err != nil {
+	_, err := p.Run()
+	// Give the terminal its own cursor shape back if cursor_style changed it
+	fmt.Print(e.RestoreCursorStyle())
+	// Remember where the cursor was in each open file for next time
+	if err := e.SaveFileMarks(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving file marks: %v\n", err)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running editor: %v\n", err)
 		os.Exit(1)
 	}
@@ -99,6 +119,7 @@ func printHelp() {
 	fmt.Println("  -h, --help     Show this help message")
 	fmt.Println("  -v, --version  Show version information")
 	fmt.Println("  --ascii        Use ASCII characters for dialogs")
+	fmt.Println("  --readonly     Open the file read-only")
 	fmt.Println()
 	fmt.Println("Keyboard Shortcuts:")
 	fmt.Println("  Ctrl+N         New file")
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// AtomicWriteFile writes data to path without ever leaving a partially
+// written file behind: the data goes to a temp file in the same directory,
+// which is synced and then renamed over the target. Like os.WriteFile, perm
+// is used only when the file is created; an existing file keeps its mode.
+// Symlinks are followed so the link itself is preserved.
+func AtomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	return atomicWrite(path, data, perm, false)
+}
+
+// AtomicWriteFileBackup is AtomicWriteFile that first copies the current
+// contents of path, if any, to path~.
+func AtomicWriteFileBackup(path string, data []byte, perm os.FileMode) error {
+	return atomicWrite(path, data, perm, true)
+}
+
+func atomicWrite(path string, data []byte, perm os.FileMode, backup bool) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+		if backup {
+			old, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(path+"~", old, perm); err != nil {
+				return err
+			}
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	// Remove the temp file on any failure; after the rename this is a no-op
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAtomicWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AtomicWriteFile(path, []byte("new contents"), 0644); err != nil {
+		t.Fatalf("AtomicWriteFile: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "new contents" {
+		t.Errorf("contents = %q, want %q", got, "new contents")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want existing mode 0600 kept", info.Mode().Perm())
+	}
+	if _, err := os.Stat(path + "~"); !os.IsNotExist(err) {
+		t.Error("AtomicWriteFile created a backup, want none")
+	}
+	assertOnlyFiles(t, dir, "config.toml")
+}
+
+func TestAtomicWriteFileFailureLeavesTarget(t *testing.T) {
+	dir := t.TempDir()
+	// A non-empty directory cannot be renamed over, so the final step fails
+	path := filepath.Join(dir, "target")
+	if err := os.MkdirAll(filepath.Join(path, "keep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AtomicWriteFile(path, []byte("data"), 0644); err == nil {
+		t.Fatal("AtomicWriteFile over a directory succeeded, want error")
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		t.Error("target was replaced after a failed write")
+	}
+	// The temp file must not be left behind
+	assertOnlyFiles(t, dir, "target")
+}
+
+func TestAtomicWriteFileBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+
+	// No backup for a brand new file
+	if err := AtomicWriteFileBackup(path, []byte("first"), 0644); err != nil {
+		t.Fatalf("AtomicWriteFileBackup: %v", err)
+	}
+	if _, err := os.Stat(path + "~"); !os.IsNotExist(err) {
+		t.Error("backup created for a new file")
+	}
+
+	if err := AtomicWriteFileBackup(path, []byte("second"), 0644); err != nil {
+		t.Fatalf("AtomicWriteFileBackup: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "second" {
+		t.Errorf("contents = %q, want %q", got, "second")
+	}
+	if got, _ := os.ReadFile(path + "~"); string(got) != "first" {
+		t.Errorf("backup = %q, want prior contents %q", got, "first")
+	}
+}
+
+func TestAtomicWriteFileFollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "real.txt")
+	link := filepath.Join(dir, "link.txt")
+	if err := os.WriteFile(real, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(real, link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	if err := AtomicWriteFile(link, []byte("new"), 0644); err != nil {
+		t.Fatalf("AtomicWriteFile: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("symlink was replaced by a regular file")
+	}
+	if got, _ := os.ReadFile(real); string(got) != "new" {
+		t.Errorf("link target contents = %q, want %q", got, "new")
+	}
+}
+
+// assertOnlyFiles fails unless dir contains exactly the named entries.
+func assertOnlyFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	if !slices.Equal(got, names) {
+		t.Errorf("directory holds %v, want %v", got, names)
+	}
+}
@@ -17,8 +17,10 @@ const (
 // TermCapabilities holds detected terminal capabilities
 type TermCapabilities struct {
 	UTF8Support   bool      // Terminal supports UTF-8
+	LimitedGlyphs bool      // TERM names a terminal without box-drawing glyphs
 	ColorMode     ColorMode // Color capability level
 	KittyGraphics bool      // Kitty graphics protocol support
+	NoColor       bool      // NO_COLOR is set or stdout is not a terminal
 }
 
 // String returns a human-readable description of the color mode
@@ -39,8 +41,10 @@ func (c ColorMode) String() string {
 func DetectCapabilities() *TermCapabilities {
 	caps := &TermCapabilities{
 		UTF8Support:   detectUTF8Support(),
+		LimitedGlyphs: isLimitedTerm(os.Getenv("TERM")),
 		ColorMode:     detectColorMode(),
 		KittyGraphics: detectKittyGraphics(),
+		NoColor:       detectNoColor(),
 	}
 	return caps
 }
@@ -59,6 +63,21 @@ func detectUTF8Support() bool {
 	return false
 }
 
+// limitedTerms are TERM values for consoles and hardware terminals whose
+// fonts lack Unicode box-drawing characters even with a UTF-8 locale
+var limitedTerms = []string{"dumb", "linux", "vt100", "vt102", "vt220", "ansi", "cons25"}
+
+// isLimitedTerm reports whether term names a terminal with limited glyphs
+func isLimitedTerm(term string) bool {
+	term = strings.ToLower(term)
+	for _, t := range limitedTerms {
+		if term == t || strings.HasPrefix(term, t+"-") {
+			return true
+		}
+	}
+	return false
+}
+
 // detectColorMode detects the terminal's color capability
 func detectColorMode() ColorMode {
 	// Check COLORTERM for truecolor support
@@ -96,13 +115,26 @@ func detectKittyGraphics() bool {
 	return os.Getenv("KITTY_WINDOW_ID") != ""
 }
 
+// detectNoColor checks whether color output should be suppressed, following
+// the NO_COLOR convention (any non-empty value) or when stdout is not a terminal
+func detectNoColor() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return true
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
 // ShouldUseASCII returns true if ASCII mode should be used based on capabilities
 // Takes into account both auto-detection and user override
 func (c *TermCapabilities) ShouldUseASCII(override *bool) bool {
 	if override != nil {
 		return *override
 	}
-	return !c.UTF8Support
+	return !c.UTF8Support || c.LimitedGlyphs
 }
 
 // ShouldUseTrueColor returns true if TrueColor should be used based on capabilities
@@ -114,6 +146,36 @@ func (c *TermCapabilities) ShouldUseTrueColor(override *bool) bool {
 	return c.ColorMode == ColorTrueColor
 }
 
+// ParseColorMode parses a color_mode setting: "truecolor" (or "24bit"),
+// "256" or "16". Returns false for "auto", empty and unknown values.
+func ParseColorMode(s string) (ColorMode, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "truecolor", "24bit":
+		return ColorTrueColor, true
+	case "256":
+		return Color256, true
+	case "16":
+		return Color16, true
+	}
+	return Color16, false
+}
+
+// ResolveColorMode picks the color depth to render with. An explicit
+// color_mode wins, then the legacy true_color switch (false meaning 256
+// colors), then the detected capability.
+func (c *TermCapabilities) ResolveColorMode(mode string, trueColor *bool) ColorMode {
+	if m, ok := ParseColorMode(mode); ok {
+		return m
+	}
+	if trueColor != nil {
+		if *trueColor {
+			return ColorTrueColor
+		}
+		return Color256
+	}
+	return c.ColorMode
+}
+
 // GlobalCapabilities holds the detected capabilities (set at startup)
 var GlobalCapabilities *TermCapabilities
 
@@ -50,6 +50,28 @@ func TestShouldUseASCII(t *testing.T) {
 	}
 }
 
+func TestLimitedTermUsesASCII(t *testing.T) {
+	tests := []struct {
+		term string
+		want bool
+	}{
+		{"linux", true},
+		{"vt100", true},
+		{"vt220-am", true},
+		{"dumb", true},
+		{"xterm-256color", false},
+		{"screen", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		caps := &TermCapabilities{UTF8Support: true, LimitedGlyphs: isLimitedTerm(tt.term)}
+		if got := caps.ShouldUseASCII(nil); got != tt.want {
+			t.Errorf("TERM=%q: ShouldUseASCII() = %v, want %v", tt.term, got, tt.want)
+		}
+	}
+}
+
 func TestShouldUseTrueColor(t *testing.T) {
 	trueVal := true
 	falseVal := false
@@ -117,3 +139,34 @@ func TestDetectCapabilities(t *testing.T) {
 		t.Errorf("DetectCapabilities().ColorMode = %d, out of valid range", caps.ColorMode)
 	}
 }
+
+func TestResolveColorMode(t *testing.T) {
+	trueVal := true
+	falseVal := false
+
+	tests := []struct {
+		name      string
+		detected  ColorMode
+		mode      string
+		trueColor *bool
+		want      ColorMode
+	}{
+		{"auto uses detection", Color256, "auto", nil, Color256},
+		{"empty uses detection", Color16, "", nil, Color16},
+		{"explicit 16", ColorTrueColor, "16", nil, Color16},
+		{"explicit truecolor", Color16, "TrueColor", nil, ColorTrueColor},
+		{"explicit wins over true_color", ColorTrueColor, "256", &trueVal, Color256},
+		{"true_color false means 256", ColorTrueColor, "", &falseVal, Color256},
+		{"true_color true", Color16, "", &trueVal, ColorTrueColor},
+		{"unknown mode uses detection", Color256, "lots", nil, Color256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := &TermCapabilities{ColorMode: tt.detected}
+			if got := caps.ResolveColorMode(tt.mode, tt.trueColor); got != tt.want {
+				t.Errorf("ResolveColorMode(%q) = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
+	"github.com/cornish/textivus-editor/editorconfig"
 )
 
 // configDirName is the name of the config directory
@@ -116,6 +119,33 @@ type Config struct {
 	RecentDirs    []string     `toml:"recent_dirs,omitempty"`    // Recently visited directories (max 10)
 	FavoriteFiles []string     `toml:"favorite_files,omitempty"` // User-favorited files (max 50)
 	FavoriteDirs  []string     `toml:"favorite_dirs,omitempty"`  // User-favorited directories (max 50)
+
+	// Language holds per-language settings keyed by lowercase lexer name,
+	// e.g. [language.rust.pairs]
+	Language map[string]LanguageConfig `toml:"language,omitempty"`
+}
+
+// LanguageConfig holds settings for one language
+type LanguageConfig struct {
+	// Pairs maps an opening character to the closing character auto-close
+	// inserts for it. An empty closing character turns pairing off for
+	// that opener. Entries are merged over the built-in pairs.
+	Pairs map[string]string `toml:"pairs,omitempty"`
+
+	// PairInStrings lists openers that still pair inside string literals
+	PairInStrings []string `toml:"pair_in_strings,omitempty"`
+}
+
+// LanguageSettings returns the settings for language, matched
+// case-insensitively, or nil if there are none.
+func (c *Config) LanguageSettings(language string) *LanguageConfig {
+	if c == nil {
+		return nil
+	}
+	if lc, ok := c.Language[strings.ToLower(language)]; ok {
+		return &lc
+	}
+	return nil
 }
 
 // MaxRecentFiles is the maximum number of recent files to track
@@ -152,6 +182,38 @@ func (c *Config) AddRecentFile(path string) {
 	}
 }
 
+// RemoveRecentFile removes a file from the recent files list
+func (c *Config) RemoveRecentFile(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	for i, f := range c.RecentFiles {
+		if f == absPath {
+			c.RecentFiles = append(c.RecentFiles[:i], c.RecentFiles[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// PruneRecentFiles drops recent files that no longer exist on disk.
+// Returns true if any were removed.
+func (c *Config) PruneRecentFiles() bool {
+	valid := make([]string, 0, len(c.RecentFiles))
+	for _, path := range c.RecentFiles {
+		if _, err := os.Stat(path); err == nil {
+			valid = append(valid, path)
+		}
+	}
+	if len(valid) == len(c.RecentFiles) {
+		return false
+	}
+	c.RecentFiles = valid
+	return true
+}
+
 // AddRecentDir adds a directory to the recent directories list
 func (c *Config) AddRecentDir(path string) {
 	// Make path absolute
@@ -305,7 +367,7 @@ type EditorConfig struct {
 	WordWrap        bool  `toml:"word_wrap"`
 	LineNumbers     bool  `toml:"line_numbers"`
 	SyntaxHighlight bool  `toml:"syntax_highlight"`
-	TrueColor       *bool `toml:"true_color"`     // nil = auto (true), false = force 256-color
+	TrueColor       *bool `toml:"true_color"`     // nil = detected depth, false = force 256-color; color_mode overrides
 	AsciiMode       *bool `toml:"ascii_mode"`     // nil = auto-detect, true/false = override
 	BackupCount     int   `toml:"backup_count"`   // 0=disabled, 1=filename~, >1=filename~1~ through filename~N~
 	Scrollbar       bool  `toml:"scrollbar"`      // Show scrollbar
@@ -313,12 +375,121 @@ type EditorConfig struct {
 	MaxBuffers      int   `toml:"max_buffers"`    // Maximum open buffers (0=unlimited, default 20)
 	TabWidth        int   `toml:"tab_width"`      // Display width of tabs (default 4)
 	TabsToSpaces    bool  `toml:"tabs_to_spaces"` // Insert spaces instead of tab characters
+	IndentSize      int   `toml:"indent_size"`    // Columns per indentation level with spaces (0 = tab_width)
+	ScrollOff       int   `toml:"scroll_off"`     // Lines of context kept above/below the cursor
+
+	ScrollbarTrack string `toml:"scrollbar_track"` // Scrollbar track glyph, one cell wide (default ░)
+	ScrollbarThumb string `toml:"scrollbar_thumb"` // Scrollbar thumb glyph, one cell wide (default █)
+
+	MinimapDensity int `toml:"minimap_density"` // Non-blank characters in a 5-column span that light a minimap dot, 1-5 (0 = 3)
+
+	LineNumberRadix int `toml:"line_number_radix"` // Base for line numbers: 10, 16 (hex) or 8 (octal); 0 = 10
+
+	TrimTrailingWhitespace bool `toml:"trim_trailing_whitespace"` // Strip trailing spaces/tabs on save and from lines left with Enter
+	InsertFinalNewline     bool `toml:"insert_final_newline"`     // Ensure a trailing newline on save
+	AutoIndent             bool `toml:"auto_indent"`              // Carry indentation onto new lines
+	AutoClose              bool `toml:"auto_close"`               // Insert matching brackets and quotes
+	ModifiedGutter         bool `toml:"modified_gutter"`          // Mark lines changed since the last save
+	HighlightActiveGutter  bool `toml:"highlight_active_gutter"`  // Paint the cursor line's gutter background (theme gutter_active_bg)
+
+	ColorMode string `toml:"color_mode"` // "auto" (detect), "truecolor", "256" or "16"; overrides true_color
+	BoxStyle  string `toml:"box_style"`  // Dialog borders: "single" (default), "double" or "rounded"; ASCII mode always uses +-|
+	ThemeFile string `toml:"theme_file"` // Standalone theme file used instead of [theme] name when set
+
+	CommentMarkers []string `toml:"comment_markers,omitempty"` // Keywords highlighted in comments (unset = TODO, FIXME, HACK, XXX, NOTE)
+
+	MaxHighlightLineLength int `toml:"max_highlight_line_length"` // Lines longer than this many bytes are drawn without syntax colors (0 = 10000)
+
+	RainbowBrackets bool     `toml:"rainbow_brackets"`          // Color brackets by nesting depth
+	RainbowPalette  []string `toml:"rainbow_palette,omitempty"` // Colors cycled through by depth (unset = gold, orchid, blue)
+
+	LintTrailingWhitespace bool `toml:"lint_trailing_whitespace"` // Flag lines ending in spaces or tabs
+	LintMixedIndent        bool `toml:"lint_mixed_indent"`        // Flag indentation mixing tabs and spaces
+	LintFinalNewline       bool `toml:"lint_final_newline"`       // Flag a last line without a newline
+
+	VirtualSpace bool `toml:"virtual_space"` // Let the cursor move past the end of lines (without word wrap)
+
+	HighlightOccurrences bool `toml:"highlight_occurrences"` // Mark other visible occurrences of the word under the cursor
+	IndentGuides         bool `toml:"indent_guides"`         // Draw guides in indentation, brightest for the cursor's block
+
+	EOLMarker         string `toml:"eol_marker"`           // Glyph drawn after the end of each line, e.g. ¶ or $ (empty = none)
+	EndOfBufferMarker bool   `toml:"end_of_buffer_marker"` // Mark rows past the last line with ~
+
+	EndOfLine string `toml:"end_of_line"` // Line ending written on save: lf, crlf or cr (empty = keep the file's own)
+
+	CursorStyle      string            `toml:"cursor_style"`                 // Cursor shape: block, bar, underline, blinking-*, or default
+	CursorStyleModes map[string]string `toml:"cursor_style_modes,omitempty"` // Per-mode overrides keyed by mode: normal, find, prompt, dialog
+}
+
+// CursorStyleFor returns the cursor_style setting for mode, falling back
+// to the general cursor_style when the mode has no override.
+func (ec EditorConfig) CursorStyleFor(mode string) string {
+	if style, ok := ec.CursorStyleModes[mode]; ok {
+		return style
+	}
+	return ec.CursorStyle
+}
+
+// IndentWidth returns the columns one level of indentation takes when
+// indenting with spaces: indent_size, or the tab width when that is unset.
+func (ec EditorConfig) IndentWidth() int {
+	if ec.IndentSize > 0 {
+		return ec.IndentSize
+	}
+	if ec.TabWidth > 0 {
+		return ec.TabWidth
+	}
+	return 4
+}
+
+// WithEditorConfig returns ec with the properties set in s applied.
+// indent_style maps to TabsToSpaces, and tab_width and indent_size to the
+// settings of the same names; the parser already falls back to indent_size
+// when tab_width is unset.
+func (ec EditorConfig) WithEditorConfig(s editorconfig.Settings) EditorConfig {
+	switch s.IndentStyle {
+	case "tab":
+		ec.TabsToSpaces = false
+	case "space":
+		ec.TabsToSpaces = true
+	}
+	if s.TabWidth > 0 {
+		ec.TabWidth = s.TabWidth
+	}
+	if s.IndentSize > 0 {
+		ec.IndentSize = s.IndentSize
+	}
+	if s.EndOfLine != "" {
+		ec.EndOfLine = s.EndOfLine
+	}
+	if s.InsertFinalNewline != nil {
+		ec.InsertFinalNewline = *s.InsertFinalNewline
+	}
+	if s.TrimTrailingWhitespace != nil {
+		ec.TrimTrailingWhitespace = *s.TrimTrailingWhitespace
+	}
+	return ec
+}
+
+// EffectiveConfig returns the editor settings for filename: the configured
+// ones overridden by any .editorconfig files that apply to it.
+func (c *Config) EffectiveConfig(filename string) (EditorConfig, error) {
+	s, err := editorconfig.Resolve(filename)
+	if err != nil {
+		return c.Editor, err
+	}
+	return c.Editor.WithEditorConfig(s), nil
 }
 
 // ThemeConfig holds the theme reference in the main config
 // Just references a theme by name - the actual colors come from theme files
 type ThemeConfig struct {
 	Name string `toml:"name"` // Theme name (built-in or from themes/ directory)
+
+	// Syntax overrides individual token colors of the theme, keyed by
+	// category: keyword, string, comment, number, function, type, operator,
+	// marker
+	Syntax map[string]string `toml:"syntax,omitempty"`
 }
 
 // DefaultConfig returns the default configuration
@@ -331,6 +502,10 @@ func DefaultConfig() *Config {
 			MaxBuffers:      20,    // Default max open buffers
 			TabWidth:        4,     // Default tab width
 			TabsToSpaces:    false, // Use real tabs by default
+			AutoIndent:      true,  // Keep indentation when pressing Enter
+
+			HighlightOccurrences: true,
+			EndOfBufferMarker:    true,
 		},
 		Theme: ThemeConfig{
 			Name: "default",
@@ -365,6 +540,19 @@ func ThemesDir() (string, error) {
 	return filepath.Join(configDir, configDirName, "themes"), nil
 }
 
+// QuotesPath returns the path to the user's About dialog quotes file
+func QuotesPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, configDirName, "quotes.txt")
+}
+
 // ConfigLoadError holds details about a config loading error
 type ConfigLoadError struct {
 	FilePath string
@@ -395,6 +583,7 @@ func Load() (*Config, error) {
 	if _, err := toml.DecodeFile(path, cfg); err != nil {
 		return cfg, &ConfigLoadError{FilePath: path, Err: err}
 	}
+	cfg.PruneRecentFiles()
 
 	return cfg, nil
 }
@@ -412,22 +601,35 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	// Create/overwrite the file
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
 	// Write header comment
-	f.WriteString("# Textivus configuration\n\n")
+	var buf bytes.Buffer
+	buf.WriteString("# Textivus configuration\n\n")
 
 	// Encode config as TOML
-	encoder := toml.NewEncoder(f)
-	return encoder.Encode(c)
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return err
+	}
+
+	// Replace the file atomically so a crash never leaves it truncated
+	return AtomicWriteFile(path, buf.Bytes(), 0644)
 }
 
 // GetResolved loads and returns the complete theme
 func (t *ThemeConfig) GetResolved() Theme {
 	return LoadTheme(t.Name)
 }
+
+// ResolvedTheme returns the theme to use: the editor's theme_file when one
+// is set, else the theme named in [theme]. If the theme file can't be
+// loaded, the named theme is returned along with the error. A user theme
+// with invalid colors is returned with an *InvalidColorsError.
+func (c *Config) ResolvedTheme() (Theme, error) {
+	if c.Editor.ThemeFile != "" {
+		theme, err := LoadThemeFile(c.Editor.ThemeFile)
+		if err == nil {
+			return theme, nil
+		}
+		return c.Theme.GetResolved(), err
+	}
+	return LoadThemeChecked(c.Theme.Name)
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/BurntSushi/toml"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -75,6 +78,51 @@ func TestAddRecentFileMaxLimit(t *testing.T) {
 	}
 }
 
+func TestRemoveRecentFile(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AddRecentFile("/path/to/a.txt")
+	cfg.AddRecentFile("/path/to/b.txt")
+	cfg.AddRecentFile("/path/to/c.txt")
+
+	if !cfg.RemoveRecentFile("/path/to/b.txt") {
+		t.Fatal("RemoveRecentFile(b.txt) = false, want true")
+	}
+	if cfg.RemoveRecentFile("/path/to/b.txt") {
+		t.Error("RemoveRecentFile(b.txt) again = true, want false")
+	}
+	want := []string{"c.txt", "a.txt"}
+	if len(cfg.RecentFiles) != len(want) {
+		t.Fatalf("RecentFiles = %v, want %v", cfg.RecentFiles, want)
+	}
+	for i, name := range want {
+		if filepath.Base(cfg.RecentFiles[i]) != name {
+			t.Errorf("RecentFiles[%d] = %q, want %s", i, cfg.RecentFiles[i], name)
+		}
+	}
+}
+
+func TestPruneRecentFiles(t *testing.T) {
+	dir := t.TempDir()
+	keep := filepath.Join(dir, "keep.txt")
+	if err := os.WriteFile(keep, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.AddRecentFile(keep)
+	cfg.AddRecentFile(filepath.Join(dir, "missing.txt"))
+
+	if !cfg.PruneRecentFiles() {
+		t.Error("PruneRecentFiles() = false, want true")
+	}
+	if len(cfg.RecentFiles) != 1 || cfg.RecentFiles[0] != keep {
+		t.Errorf("RecentFiles = %v, want [%s]", cfg.RecentFiles, keep)
+	}
+	if cfg.PruneRecentFiles() {
+		t.Error("PruneRecentFiles() with nothing missing = true, want false")
+	}
+}
+
 func TestAddRecentDir(t *testing.T) {
 	cfg := DefaultConfig()
 
@@ -282,3 +330,67 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestThemeSyntaxOverrides(t *testing.T) {
+	cfg := DefaultConfig()
+	data := `
+[theme]
+name = "default"
+
+[theme.syntax]
+comment = "#ff0000"
+keyword = "12"
+`
+	if _, err := toml.Decode(data, cfg); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	want := map[string]string{"comment": "#ff0000", "keyword": "12"}
+	if len(cfg.Theme.Syntax) != len(want) {
+		t.Fatalf("Theme.Syntax = %v, want %v", cfg.Theme.Syntax, want)
+	}
+	for k, v := range want {
+		if cfg.Theme.Syntax[k] != v {
+			t.Errorf("Theme.Syntax[%q] = %q, want %q", k, cfg.Theme.Syntax[k], v)
+		}
+	}
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	dir := t.TempDir()
+	ec := "root = true\n[*.py]\nindent_style = space\nindent_size = 2\ntrim_trailing_whitespace = true\n"
+	if err := os.WriteFile(filepath.Join(dir, ".editorconfig"), []byte(ec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+
+	got, err := cfg.EffectiveConfig(filepath.Join(dir, "app.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.TabsToSpaces || got.TabWidth != 2 || !got.TrimTrailingWhitespace {
+		t.Errorf("app.py: TabsToSpaces=%v TabWidth=%d Trim=%v, want true 2 true",
+			got.TabsToSpaces, got.TabWidth, got.TrimTrailingWhitespace)
+	}
+
+	// tab_width sets the tab width and indent_size the indent level
+	ec = "root = true\n[*.c]\ntab_width = 8\nindent_style = space\nindent_size = 2\nend_of_line = crlf\n"
+	if err := os.WriteFile(filepath.Join(dir, ".editorconfig"), []byte(ec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = cfg.EffectiveConfig(filepath.Join(dir, "lib.c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TabWidth != 8 || got.IndentWidth() != 2 || got.EndOfLine != "crlf" {
+		t.Errorf("lib.c: TabWidth=%d IndentWidth=%d EndOfLine=%q, want 8 2 crlf",
+			got.TabWidth, got.IndentWidth(), got.EndOfLine)
+	}
+
+	got, err = cfg.EffectiveConfig(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cfg.Editor) {
+		t.Errorf("main.go: settings changed by a section that does not match it")
+	}
+}
@@ -32,6 +32,9 @@ type KeybindingsConfig struct {
 	Copy      KeyBinding `toml:"copy"`
 	Paste     KeyBinding `toml:"paste"`
 	CutLine   KeyBinding `toml:"cut_line"`
+	Duplicate KeyBinding `toml:"duplicate"`
+	JoinLines KeyBinding `toml:"join_lines"`
+	CycleCase KeyBinding `toml:"cycle_case"`
 	SelectAll KeyBinding `toml:"select_all"`
 
 	// Search operations
@@ -76,6 +79,9 @@ func DefaultKeybindings() *KeybindingsConfig {
 		Copy:      KeyBinding{Primary: "ctrl+c"},
 		Paste:     KeyBinding{Primary: "ctrl+v"},
 		CutLine:   KeyBinding{Primary: "ctrl+k"},
+		Duplicate: KeyBinding{Primary: "ctrl+d"},
+		JoinLines: KeyBinding{Primary: "alt+j"},
+		CycleCase: KeyBinding{Primary: "alt+u"},
 		SelectAll: KeyBinding{Primary: "ctrl+a"},
 
 		// Search operations
@@ -117,6 +123,9 @@ var ActionNames = map[string]string{
 	"copy":                "Copy",
 	"paste":               "Paste",
 	"cut_line":            "Cut Line",
+	"duplicate":           "Duplicate",
+	"join_lines":          "Join Lines",
+	"cycle_case":          "Change Case",
 	"select_all":          "Select All",
 	"find":                "Find",
 	"find_next":           "Find Next",
@@ -220,6 +229,12 @@ func (kb *KeybindingsConfig) GetBinding(action string) KeyBinding {
 		return kb.Paste
 	case "cut_line":
 		return kb.CutLine
+	case "duplicate":
+		return kb.Duplicate
+	case "join_lines":
+		return kb.JoinLines
+	case "cycle_case":
+		return kb.CycleCase
 	case "select_all":
 		return kb.SelectAll
 	case "find":
@@ -279,6 +294,12 @@ func (kb *KeybindingsConfig) SetBinding(action string, binding KeyBinding) {
 		kb.Paste = binding
 	case "cut_line":
 		kb.CutLine = binding
+	case "duplicate":
+		kb.Duplicate = binding
+	case "join_lines":
+		kb.JoinLines = binding
+	case "cycle_case":
+		kb.CycleCase = binding
 	case "select_all":
 		kb.SelectAll = binding
 	case "find":
@@ -312,7 +333,7 @@ func (kb *KeybindingsConfig) SetBinding(action string, binding KeyBinding) {
 func AllActions() []string {
 	return []string{
 		"new", "open", "save", "save_as", "close", "recent_files", "quit",
-		"undo", "redo", "cut", "copy", "paste", "cut_line", "select_all",
+		"undo", "redo", "cut", "copy", "paste", "cut_line", "duplicate", "join_lines", "cycle_case", "select_all",
 		"find", "find_next", "replace", "goto_line",
 		"word_left", "word_right", "doc_start", "doc_end",
 		"next_buffer", "prev_buffer",
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
@@ -50,6 +55,11 @@ type UIColors struct {
 	// Minimap colors
 	MinimapIndicator string `toml:"minimap_indicator"` // Viewport indicator color
 	MinimapText      string `toml:"minimap_text"`      // Braille text color
+	// Gutter colors
+	ModifiedLine   string `toml:"modified_line"`    // Unsaved-change bar color
+	GutterActiveBg string `toml:"gutter_active_bg"` // Cursor line's gutter background (highlight_active_gutter)
+	// Text column markers
+	NonText string `toml:"non_text"` // End-of-line and end-of-buffer marker color
 }
 
 // SyntaxColors holds syntax highlighting color settings
@@ -93,6 +103,9 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "6",  // Cyan
 			MinimapIndicator: "6",  // Cyan
 			MinimapText:      "8",  // Gray
+			ModifiedLine:     "10", // Bright green
+			GutterActiveBg:   "4",  // Dark blue
+			NonText:          "8",  // Gray
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "14", // Bright cyan
@@ -132,6 +145,9 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "43",  // Teal
 			MinimapIndicator: "43",  // Teal
 			MinimapText:      "245", // Gray
+			ModifiedLine:     "114", // Green
+			GutterActiveBg:   "236", // Dark gray
+			NonText:          "240", // Medium gray
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "176", // Purple
@@ -171,6 +187,9 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "32",  // Blue
 			MinimapIndicator: "32",  // Blue
 			MinimapText:      "245", // Gray
+			ModifiedLine:     "28",  // Green
+			GutterActiveBg:   "254", // Very light gray
+			NonText:          "249", // Medium gray
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "26",  // Blue
@@ -210,6 +229,9 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "208", // Orange
 			MinimapIndicator: "208", // Orange
 			MinimapText:      "59",  // Gray
+			ModifiedLine:     "148", // Green
+			GutterActiveBg:   "237", // Dark gray
+			NonText:          "59",  // Gray
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "197", // Pink-red
@@ -249,6 +271,9 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "#5E81AC", // nord10
 			MinimapIndicator: "#88C0D0", // nord8
 			MinimapText:      "#4C566A", // nord3
+			ModifiedLine:     "#EBCB8B", // nord13
+			GutterActiveBg:   "#3B4252", // nord1
+			NonText:          "#4C566A", // nord3
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "#81A1C1", // nord9
@@ -288,6 +313,9 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "#BD93F9", // purple
 			MinimapIndicator: "#BD93F9", // purple
 			MinimapText:      "#6272A4", // comment
+			ModifiedLine:     "#50FA7B", // green
+			GutterActiveBg:   "#44475A", // current line
+			NonText:          "#6272A4", // comment
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "#FF79C6", // pink
@@ -327,6 +355,9 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "#D79921", // yellow
 			MinimapIndicator: "#D79921", // yellow
 			MinimapText:      "#665C54", // bg3
+			ModifiedLine:     "#B8BB26", // bright green
+			GutterActiveBg:   "#3C3836", // bg1
+			NonText:          "#665C54", // bg3
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "#FB4934", // bright red
@@ -366,6 +397,9 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "#268BD2", // blue
 			MinimapIndicator: "#2AA198", // cyan
 			MinimapText:      "#586E75", // base01
+			ModifiedLine:     "#B58900", // yellow
+			GutterActiveBg:   "#073642", // base02
+			NonText:          "#586E75", // base01
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "#859900", // green
@@ -405,6 +439,9 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "#CBA6F7", // mauve
 			MinimapIndicator: "#F5C2E7", // pink
 			MinimapText:      "#6C7086", // overlay0
+			ModifiedLine:     "#A6E3A1", // green
+			GutterActiveBg:   "#313244", // surface0
+			NonText:          "#6C7086", // overlay0
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "#CBA6F7", // mauve
@@ -423,29 +460,59 @@ func DefaultTheme() Theme {
 	return builtinThemes["default"]
 }
 
+// BuiltinTheme returns the built-in theme with the given name
+func BuiltinTheme(name string) (Theme, bool) {
+	theme, ok := builtinThemes[name]
+	return theme, ok
+}
+
 // LoadTheme loads a theme by name
-// Checks user themes directory first, then falls back to built-in themes
+// Checks user themes directory first, then falls back to built-in themes.
+// Invalid colors in a user theme are replaced with the defaults; use
+// LoadThemeChecked to hear about them.
 func LoadTheme(name string) Theme {
+	theme, _ := LoadThemeChecked(name)
+	return theme
+}
+
+// LoadThemeChecked is LoadTheme that also returns an *InvalidColorsError
+// when the user's theme file had colors it replaced with the defaults. The
+// theme is usable either way.
+func LoadThemeChecked(name string) (Theme, error) {
 	if name == "" {
-		return DefaultTheme()
+		return DefaultTheme(), nil
 	}
 
 	// Try loading from user themes directory
 	theme, err := loadUserTheme(name)
-	if err == nil {
-		return theme
+	var invalid *InvalidColorsError
+	if err == nil || errors.As(err, &invalid) {
+		return theme, err
 	}
 
 	// Fall back to built-in theme
-	if builtin, ok := builtinThemes[name]; ok {
-		return builtin
+	if builtin, ok := BuiltinTheme(name); ok {
+		return builtin, nil
 	}
 
 	// Default if not found
-	return DefaultTheme()
+	return DefaultTheme(), nil
+}
+
+// InvalidColorsError reports colors in a user theme that were not valid
+// and were replaced with the defaults.
+type InvalidColorsError struct {
+	Path   string
+	Colors []string // Each as its TOML key and the value found
+}
+
+func (e *InvalidColorsError) Error() string {
+	return fmt.Sprintf("%s: invalid colors replaced with defaults: %s", e.Path, strings.Join(e.Colors, ", "))
 }
 
-// loadUserTheme attempts to load a theme from the user's themes directory
+// loadUserTheme attempts to load a theme from the user's themes directory.
+// Themes there predate color checking, so an invalid color doesn't reject
+// the theme: it gets the default color and an *InvalidColorsError names it.
 func loadUserTheme(name string) (Theme, error) {
 	themesDir, err := ThemesDir()
 	if err != nil {
@@ -461,11 +528,81 @@ func loadUserTheme(name string) (Theme, error) {
 	if _, err := toml.DecodeFile(themePath, &theme); err != nil {
 		return Theme{}, err
 	}
+	var invalid []string
+	for _, group := range []interface{}{&theme.UI, &theme.Syntax} {
+		invalid = append(invalid, clearInvalidColors(group)...)
+	}
+
+	// Merge with default theme to fill in any missing values
+	theme = mergeWithDefault(theme)
+	if len(invalid) > 0 {
+		return theme, &InvalidColorsError{Path: themePath, Colors: invalid}
+	}
+	return theme, nil
+}
+
+// LoadThemeFile parses a standalone theme file: a TOML document with the
+// theme's name, description and author and its [ui] and [syntax] colors.
+// Each color is a 0-255 palette index or a #RGB or #RRGGBB hex value; an
+// invalid one is an error. Omitted colors are taken from the built-in
+// theme of the same name, or the default theme.
+func LoadThemeFile(path string) (Theme, error) {
+	var theme Theme
+	if _, err := toml.DecodeFile(path, &theme); err != nil {
+		return Theme{}, err
+	}
+	for _, group := range []interface{}{theme.UI, theme.Syntax} {
+		if err := validateColors(group); err != nil {
+			return Theme{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
 
 	// Merge with default theme to fill in any missing values
 	return mergeWithDefault(theme), nil
 }
 
+// validateColors checks every color field of a UIColors or SyntaxColors
+// value, naming the first invalid one by its TOML key.
+func validateColors(group interface{}) error {
+	v := reflect.ValueOf(group)
+	for i := 0; i < v.NumField(); i++ {
+		color := v.Field(i).String()
+		if color != "" && !validColor(color) {
+			return fmt.Errorf("%s: invalid color %q", v.Type().Field(i).Tag.Get("toml"), color)
+		}
+	}
+	return nil
+}
+
+// clearInvalidColors empties every invalid color field of the UIColors or
+// SyntaxColors group points to, so mergeWithDefault fills it in, and
+// returns each one's TOML key and former value.
+func clearInvalidColors(group interface{}) []string {
+	v := reflect.ValueOf(group).Elem()
+	var cleared []string
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if color := f.String(); f.Kind() == reflect.String && color != "" && !validColor(color) {
+			cleared = append(cleared, fmt.Sprintf("%s %q", v.Type().Field(i).Tag.Get("toml"), color))
+			f.SetString("")
+		}
+	}
+	return cleared
+}
+
+// validColor reports whether color is a palette index or hex color.
+func validColor(color string) bool {
+	if hex, ok := strings.CutPrefix(color, "#"); ok {
+		if len(hex) != 3 && len(hex) != 6 {
+			return false
+		}
+		_, err := strconv.ParseUint(hex, 16, 32)
+		return err == nil
+	}
+	n, err := strconv.Atoi(color)
+	return err == nil && n >= 0 && n <= 255
+}
+
 // mergeWithDefault fills in any missing theme values with defaults
 // If a built-in theme with the same name exists, use it for defaults
 func mergeWithDefault(theme Theme) Theme {
@@ -549,6 +686,15 @@ func mergeWithDefault(theme Theme) Theme {
 	if theme.UI.MinimapText == "" {
 		theme.UI.MinimapText = def.UI.MinimapText
 	}
+	if theme.UI.ModifiedLine == "" {
+		theme.UI.ModifiedLine = def.UI.ModifiedLine
+	}
+	if theme.UI.GutterActiveBg == "" {
+		theme.UI.GutterActiveBg = def.UI.GutterActiveBg
+	}
+	if theme.UI.NonText == "" {
+		theme.UI.NonText = def.UI.NonText
+	}
 
 	// Syntax colors
 	if theme.Syntax.Keyword == "" {
@@ -651,7 +797,7 @@ func GetTheme(name string) Theme {
 	}
 
 	// Fall back to built-in
-	if builtin, ok := builtinThemes[name]; ok {
+	if builtin, ok := BuiltinTheme(name); ok {
 		return builtin
 	}
 
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+// emptyFields returns the names of string fields left empty in v.
+func emptyFields(v interface{}) []string {
+	var empty []string
+	rv := reflect.ValueOf(v)
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Field(i)
+		if f.Kind() == reflect.String && f.String() == "" {
+			empty = append(empty, rv.Type().Field(i).Name)
+		}
+	}
+	return empty
+}
+
+func TestBuiltinThemesFullyPopulated(t *testing.T) {
+	for _, name := range ThemeNames() {
+		theme, ok := BuiltinTheme(name)
+		if !ok {
+			t.Errorf("BuiltinTheme(%q) not found", name)
+			continue
+		}
+		if theme.Name != name {
+			t.Errorf("BuiltinTheme(%q).Name = %q", name, theme.Name)
+		}
+		if empty := emptyFields(theme.UI); len(empty) > 0 {
+			t.Errorf("theme %q has empty UI colors: %v", name, empty)
+		}
+		if empty := emptyFields(theme.Syntax); len(empty) > 0 {
+			t.Errorf("theme %q has empty syntax colors: %v", name, empty)
+		}
+	}
+}
+
+func TestBuiltinThemeUnknown(t *testing.T) {
+	if _, ok := BuiltinTheme("no-such-theme"); ok {
+		t.Error("BuiltinTheme(\"no-such-theme\") ok = true, want false")
+	}
+}
+
+func TestLoadThemeFallsBackToDefault(t *testing.T) {
+	theme := LoadTheme("no-such-theme")
+	if theme.Name != DefaultTheme().Name {
+		t.Errorf("LoadTheme(unknown).Name = %q, want %q", theme.Name, DefaultTheme().Name)
+	}
+}
+
+// writeThemeFile writes content to a theme file in a temporary directory.
+func writeThemeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "theme.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadThemeFileComplete(t *testing.T) {
+	want := DefaultTheme()
+	want.Name = "custom"
+	want.Author = "someone"
+	want.UI.MenuBg = "#123456"
+	want.UI.StatusBg = "#abc"
+	want.Syntax.Keyword = "208"
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadThemeFile(writeThemeFile(t, buf.String()))
+	if err != nil {
+		t.Fatalf("LoadThemeFile: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadThemeFile = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadThemeFilePartial(t *testing.T) {
+	path := writeThemeFile(t, `
+name = "nord"
+
+[ui]
+menu_bg = "#000000"
+
+[syntax]
+keyword = "9"
+`)
+	got, err := LoadThemeFile(path)
+	if err != nil {
+		t.Fatalf("LoadThemeFile: %v", err)
+	}
+	nord, _ := BuiltinTheme("nord")
+	if got.UI.MenuBg != "#000000" || got.Syntax.Keyword != "9" {
+		t.Errorf("colors from the file not kept: menu_bg %q, keyword %q", got.UI.MenuBg, got.Syntax.Keyword)
+	}
+	if got.UI.StatusBg != nord.UI.StatusBg || got.Syntax.String != nord.Syntax.String {
+		t.Errorf("omitted colors not taken from the built-in theme of the same name: status_bg %q, string %q",
+			got.UI.StatusBg, got.Syntax.String)
+	}
+	if empty := emptyFields(got.UI); len(empty) > 0 {
+		t.Errorf("empty UI colors after defaults: %v", empty)
+	}
+}
+
+func TestLoadThemeFileInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantKey string
+	}{
+		{"bad hex digit", "[ui]\nmenu_bg = \"#12345G\"\n", "menu_bg"},
+		{"wrong hex length", "[syntax]\nkeyword = \"#1234\"\n", "keyword"},
+		{"palette index out of range", "[ui]\nstatus_fg = \"256\"\n", "status_fg"},
+		{"color name", "[ui]\nline_number = \"red\"\n", "line_number"},
+		{"not TOML", "[ui\n", ""},
+	}
+
+	for _, tt := range tests {
+		_, err := LoadThemeFile(writeThemeFile(t, tt.content))
+		if err == nil {
+			t.Errorf("%s: LoadThemeFile returned no error", tt.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.wantKey) {
+			t.Errorf("%s: error %q does not name %q", tt.name, err, tt.wantKey)
+		}
+	}
+}
+
+func TestLoadThemeKeepsUserThemeWithInvalidColors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir, err := ThemesDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "name = \"old\"\n[ui]\nmenu_bg = \"red\"\nstatus_bg = \"#123456\"\n[syntax]\nkeyword = \"300\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "old.toml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	theme, err := LoadThemeChecked("old")
+	var invalid *InvalidColorsError
+	if !errors.As(err, &invalid) || len(invalid.Colors) != 2 ||
+		!strings.Contains(err.Error(), "menu_bg") || !strings.Contains(err.Error(), "keyword") {
+		t.Errorf("LoadThemeChecked error = %v, want one naming menu_bg and keyword", err)
+	}
+	def := DefaultTheme()
+	if theme.Name != "old" || theme.UI.StatusBg != "#123456" {
+		t.Errorf("valid settings not kept: name %q, status_bg %q", theme.Name, theme.UI.StatusBg)
+	}
+	if theme.UI.MenuBg != def.UI.MenuBg || theme.Syntax.Keyword != def.Syntax.Keyword {
+		t.Errorf("invalid colors not replaced by defaults: menu_bg %q, keyword %q", theme.UI.MenuBg, theme.Syntax.Keyword)
+	}
+	if got := LoadTheme("old"); !reflect.DeepEqual(got, theme) {
+		t.Errorf("LoadTheme = %+v, want the same theme as LoadThemeChecked", got)
+	}
+
+	// A theme file named in the config is still checked strictly
+	if _, err := LoadThemeFile(filepath.Join(dir, "old.toml")); err == nil {
+		t.Error("LoadThemeFile accepted invalid colors")
+	}
+}
+
+func TestResolvedThemeFile(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Theme.Name = "dracula"
+	cfg.Editor.ThemeFile = writeThemeFile(t, "name = \"mine\"\n")
+	theme, err := cfg.ResolvedTheme()
+	if err != nil || theme.Name != "mine" {
+		t.Errorf("ResolvedTheme() = %q, %v; want the theme file", theme.Name, err)
+	}
+
+	cfg.Editor.ThemeFile = filepath.Join(t.TempDir(), "missing.toml")
+	theme, err = cfg.ResolvedTheme()
+	if err == nil || theme.Name != "dracula" {
+		t.Errorf("ResolvedTheme() with a missing file = %q, %v; want dracula and an error", theme.Name, err)
+	}
+}
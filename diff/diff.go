@@ -0,0 +1,233 @@
+// Package diff compares two documents line by line for side-by-side
+// display. Lines are matched with Myers' shortest-edit-script diff and
+// returned as aligned rows, with the changed characters marked on lines
+// that were edited rather than added or removed.
+package diff
+
+// Op tags what happened to a row between the left and right documents.
+type Op int
+
+const (
+	Equal   Op = iota // Same on both sides
+	Added             // Only on the right
+	Removed           // Only on the left
+	Changed           // On both sides, with different text
+)
+
+// String returns the name of the op.
+func (o Op) String() string {
+	switch o {
+	case Equal:
+		return "equal"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// Span is a rune column range [Start, End) within a line.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Row is one aligned row of a side-by-side diff. Left and Right are line
+// indices into the two documents, or -1 where that side has no line (an
+// added or removed row). On Changed rows the spans mark the characters that
+// differ on each side.
+type Row struct {
+	Op         Op
+	Left       int
+	Right      int
+	LeftSpans  []Span
+	RightSpans []Span
+}
+
+// Lines diffs left against right. Runs of removed lines followed by added
+// lines are paired up into Changed rows, one pair per row, and whatever is
+// left over stays Removed or Added. The diff takes time proportional to
+// the line count times the number of differing lines; past maxEdits
+// differences the middle is shown as one removed block against one added
+// block.
+func Lines(left, right []string) []Row {
+	prefix := 0
+	for prefix < len(left) && prefix < len(right) && left[prefix] == right[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(left)-prefix && suffix < len(right)-prefix &&
+		left[len(left)-1-suffix] == right[len(right)-1-suffix] {
+		suffix++
+	}
+
+	var rows []Row
+	for i := 0; i < prefix; i++ {
+		rows = append(rows, Row{Op: Equal, Left: i, Right: i})
+	}
+	rows = append(rows, middle(left, right, prefix, len(left)-suffix, len(right)-suffix)...)
+	for i := suffix; i > 0; i-- {
+		rows = append(rows, Row{Op: Equal, Left: len(left) - i, Right: len(right) - i})
+	}
+	return rows
+}
+
+// maxEdits bounds the number of differing lines the diff searches for.
+// The search keeps a record of its frontier for every step, so this also
+// caps memory at about maxEdits² ints.
+const maxEdits = 1000
+
+// middle diffs left[start:leftEnd] against right[start:rightEnd].
+func middle(left, right []string, start, leftEnd, rightEnd int) []Row {
+	a, b := left[start:leftEnd], right[start:rightEnd]
+	ops, ok := editScript(a, b)
+	if !ok {
+		ops = ops[:0]
+		for range a {
+			ops = append(ops, Removed)
+		}
+		for range b {
+			ops = append(ops, Added)
+		}
+	}
+
+	var rows []Row
+	var removed, added []int
+	flush := func() {
+		rows = append(rows, pairUp(left, right, removed, added)...)
+		removed, added = removed[:0], added[:0]
+	}
+	i, j := 0, 0
+	for _, op := range ops {
+		switch op {
+		case Equal:
+			flush()
+			rows = append(rows, Row{Op: Equal, Left: start + i, Right: start + j})
+			i++
+			j++
+		case Removed:
+			removed = append(removed, start+i)
+			i++
+		case Added:
+			added = append(added, start+j)
+			j++
+		}
+	}
+	flush()
+	return rows
+}
+
+// editScript returns a shortest sequence of Equal, Removed and Added steps
+// turning a into b, found with Myers' O(ND) algorithm. It gives up, with
+// ok false, when a and b differ by more than maxEdits lines.
+func editScript(a, b []string) (ops []Op, ok bool) {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEdits)
+
+	// v[offset+k] is the furthest x reached on diagonal k = x-y; trace[d]
+	// holds diagonals -d..d as they stood after step d
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	if !found {
+		return nil, false
+	}
+
+	// Walk back from the end, collecting the steps in reverse
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, Equal)
+			x--
+			y--
+		}
+		if x == prevX {
+			ops = append(ops, Added)
+		} else {
+			ops = append(ops, Removed)
+		}
+		x, y = prevX, prevY
+	}
+	for ; x > 0; x-- {
+		ops = append(ops, Equal)
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops, true
+}
+
+// pairUp turns a run of removed and added lines into rows, pairing them in
+// order as Changed rows.
+func pairUp(left, right []string, removed, added []int) []Row {
+	var rows []Row
+	n := min(len(removed), len(added))
+	for k := 0; k < n; k++ {
+		l, r := removed[k], added[k]
+		ls, rs := Chars(left[l], right[r])
+		rows = append(rows, Row{Op: Changed, Left: l, Right: r, LeftSpans: ls, RightSpans: rs})
+	}
+	for _, l := range removed[n:] {
+		rows = append(rows, Row{Op: Removed, Left: l, Right: -1})
+	}
+	for _, r := range added[n:] {
+		rows = append(rows, Row{Op: Added, Left: -1, Right: r})
+	}
+	return rows
+}
+
+// Chars returns the rune ranges that differ between two versions of a
+// line: everything between their common prefix and common suffix. A side
+// whose range is empty (pure insertion or deletion) gets no span.
+func Chars(left, right string) (leftSpans, rightSpans []Span) {
+	a, b := []rune(left), []rune(right)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	if end := len(a) - suffix; end > prefix {
+		leftSpans = []Span{{Start: prefix, End: end}}
+	}
+	if end := len(b) - suffix; end > prefix {
+		rightSpans = []Span{{Start: prefix, End: end}}
+	}
+	return leftSpans, rightSpans
+}
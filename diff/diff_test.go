@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		left  []string
+		right []string
+		want  []Row
+	}{
+		{
+			"identical",
+			[]string{"a", "b"},
+			[]string{"a", "b"},
+			[]Row{{Op: Equal, Left: 0, Right: 0}, {Op: Equal, Left: 1, Right: 1}},
+		},
+		{
+			"insertion",
+			[]string{"a", "c"},
+			[]string{"a", "b", "c"},
+			[]Row{{Op: Equal, Left: 0, Right: 0}, {Op: Added, Left: -1, Right: 1}, {Op: Equal, Left: 1, Right: 2}},
+		},
+		{
+			"deletion",
+			[]string{"a", "b", "c", "d"},
+			[]string{"a", "d"},
+			[]Row{
+				{Op: Equal, Left: 0, Right: 0},
+				{Op: Removed, Left: 1, Right: -1},
+				{Op: Removed, Left: 2, Right: -1},
+				{Op: Equal, Left: 3, Right: 1},
+			},
+		},
+		{
+			"modified line",
+			[]string{"x := 1", "return x"},
+			[]string{"x := 42", "return x"},
+			[]Row{
+				{Op: Changed, Left: 0, Right: 0, LeftSpans: []Span{{5, 6}}, RightSpans: []Span{{5, 7}}},
+				{Op: Equal, Left: 1, Right: 1},
+			},
+		},
+		{
+			"changes between common lines",
+			[]string{"head", "one", "same", "two", "tail"},
+			[]string{"head", "same", "2", "extra", "tail"},
+			[]Row{
+				{Op: Equal, Left: 0, Right: 0},
+				{Op: Removed, Left: 1, Right: -1},
+				{Op: Equal, Left: 2, Right: 1},
+				{Op: Changed, Left: 3, Right: 2, LeftSpans: []Span{{0, 3}}, RightSpans: []Span{{0, 1}}},
+				{Op: Added, Left: -1, Right: 3},
+				{Op: Equal, Left: 4, Right: 4},
+			},
+		},
+		{"both empty", nil, nil, nil},
+	}
+
+	for _, tt := range tests {
+		if got := Lines(tt.left, tt.right); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Lines =\n%+v\nwant\n%+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLinesLarge(t *testing.T) {
+	// A few edits scattered through a long document are found exactly
+	left := make([]string, 20000)
+	for i := range left {
+		left[i] = strconv.Itoa(i)
+	}
+	right := append([]string(nil), left...)
+	right[100] = "changed"
+	right = append(right[:5000], right[5001:]...)
+	rows := Lines(left, right)
+	var edits []Row
+	for _, r := range rows {
+		if r.Op != Equal {
+			edits = append(edits, Row{Op: r.Op, Left: r.Left, Right: r.Right})
+		}
+	}
+	want := []Row{{Op: Changed, Left: 100, Right: 100}, {Op: Removed, Left: 5000, Right: -1}}
+	if !reflect.DeepEqual(edits, want) {
+		t.Errorf("edits = %+v, want %+v", edits, want)
+	}
+
+	// Documents with nothing in common past maxEdits fall back to one block
+	a := make([]string, maxEdits)
+	b := make([]string, maxEdits)
+	for i := range a {
+		a[i] = "a" + strconv.Itoa(i)
+		b[i] = "b" + strconv.Itoa(i)
+	}
+	rows = Lines(append([]string{"x", "same"}, a...), append([]string{"y", "same"}, b...))
+	if len(rows) != maxEdits+2 || rows[0].Op != Changed || rows[1].Op != Changed {
+		t.Fatalf("fallback gave %d rows starting %+v, want %d Changed rows", len(rows), rows[:2], maxEdits+2)
+	}
+}
+
+func TestChars(t *testing.T) {
+	tests := []struct {
+		name      string
+		left      string
+		right     string
+		wantLeft  []Span
+		wantRight []Span
+	}{
+		{"word replaced", "the cat sat", "the dog sat", []Span{{4, 7}}, []Span{{4, 7}}},
+		{"insertion", "abc", "abXc", nil, []Span{{2, 3}}},
+		{"deletion", "abXc", "abc", []Span{{2, 3}}, nil},
+		{"rune columns", "日本語 x", "日本人 x", []Span{{2, 3}}, []Span{{2, 3}}},
+		{"identical", "same", "same", nil, nil},
+	}
+
+	for _, tt := range tests {
+		l, r := Chars(tt.left, tt.right)
+		if !reflect.DeepEqual(l, tt.wantLeft) || !reflect.DeepEqual(r, tt.wantRight) {
+			t.Errorf("%s: Chars = %v, %v; want %v, %v", tt.name, l, r, tt.wantLeft, tt.wantRight)
+		}
+	}
+}
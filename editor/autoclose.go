@@ -0,0 +1,142 @@
+package editor
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/config"
+)
+
+// closingPairs maps opening brackets and quotes to their closing characters.
+var closingPairs = map[rune]rune{
+	'(':  ')',
+	'[':  ']',
+	'{':  '}',
+	'"':  '"',
+	'\'': '\'',
+	'`':  '`',
+}
+
+// languageUnpaired lists, by lowercase lexer name, openers that should not
+// auto-close because the language uses them on their own: Rust lifetimes,
+// OCaml type variables and Lisp quoting.
+var languageUnpaired = map[string][]rune{
+	"rust":        {'\''},
+	"ocaml":       {'\''},
+	"common lisp": {'\'', '`'},
+	"scheme":      {'\'', '`'},
+	"clojure":     {'\'', '`'},
+	"emacslisp":   {'\'', '`'},
+}
+
+// Pairs is the set of characters auto-close pairs for a language.
+type Pairs struct {
+	Close    map[rune]rune // Opening character to the closing one inserted
+	InString map[rune]bool // Openers that still pair inside a string literal
+}
+
+// DefaultPairs returns the pairs used when no language applies.
+func DefaultPairs() Pairs {
+	p := Pairs{Close: make(map[rune]rune, len(closingPairs))}
+	for open, close := range closingPairs {
+		p.Close[open] = close
+	}
+	return p
+}
+
+// LanguagePairs returns the pairs for language (a lexer name, matched
+// case-insensitively): the defaults less the language's built-in
+// exceptions, with settings from lc merged on top. lc may be nil.
+func LanguagePairs(language string, lc *config.LanguageConfig) Pairs {
+	p := DefaultPairs()
+	for _, open := range languageUnpaired[strings.ToLower(language)] {
+		delete(p.Close, open)
+	}
+	if lc == nil {
+		return p
+	}
+	for open, close := range lc.Pairs {
+		o, n := utf8.DecodeRuneInString(open)
+		if n == 0 || n != len(open) {
+			continue // Only single characters can be typed as openers
+		}
+		if c, _ := utf8.DecodeRuneInString(close); close == "" {
+			delete(p.Close, o)
+		} else {
+			p.Close[o] = c
+		}
+	}
+	for _, open := range lc.PairInStrings {
+		if o, n := utf8.DecodeRuneInString(open); n > 0 {
+			if p.InString == nil {
+				p.InString = make(map[rune]bool)
+			}
+			p.InString[o] = true
+		}
+	}
+	return p
+}
+
+// isCloser reports whether r closes one of the pairs.
+func (p Pairs) isCloser(r rune) bool {
+	for _, c := range p.Close {
+		if c == r {
+			return true
+		}
+	}
+	return false
+}
+
+// isClosingBracket reports whether r closes a bracket pair.
+func isClosingBracket(r rune) bool {
+	return r == ')' || r == ']' || r == '}'
+}
+
+// AutoClose decides what to insert when ch is typed in front of nextChar
+// (0 at end of line), with nothing before it on the line. It returns the
+// text to insert, with the cursor meant to land after its first rune, or
+// moveOver=true when the cursor should simply step over an identical
+// closing character that is already present.
+func AutoClose(ch rune, nextChar rune) (insert string, moveOver bool) {
+	return AutoCloseInContext(ch, nextChar, false)
+}
+
+// AutoCloseInContext is AutoClose with token context: when inString is true
+// the cursor is inside a string literal and nothing is auto-closed.
+func AutoCloseInContext(ch rune, nextChar rune, inString bool) (insert string, moveOver bool) {
+	return AutoCloseWithPairs(ch, 0, nextChar, inString, DefaultPairs())
+}
+
+// AutoCloseWithPairs is AutoCloseInContext using the given pairs, so the
+// behavior can follow the document's language, and prevChar, the character
+// before the cursor (0 at start of line). Openers listed in pairs.InString
+// still pair inside a string literal.
+func AutoCloseWithPairs(ch, prevChar, nextChar rune, inString bool, pairs Pairs) (insert string, moveOver bool) {
+	// Step over a closing character the user is typing again
+	if pairs.isCloser(ch) && nextChar == ch {
+		return "", true
+	}
+
+	closing, ok := pairs.Close[ch]
+	if !ok {
+		return string(ch), false
+	}
+
+	// Only pair when the cursor isn't directly in front of a word,
+	// so typing before existing text doesn't leave stray closers
+	if nextChar != 0 && !unicode.IsSpace(nextChar) && !isClosingBracket(nextChar) {
+		return string(ch), false
+	}
+
+	// A quote right after a word is an apostrophe or suffix, as in don't
+	if closing == ch && (unicode.IsLetter(prevChar) || unicode.IsDigit(prevChar)) {
+		return string(ch), false
+	}
+
+	// Inside a string a bracket is just text, and a quote likely ends the string
+	if inString && !pairs.InString[ch] {
+		return string(ch), false
+	}
+	return string(ch) + string(closing), false
+}
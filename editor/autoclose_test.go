@@ -0,0 +1,149 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cornish/textivus-editor/config"
+)
+
+func TestAutoClose(t *testing.T) {
+	tests := []struct {
+		name         string
+		ch           rune
+		next         rune
+		inString     bool
+		wantInsert   string
+		wantMoveOver bool
+	}{
+		{"open paren at end of line", '(', 0, false, "()", false},
+		{"open paren before space", '(', ' ', false, "()", false},
+		{"open brace before closer", '{', ')', false, "{}", false},
+		{"open paren before word", '(', 'x', false, "(", false},
+		{"close paren over existing", ')', ')', false, "", true},
+		{"close paren with nothing to skip", ')', 0, false, ")", false},
+		{"quote pairs", '"', 0, false, `""`, false},
+		{"quote over existing quote", '"', '"', false, "", true},
+		{"single quote pairs", '\'', ' ', false, "''", false},
+		{"quote before word", '\'', 's', false, "'", false},
+		{"bracket inside string", '[', 0, true, "[", false},
+		{"quote inside string", '"', 0, true, `"`, false},
+		{"plain character", 'a', 0, false, "a", false},
+	}
+
+	for _, tt := range tests {
+		insert, moveOver := AutoCloseInContext(tt.ch, tt.next, tt.inString)
+		if insert != tt.wantInsert || moveOver != tt.wantMoveOver {
+			t.Errorf("%s: AutoCloseInContext(%q, %q, %v) = (%q, %v), want (%q, %v)",
+				tt.name, tt.ch, tt.next, tt.inString, insert, moveOver, tt.wantInsert, tt.wantMoveOver)
+		}
+	}
+
+	if insert, moveOver := AutoClose('(', 0); insert != "()" || moveOver {
+		t.Errorf("AutoClose('(', 0) = (%q, %v), want (\"()\", false)", insert, moveOver)
+	}
+}
+
+func TestLanguagePairs(t *testing.T) {
+	var cfg config.Config
+	const settings = `
+[language.go.pairs]
+"<" = ">"
+
+[language.python]
+pair_in_strings = ["{"]
+
+[language.rust.pairs]
+"|" = "|"
+`
+	if _, err := toml.Decode(settings, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		language   string
+		ch         rune
+		inString   bool
+		wantInsert string
+	}{
+		{"go pairs single quotes", "Go", '\'', false, "''"},
+		{"rust leaves lifetimes alone", "Rust", '\'', false, "'"},
+		{"rust still pairs brackets", "Rust", '(', false, "()"},
+		{"rust pair added by config", "Rust", '|', false, "||"},
+		{"go pair added by config", "Go", '<', false, "<>"},
+		{"python braces pair in strings", "Python", '{', true, "{}"},
+		{"python parens still plain in strings", "Python", '(', true, "("},
+		{"unknown language uses defaults", "", '\'', false, "''"},
+	}
+
+	for _, tt := range tests {
+		pairs := LanguagePairs(tt.language, cfg.LanguageSettings(tt.language))
+		insert, _ := AutoCloseWithPairs(tt.ch, 0, 0, tt.inString, pairs)
+		if insert != tt.wantInsert {
+			t.Errorf("%s: AutoCloseWithPairs(%q) = %q, want %q", tt.name, tt.ch, insert, tt.wantInsert)
+		}
+	}
+}
+
+func TestAutoCloseAfterWord(t *testing.T) {
+	tests := []struct {
+		name       string
+		ch, prev   rune
+		wantInsert string
+	}{
+		{"apostrophe after a letter", '\'', 'n', "'"},
+		{"quote after a digit", '"', '9', `"`},
+		{"quote after a space", '\'', ' ', "''"},
+		{"quote after an opener", '"', '(', `""`},
+		{"bracket after a letter", '(', 'f', "()"},
+	}
+	for _, tt := range tests {
+		if insert, _ := AutoCloseWithPairs(tt.ch, tt.prev, 0, false, DefaultPairs()); insert != tt.wantInsert {
+			t.Errorf("%s: insert = %q, want %q", tt.name, insert, tt.wantInsert)
+		}
+	}
+
+	e := New()
+	e.config.Editor.AutoClose = true
+	for _, r := range "don't" {
+		e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if got := e.activeDoc().buffer.String(); got != "don't" {
+		t.Errorf("typing don't gave %q", got)
+	}
+}
+
+func TestLanguagePairsConfigDisables(t *testing.T) {
+	lc := &config.LanguageConfig{Pairs: map[string]string{"'": "", "`": ""}}
+	pairs := LanguagePairs("Go", lc)
+	for _, ch := range []rune{'\'', '`'} {
+		if insert, _ := AutoCloseWithPairs(ch, 0, 0, false, pairs); insert != string(ch) {
+			t.Errorf("disabled %q: insert = %q, want %q", ch, insert, string(ch))
+		}
+	}
+	// Stepping over a closer still works for pairs left enabled
+	if _, moveOver := AutoCloseWithPairs('"', 0, '"', false, pairs); !moveOver {
+		t.Error(`typing " before " did not step over`)
+	}
+}
+
+func TestBracketedPasteInsertsVerbatim(t *testing.T) {
+	e := New()
+	e.config.Editor.AutoClose = true
+	e.config.Editor.AutoIndent = true
+	paste := tea.KeyMsg{Type: tea.KeyRunes, Paste: true, Runes: []rune("if (x) {\r\n\tf('a')\r\n}")}
+	e.Update(paste)
+	if got, want := e.activeDoc().buffer.String(), "if (x) {\n\tf('a')\n}"; got != want {
+		t.Errorf("after paste: buffer = %q, want %q", got, want)
+	}
+}
+
+func TestBracketedPasteBareCR(t *testing.T) {
+	e := New()
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Paste: true, Runes: []rune("one\rtwo\r\nthree")})
+	if got, want := e.activeDoc().buffer.String(), "one\ntwo\nthree"; got != want {
+		t.Errorf("after paste: buffer = %q, want %q", got, want)
+	}
+}
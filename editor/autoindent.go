@@ -0,0 +1,46 @@
+package editor
+
+import "strings"
+
+// braceLanguages are the lexer names (lowercased) of C-like languages where an
+// opening brace at the end of a line starts a new indent level.
+var braceLanguages = map[string]bool{
+	"go": true, "c": true, "c++": true, "c#": true, "java": true,
+	"javascript": true, "typescript": true, "tsx": true, "rust": true,
+	"swift": true, "kotlin": true, "scala": true, "dart": true, "php": true,
+	"css": true, "json": true, "groovy": true, "zig": true,
+}
+
+// AutoIndent returns the whitespace prefix for a new line inserted after
+// prevLine. The new line inherits prevLine's indentation, plus one level when
+// prevLine ends with an opening brace in a C-like language. With
+// tabsToSpaces the indentation is all spaces; otherwise the extra level uses
+// a tab if prevLine is tab-indented and indentWidth spaces if not. Tabs
+// inherited from prevLine span tabWidth columns.
+func AutoIndent(prevLine string, tabWidth, indentWidth int, tabsToSpaces bool, lang string) string {
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	if indentWidth <= 0 {
+		indentWidth = tabWidth
+	}
+	indent := leadingWhitespace(prevLine)
+	if tabsToSpaces {
+		indent = strings.Repeat(" ", indentColumns(indent, tabWidth))
+	}
+
+	trimmed := strings.TrimRight(prevLine, " \t")
+	if !strings.HasSuffix(trimmed, "{") || !braceLanguages[strings.ToLower(lang)] {
+		return indent
+	}
+
+	if !tabsToSpaces && (indent == "" || strings.HasPrefix(indent, "\t")) {
+		return indent + "\t"
+	}
+	return indent + strings.Repeat(" ", indentWidth)
+}
+
+// leadingWhitespace returns the run of spaces and tabs that starts line.
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
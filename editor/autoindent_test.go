@@ -0,0 +1,53 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAutoIndent(t *testing.T) {
+	tests := []struct {
+		name     string
+		prevLine string
+		tabWidth int
+		indent   int
+		spaces   bool
+		lang     string
+		want     string
+	}{
+		{"no indent", "hello", 4, 4, false, "Go", ""},
+		{"space-indented", "    x := 1", 4, 4, false, "Go", "    "},
+		{"tab-indented", "\t\tx := 1", 4, 4, false, "Go", "\t\t"},
+		{"whitespace-only line", "  ", 4, 4, false, "Go", "  "},
+		{"brace adds tab level", "\tfunc main() {", 4, 4, false, "Go", "\t\t"},
+		{"brace adds space level", "  if (x) {  ", 2, 2, false, "JavaScript", "    "},
+		{"brace at top level", "func main() {", 4, 4, false, "Go", "\t"},
+		{"brace ignored for other languages", "  x = {", 4, 4, false, "Python", "  "},
+		{"brace ignored without language", "  x = {", 4, 4, false, "", "  "},
+		{"tabs to spaces at top level", "func main() {", 4, 4, true, "Go", "    "},
+		{"tabs to spaces expands inherited tabs", "\t  if x {", 4, 4, true, "Go", "          "},
+		{"tabs to spaces without brace", "\tx := 1", 2, 2, true, "Go", "  "},
+		{"indent size differs from tab width", "func main() {", 8, 2, true, "Go", "  "},
+		{"inherited tab keeps the tab width", "\tif x {", 8, 2, true, "Go", "          "},
+		{"unset indent size uses the tab width", "  if (x) {", 4, 0, false, "JavaScript", "      "},
+	}
+
+	for _, tt := range tests {
+		if got := AutoIndent(tt.prevLine, tt.tabWidth, tt.indent, tt.spaces, tt.lang); got != tt.want {
+			t.Errorf("%s: AutoIndent(%q, %d, %d, %v, %q) = %q, want %q",
+				tt.name, tt.prevLine, tt.tabWidth, tt.indent, tt.spaces, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestTabIndentsByIndentSize(t *testing.T) {
+	e := New()
+	e.config.Editor.TabsToSpaces = true
+	e.config.Editor.TabWidth = 8
+	e.config.Editor.IndentSize = 2
+	e.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := e.activeDoc().buffer.String(); got != "  " {
+		t.Errorf("after Tab: buffer = %q, want one 2-column indent", got)
+	}
+}
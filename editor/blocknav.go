@@ -0,0 +1,114 @@
+package editor
+
+import "strings"
+
+// isBlankLine reports whether line is empty or whitespace-only.
+func isBlankLine(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// NextBlankLine returns the first blank line after the paragraph containing
+// line, like Vim's "}". From a blank line it moves past the next paragraph.
+// Returns the last line of the document if there is no further blank line.
+func NextBlankLine(lines []string, line int) int {
+	if len(lines) == 0 {
+		return 0
+	}
+	i := line + 1
+	if line >= 0 && line < len(lines) && isBlankLine(lines[line]) {
+		for i < len(lines) && isBlankLine(lines[i]) {
+			i++
+		}
+	}
+	for i < len(lines) && !isBlankLine(lines[i]) {
+		i++
+	}
+	if i >= len(lines) {
+		return len(lines) - 1
+	}
+	return i
+}
+
+// PrevBlankLine returns the first blank line before the paragraph containing
+// line, like Vim's "{". From a blank line it moves past the previous paragraph.
+// Returns 0 if there is no earlier blank line.
+func PrevBlankLine(lines []string, line int) int {
+	if line > len(lines) {
+		line = len(lines)
+	}
+	i := line - 1
+	if line >= 0 && line < len(lines) && isBlankLine(lines[line]) {
+		for i >= 0 && isBlankLine(lines[i]) {
+			i--
+		}
+	}
+	for i >= 0 && !isBlankLine(lines[i]) {
+		i--
+	}
+	if i < 0 {
+		return 0
+	}
+	return i
+}
+
+// MatchingBlockEnd returns the last line of the indentation block that line
+// opens. The block is every following line indented deeper than line (blank
+// lines included), plus a closing line at the same indent that starts with
+// a bracket, such as Go's "}". If line has no deeper lines after it, the
+// block is taken to be the run of lines at line's own indentation level.
+// Tabs in the indentation count up to the next multiple of tabWidth.
+func MatchingBlockEnd(lines []string, line, tabWidth int) int {
+	if len(lines) == 0 {
+		return 0
+	}
+	if line < 0 {
+		line = 0
+	}
+	if line >= len(lines) {
+		return len(lines) - 1
+	}
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	indentWidth := func(line string) int {
+		return indentColumns(leadingWhitespace(line), tabWidth)
+	}
+
+	base := indentWidth(lines[line])
+	end := line
+
+	// Find the next non-blank line to decide between header and body mode
+	next := line + 1
+	for next < len(lines) && isBlankLine(lines[next]) {
+		next++
+	}
+	opensBlock := next < len(lines) && indentWidth(lines[next]) > base
+
+	for i := line + 1; i < len(lines); i++ {
+		if isBlankLine(lines[i]) {
+			continue
+		}
+		w := indentWidth(lines[i])
+		if opensBlock {
+			if w > base {
+				end = i
+				continue
+			}
+			if w == base && startsWithCloser(lines[i]) {
+				end = i
+			}
+			break
+		}
+		if w < base || (w == base && startsWithCloser(lines[i])) {
+			break
+		}
+		end = i
+	}
+	return end
+}
+
+// startsWithCloser reports whether the first non-blank character closes a bracket.
+func startsWithCloser(line string) bool {
+	body := strings.TrimLeft(line, " \t")
+	return body != "" && strings.ContainsRune(")]}", rune(body[0]))
+}
@@ -0,0 +1,91 @@
+package editor
+
+import "testing"
+
+func TestNextPrevBlankLine(t *testing.T) {
+	lines := []string{
+		"para one",  // 0
+		"continues", // 1
+		"",          // 2
+		"  \t",      // 3 whitespace-only counts as blank
+		"para two",  // 4
+		"more",      // 5
+		"",          // 6
+		"last",      // 7
+	}
+
+	nextTests := []struct{ from, want int }{
+		{0, 2},
+		{1, 2},
+		{2, 6},
+		{3, 6},
+		{6, 7}, // No blank after the final paragraph: clamp to last line
+		{7, 7},
+	}
+	for _, tt := range nextTests {
+		if got := NextBlankLine(lines, tt.from); got != tt.want {
+			t.Errorf("NextBlankLine(%d) = %d, want %d", tt.from, got, tt.want)
+		}
+	}
+
+	prevTests := []struct{ from, want int }{
+		{7, 6},
+		{6, 3},
+		{5, 3},
+		{1, 0}, // No blank before the first paragraph: clamp to first line
+		{0, 0},
+	}
+	for _, tt := range prevTests {
+		if got := PrevBlankLine(lines, tt.from); got != tt.want {
+			t.Errorf("PrevBlankLine(%d) = %d, want %d", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestMatchingBlockEnd(t *testing.T) {
+	lines := []string{
+		"func main() {", // 0
+		"\tif x {",      // 1
+		"\t\tfoo()",     // 2
+		"",              // 3
+		"\t\tbar()",     // 4
+		"\t}",           // 5
+		"\tbaz()",       // 6
+		"}",             // 7
+		"",              // 8
+		"def f():",      // 9
+		"    return 1",  // 10
+		"x = 2",         // 11
+	}
+
+	tests := []struct {
+		name string
+		line int
+		want int
+	}{
+		{"function body includes closing brace", 0, 7},
+		{"nested block", 1, 5},
+		{"body line runs to end of its level", 2, 4},
+		{"python block without closer", 9, 10},
+		{"last line", 11, 11},
+		{"past end clamps", 50, 11},
+	}
+
+	for _, tt := range tests {
+		if got := MatchingBlockEnd(lines, tt.line, 4); got != tt.want {
+			t.Errorf("%s: MatchingBlockEnd(%d) = %d, want %d", tt.name, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestMatchingBlockEndTabWidth(t *testing.T) {
+	// Whether the tab-indented line is inside the block depends on how
+	// wide a tab is
+	lines := []string{"    if x {", "\tfoo()", "    }"}
+	if got := MatchingBlockEnd(lines, 0, 8); got != 2 {
+		t.Errorf("tab width 8: MatchingBlockEnd = %d, want 2", got)
+	}
+	if got := MatchingBlockEnd(lines, 0, 4); got != 1 {
+		t.Errorf("tab width 4: MatchingBlockEnd = %d, want 1", got)
+	}
+}
@@ -0,0 +1,159 @@
+package editor
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// BlockSelection is a rectangular (column) selection. It covers lines
+// StartLine through EndLine inclusive and display columns [StartCol, EndCol)
+// on each of them, so the block stays square across tabs and wide
+// characters.
+type BlockSelection struct {
+	StartLine int
+	EndLine   int
+	StartCol  int
+	EndCol    int
+}
+
+// NewBlockSelection creates the block spanned by two corners, which may be
+// given in any order. The corners' rune columns are converted to display
+// columns on their own lines.
+func NewBlockSelection(lines []string, tabWidth int, anchor, cursor Position) BlockSelection {
+	b := BlockSelection{
+		StartLine: anchor.Line,
+		EndLine:   cursor.Line,
+		StartCol:  displayColumn(lineAt(lines, anchor.Line), anchor.Col, tabWidth),
+		EndCol:    displayColumn(lineAt(lines, cursor.Line), cursor.Col, tabWidth),
+	}
+	if b.StartLine > b.EndLine {
+		b.StartLine, b.EndLine = b.EndLine, b.StartLine
+	}
+	if b.StartCol > b.EndCol {
+		b.StartCol, b.EndCol = b.EndCol, b.StartCol
+	}
+	return b
+}
+
+// Width returns the number of columns in the block.
+func (b BlockSelection) Width() int {
+	return b.EndCol - b.StartCol
+}
+
+// Text extracts the selected rectangle as clipboard text, one row per line.
+// Tabs come out as the spaces they occupy, and a tab or wide character cut
+// by an edge of the block contributes spaces for its cells inside it.
+// Lines that end before the block's right edge are padded with spaces when
+// pad is true, so every row has the same width; otherwise they contribute
+// only the characters they have, possibly none.
+func (b BlockSelection) Text(lines []string, tabWidth int, pad bool) string {
+	rows := make([]string, 0, b.EndLine-b.StartLine+1)
+	for i := b.StartLine; i <= b.EndLine && i < len(lines); i++ {
+		if i < 0 {
+			continue
+		}
+		var sb strings.Builder
+		filled := 0
+		for _, c := range displayCells(lines[i], tabWidth) {
+			start, end := max(c.col, b.StartCol), min(c.col+c.width, b.EndCol)
+			switch {
+			case start >= end:
+				continue
+			case start == c.col && end == c.col+c.width && c.text != "\t":
+				sb.WriteString(c.text)
+			default:
+				sb.WriteString(strings.Repeat(" ", end-start))
+			}
+			filled += end - start
+		}
+		if pad && filled < b.Width() {
+			sb.WriteString(strings.Repeat(" ", b.Width()-filled))
+		}
+		rows = append(rows, sb.String())
+	}
+	return strings.Join(rows, "\n")
+}
+
+// SelectionMap returns the per-line highlight ranges for RenderState.Selection,
+// in rune columns: every character with a cell inside the block is included.
+func (b BlockSelection) SelectionMap(lines []string, tabWidth int) map[int]ui.SelectionRange {
+	m := make(map[int]ui.SelectionRange, b.EndLine-b.StartLine+1)
+	for i := b.StartLine; i <= b.EndLine; i++ {
+		line := lineAt(lines, i)
+		m[i] = ui.SelectionRange{
+			Start: runeColumn(line, b.StartCol, tabWidth, false),
+			End:   runeColumn(line, b.EndCol, tabWidth, true),
+		}
+	}
+	return m
+}
+
+// displayCell is one grapheme cluster of a line as drawn: its rune column,
+// display column and width in cells.
+type displayCell struct {
+	text  string
+	rune  int
+	col   int
+	width int
+}
+
+// displayCells lays line out the way the text renderer draws it, one
+// grapheme cluster per cell run, with tabs running to the next tab stop.
+func displayCells(line string, tabWidth int) []displayCell {
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	var cells []displayCell
+	r, col := 0, 0
+	for rest, state := line, -1; rest != ""; {
+		var cluster string
+		var w int
+		cluster, rest, w, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		if cluster == "\t" {
+			w = tabWidth - col%tabWidth
+		}
+		cells = append(cells, displayCell{text: cluster, rune: r, col: col, width: w})
+		r += utf8.RuneCountInString(cluster)
+		col += w
+	}
+	return cells
+}
+
+// displayColumn converts a rune column in line to a display column.
+// Columns past the end of the line are one cell each.
+func displayColumn(line string, col, tabWidth int) int {
+	display, r := 0, 0
+	for _, c := range displayCells(line, tabWidth) {
+		if c.rune >= col {
+			return c.col
+		}
+		display, r = c.col+c.width, c.rune+utf8.RuneCountInString(c.text)
+	}
+	return display + max(col-r, 0)
+}
+
+// runeColumn converts a display column in line to a rune column. A column
+// inside a tab or wide character maps to its start, or past it when after
+// is true. Columns past the end of the line are one cell each.
+func runeColumn(line string, col, tabWidth int, after bool) int {
+	display, r := 0, 0
+	for _, c := range displayCells(line, tabWidth) {
+		if c.col >= col || (c.col+c.width > col && !after) {
+			return c.rune
+		}
+		display, r = c.col+c.width, c.rune+utf8.RuneCountInString(c.text)
+	}
+	return r + max(col-display, 0)
+}
+
+// lineAt returns line i of lines, or "" past either end.
+func lineAt(lines []string, i int) string {
+	if i < 0 || i >= len(lines) {
+		return ""
+	}
+	return lines[i]
+}
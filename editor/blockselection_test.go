@@ -0,0 +1,88 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+func TestBlockSelectionText(t *testing.T) {
+	lines := []string{
+		"0123456789",
+		"abc",
+		"",
+		"日本語テキスト",
+		"ABCDEFGH",
+	}
+	// Rows 1-3, display columns 2-6: a 3x4 block, which cuts the wide
+	// characters on row 3 at 本 and 語
+	b := NewBlockSelection(lines, 4, Position{Line: 3, Col: 3}, Position{Line: 1, Col: 2})
+
+	tests := []struct {
+		name string
+		pad  bool
+		want string
+	}{
+		{"padded", true, "c   \n    \n本語"},
+		{"unpadded", false, "c\n\n本語"},
+	}
+
+	for _, tt := range tests {
+		if got := b.Text(lines, 4, tt.pad); got != tt.want {
+			t.Errorf("%s: Text = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBlockSelectionNormalizes(t *testing.T) {
+	b := NewBlockSelection(nil, 4, Position{Line: 5, Col: 8}, Position{Line: 2, Col: 3})
+	want := BlockSelection{StartLine: 2, EndLine: 5, StartCol: 3, EndCol: 8}
+	if b != want {
+		t.Errorf("NewBlockSelection = %+v, want %+v", b, want)
+	}
+}
+
+func TestBlockSelectionMap(t *testing.T) {
+	b := BlockSelection{StartLine: 1, EndLine: 2, StartCol: 4, EndCol: 7}
+	m := b.SelectionMap(nil, 4)
+	if len(m) != 2 {
+		t.Fatalf("SelectionMap has %d lines, want 2", len(m))
+	}
+	for _, line := range []int{1, 2} {
+		if got := m[line]; got != (ui.SelectionRange{Start: 4, End: 7}) {
+			t.Errorf("line %d range = %+v, want {4 7}", line, got)
+		}
+	}
+}
+
+func TestBlockSelectionDisplayColumns(t *testing.T) {
+	lines := []string{
+		"\tx = 1",
+		"abc日本",
+		"abcdefghij",
+	}
+	// The cursor after the tab on line 0 is at display column 4
+	b := NewBlockSelection(lines, 4, Position{Line: 0, Col: 1}, Position{Line: 2, Col: 7})
+	want := BlockSelection{StartLine: 0, EndLine: 2, StartCol: 4, EndCol: 7}
+	if b != want {
+		t.Fatalf("NewBlockSelection = %+v, want %+v", b, want)
+	}
+
+	if got, want := b.Text(lines, 4, true), "x =\n 本\nefg"; got != want {
+		t.Errorf("Text = %q, want %q", got, want)
+	}
+
+	// 日 spans columns 3-4, so it is cut by the left edge: it copies as a
+	// space but is still highlighted
+	m := b.SelectionMap(lines, 4)
+	wantMap := map[int]ui.SelectionRange{
+		0: {Start: 1, End: 4},
+		1: {Start: 3, End: 5},
+		2: {Start: 4, End: 7},
+	}
+	for line, r := range wantMap {
+		if m[line] != r {
+			t.Errorf("line %d range = %+v, want %+v", line, m[line], r)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package editor
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Bookmarks are named positions within one document, named by a single
+// letter or digit. Positions use rune columns, like LineStore. Edits
+// reported through AdjustForInsert and AdjustForDelete move the marks so
+// each stays on the text it was set on.
+type Bookmarks struct {
+	marks map[string]Position
+}
+
+// NewBookmarks creates an empty set of bookmarks.
+func NewBookmarks() *Bookmarks {
+	return &Bookmarks{marks: make(map[string]Position)}
+}
+
+// Set places bookmark name at pos, replacing any earlier position. Returns
+// false if name is not a single letter or digit.
+func (b *Bookmarks) Set(name string, pos Position) bool {
+	r, size := utf8.DecodeRuneInString(name)
+	if size == 0 || size != len(name) || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+	b.marks[name] = pos
+	return true
+}
+
+// Get returns the position of bookmark name.
+func (b *Bookmarks) Get(name string) (Position, bool) {
+	pos, ok := b.marks[name]
+	return pos, ok
+}
+
+// Remove deletes bookmark name.
+func (b *Bookmarks) Remove(name string) {
+	delete(b.marks, name)
+}
+
+// Len returns the number of bookmarks.
+func (b *Bookmarks) Len() int {
+	return len(b.marks)
+}
+
+// Names returns the bookmark names in document order.
+func (b *Bookmarks) Names() []string {
+	names := make([]string, 0, len(b.marks))
+	for name := range b.marks {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := b.marks[names[i]], b.marks[names[j]]
+		if pi != pj {
+			return positionLess(pi, pj)
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// NextAfter returns the first bookmark on a line after line, wrapping
+// around to the first bookmark in the document when none follows. ok is
+// false when there are no bookmarks.
+func (b *Bookmarks) NextAfter(line int) (name string, pos Position, ok bool) {
+	names := b.Names()
+	if len(names) == 0 {
+		return "", Position{}, false
+	}
+	for _, n := range names {
+		if b.marks[n].Line > line {
+			return n, b.marks[n], true
+		}
+	}
+	return names[0], b.marks[names[0]], true
+}
+
+// AdjustForInsert moves the bookmarks after pos to account for text
+// inserted there.
+func (b *Bookmarks) AdjustForInsert(pos Position, text string) {
+	if text == "" {
+		return
+	}
+	end := insertEnd(pos, text)
+	for name, p := range b.marks {
+		b.marks[name] = shiftForInsert(p, pos, end)
+	}
+}
+
+// AdjustForDelete moves the bookmarks after r to account for its text being
+// removed. Bookmarks inside r move to its start.
+func (b *Bookmarks) AdjustForDelete(r Range) {
+	if positionLess(r.End, r.Start) {
+		r.Start, r.End = r.End, r.Start
+	}
+	for name, p := range b.marks {
+		b.marks[name] = shiftForDelete(p, r)
+	}
+}
@@ -0,0 +1,114 @@
+package editor
+
+import "testing"
+
+func TestBookmarksSetGet(t *testing.T) {
+	tests := []struct {
+		name   string
+		pos    Position
+		wantOK bool
+	}{
+		{"a", Position{Line: 3, Col: 2}, true},
+		{"7", Position{Line: 9}, true},
+		{"é", Position{Line: 1}, true},
+		{"", Position{}, false},
+		{"ab", Position{}, false},
+		{"-", Position{}, false},
+	}
+
+	b := NewBookmarks()
+	for _, tt := range tests {
+		if ok := b.Set(tt.name, tt.pos); ok != tt.wantOK {
+			t.Errorf("Set(%q) = %v, want %v", tt.name, ok, tt.wantOK)
+		}
+		got, ok := b.Get(tt.name)
+		if ok != tt.wantOK || (ok && got != tt.pos) {
+			t.Errorf("Get(%q) = %v, %v; want %v, %v", tt.name, got, ok, tt.pos, tt.wantOK)
+		}
+	}
+
+	b.Set("a", Position{Line: 5})
+	if got, _ := b.Get("a"); got != (Position{Line: 5}) {
+		t.Errorf("Get(a) after moving it = %v, want line 5", got)
+	}
+	b.Remove("a")
+	if _, ok := b.Get("a"); ok {
+		t.Error("Get(a) after Remove found it")
+	}
+}
+
+func TestBookmarksNextAfter(t *testing.T) {
+	b := NewBookmarks()
+	if _, _, ok := b.NextAfter(0); ok {
+		t.Error("NextAfter with no bookmarks reported one")
+	}
+	b.Set("c", Position{Line: 20})
+	b.Set("a", Position{Line: 4, Col: 1})
+	b.Set("b", Position{Line: 10})
+
+	tests := []struct {
+		line int
+		want string
+	}{
+		{0, "a"},
+		{4, "b"}, // A mark on the current line is skipped
+		{9, "b"},
+		{10, "c"},
+		{20, "a"}, // Wraps around
+		{99, "a"},
+	}
+
+	for _, tt := range tests {
+		name, pos, ok := b.NextAfter(tt.line)
+		if !ok || name != tt.want {
+			t.Errorf("NextAfter(%d) = %q, want %q", tt.line, name, tt.want)
+		}
+		if want, _ := b.Get(tt.want); pos != want {
+			t.Errorf("NextAfter(%d) position = %v, want %v", tt.line, pos, want)
+		}
+	}
+}
+
+func TestBookmarksAdjust(t *testing.T) {
+	tests := []struct {
+		name   string
+		adjust func(b *Bookmarks)
+		want   map[string]Position
+	}{
+		{
+			"lines inserted above",
+			func(b *Bookmarks) { b.AdjustForInsert(Position{Line: 1, Col: 0}, "new\nlines\n") },
+			map[string]Position{"a": {Line: 0, Col: 3}, "b": {Line: 7, Col: 2}, "c": {Line: 10, Col: 0}},
+		},
+		{
+			"text inserted before a mark on its line",
+			func(b *Bookmarks) { b.AdjustForInsert(Position{Line: 5, Col: 0}, "xy") },
+			map[string]Position{"a": {Line: 0, Col: 3}, "b": {Line: 5, Col: 4}, "c": {Line: 8, Col: 0}},
+		},
+		{
+			"lines deleted above",
+			func(b *Bookmarks) { b.AdjustForDelete(Range{Start: Position{Line: 1}, End: Position{Line: 3}}) },
+			map[string]Position{"a": {Line: 0, Col: 3}, "b": {Line: 3, Col: 2}, "c": {Line: 6, Col: 0}},
+		},
+		{
+			"mark inside deleted text moves to its start",
+			func(b *Bookmarks) {
+				b.AdjustForDelete(Range{Start: Position{Line: 6, Col: 1}, End: Position{Line: 4, Col: 1}})
+			},
+			map[string]Position{"a": {Line: 0, Col: 3}, "b": {Line: 4, Col: 1}, "c": {Line: 6, Col: 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		b := NewBookmarks()
+		b.Set("a", Position{Line: 0, Col: 3})
+		b.Set("b", Position{Line: 5, Col: 2})
+		b.Set("c", Position{Line: 8, Col: 0})
+		tt.adjust(b)
+		for name, want := range tt.want {
+			if got, _ := b.Get(name); got != want {
+				t.Errorf("%s: bookmark %s at %v, want %v", tt.name, name, got, want)
+			}
+		}
+	}
+}
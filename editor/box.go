@@ -0,0 +1,130 @@
+package editor
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// BoxStyle selects the glyphs used to draw a dialog border
+type BoxStyle int
+
+const (
+	BoxSingle  BoxStyle = iota // ┌─┐ single-line
+	BoxDouble                  // ╔═╗ double-line
+	BoxRounded                 // ╭─╮ single-line with rounded corners
+	BoxASCII                   // +-+ ASCII fallback
+)
+
+// DoubleBoxChars provides double-line box drawing characters
+var DoubleBoxChars = BoxChars{
+	TopLeft:     "╔",
+	TopRight:    "╗",
+	BottomLeft:  "╚",
+	BottomRight: "╝",
+	Horizontal:  "═",
+	Vertical:    "║",
+	TeeLeft:     "╠",
+	TeeRight:    "╣",
+	Lock:        "🔒",
+	Ellipsis:    "…",
+	Star:        "★",
+	InputCursor: "▂",
+}
+
+// RoundedBoxChars provides single-line box drawing characters with rounded corners
+var RoundedBoxChars = BoxChars{
+	TopLeft:     "╭",
+	TopRight:    "╮",
+	BottomLeft:  "╰",
+	BottomRight: "╯",
+	Horizontal:  "─",
+	Vertical:    "│",
+	TeeLeft:     "├",
+	TeeRight:    "┤",
+	Lock:        "🔒",
+	Ellipsis:    "…",
+	Star:        "★",
+	InputCursor: "▂",
+}
+
+// ParseBoxStyle parses a box_style setting: "single", "double", "rounded"
+// or "ascii". Anything else gives BoxSingle.
+func ParseBoxStyle(name string) BoxStyle {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "double":
+		return BoxDouble
+	case "rounded":
+		return BoxRounded
+	case "ascii":
+		return BoxASCII
+	default:
+		return BoxSingle
+	}
+}
+
+// Chars returns the box characters for the style
+func (s BoxStyle) Chars() BoxChars {
+	switch s {
+	case BoxDouble:
+		return DoubleBoxChars
+	case BoxRounded:
+		return RoundedBoxChars
+	case BoxASCII:
+		return AsciiBoxChars
+	default:
+		return UnicodeBoxChars
+	}
+}
+
+// DrawBox returns the lines of an empty bordered box of the given size.
+// A non-empty title is centered in the top border. Interior rows are
+// filled with spaces so callers can overwrite them with content.
+func DrawBox(width, height int, title string, style BoxStyle) []string {
+	if width < 2 || height < 2 {
+		return nil
+	}
+	chars := style.Chars()
+	innerWidth := width - 2
+
+	lines := make([]string, 0, height)
+	lines = append(lines, boxTopBorder(chars, innerWidth, title))
+	empty := chars.Vertical + strings.Repeat(" ", innerWidth) + chars.Vertical
+	for i := 0; i < height-2; i++ {
+		lines = append(lines, empty)
+	}
+	lines = append(lines, boxBottomBorder(chars, innerWidth))
+	return lines
+}
+
+// boxTopBorder builds a top border with title centered in it
+func boxTopBorder(chars BoxChars, innerWidth int, title string) string {
+	if runewidth.StringWidth(title) > innerWidth {
+		title = runewidth.Truncate(title, innerWidth, "")
+	}
+	titlePadLeft := (innerWidth - runewidth.StringWidth(title)) / 2
+	titlePadRight := innerWidth - runewidth.StringWidth(title) - titlePadLeft
+	return chars.TopLeft +
+		strings.Repeat(chars.Horizontal, titlePadLeft) +
+		title +
+		strings.Repeat(chars.Horizontal, titlePadRight) +
+		chars.TopRight
+}
+
+// boxBottomBorder builds a plain bottom border
+func boxBottomBorder(chars BoxChars, innerWidth int) string {
+	return chars.BottomLeft + strings.Repeat(chars.Horizontal, innerWidth) + chars.BottomRight
+}
+
+// setBoxStyle selects the style of every dialog border and the logo art,
+// along with the glyphs drawn in it
+func (e *Editor) setBoxStyle(style BoxStyle) {
+	e.boxStyle = style
+	e.box = style.Chars()
+}
+
+// asciiBoxes reports whether dialogs are drawn with the ASCII fallback,
+// which also switches the about logo and other decorations to ASCII
+func (e *Editor) asciiBoxes() bool {
+	return e.boxStyle == BoxASCII
+}
@@ -0,0 +1,164 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cornish/textivus-editor/config"
+)
+
+func TestDrawBox(t *testing.T) {
+	tests := []struct {
+		style                   BoxStyle
+		topLeft, topRight       string
+		bottomLeft, bottomRight string
+		horizontal, vertical    string
+	}{
+		{BoxSingle, "┌", "┐", "└", "┘", "─", "│"},
+		{BoxDouble, "╔", "╗", "╚", "╝", "═", "║"},
+		{BoxRounded, "╭", "╮", "╰", "╯", "─", "│"},
+		{BoxASCII, "+", "+", "+", "+", "-", "|"},
+	}
+
+	for _, tt := range tests {
+		lines := DrawBox(10, 4, "", tt.style)
+		if len(lines) != 4 {
+			t.Fatalf("style %d: got %d lines, want 4", tt.style, len(lines))
+		}
+		wantTop := tt.topLeft + strings.Repeat(tt.horizontal, 8) + tt.topRight
+		wantMid := tt.vertical + strings.Repeat(" ", 8) + tt.vertical
+		wantBottom := tt.bottomLeft + strings.Repeat(tt.horizontal, 8) + tt.bottomRight
+		if lines[0] != wantTop {
+			t.Errorf("style %d: top = %q, want %q", tt.style, lines[0], wantTop)
+		}
+		for i := 1; i < 3; i++ {
+			if lines[i] != wantMid {
+				t.Errorf("style %d: row %d = %q, want %q", tt.style, i, lines[i], wantMid)
+			}
+		}
+		if lines[3] != wantBottom {
+			t.Errorf("style %d: bottom = %q, want %q", tt.style, lines[3], wantBottom)
+		}
+	}
+}
+
+func TestDrawBoxTitle(t *testing.T) {
+	tests := []struct {
+		width int
+		title string
+		want  string
+	}{
+		{12, " Hi ", "+--- Hi ---+"},
+		{11, "abc", "+---abc---+"},
+		{10, "abc", "+--abc---+"},
+		{6, "too long", "+too +"},
+	}
+
+	for _, tt := range tests {
+		got := DrawBox(tt.width, 2, tt.title, BoxASCII)[0]
+		if got != tt.want {
+			t.Errorf("DrawBox(%d, %q) top = %q, want %q", tt.width, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestSetBoxStyle(t *testing.T) {
+	e := New()
+	for _, style := range []BoxStyle{BoxSingle, BoxDouble, BoxRounded, BoxASCII} {
+		e.setBoxStyle(style)
+		if e.boxStyle != style || e.box != style.Chars() {
+			t.Errorf("setBoxStyle(%d): style %d, glyphs %+v", style, e.boxStyle, e.box)
+		}
+		if got := e.asciiBoxes(); got != (style == BoxASCII) {
+			t.Errorf("setBoxStyle(%d): asciiBoxes() = %v", style, got)
+		}
+	}
+}
+
+func TestBoxStyleFromConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		ascii bool
+		want  BoxStyle
+	}{
+		{"", false, BoxSingle},
+		{"double", false, BoxDouble},
+		{"Rounded", false, BoxRounded},
+		{"ascii", false, BoxASCII},
+		{"bogus", false, BoxSingle},
+		{"double", true, BoxASCII}, // ASCII mode wins
+	}
+
+	for _, tt := range tests {
+		cfg := config.DefaultConfig()
+		cfg.Editor.BoxStyle = tt.name
+		cfg.Editor.AsciiMode = &tt.ascii
+		e := NewWithConfig(cfg)
+		if e.boxStyle != tt.want || e.box != tt.want.Chars() {
+			t.Errorf("box_style %q, ascii %v: style %d, want %d", tt.name, tt.ascii, e.boxStyle, tt.want)
+		}
+	}
+}
+
+func TestASCIIModeDrawsNoUnicode(t *testing.T) {
+	e := New()
+	e.setBoxStyle(BoxASCII)
+	e.OnResize(120, 50)
+	e.config.FavoriteDirs = []string{"/tmp"}
+	e.loadDirectory(t.TempDir())
+	content := strings.TrimSuffix(strings.Repeat(strings.Repeat(" ", 120)+"\n", 48), "\n")
+
+	isASCII := func(s string) bool {
+		return strings.IndexFunc(s, func(r rune) bool { return r > 0x7f }) < 0
+	}
+	if out := e.overlayFileBrowser(content); !isASCII(out) || !strings.Contains(out, "* Favorites") {
+		t.Errorf("file browser in ASCII mode:\n%s", out)
+	}
+
+	e.mode = ModeFind
+	e.findQuery = "x"
+	if out := e.View(); !strings.Contains(out, "Find: x_") {
+		t.Errorf("find bar in ASCII mode has no ASCII cursor:\n%s", out)
+	}
+}
+
+// isBoxGlyph reports whether r is a Unicode box-drawing or block character
+func isBoxGlyph(r rune) bool {
+	return r >= 0x2500 && r <= 0x259F
+}
+
+func TestDialogsFollowBoxStyle(t *testing.T) {
+	dialogs := []struct {
+		name    string
+		overlay func(e *Editor, content string) string
+	}{
+		{"about", (*Editor).overlayAboutDialog},
+		{"help", (*Editor).overlayHelpDialog},
+		{"theme", (*Editor).overlayThemeDialog},
+		{"settings", (*Editor).overlaySettingsDialog},
+		{"encoding", (*Editor).overlayEncodingDialog},
+		{"file browser", (*Editor).overlayFileBrowser},
+	}
+
+	for _, style := range []BoxStyle{BoxASCII, BoxSingle} {
+		for _, d := range dialogs {
+			e := New()
+			e.setBoxStyle(style)
+			e.OnResize(120, 50)
+			e.fileBrowserDir = t.TempDir()
+			content := strings.TrimSuffix(strings.Repeat(strings.Repeat(" ", 120)+"\n", 48), "\n")
+
+			out := d.overlay(e, content)
+			hasBox := strings.IndexFunc(out, isBoxGlyph) >= 0
+			if style == BoxASCII && hasBox {
+				t.Errorf("%s dialog in ASCII mode contains box-drawing glyphs:\n%s", d.name, out)
+			}
+			if style == BoxSingle && !strings.Contains(out, "┌") {
+				t.Errorf("%s dialog in Unicode mode has no box-drawing border:\n%s", d.name, out)
+			}
+			if style == BoxASCII && !strings.Contains(out, "+-") {
+				t.Errorf("%s dialog in ASCII mode has no ASCII border:\n%s", d.name, out)
+			}
+		}
+	}
+}
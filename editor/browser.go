@@ -385,7 +385,7 @@ func (e *Editor) browserEnterDirectory() bool {
 		e.browserGoToParent()
 		return true
 	}
-	if entry.Name == FavoritesEntryName {
+	if entry.IsSpecial && entry.Name == FavoritesEntryName {
 		e.loadFavorites()
 		return true
 	}
@@ -624,8 +624,17 @@ func (e *Editor) showSaveAs() {
 	e.mode = ModeSaveAs
 }
 
-// FavoritesEntryName is the display name for the favorites virtual directory
-const FavoritesEntryName = "★ Favorites"
+// FavoritesEntryName is the name of the favorites virtual directory. It is
+// drawn after a star; see browserEntryName.
+const FavoritesEntryName = "Favorites"
+
+// browserEntryName returns the name the file browser shows for entry
+func (e *Editor) browserEntryName(entry FileEntry) string {
+	if entry.IsSpecial && entry.Name == FavoritesEntryName {
+		return e.box.Star + " " + entry.Name
+	}
+	return entry.Name
+}
 
 // loadDirectory reads the contents of a directory and populates the file browser
 func (e *Editor) loadDirectory(path string) {
@@ -873,11 +882,8 @@ func (e *Editor) overlayFileBrowser(viewportContent string) string {
 	dialogLines = append(dialogLines, e.box.TeeLeft+strings.Repeat(e.box.Horizontal, innerWidth)+e.box.TeeRight)
 
 	// File list
-	// Prefix width: star (★) or space, plus space = 2 visual chars
-	starChar := "★"
-	if e.box.Lock == "*" {
-		starChar = "*" // ASCII mode
-	}
+	// Prefix width: star or space, plus space = 2 visual chars
+	starChar := e.box.Star
 	prefixWidth := 2
 	for i := 0; i < visibleHeight; i++ {
 		idx := e.fileBrowserScroll + i
@@ -889,14 +895,14 @@ func (e *Editor) overlayFileBrowser(viewportContent string) string {
 			if entry.IsFavorite {
 				prefix = starChar + " "
 			} else if entry.IsSpecial {
-				prefix = "  " // Special entries (.. and ★ Favorites) get no star
+				prefix = "  " // Special entries (.. and Favorites) get no star
 			} else {
 				prefix = "  "
 			}
 
 			// Truncate filename if needed (leave room for prefix and size column)
 			nameWidth := 34 // Reduced to make room for star prefix
-			name := entry.Name
+			name := e.browserEntryName(entry)
 			if runewidth.StringWidth(name) > nameWidth {
 				name = runewidth.Truncate(name, nameWidth-1, e.box.Ellipsis)
 			}
@@ -976,7 +982,7 @@ func (e *Editor) overlayFileBrowser(viewportContent string) string {
 			styledLine.WriteString("\033[0m")
 
 			// Overlay on viewport line
-			viewportLines[viewportY] = overlayLineAt(styledLine.String(), viewportLines[viewportY], startX)
+			viewportLines[viewportY] = ui.OverlayAt(styledLine.String(), viewportLines[viewportY], startX)
 		}
 	}
 
@@ -1090,10 +1096,7 @@ func (e *Editor) overlaySaveAs(viewportContent string) string {
 	dialogLines = append(dialogLines, e.box.TeeLeft+strings.Repeat(e.box.Horizontal, innerWidth)+e.box.TeeRight)
 
 	// File list
-	starChar := "★"
-	if e.box.Lock == "*" {
-		starChar = "*" // ASCII mode
-	}
+	starChar := e.box.Star
 	for i := 0; i < visibleHeight; i++ {
 		idx := e.fileBrowserScroll + i
 		if idx < len(e.fileBrowserEntries) {
@@ -1109,7 +1112,7 @@ func (e *Editor) overlaySaveAs(viewportContent string) string {
 
 			// Truncate filename if needed (leave room for prefix and size column)
 			nameWidth := 34 // Reduced to make room for star prefix
-			name := entry.Name
+			name := e.browserEntryName(entry)
 			if runewidth.StringWidth(name) > nameWidth {
 				name = runewidth.Truncate(name, nameWidth-1, e.box.Ellipsis)
 			}
@@ -1193,7 +1196,7 @@ func (e *Editor) overlaySaveAs(viewportContent string) string {
 			styledLine.WriteString("\033[0m")
 
 			// Overlay on viewport line
-			viewportLines[viewportY] = overlayLineAt(styledLine.String(), viewportLines[viewportY], startX)
+			viewportLines[viewportY] = ui.OverlayAt(styledLine.String(), viewportLines[viewportY], startX)
 		}
 	}
 
@@ -11,6 +11,7 @@ type Buffer struct {
 	data     []byte
 	gapStart int // Start of the gap (cursor position in logical text)
 	gapEnd   int // End of the gap (exclusive)
+	version  int // Bumped by every change to the text
 }
 
 const initialGapSize = 1024
@@ -37,6 +38,12 @@ func (b *Buffer) Length() int {
 	return len(b.data) - b.gapSize()
 }
 
+// Version returns a counter that changes whenever the text does, so callers
+// can tell cheaply whether work derived from the text is out of date.
+func (b *Buffer) Version() int {
+	return b.version
+}
+
 // gapSize returns the current size of the gap.
 func (b *Buffer) gapSize() int {
 	return b.gapEnd - b.gapStart
@@ -102,6 +109,7 @@ func (b *Buffer) Insert(s string) {
 	b.expandGap(len(s))
 	copy(b.data[b.gapStart:], s)
 	b.gapStart += len(s)
+	b.version++
 }
 
 // InsertRune inserts a single rune at the current cursor position.
@@ -111,6 +119,7 @@ func (b *Buffer) InsertRune(r rune) {
 	b.expandGap(n)
 	copy(b.data[b.gapStart:], buf[:n])
 	b.gapStart += n
+	b.version++
 }
 
 // DeleteBefore deletes n bytes before the cursor.
@@ -123,6 +132,7 @@ func (b *Buffer) DeleteBefore(n int) string {
 	}
 	deleted := string(b.data[b.gapStart-n : b.gapStart])
 	b.gapStart -= n
+	b.version++
 	return deleted
 }
 
@@ -137,6 +147,7 @@ func (b *Buffer) DeleteAfter(n int) string {
 	}
 	deleted := string(b.data[b.gapEnd : b.gapEnd+n])
 	b.gapEnd += n
+	b.version++
 	return deleted
 }
 
@@ -0,0 +1,69 @@
+package editor
+
+import "strings"
+
+// lineCommentPrefixes maps lexer names (lowercased) to their line-comment prefix.
+var lineCommentPrefixes = map[string]string{
+	"go": "//", "c": "//", "c++": "//", "c#": "//", "java": "//",
+	"javascript": "//", "typescript": "//", "tsx": "//", "rust": "//",
+	"swift": "//", "kotlin": "//", "scala": "//", "dart": "//", "php": "//",
+	"groovy": "//", "zig": "//", "protocol buffer": "//",
+	"python": "#", "python 2": "#", "bash": "#", "ruby": "#", "perl": "#",
+	"yaml": "#", "toml": "#", "makefile": "#", "r": "#", "docker": "#",
+	"powershell": "#", "nim": "#", "elixir": "#", "cmake": "#",
+	"sql": "--", "lua": "--", "haskell": "--", "ada": "--", "plpgsql": "--",
+}
+
+// LineCommentPrefix returns the line-comment prefix for a lexer name,
+// or "" if the language has no known line comment.
+func LineCommentPrefix(lang string) string {
+	return lineCommentPrefixes[strings.ToLower(lang)]
+}
+
+// ToggleLineComments comments or uncomments lines using the line-comment
+// prefix for lang. If every non-blank line already starts with the prefix
+// (after indentation) the block is uncommented, otherwise it is commented.
+// The prefix goes after each line's leading whitespace so indentation is
+// preserved. Lines are returned unchanged if lang has no line comment.
+func ToggleLineComments(lines []string, lang string) []string {
+	out := make([]string, len(lines))
+	copy(out, lines)
+
+	prefix := LineCommentPrefix(lang)
+	if prefix == "" {
+		return out
+	}
+
+	commented := true
+	hasContent := false
+	for _, line := range lines {
+		body := strings.TrimLeft(line, " \t")
+		if body == "" {
+			continue
+		}
+		hasContent = true
+		if !strings.HasPrefix(body, prefix) {
+			commented = false
+			break
+		}
+	}
+	if !hasContent {
+		return out
+	}
+
+	for i, line := range lines {
+		indent := leadingWhitespace(line)
+		body := line[len(indent):]
+		if body == "" {
+			continue
+		}
+		if commented {
+			body = strings.TrimPrefix(body, prefix)
+			body = strings.TrimPrefix(body, " ")
+		} else {
+			body = prefix + " " + body
+		}
+		out[i] = indent + body
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToggleLineComments(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		lang  string
+		want  []string
+	}{
+		{
+			"go comment",
+			[]string{"func main() {", "\tfmt.Println()", "}"},
+			"Go",
+			[]string{"// func main() {", "\t// fmt.Println()", "// }"},
+		},
+		{
+			"go uncomment",
+			[]string{"// func main() {", "\t// fmt.Println()", "// }"},
+			"Go",
+			[]string{"func main() {", "\tfmt.Println()", "}"},
+		},
+		{
+			"python comment keeps blank lines",
+			[]string{"def f():", "", "    return 1"},
+			"Python",
+			[]string{"# def f():", "", "    # return 1"},
+		},
+		{
+			"sql comment",
+			[]string{"SELECT 1;"},
+			"SQL",
+			[]string{"-- SELECT 1;"},
+		},
+		{
+			"mixed block uncomments fully",
+			[]string{"  //a", "", "    // b", "//  c"},
+			"Go",
+			[]string{"  a", "", "    b", " c"},
+		},
+		{
+			"partially commented block is commented",
+			[]string{"// a", "b"},
+			"Go",
+			[]string{"// // a", "// b"},
+		},
+		{
+			"unknown language unchanged",
+			[]string{"text"},
+			"Plaintext",
+			[]string{"text"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := ToggleLineComments(tt.lines, tt.lang)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: ToggleLineComments(%q, %q) = %q, want %q", tt.name, tt.lines, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -13,8 +13,9 @@ type Position struct {
 
 // Cursor manages the cursor position within a buffer.
 type Cursor struct {
-	buf *Buffer
-	pos int // Byte offset in the buffer
+	buf     *Buffer
+	pos     int // Byte offset in the buffer
+	virtual int // Columns past the end of the line, in virtual space mode
 }
 
 // NewCursor creates a new cursor for the given buffer.
@@ -45,62 +46,95 @@ func (c *Cursor) SetByteOffset(offset int) {
 		offset = c.buf.Length()
 	}
 	c.pos = offset
-	c.buf.MoveCursor(offset)
+	c.moved()
 }
 
 // SetPosition sets the cursor to a specific line and column.
 func (c *Cursor) SetPosition(line, col int) {
 	c.pos = c.buf.LineColToPosition(line, col)
-	c.buf.MoveCursor(c.pos)
+	c.moved()
 }
 
-// MoveLeft moves the cursor left by one character (rune).
+// MoveLeft moves the cursor left by one character (grapheme cluster).
 func (c *Cursor) MoveLeft() bool {
+	if c.virtual > 0 {
+		c.virtual--
+		return true
+	}
 	if c.pos == 0 {
 		return false
 	}
-	// Move back through UTF-8 bytes to find the previous rune start
-	c.pos--
-	for c.pos > 0 && !utf8.RuneStart(c.buf.ByteAt(c.pos)) {
+	if c.buf.ByteAt(c.pos-1) == '\n' {
 		c.pos--
+	} else {
+		// Step back over a whole grapheme cluster within the line
+		line, _ := c.buf.PositionToLineCol(c.pos)
+		start := c.buf.LineStartOffset(line)
+		c.pos -= lastGraphemeLen(c.buf.Substring(start, c.pos))
 	}
-	c.buf.MoveCursor(c.pos)
+	c.moved()
 	return true
 }
 
-// MoveRight moves the cursor right by one character (rune).
+// MoveRight moves the cursor right by one character (grapheme cluster).
 func (c *Cursor) MoveRight() bool {
 	if c.pos >= c.buf.Length() {
 		return false
 	}
-	_, size := c.buf.RuneAt(c.pos)
+	r, size := c.buf.RuneAt(c.pos)
 	if size == 0 {
 		return false
 	}
-	c.pos += size
-	c.buf.MoveCursor(c.pos)
+	if r == '\n' {
+		c.pos += size
+	} else {
+		line, _ := c.buf.PositionToLineCol(c.pos)
+		end := c.buf.LineEndOffset(line)
+		c.pos += firstGraphemeLen(c.buf.Substring(c.pos, end))
+	}
+	c.moved()
+	return true
+}
+
+// MoveRightVirtual moves right like MoveRight, except that at the end of a
+// line it steps into virtual space past the end instead of wrapping to the
+// next line. The buffer is not changed.
+func (c *Cursor) MoveRightVirtual() bool {
+	line, _ := c.buf.PositionToLineCol(c.pos)
+	if c.pos < c.buf.LineEndOffset(line) {
+		return c.MoveRight()
+	}
+	c.virtual++
 	return true
 }
 
+// Virtual returns how many columns past the end of the line the cursor is.
+func (c *Cursor) Virtual() int {
+	return c.virtual
+}
+
+// ClearVirtual pulls the cursor back from virtual space to the line end.
+func (c *Cursor) ClearVirtual() {
+	c.virtual = 0
+}
+
 // MoveUp moves the cursor up one line, trying to maintain the column.
 func (c *Cursor) MoveUp() bool {
-	line, col := c.buf.PositionToLineCol(c.pos)
+	line, _ := c.buf.PositionToLineCol(c.pos)
 	if line == 0 {
 		return false
 	}
-	c.pos = c.buf.LineColToPosition(line-1, col)
-	c.buf.MoveCursor(c.pos)
+	c.moveToLine(line-1, c.runeCol())
 	return true
 }
 
 // MoveDown moves the cursor down one line, trying to maintain the column.
 func (c *Cursor) MoveDown() bool {
-	line, col := c.buf.PositionToLineCol(c.pos)
+	line, _ := c.buf.PositionToLineCol(c.pos)
 	if line >= c.buf.LineCount()-1 {
 		return false
 	}
-	c.pos = c.buf.LineColToPosition(line+1, col)
-	c.buf.MoveCursor(c.pos)
+	c.moveToLine(line+1, c.runeCol())
 	return true
 }
 
@@ -108,88 +142,106 @@ func (c *Cursor) MoveDown() bool {
 func (c *Cursor) MoveToLineStart() {
 	line, _ := c.buf.PositionToLineCol(c.pos)
 	c.pos = c.buf.LineStartOffset(line)
-	c.buf.MoveCursor(c.pos)
+	c.moved()
+}
+
+// MoveToSmartLineStart moves the cursor to the first non-blank character of
+// the line, or to column 0 if it is already there (see SmartHomeColumn).
+func (c *Cursor) MoveToSmartLineStart() {
+	line, _ := c.buf.PositionToLineCol(c.pos)
+	start := c.buf.LineStartOffset(line)
+	text := c.buf.Substring(start, c.buf.LineEndOffset(line))
+	col := utf8.RuneCountInString(c.buf.Substring(start, c.pos))
+	c.pos = start + runeColToByte(text, SmartHomeColumn(text, col))
+	c.moved()
+}
+
+// SmartHomeColumn returns the rune column Home should move to from
+// currentCol: the first non-blank character, or column 0 when the cursor is
+// already on it. Blank lines always go to column 0.
+func SmartHomeColumn(line string, currentCol int) int {
+	first := 0
+	for _, r := range line {
+		if r != ' ' && r != '\t' {
+			break
+		}
+		first++
+	}
+	if first == utf8.RuneCountInString(line) || currentCol == first {
+		return 0
+	}
+	return first
 }
 
 // MoveToLineEnd moves the cursor to the end of the current line.
 func (c *Cursor) MoveToLineEnd() {
 	line, _ := c.buf.PositionToLineCol(c.pos)
 	c.pos = c.buf.LineEndOffset(line)
-	c.buf.MoveCursor(c.pos)
+	c.moved()
 }
 
 // MoveToStart moves the cursor to the start of the buffer.
 func (c *Cursor) MoveToStart() {
 	c.pos = 0
-	c.buf.MoveCursor(c.pos)
+	c.moved()
 }
 
 // MoveToEnd moves the cursor to the end of the buffer.
 func (c *Cursor) MoveToEnd() {
 	c.pos = c.buf.Length()
-	c.buf.MoveCursor(c.pos)
+	c.moved()
 }
 
-// MoveWordLeft moves the cursor to the start of the previous word.
+// MoveWordLeft moves the cursor to the start of the previous word. At the
+// start of a line it moves to the start of the last word on the line above.
 func (c *Cursor) MoveWordLeft() bool {
 	if c.pos == 0 {
 		return false
 	}
 
-	// Skip any whitespace/punctuation before the word
-	for c.pos > 0 {
-		c.pos--
-		for c.pos > 0 && !utf8.RuneStart(c.buf.ByteAt(c.pos)) {
-			c.pos--
-		}
-		r, _ := c.buf.RuneAt(c.pos)
-		if isWordChar(r) {
-			break
-		}
+	line, _ := c.buf.PositionToLineCol(c.pos)
+	start := c.buf.LineStartOffset(line)
+	if c.pos == start {
+		line--
+		start = c.buf.LineStartOffset(line)
+		c.pos = c.buf.LineEndOffset(line)
 	}
 
-	// Move to the start of the word
-	for c.pos > 0 {
-		prevPos := c.pos - 1
-		for prevPos > 0 && !utf8.RuneStart(c.buf.ByteAt(prevPos)) {
-			prevPos--
-		}
-		r, _ := c.buf.RuneAt(prevPos)
-		if !isWordChar(r) {
-			break
-		}
-		c.pos = prevPos
-	}
+	text := c.buf.Substring(start, c.buf.LineEndOffset(line))
+	col := utf8.RuneCountInString(c.buf.Substring(start, c.pos))
+	c.pos = start + runeColToByte(text, PrevWordStart(text, col))
 
-	c.buf.MoveCursor(c.pos)
+	c.moved()
 	return true
 }
 
-// MoveWordRight moves the cursor to the start of the next word.
+// MoveWordRight moves the cursor to the start of the next word. At the end
+// of a line it moves to the first word on the line below.
 func (c *Cursor) MoveWordRight() bool {
 	if c.pos >= c.buf.Length() {
 		return false
 	}
 
-	// Skip current word
-	for c.pos < c.buf.Length() {
-		r, size := c.buf.RuneAt(c.pos)
-		if !isWordChar(r) {
-			break
+	line, _ := c.buf.PositionToLineCol(c.pos)
+	start := c.buf.LineStartOffset(line)
+	end := c.buf.LineEndOffset(line)
+	if c.pos >= end {
+		c.pos = end + 1
+		line++
+		start = c.pos
+		end = c.buf.LineEndOffset(line)
+		// Skip leading indentation on the new line
+		if r, _ := c.buf.RuneAt(start); r != ' ' && r != '\t' {
+			c.moved()
+			return true
 		}
-		c.pos += size
 	}
 
-	// Skip whitespace/punctuation to next word
-	for c.pos < c.buf.Length() {
-		r, size := c.buf.RuneAt(c.pos)
-		if isWordChar(r) {
-			break
-		}
-		c.pos += size
-	}
+	text := c.buf.Substring(start, end)
+	col := utf8.RuneCountInString(c.buf.Substring(start, c.pos))
+	c.pos = start + runeColToByte(text, NextWordStart(text, col))
 
-	c.buf.MoveCursor(c.pos)
+	c.moved()
 	return true
 }
 
@@ -210,6 +262,36 @@ func (c *Cursor) Col() int {
 	return col
 }
 
+// runeCol returns the cursor's column in runes, not counting virtual space.
+func (c *Cursor) runeCol() int {
+	line, _ := c.buf.PositionToLineCol(c.pos)
+	return utf8.RuneCountInString(c.buf.Substring(c.buf.LineStartOffset(line), c.pos))
+}
+
+// moveToLine moves the cursor to rune column col on line, or to the line
+// end if the line is shorter. A cursor in virtual space stays there, at the
+// same column counting one per virtual column, where the line is shorter
+// than that.
+func (c *Cursor) moveToLine(line, col int) {
+	want := col + c.virtual
+	start := c.buf.LineStartOffset(line)
+	text := c.buf.Substring(start, c.buf.LineEndOffset(line))
+	c.pos = start + runeColToByte(text, want)
+	virtual := 0
+	if c.virtual > 0 {
+		virtual = max(want-utf8.RuneCountInString(text), 0)
+	}
+	c.moved()
+	c.virtual = virtual
+}
+
+// moved ends every cursor movement: any virtual space is dropped and the
+// buffer's gap follows the cursor.
+func (c *Cursor) moved() {
+	c.virtual = 0
+	c.buf.MoveCursor(c.pos)
+}
+
 // Sync ensures the buffer's gap is at the cursor position.
 func (c *Cursor) Sync() {
 	c.buf.MoveCursor(c.pos)
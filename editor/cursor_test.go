@@ -0,0 +1,158 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSmartHomeColumn(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		col  int
+		want int
+	}{
+		{"from middle goes to first non-blank", "    foo", 6, 4},
+		{"from end goes to first non-blank", "    foo", 7, 4},
+		{"from first non-blank goes to zero", "    foo", 4, 0},
+		{"from zero goes to first non-blank", "    foo", 0, 4},
+		{"from inside indentation", "    foo", 2, 4},
+		{"tabs count as blank", "\t\tfoo", 3, 2},
+		{"unindented line", "foo", 2, 0},
+		{"unindented line at zero", "foo", 0, 0},
+		{"blank line", "    ", 2, 0},
+		{"empty line", "", 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := SmartHomeColumn(tt.line, tt.col); got != tt.want {
+			t.Errorf("%s: SmartHomeColumn(%q, %d) = %d, want %d", tt.name, tt.line, tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestVirtualSpace(t *testing.T) {
+	e := New()
+	e.config.Editor.VirtualSpace = true
+	e.insertText("ab\nlonger line")
+	e.activeDoc().cursor.SetPosition(0, 0)
+
+	right := tea.KeyMsg{Type: tea.KeyRight}
+	for i := 0; i < 5; i++ {
+		e.Update(right)
+	}
+	doc := e.activeDoc()
+	if doc.cursor.Line() != 0 || doc.cursor.Col() != 2 || doc.cursor.Virtual() != 3 {
+		t.Fatalf("after 5 rights: line %d col %d virtual %d, want line 0 col 2 virtual 3",
+			doc.cursor.Line(), doc.cursor.Col(), doc.cursor.Virtual())
+	}
+	if got := doc.buffer.String(); got != "ab\nlonger line" {
+		t.Errorf("moving into virtual space changed the buffer to %q", got)
+	}
+	if got := e.buildRenderState().CursorCol; got != 5 {
+		t.Errorf("render state CursorCol = %d, want 5", got)
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if got := doc.cursor.Virtual(); got != 2 {
+		t.Errorf("after left: virtual = %d, want 2", got)
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if got := doc.buffer.String(); got != "ab  x\nlonger line" {
+		t.Errorf("after typing: buffer = %q, want %q", got, "ab  x\nlonger line")
+	}
+	if doc.cursor.Col() != 5 || doc.cursor.Virtual() != 0 {
+		t.Errorf("after typing: col %d virtual %d, want col 5 virtual 0", doc.cursor.Col(), doc.cursor.Virtual())
+	}
+}
+
+func TestVirtualSpaceOff(t *testing.T) {
+	e := New()
+	e.insertText("ab\ncd")
+	e.activeDoc().cursor.SetPosition(0, 2)
+	e.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if doc := e.activeDoc(); doc.cursor.Line() != 1 || doc.cursor.Col() != 0 || doc.cursor.Virtual() != 0 {
+		t.Errorf("right at EOL without virtual space: line %d col %d virtual %d, want next line start",
+			doc.cursor.Line(), doc.cursor.Col(), doc.cursor.Virtual())
+	}
+}
+
+func TestVirtualSpaceAcrossLines(t *testing.T) {
+	e := New()
+	e.config.Editor.VirtualSpace = true
+	e.insertText("ab\nx\nlonger line")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(0, 0)
+	for i := 0; i < 5; i++ {
+		e.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if doc.cursor.Line() != 1 || doc.cursor.Col() != 1 || doc.cursor.Virtual() != 4 {
+		t.Errorf("down onto a shorter line: line %d col %d virtual %d, want line 1 col 1 virtual 4",
+			doc.cursor.Line(), doc.cursor.Col(), doc.cursor.Virtual())
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if doc.cursor.Line() != 2 || doc.cursor.Col() != 5 || doc.cursor.Virtual() != 0 {
+		t.Errorf("down onto a longer line: line %d col %d virtual %d, want line 2 col 5 virtual 0",
+			doc.cursor.Line(), doc.cursor.Col(), doc.cursor.Virtual())
+	}
+}
+
+func TestVirtualSpaceAcrossMultibyteLines(t *testing.T) {
+	e := New()
+	e.config.Editor.VirtualSpace = true
+	e.insertText("éé\nx\nлиния")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(0, 0)
+	for i := 0; i < 4; i++ {
+		e.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+
+	// Two characters and two virtual columns make column 4, not the 4
+	// bytes of "éé" plus two
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if doc.cursor.Line() != 1 || doc.cursor.Col() != 1 || doc.cursor.Virtual() != 3 {
+		t.Errorf("down onto a shorter line: line %d col %d virtual %d, want line 1 col 1 virtual 3",
+			doc.cursor.Line(), doc.cursor.Col(), doc.cursor.Virtual())
+	}
+	// Column 4 of "линия" is its fifth letter, at byte 8
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if doc.cursor.Line() != 2 || doc.cursor.Col() != 8 || doc.cursor.Virtual() != 0 {
+		t.Errorf("down onto a longer line: line %d col %d virtual %d, want line 2 col 8 virtual 0",
+			doc.cursor.Line(), doc.cursor.Col(), doc.cursor.Virtual())
+	}
+}
+
+func TestVirtualSpaceUndoesWithInsert(t *testing.T) {
+	e := New()
+	e.config.Editor.VirtualSpace = true
+	e.insertText("ab\ncd")
+	doc := e.activeDoc()
+	doc.undoStack.BreakMerge()
+	doc.cursor.SetPosition(0, 2)
+	e.Update(tea.KeyMsg{Type: tea.KeyRight})
+	e.Update(tea.KeyMsg{Type: tea.KeyRight})
+
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if got := doc.buffer.String(); got != "ab  x\ncd" {
+		t.Fatalf("after typing: buffer = %q, want %q", got, "ab  x\ncd")
+	}
+	e.undo()
+	if got := doc.buffer.String(); got != "ab\ncd" {
+		t.Errorf("one undo left %q, want %q", got, "ab\ncd")
+	}
+
+	doc.cursor.SetPosition(0, 2)
+	e.Update(tea.KeyMsg{Type: tea.KeyRight})
+	e.Update(tea.KeyMsg{Type: tea.KeyDelete})
+	if got := doc.buffer.String(); got != "ab cd" {
+		t.Fatalf("after delete: buffer = %q, want %q", got, "ab cd")
+	}
+	e.undo()
+	if got := doc.buffer.String(); got != "ab\ncd" {
+		t.Errorf("one undo after delete left %q, want %q", got, "ab\ncd")
+	}
+}
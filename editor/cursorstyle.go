@@ -0,0 +1,69 @@
+package editor
+
+import "github.com/cornish/textivus-editor/ui"
+
+// cursorStyleMode names the group of modes a cursor_style_modes entry
+// applies to.
+func cursorStyleMode(m Mode) string {
+	switch m {
+	case ModeNormal, ModeMenu:
+		return "normal"
+	case ModeFind, ModeFindReplace:
+		return "find"
+	case ModePrompt:
+		return "prompt"
+	default:
+		return "dialog"
+	}
+}
+
+// cursorStyleSequence returns the DECSCUSR escape for the configured cursor
+// style in the current mode, or "" when none is configured.
+func (e *Editor) cursorStyleSequence() string {
+	if e.config == nil {
+		return ""
+	}
+	style, ok := ui.ParseCursorStyle(e.config.Editor.CursorStyleFor(cursorStyleMode(e.mode)))
+	if !ok {
+		return ""
+	}
+	return style.Sequence()
+}
+
+// updateCursorStyle returns the escape to write when the cursor style for
+// the current mode differs from the one last written.
+func (e *Editor) updateCursorStyle() string {
+	seq := e.cursorStyleSequence()
+	if seq == "" {
+		if e.cursorStyle == "" {
+			return ""
+		}
+		seq = ui.ResetCursorStyle // This mode has no style; undo the last one
+	}
+	if seq == e.cursorStyle {
+		return ""
+	}
+	e.cursorStyle = seq
+	return seq
+}
+
+// RestoreCursorStyle returns the escape that gives the terminal back its
+// default cursor shape, or "" if the editor never changed it. Write it
+// after the program exits.
+func (e *Editor) RestoreCursorStyle() string {
+	if e.cursorStyle == "" || e.cursorStyle == ui.ResetCursorStyle {
+		return ""
+	}
+	return ui.ResetCursorStyle
+}
+
+// cursorShape returns the shape the text cursor is drawn in for the
+// current mode: the configured cursor style, or the default block when
+// none is set.
+func (e *Editor) cursorShape() ui.CursorStyle {
+	if e.config == nil {
+		return ui.CursorDefault
+	}
+	style, _ := ui.ParseCursorStyle(e.config.Editor.CursorStyleFor(cursorStyleMode(e.mode)))
+	return style
+}
@@ -0,0 +1,96 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+func TestCursorShapeByMode(t *testing.T) {
+	e := New()
+	e.config.Editor.CursorStyle = "bar"
+	e.config.Editor.CursorStyleModes = map[string]string{"find": "blinking-underline"}
+
+	tests := []struct {
+		mode Mode
+		want ui.CursorStyle
+	}{
+		{ModeNormal, ui.CursorBar},
+		{ModeFind, ui.CursorBlinkingUnderline},
+		{ModeHelp, ui.CursorBar},
+	}
+	for _, tt := range tests {
+		e.mode = tt.mode
+		if got := e.cursorShape(); got != tt.want {
+			t.Errorf("mode %d: cursorShape() = %d, want %d", tt.mode, got, tt.want)
+		}
+	}
+
+	e.mode = ModeNormal
+	if got := e.buildRenderState().CursorShape; got != ui.CursorBar {
+		t.Errorf("render state cursor shape = %d, want bar", got)
+	}
+}
+
+func TestCursorShapeUnset(t *testing.T) {
+	e := New()
+	e.config.Editor.CursorStyle = ""
+	if got := e.cursorShape(); got != ui.CursorDefault {
+		t.Errorf("with no cursor_style: cursorShape() = %d, want the default block", got)
+	}
+	e.config.Editor.CursorStyle = "beam"
+	if got := e.cursorShape(); got != ui.CursorDefault {
+		t.Errorf("with an unknown cursor_style: cursorShape() = %d, want the default block", got)
+	}
+}
+
+func TestCursorStyleEmission(t *testing.T) {
+	steps := []struct {
+		mode Mode
+		want string
+	}{
+		{ModeNormal, "\033[6 q"},
+		{ModeNormal, ""}, // Unchanged, nothing written
+		{ModeFind, "\033[3 q"},
+		{ModeHelp, "\033[6 q"},
+		{ModeNormal, ""},
+	}
+
+	e := New()
+	e.config.Editor.CursorStyle = "bar"
+	e.config.Editor.CursorStyleModes = map[string]string{"find": "blinking-underline"}
+	for i, s := range steps {
+		e.mode = s.mode
+		if got := e.updateCursorStyle(); got != s.want {
+			t.Errorf("step %d (mode %d): escape = %q, want %q", i, s.mode, got, s.want)
+		}
+	}
+	if got := e.RestoreCursorStyle(); got != "\033[0 q" {
+		t.Errorf("RestoreCursorStyle() = %q, want reset", got)
+	}
+}
+
+func TestCursorStyleUnset(t *testing.T) {
+	e := New()
+	e.config.Editor.CursorStyle = ""
+	if got := e.updateCursorStyle(); got != "" {
+		t.Errorf("with no cursor_style: escape = %q, want none", got)
+	}
+	if got := e.RestoreCursorStyle(); got != "" {
+		t.Errorf("with no cursor_style: RestoreCursorStyle() = %q, want none", got)
+	}
+
+	// A mode-only override is undone when leaving that mode
+	e.config.Editor.CursorStyleModes = map[string]string{"prompt": "block"}
+	e.mode = ModePrompt
+	if got := e.updateCursorStyle(); got != "\033[2 q" {
+		t.Errorf("prompt mode: escape = %q, want block", got)
+	}
+	e.mode = ModeNormal
+	if got := e.updateCursorStyle(); got != "\033[0 q" {
+		t.Errorf("back to normal: escape = %q, want reset", got)
+	}
+	if got := e.RestoreCursorStyle(); got != "" {
+		t.Errorf("after reset: RestoreCursorStyle() = %q, want none", got)
+	}
+}
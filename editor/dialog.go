@@ -43,19 +43,12 @@ func (e *Editor) NewDialogBuilder(width int) *DialogBuilder {
 
 // AddTitleBorder adds the top border with an embedded title
 func (db *DialogBuilder) AddTitleBorder(title string) {
-	titlePadLeft := (db.innerWidth - runewidth.StringWidth(title)) / 2
-	titlePadRight := db.innerWidth - runewidth.StringWidth(title) - titlePadLeft
-	line := db.box.TopLeft +
-		strings.Repeat(db.box.Horizontal, titlePadLeft) +
-		title +
-		strings.Repeat(db.box.Horizontal, titlePadRight) +
-		db.box.TopRight
-	db.lines = append(db.lines, line)
+	db.lines = append(db.lines, boxTopBorder(db.box, db.innerWidth, title))
 }
 
 // AddBottomBorder adds the bottom border
 func (db *DialogBuilder) AddBottomBorder() {
-	db.lines = append(db.lines, db.box.BottomLeft+strings.Repeat(db.box.Horizontal, db.innerWidth)+db.box.BottomRight)
+	db.lines = append(db.lines, boxBottomBorder(db.box, db.innerWidth))
 }
 
 // AddEmptyLine adds an empty line with borders
@@ -147,7 +140,7 @@ func (db *DialogBuilder) Overlay(viewportContent string, viewportWidth, viewport
 			styledLine.WriteString(db.themeUI.resetStyle)
 
 			// Overlay on viewport line
-			viewportLines[viewportY] = overlayLineAt(styledLine.String(), viewportLines[viewportY], startX)
+			viewportLines[viewportY] = ui.OverlayAt(styledLine.String(), viewportLines[viewportY], startX)
 		}
 	}
 
@@ -0,0 +1,50 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cornish/textivus-editor/config"
+	"github.com/cornish/textivus-editor/ui"
+)
+
+func TestDialogOverlayUsesThemeColors(t *testing.T) {
+	e := New()
+	e.styles = ui.NewStyles(config.GetTheme("default"))
+
+	db := e.NewDialogBuilder(20)
+	db.AddTitleBorder("Test")
+	db.AddBottomBorder()
+	out := db.Overlay(strings.Repeat("\n", 9), 40, 10)
+
+	theme := e.styles.Theme.UI
+	want := ui.ColorToANSIBg(theme.DialogBg) + ui.ColorToANSIFg(theme.DialogFg)
+	if !strings.Contains(out, want) {
+		t.Errorf("overlay does not contain dialog style %q:\n%q", want, out)
+	}
+}
+
+func TestDialogOverlayFollowsThemeChange(t *testing.T) {
+	e := New()
+
+	render := func() string {
+		db := e.NewDialogBuilder(20)
+		db.AddTitleBorder("Test")
+		db.AddBottomBorder()
+		return db.Overlay(strings.Repeat("\n", 9), 40, 10)
+	}
+
+	e.styles = ui.NewStyles(config.GetTheme("default"))
+	before := render()
+	e.styles = ui.NewStyles(config.GetTheme("dracula"))
+	after := render()
+
+	if before == after {
+		t.Fatal("overlay unchanged after switching theme")
+	}
+	theme := e.styles.Theme.UI
+	want := ui.ColorToANSIBg(theme.DialogBg) + ui.ColorToANSIFg(theme.DialogFg)
+	if !strings.Contains(after, want) {
+		t.Errorf("overlay does not contain new dialog style %q", want)
+	}
+}
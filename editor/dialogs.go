@@ -4,116 +4,13 @@ import (
 	"fmt"
 	"github.com/cornish/textivus-editor/config"
 	enc "github.com/cornish/textivus-editor/encoding"
+	"github.com/cornish/textivus-editor/quotes"
 	"github.com/cornish/textivus-editor/ui"
 	"strings"
 
 	"github.com/mattn/go-runewidth"
 )
 
-// overlayLineAt overlays the dropdown line on top of the viewport line at the given offset,
-// preserving viewport content on both sides of the dropdown (including ANSI color codes)
-func overlayLineAt(dropLine, viewportLine string, offset int) string {
-	// Calculate the visual width of the dropdown line (strip ANSI codes)
-	dropWidth := visualWidth(dropLine)
-
-	// Extract prefix and suffix from viewport line, preserving ANSI codes
-	prefix := sliceAnsiString(viewportLine, 0, offset)
-	suffix := sliceAnsiString(viewportLine, offset+dropWidth, -1)
-
-	// Build the result: prefix + dropdown + suffix
-	var result strings.Builder
-
-	// Prefix: viewport content before the dropdown (or spaces if line is short)
-	prefixWidth := visualWidth(prefix)
-	result.WriteString(prefix)
-	if prefixWidth < offset {
-		// Viewport line is shorter than offset - add padding
-		result.WriteString(strings.Repeat(" ", offset-prefixWidth))
-	}
-
-	// The dropdown itself
-	result.WriteString(dropLine)
-
-	// Suffix: viewport content after the dropdown (with ANSI codes preserved)
-	if suffix != "" {
-		result.WriteString(suffix)
-	}
-
-	return result.String()
-}
-
-// sliceAnsiString extracts a substring from an ANSI-coded string based on visual positions.
-// start and end are visual column positions (0-indexed). Use end=-1 for "to the end".
-// ANSI escape codes are preserved and passed through correctly.
-// Wide characters that would be split are excluded and replaced with spaces to maintain exact width.
-func sliceAnsiString(s string, start, end int) string {
-	var result strings.Builder
-	visualPos := 0
-	outputWidth := 0
-	inEscape := false
-	var escapeSeq strings.Builder
-
-	for _, r := range s {
-		if r == '\033' {
-			inEscape = true
-			escapeSeq.Reset()
-			escapeSeq.WriteRune(r)
-			continue
-		}
-
-		if inEscape {
-			escapeSeq.WriteRune(r)
-			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
-				inEscape = false
-				// Include escape sequences that appear within our range
-				// or at the boundary (to preserve color state)
-				if end == -1 || visualPos < end {
-					result.WriteString(escapeSeq.String())
-				}
-			}
-			continue
-		}
-
-		// Regular character - check if it's in our range
-		charWidth := runewidth.RuneWidth(r)
-		charEnd := visualPos + charWidth
-
-		if end != -1 && visualPos < end && charEnd > end {
-			// Character would extend past end boundary - skip it but pad with spaces
-			// (This handles wide chars that would be split)
-			spacesNeeded := end - visualPos
-			result.WriteString(strings.Repeat(" ", spacesNeeded))
-			outputWidth += spacesNeeded
-			visualPos = charEnd
-			continue
-		}
-
-		if visualPos >= start && (end == -1 || charEnd <= end) {
-			// Character fully within range
-			if visualPos > start && outputWidth == 0 {
-				// We're starting mid-string, might have skipped a wide char
-				// Pad with spaces to maintain alignment
-				result.WriteString(strings.Repeat(" ", visualPos-start))
-				outputWidth += visualPos - start
-			}
-			result.WriteRune(r)
-			outputWidth += charWidth
-		} else if visualPos < start && charEnd > start {
-			// Wide character straddles the start boundary - skip it, pad with space
-			spacesNeeded := charEnd - start
-			if end != -1 && start+spacesNeeded > end {
-				spacesNeeded = end - start
-			}
-			result.WriteString(strings.Repeat(" ", spacesNeeded))
-			outputWidth += spacesNeeded
-		}
-
-		visualPos = charEnd
-	}
-
-	return result.String()
-}
-
 // stripAnsi removes ANSI escape sequences from a string
 func stripAnsi(s string) string {
 	var result strings.Builder
@@ -144,7 +41,7 @@ func (e *Editor) overlayAboutDialog(viewportContent string) string {
 	// Use the stored quote (selected when dialog opened)
 	quote := e.aboutQuote
 	if quote == "" {
-		quote = "A Festivus for the rest of us!"
+		quote = quotes.Fallback
 	}
 
 	// Box dimensions - content is 64 chars, plus 2 for borders = 66
@@ -194,7 +91,7 @@ func (e *Editor) overlayAboutDialog(viewportContent string) string {
 
 	// Choose logo based on ASCII mode
 	var logoLines []string
-	if e.box.Lock == "*" {
+	if e.asciiBoxes() {
 		// ASCII mode - use asterisk art
 		logoLines = []string{
 			"     *****  *****  *   *  *****  ***  *   *  *   *   ****      ",
@@ -254,7 +151,7 @@ func (e *Editor) overlayAboutDialog(viewportContent string) string {
 		kittyStatus = "Yes"
 	}
 	aboutLines = append(aboutLines,
-		e.box.Vertical+centerText("─── Terminal ───")+e.box.Vertical,
+		e.box.Vertical+centerText(strings.Repeat(e.box.Horizontal, 3)+" Terminal "+strings.Repeat(e.box.Horizontal, 3))+e.box.Vertical,
 		e.box.Vertical+centerText(fmt.Sprintf("UTF-8: %s   Colors: %s   Kitty: %s", utf8Status, caps.ColorMode.String(), kittyStatus))+e.box.Vertical,
 		e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical,
 	)
@@ -302,7 +199,7 @@ func (e *Editor) overlayAboutDialog(viewportContent string) string {
 			styledLine.WriteString(resetStyle)
 
 			// Overlay on viewport line
-			viewportLines[viewportY] = overlayLineAt(styledLine.String(), viewportLines[viewportY], startX)
+			viewportLines[viewportY] = ui.OverlayAt(styledLine.String(), viewportLines[viewportY], startX)
 		}
 	}
 
@@ -364,6 +261,11 @@ func (e *Editor) overlayHelpDialog(viewportContent string) string {
 		fmtKey("copy", "Copy"),
 		fmtKey("paste", "Paste"),
 		fmtKey("cut_line", "Cut line"),
+		fmtKey("duplicate", "Duplicate"),
+		"  Alt+Up/Dn    Move lines",
+		fmtKey("join_lines", "Join lines"),
+		fmtKey("cycle_case", "Change case"),
+		"  Alt+=/-      Expand/shrink",
 		fmtKey("select_all", "Select all"),
 		"",
 		"  SEARCH",
@@ -381,6 +283,8 @@ func (e *Editor) overlayHelpDialog(viewportContent string) string {
 		fmtKey("doc_start", "Start of file"),
 		fmtKey("doc_end", "End of file"),
 		"  PgUp/PgDn    Page up/down",
+		"  Alt+PgUp/Dn  Half page up/down",
+		"  Alt+L/T/Z    Center/top/bottom",
 		fmtKey("goto_line", "Go to line"),
 		"",
 		"  SELECTION",
@@ -390,17 +294,11 @@ func (e *Editor) overlayHelpDialog(viewportContent string) string {
 		"  MOUSE: Click, Drag, Scroll",
 	}
 
-	// Build help lines
-	var helpLines []string
-
-	// Top border with title
-	title := " Keyboard Shortcuts "
-	titlePadLeft := (innerWidth - len(title)) / 2
-	titlePadRight := innerWidth - len(title) - titlePadLeft
-	helpLines = append(helpLines, e.box.TopLeft+strings.Repeat(e.box.Horizontal, titlePadLeft)+title+strings.Repeat(e.box.Horizontal, titlePadRight)+e.box.TopRight)
+	// Build the body rows, then frame them with a box of matching height
+	var body []string
 
 	// Empty line
-	helpLines = append(helpLines, e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical)
+	body = append(body, "")
 
 	// Build two-column content
 	maxRows := len(leftCol)
@@ -418,29 +316,32 @@ func (e *Editor) overlayHelpDialog(viewportContent string) string {
 		if i < len(rightCol) {
 			right = rightCol[i]
 		}
-		line := padText(left, colWidth) + colSep + padText(right, colWidth)
-		helpLines = append(helpLines, e.box.Vertical+line+e.box.Vertical)
+		body = append(body, padText(left, colWidth)+colSep+padText(right, colWidth))
 	}
 
 	// Empty line
-	helpLines = append(helpLines, e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical)
+	body = append(body, "")
 
 	// Options section
 	toggleLnKey := config.FormatKeyForDisplay(e.keybindings.GetBinding("toggle_line_numbers").Primary)
 	if toggleLnKey == "" {
 		toggleLnKey = "(none)"
 	}
-	helpLines = append(helpLines, e.box.Vertical+centerText("OPTIONS: "+toggleLnKey+" Line Numbers", innerWidth)+e.box.Vertical)
-	helpLines = append(helpLines, e.box.Vertical+centerText("MENUS: F10 or Alt+F/E/O/H", innerWidth)+e.box.Vertical)
+	body = append(body, centerText("OPTIONS: "+toggleLnKey+" Line Numbers", innerWidth))
+	body = append(body, centerText("MENUS: F10 or Alt+F/E/O/H", innerWidth))
 
 	// Empty line
-	helpLines = append(helpLines, e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical)
+	body = append(body, "")
 
 	// Footer
-	helpLines = append(helpLines, e.box.Vertical+centerText("Press any key to continue...", innerWidth)+e.box.Vertical)
+	body = append(body, centerText("Press any key to continue...", innerWidth))
 
-	// Bottom border
-	helpLines = append(helpLines, e.box.BottomLeft+strings.Repeat(e.box.Horizontal, innerWidth)+e.box.BottomRight)
+	helpLines := DrawBox(boxWidth, len(body)+2, " Keyboard Shortcuts ", e.boxStyle)
+	for i, row := range body {
+		if row != "" {
+			helpLines[i+1] = e.box.Vertical + padText(row, innerWidth) + e.box.Vertical
+		}
+	}
 
 	boxHeight := len(helpLines)
 
@@ -471,7 +372,7 @@ func (e *Editor) overlayHelpDialog(viewportContent string) string {
 			styledLine.WriteString(resetStyle)
 
 			// Overlay on viewport line
-			viewportLines[viewportY] = overlayLineAt(styledLine.String(), viewportLines[viewportY], startX)
+			viewportLines[viewportY] = ui.OverlayAt(styledLine.String(), viewportLines[viewportY], startX)
 		}
 	}
 
@@ -580,7 +481,7 @@ func (e *Editor) overlayThemeDialog(viewportContent string) string {
 			styledLine.WriteString(resetStyle)
 
 			// Overlay on viewport line
-			viewportLines[viewportY] = overlayLineAt(styledLine.String(), viewportLines[viewportY], startX)
+			viewportLines[viewportY] = ui.OverlayAt(styledLine.String(), viewportLines[viewportY], startX)
 		}
 	}
 
@@ -1005,7 +906,7 @@ func (e *Editor) overlayKeybindingsDialog(viewportContent string) string {
 			styledLine.WriteString(dialogLine)
 			styledLine.WriteString(resetStyle)
 
-			viewportLines[viewportY] = overlayLineAt(styledLine.String(), viewportLines[viewportY], startX)
+			viewportLines[viewportY] = ui.OverlayAt(styledLine.String(), viewportLines[viewportY], startX)
 		}
 	}
 
@@ -1,18 +1,22 @@
 package editor
 
 import (
+	"errors"
 	"fmt"
-	"math/rand"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/cornish/textivus-editor/clipboard"
 	"github.com/cornish/textivus-editor/config"
+	"github.com/cornish/textivus-editor/editorconfig"
 	enc "github.com/cornish/textivus-editor/encoding"
+	"github.com/cornish/textivus-editor/filemarks"
+	"github.com/cornish/textivus-editor/quotes"
 	"github.com/cornish/textivus-editor/syntax"
+	"github.com/cornish/textivus-editor/textio"
 	"github.com/cornish/textivus-editor/ui"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -48,7 +52,7 @@ type FileEntry struct {
 	Readable   bool   // For directories: whether we can read/enter it
 	IsFavorite bool   // Whether this item is favorited
 	FullPath   string // Full path (used in favorites view)
-	IsSpecial  bool   // True for special entries like "★ Favorites" or ".."
+	IsSpecial  bool   // True for special entries like Favorites or ".."
 }
 
 // BoxChars holds characters used for drawing dialog boxes
@@ -63,6 +67,8 @@ type BoxChars struct {
 	TeeRight    string
 	Lock        string
 	Ellipsis    string
+	Star        string // Marks favorites in the file browser
+	InputCursor string // Cursor at the end of the find and prompt bars
 }
 
 // UnicodeBoxChars provides Unicode box drawing characters
@@ -77,6 +83,8 @@ var UnicodeBoxChars = BoxChars{
 	TeeRight:    "┤",
 	Lock:        "🔒",
 	Ellipsis:    "…",
+	Star:        "★",
+	InputCursor: "▂", // Lower quarter block
 }
 
 // AsciiBoxChars provides ASCII fallback characters
@@ -91,6 +99,8 @@ var AsciiBoxChars = BoxChars{
 	TeeRight:    "+",
 	Lock:        "*",
 	Ellipsis:    "...",
+	Star:        "*",
+	InputCursor: "_",
 }
 
 // PromptAction represents what to do with the prompt result
@@ -124,52 +134,6 @@ func fileCheckCmd() tea.Cmd {
 	})
 }
 
-// FestivusQuotes are displayed randomly in the About dialog.
-// Feel free to add more Seinfeld Festivus quotes!
-var FestivusQuotes = []string{
-	"A Festivus for the rest of us!",
-	"I got a lot of problems with you people!",
-	"I find tinsel distracting.",
-	"It's a Festivus miracle!",
-	"Serenity now!",
-	"The tradition of Festivus begins with the airing of grievances.",
-	"Until you pin me, George, Festivus is not over!",
-	"No bagel, no bagel, no bagel!",
-	"I find your belief system fascinating.",
-	"This new holiday is scratching me right where I itch.",
-	"Weren't there feats of strength that ended up with you crying?",
-	"Instead there's a pole. Requires no decoration.",
-	"We don't care and it shows.",
-	"You couldn't smooth a silk sheet with a hot babe in it.",
-	"Another piece of the puzzle falls into place.",
-	"Instead of a tree didn't your father put up an aluminum pole?",
-	"Happy Festivus.",
-	"What's Festivus?",
-	"It's a stupid holiday my father invented. It doesn't exist.",
-	"Happy Festivus, Georgie.",
-	"Frank invented a holiday? He's so prolific.",
-	"As I rained blows upon him, I realized there had to be another way.",
-	"But out of that a new holiday was born.",
-	"Festivus is back!",
-	"I'll get the pole out of the crawl space.",
-	"What is that? Is that the pole?",
-	"Festivus is your heritage. It's part of who you are.",
-	"That's why I hate it.",
-	"You're just weak. You're weak.",
-	"It's time for the Festivus feats of strength.",
-	"I don't really celebrate Christmas. I celebrate Festivus.",
-	"I was afraid that I would be persecuted for my beliefs.",
-	"It's made from aluminum. Very high strength to weight ratio.",
-	"Not the feats of strength!",
-	"Oh, please. Somebody stop this.",
-	"Stop crying and fight your father.",
-	"I give! I give!",
-	"This is the best Festivus ever!",
-	"When George was growing up his father hated all the commercial religious aspects of Christmas, so he made up his own holiday.",
-	"At the Festivus dinner you gather your family around and tell them all the ways they have disappointed you over the past year.",
-	"George, you're forgetting how much Festivus has meant to us all.",
-}
-
 // Document holds the state for a single open file/buffer
 type Document struct {
 	buffer      *Buffer
@@ -180,8 +144,37 @@ type Document struct {
 	modified    bool
 	scrollY     int // viewport scroll position for this document
 	highlighter *syntax.Highlighter
-	modTime     time.Time     // file modification time when loaded/saved
-	encoding    *enc.Encoding // detected file encoding
+	disk        FileInfoSnapshot // file state on disk when loaded/saved
+	encoding    *enc.Encoding    // detected file encoding
+	savedLines  []string         // buffer lines as of the last load or save
+	dirtyLines  modifiedCache    // ModifiedLines result for the current text
+	readOnly    bool             // edits blocked: unwritable file or --readonly
+	binary      bool             // contents looked binary when loaded
+	rainbow     rainbowCache     // bracket depths for rainbow brackets
+
+	editorConfig    editorconfig.Settings // .editorconfig settings for the file
+	editorConfigFor string                // filename editorConfig was resolved for
+}
+
+// CanEdit reports whether the document may be modified.
+func (d *Document) CanEdit() bool {
+	return !d.readOnly
+}
+
+// IsBinary reports whether the document's file looked binary when loaded.
+func (d *Document) IsBinary() bool {
+	return d.binary
+}
+
+// cursorRuneCol returns the cursor's column in runes, counting virtual
+// space past the line end, as the viewport measures wrapped rows. The
+// cursor's own Col is a byte offset.
+func (d *Document) cursorRuneCol(lines []string) int {
+	col := d.cursor.Virtual()
+	if line := d.cursor.Line(); line < len(lines) {
+		col += utf8.RuneCountInString(lines[line][:min(d.cursor.Col(), len(lines[line]))])
+	}
+	return col
 }
 
 // Editor is the main Bubbletea model for the text editor
@@ -189,6 +182,7 @@ type Editor struct {
 	// Documents (multiple buffer support)
 	documents []*Document
 	activeIdx int
+	watcher   *FileWatcher // Reports open files changed on disk
 
 	// Shared components
 	clipboard *clipboard.Clipboard
@@ -199,11 +193,13 @@ type Editor struct {
 	viewport  *ui.Viewport
 	scrollbar *ui.Scrollbar
 	styles    ui.Styles
-	box       BoxChars // Characters for drawing dialog boxes
+	boxStyle  BoxStyle // Style of dialog borders and other decorations
+	box       BoxChars // Characters for drawing dialog boxes, from boxStyle
 
 	// Column-based rendering
 	compositor       *ui.Compositor
 	lineNumRenderer  *ui.LineNumberRenderer
+	modifiedGutter   *ui.ModifiedGutterRenderer
 	textRenderer     *ui.TextRenderer
 	minimapRenderer  ui.MinimapController
 	scrollbarAdapter *ui.ScrollbarColumnAdapter
@@ -216,6 +212,10 @@ type Editor struct {
 	// Find mode state
 	findQuery  string
 	findActive bool
+	isearch    *ISearch // Find-as-you-type state, started by the first character typed
+
+	// Ranges the selection was expanded from, for shrinking back
+	selectionHistory SelectionHistory
 
 	// Find and Replace mode state
 	replaceQuery string
@@ -234,17 +234,22 @@ type Editor struct {
 	// Terminal state
 	pendingTitle   string // Title to set on next render
 	pendingEscapes string // Escape sequences to output on next render (e.g., clear Kitty graphics)
+	cursorStyle    string // DECSCUSR escape last written for the terminal cursor shape
 
 	// Mouse state
-	mouseDown   bool
-	mouseStartX int
-	mouseStartY int
+	mouseDown    bool
+	mouseStartX  int
+	mouseStartY  int
+	gutterDrag   bool // The press was on a line number: dragging selects whole lines
+	gutterAnchor int  // Line the line number press was on
 
 	// Key throttling
 	lastPageKey time.Time
 
 	// About dialog state
 	aboutQuote string
+	quotes     *quotes.Provider // Loaded the first time the About dialog opens
+	fileMarks  *filemarks.Store // Cursor positions per file, loaded on first use
 
 	// File browser state (shared with Save As)
 	fileBrowserDir       string      // Current directory
@@ -272,6 +277,7 @@ type Editor struct {
 	// Configuration
 	config      *config.Config
 	keybindings *config.KeybindingsConfig
+	readOnly    bool // --readonly: open every file read-only
 
 	// Keybindings dialog state
 	kbDialogIndex     int    // Selected action index
@@ -431,6 +437,18 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 		e.cutLine()
 		return true, nil
 	}
+	if e.matchesBinding(keyStr, "duplicate") {
+		e.duplicate()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "join_lines") {
+		e.joinLines()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "cycle_case") {
+		e.cycleSelectionCase()
+		return true, nil
+	}
 	if e.matchesBinding(keyStr, "select_all") {
 		e.selectAll()
 		return true, nil
@@ -441,6 +459,7 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 		e.mode = ModeFind
 		e.findQuery = ""
 		e.findActive = true
+		e.isearch = nil
 		e.updateViewportSize()
 		return true, nil
 	}
@@ -515,14 +534,25 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 // fileChangedOnDisk checks if the file has been modified externally since last load/save
 func (e *Editor) fileChangedOnDisk() bool {
 	doc := e.activeDoc()
-	if doc.filename == "" || doc.modTime.IsZero() {
+	if doc.filename == "" || !doc.disk.Exists {
 		return false
 	}
-	fileInfo, err := os.Stat(doc.filename)
-	if err != nil {
-		return false // File doesn't exist or can't be read
+	// A deleted file is not a conflict: saving simply recreates it
+	now := SnapshotFile(doc.filename)
+	return now.Exists && !now.Equal(doc.disk)
+}
+
+// handleDiskChange is the file watcher's hook for files changed externally.
+func (e *Editor) handleDiskChange(path string, now FileInfoSnapshot) {
+	name := filepath.Base(path)
+	if path == e.activeDoc().filename {
+		name = "File"
+	}
+	if !now.Exists {
+		e.statusbar.SetMessage(name+" deleted on disk!", "error")
+	} else {
+		e.statusbar.SetMessage(name+" changed on disk!", "error")
 	}
-	return fileInfo.ModTime().After(doc.modTime)
 }
 
 // New creates a new editor instance with default config
@@ -533,16 +563,16 @@ func New() *Editor {
 // NewWithConfig creates a new editor instance with the given configuration
 func NewWithConfig(cfg *config.Config) *Editor {
 	// Create styles from the configured theme
-	theme := cfg.Theme.GetResolved()
+	theme, themeErr := cfg.ResolvedTheme()
 	styles := ui.NewStyles(theme)
 
 	// Determine ASCII mode: config override or auto-detect from capabilities
 	caps := config.GetCapabilities()
 	asciiMode := caps.ShouldUseASCII(cfg.Editor.AsciiMode)
 
-	box := UnicodeBoxChars
+	boxStyle := ParseBoxStyle(cfg.Editor.BoxStyle)
 	if asciiMode {
-		box = AsciiBoxChars
+		boxStyle = BoxASCII
 	}
 
 	// Create the initial document
@@ -557,6 +587,7 @@ func NewWithConfig(cfg *config.Config) *Editor {
 		modified:    false,
 		scrollY:     0,
 		encoding:    enc.GetEncodingByID("utf-8"), // Default to UTF-8
+		savedLines:  []string{""},
 	}
 
 	scrollbar := ui.NewScrollbar(styles)
@@ -578,7 +609,6 @@ func NewWithConfig(cfg *config.Config) *Editor {
 		viewport:    ui.NewViewport(styles),
 		scrollbar:   scrollbar,
 		styles:      styles,
-		box:         box,
 		mode:        ModeNormal,
 		width:       80,
 		height:      24,
@@ -586,14 +616,18 @@ func NewWithConfig(cfg *config.Config) *Editor {
 		keybindings: config.LoadKeybindings(),
 		// Initialize column renderers
 		lineNumRenderer:  ui.NewLineNumberRenderer(styles),
+		modifiedGutter:   ui.NewModifiedGutterRenderer(styles),
 		textRenderer:     ui.NewTextRenderer(styles),
 		minimapRenderer:  minimapRenderer,
 		scrollbarAdapter: ui.NewScrollbarColumnAdapter(scrollbar),
 	}
+	e.setBoxStyle(boxStyle)
 
 	// Initialize compositor with default dimensions
 	e.compositor = ui.NewCompositor(80, 22) // Will be resized on first render
 
+	e.watcher = NewFileWatcher(e.handleDiskChange)
+
 	// Update menu shortcuts from keybindings config
 	e.menubar.UpdateShortcuts(e.keybindings)
 
@@ -601,6 +635,10 @@ func NewWithConfig(cfg *config.Config) *Editor {
 	if cfg != nil {
 		e.viewport.SetWordWrap(cfg.Editor.WordWrap)
 		e.viewport.ShowLineNumbers(cfg.Editor.LineNumbers)
+		e.viewport.SetScrollOff(cfg.Editor.ScrollOff)
+		e.modifiedGutter.SetEnabled(cfg.Editor.ModifiedGutter)
+		e.lineNumRenderer.SetHighlightActive(cfg.Editor.HighlightActiveGutter)
+		e.lineNumRenderer.SetRadix(cfg.Editor.LineNumberRadix)
 
 		// Update menu checkboxes to reflect config
 		if cfg.Editor.WordWrap {
@@ -618,9 +656,11 @@ func NewWithConfig(cfg *config.Config) *Editor {
 			e.activeDoc().highlighter.SetEnabled(false)
 		}
 
-		// Apply true color setting (default is true)
-		if cfg.Editor.TrueColor != nil && !*cfg.Editor.TrueColor {
-			ui.UseTrueColor = false
+		// Apply color depth: color_mode or true_color override, else detected
+		ui.ColorDepth = caps.ResolveColorMode(cfg.Editor.ColorMode, cfg.Editor.TrueColor)
+		syntax.ColorConverter = ui.ColorToANSIFg
+		if len(cfg.Editor.RainbowPalette) > 0 {
+			syntax.RainbowPalette = cfg.Editor.RainbowPalette
 		}
 
 		// Apply scrollbar setting
@@ -628,6 +668,7 @@ func NewWithConfig(cfg *config.Config) *Editor {
 			e.scrollbar.SetEnabled(true)
 			e.menubar.SetItemLabel(ui.ActionScrollbar, "[x] Scrollbar")
 		}
+		e.scrollbar.SetGlyphs(cfg.Editor.ScrollbarTrack, cfg.Editor.ScrollbarThumb)
 		// Update viewport to account for scrollbar width
 		e.viewport.SetScrollbarWidth(e.scrollbar.Width())
 
@@ -636,6 +677,7 @@ func NewWithConfig(cfg *config.Config) *Editor {
 			e.minimapRenderer.SetEnabled(true)
 			e.menubar.SetItemLabel(ui.ActionMinimap, "[x] Minimap")
 		}
+		e.minimapRenderer.SetDensityThreshold(cfg.Editor.MinimapDensity)
 
 		// Apply theme syntax colors
 		e.activeDoc().highlighter.SetColors(syntax.SyntaxColors{
@@ -648,14 +690,45 @@ func NewWithConfig(cfg *config.Config) *Editor {
 			Type:     theme.Syntax.Type,
 			Error:    theme.UI.ErrorFg,
 		})
+		e.configureHighlighter(e.activeDoc().highlighter)
 	}
 
 	// Setup compositor columns AFTER config is applied
 	e.setupCompositorColumns()
 
+	var invalidColors *config.InvalidColorsError
+	if errors.As(themeErr, &invalidColors) {
+		e.statusbar.SetMessage("Theme: "+themeErr.Error(), "warning")
+	} else if themeErr != nil {
+		e.statusbar.SetMessage("Theme file: "+themeErr.Error(), "error")
+	}
+
 	return e
 }
 
+// configureHighlighter applies the config's highlighting settings to a
+// document's highlighter. Every new document goes through it.
+func (e *Editor) configureHighlighter(h *syntax.Highlighter) {
+	h.SetColorOverrides(syntaxOverrides(e.config))
+	if e.config != nil {
+		h.SetMarkers(e.config.Editor.CommentMarkers)
+		h.SetMaxLineLength(e.config.Editor.MaxHighlightLineLength)
+	}
+}
+
+// syntaxOverrides converts the config's [theme.syntax] table into
+// per-category highlighter colors
+func syntaxOverrides(cfg *config.Config) map[syntax.TokenCategory]string {
+	if cfg == nil || len(cfg.Theme.Syntax) == 0 {
+		return nil
+	}
+	overrides := make(map[syntax.TokenCategory]string, len(cfg.Theme.Syntax))
+	for name, color := range cfg.Theme.Syntax {
+		overrides[syntax.TokenCategory(strings.ToLower(name))] = color
+	}
+	return overrides
+}
+
 // LoadFile loads a file into the editor
 func (e *Editor) LoadFile(filename string) error {
 	// Convert to absolute path for consistent comparison
@@ -676,11 +749,10 @@ func (e *Editor) LoadFile(filename string) error {
 	if err != nil {
 		return err
 	}
-	fileInfo, err := os.Stat(absPath)
-	var modTime time.Time
-	if err == nil {
-		modTime = fileInfo.ModTime()
-	}
+	disk := SnapshotFile(absPath)
+	// Binary files are shown read-only so saving cannot mangle them
+	binary := textio.IsBinary(rawContent)
+	readOnly := e.readOnly || binary || !isWritable(absPath)
 
 	// Detect encoding
 	detection := enc.Detect(rawContent)
@@ -713,7 +785,10 @@ func (e *Editor) LoadFile(filename string) error {
 		currentDoc.scrollY = 0
 		currentDoc.filename = absPath
 		currentDoc.modified = false
-		currentDoc.modTime = modTime
+		currentDoc.savedLines = currentDoc.buffer.Lines()
+		currentDoc.disk = disk
+		currentDoc.readOnly = readOnly
+		currentDoc.binary = binary
 		currentDoc.highlighter.SetFile(filename)
 		currentDoc.encoding = detectedEnc
 	} else {
@@ -737,22 +812,37 @@ func (e *Editor) LoadFile(filename string) error {
 			filename:    absPath,
 			modified:    false,
 			scrollY:     0,
-			modTime:     modTime,
+			disk:        disk,
+			readOnly:    readOnly,
+			binary:      binary,
 			encoding:    detectedEnc,
+			savedLines:  buf.Lines(),
 		}
+		e.configureHighlighter(doc.highlighter)
 		e.documents = append(e.documents, doc)
 		e.activeIdx = len(e.documents) - 1
 	}
 
-	// Warn if encoding is unsupported
-	if detectedEnc != nil && !detectedEnc.Supported {
+	// Warn about binary content or an unsupported encoding
+	if binary {
+		e.statusbar.SetMessage("Warning: Binary file opened read-only", "error")
+	} else if detectedEnc != nil && !detectedEnc.Supported {
 		e.statusbar.SetMessage("Warning: Unsupported encoding "+detectedEnc.Name, "error")
 	}
 
+	// Go back to where the cursor was when the file was last closed
+	doc := e.activeDoc()
 	e.viewport.SetScrollY(0)
+	if e.restoreFileMark(doc) {
+		e.viewport.SetScrollY(doc.scrollY)
+		e.viewport.ClampScroll(e.viewport.CountVisualLines(doc.buffer.Lines()), e.viewport.Height())
+		e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	}
 	e.updateTitle()
 	e.updateMenuState()
 
+	e.watcher.Watch(absPath)
+
 	// Track in recent files and directories
 	if e.config != nil {
 		e.config.AddRecentFile(absPath)
@@ -766,6 +856,9 @@ func (e *Editor) LoadFile(filename string) error {
 // SaveFile saves the buffer to the current filename
 // Returns true if save was initiated (might be async if prompting for filename)
 func (e *Editor) SaveFile() bool {
+	if !e.checkEditable() {
+		return false
+	}
 	if e.activeDoc().filename == "" {
 		// No filename - prompt for one
 		e.showPrompt("Save as: ", PromptSaveAs)
@@ -781,6 +874,36 @@ func (e *Editor) SaveFile() bool {
 	return e.doSave()
 }
 
+// applySaveTransforms trims whitespace, normalizes the final newline and
+// converts line endings in the active buffer when enabled in config. The change is recorded for undo.
+func (e *Editor) applySaveTransforms() {
+	if e.config == nil || !e.activeDoc().CanEdit() {
+		return
+	}
+	doc := e.activeDoc()
+	content := doc.buffer.String()
+	settings := e.editorSettings()
+	transformed := ApplySaveTransforms(content,
+		settings.TrimTrailingWhitespace, settings.InsertFinalNewline)
+	transformed = ConvertLineEndings(transformed, settings.EndOfLine)
+	if transformed == content {
+		return
+	}
+
+	pos := doc.cursor.Position()
+	before := doc.cursor.ByteOffset()
+	doc.buffer.Replace(0, len(content), transformed)
+	doc.cursor.SetPosition(pos.Line, pos.Col)
+	doc.selection.Clear()
+	doc.undoStack.Push(&UndoEntry{
+		Position:     0,
+		Deleted:      content,
+		Inserted:     transformed,
+		CursorBefore: before,
+		CursorAfter:  doc.cursor.ByteOffset(),
+	})
+}
+
 // doSave performs the actual file save
 func (e *Editor) doSave() bool {
 	// Create backup if enabled and file exists
@@ -791,6 +914,8 @@ func (e *Editor) doSave() bool {
 		}
 	}
 
+	e.applySaveTransforms()
+
 	content := e.activeDoc().buffer.String()
 	var outputData []byte
 	docEnc := e.activeDoc().encoding
@@ -847,12 +972,12 @@ func (e *Editor) doSave() bool {
 		return false
 	}
 
-	// Update stored mod time after successful save
-	if fileInfo, err := os.Stat(e.activeDoc().filename); err == nil {
-		e.activeDoc().modTime = fileInfo.ModTime()
-	}
+	// Update stored file state after successful save
+	e.activeDoc().disk = SnapshotFile(e.activeDoc().filename)
+	e.watcher.Watch(e.activeDoc().filename)
 
 	e.activeDoc().modified = false
+	e.activeDoc().savedLines = e.activeDoc().buffer.Lines()
 	e.statusbar.SetMessage("Saved: "+e.activeDoc().filename, "success")
 	e.updateTitle()
 	e.updateMenuState()
@@ -930,6 +1055,8 @@ func (e *Editor) doSaveInDialog() bool {
 		}
 	}
 
+	e.applySaveTransforms()
+
 	content := e.activeDoc().buffer.String()
 	var outputData []byte
 	docEnc := e.activeDoc().encoding
@@ -985,7 +1112,10 @@ func (e *Editor) doSaveInDialog() bool {
 		return false
 	}
 
+	e.activeDoc().disk = SnapshotFile(e.activeDoc().filename)
+	e.watcher.Watch(e.activeDoc().filename)
 	e.activeDoc().modified = false
+	e.activeDoc().savedLines = e.activeDoc().buffer.Lines()
 	e.fileBrowserError = ""
 	e.statusbar.SetMessage("Saved: "+e.activeDoc().filename, "success")
 	e.updateMenuState()
@@ -1021,6 +1151,10 @@ func (e *Editor) Init() tea.Cmd {
 
 // Update implements tea.Model
 func (e *Editor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Any message may edit, load or switch documents, so the gutter is
+	// sized here rather than in View
+	defer e.syncLineNumberWidth()
+
 	// Check for pending quit (after user confirmed discard)
 	if e.pendingQuit {
 		return e, tea.Quit
@@ -1028,17 +1162,13 @@ func (e *Editor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		e.width = msg.Width
-		e.height = msg.Height
-		e.menubar.SetWidth(msg.Width)
-		e.statusbar.SetWidth(msg.Width)
-		e.updateViewportSize()
+		e.OnResize(msg.Width, msg.Height)
 		return e, nil
 
 	case fileCheckMsg:
 		// Periodic check for external file changes
-		if e.fileChangedOnDisk() && e.mode == ModeNormal {
-			e.statusbar.SetMessage("File changed on disk!", "error")
+		if e.mode == ModeNormal {
+			e.watcher.Check()
 		}
 		return e, fileCheckCmd() // Schedule next check
 
@@ -1089,15 +1219,25 @@ func (e *Editor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // setupCompositorColumns configures the compositor columns based on current settings.
 func (e *Editor) setupCompositorColumns() {
 	columns := []ui.Column{
-		// Line numbers (fixed width 5)
+		// Line numbers (fixed width, see syncLineNumberWidth)
 		{
-			Width:    5,
+			Name:     ui.ColumnLineNumbers,
+			Width:    ui.DefaultLineNumberWidth,
 			Flexible: false,
 			Enabled:  e.viewport.ShowLineNum(),
 			Renderer: e.lineNumRenderer,
 		},
+		// Modified-lines bar (fixed width 1)
+		{
+			Name:     ui.ColumnModified,
+			Width:    1,
+			Flexible: false,
+			Enabled:  e.modifiedGutter.IsEnabled(),
+			Renderer: e.modifiedGutter,
+		},
 		// Text content (flexible)
 		{
+			Name:     ui.ColumnText,
 			Width:    0,
 			Flexible: true,
 			Enabled:  true,
@@ -1105,6 +1245,7 @@ func (e *Editor) setupCompositorColumns() {
 		},
 		// Minimap (fixed width 8)
 		{
+			Name:     ui.ColumnMinimap,
 			Width:    ui.MinimapWidth(),
 			Flexible: false,
 			Enabled:  e.minimapRenderer.IsEnabled(),
@@ -1112,6 +1253,7 @@ func (e *Editor) setupCompositorColumns() {
 		},
 		// Scrollbar (fixed width 1)
 		{
+			Name:     ui.ColumnScrollbar,
 			Width:    1,
 			Flexible: false,
 			Enabled:  e.scrollbar.IsEnabled(),
@@ -1119,6 +1261,46 @@ func (e *Editor) setupCompositorColumns() {
 		},
 	}
 	e.compositor.SetColumns(columns)
+	e.syncLineNumberWidth()
+
+	gutterWidth := 0
+	if e.modifiedGutter.IsEnabled() {
+		gutterWidth = 1
+	}
+	e.viewport.SetGutterWidth(gutterWidth)
+}
+
+// syncLineNumberWidth sizes the line number column to fit the active
+// document's last line number, so huge files don't overflow the gutter.
+func (e *Editor) syncLineNumberWidth() {
+	width := e.lineNumRenderer.GutterWidth(e.activeDoc().buffer.LineCount())
+	e.viewport.SetLineNumberWidth(width)
+	if col, ok := e.compositor.ColumnByName(ui.ColumnLineNumbers); ok {
+		col.Width = width
+	}
+}
+
+// OnResize lays the editor out for a new terminal size: the bars, viewport
+// and compositor columns are resized, and every document's scroll position
+// is pulled back within the document so none is left scrolled past its end.
+func (e *Editor) OnResize(width, height int) {
+	e.width = width
+	e.height = height
+	e.menubar.SetWidth(width)
+	e.statusbar.SetWidth(width)
+	e.updateViewportSize()
+
+	viewportHeight := e.viewport.Height()
+	for i, doc := range e.documents {
+		total := e.viewport.CountVisualLines(doc.buffer.Lines())
+		if i == e.activeIdx {
+			e.viewport.ClampScroll(total, viewportHeight)
+			continue
+		}
+		if maxScroll := total - viewportHeight; doc.scrollY > maxScroll {
+			doc.scrollY = max(maxScroll, 0)
+		}
+	}
 }
 
 // updateViewportSize recalculates the viewport size based on current state
@@ -1184,11 +1366,7 @@ func (e *Editor) buildRenderState() *ui.RenderState {
 		endLine := len(lines)
 		// If minimap is disabled, only generate for visible lines (performance)
 		if !e.minimapRenderer.IsEnabled() {
-			startLine = e.viewport.ScrollY()
-			endLine = startLine + e.viewport.Height()
-			if endLine > len(lines) {
-				endLine = len(lines)
-			}
+			startLine, endLine = e.visibleLines(lines)
 		}
 		for i := startLine; i < endLine; i++ {
 			colors := e.activeDoc().highlighter.GetLineColors(lines[i])
@@ -1196,6 +1374,16 @@ func (e *Editor) buildRenderState() *ui.RenderState {
 				lineColors[i] = colors
 			}
 		}
+		if e.config != nil && e.config.Editor.RainbowBrackets {
+			e.addRainbowSpans(lines, lineColors, startLine, endLine)
+		}
+		e.highlightMatchingTag(lines, lineColors)
+	}
+	if e.config != nil && e.config.Editor.HighlightOccurrences {
+		if lineColors == nil {
+			lineColors = make(map[int][]syntax.ColorSpan)
+		}
+		e.highlightWordOccurrences(lines, lineColors)
 	}
 
 	// Calculate total visual lines
@@ -1203,23 +1391,106 @@ func (e *Editor) buildRenderState() *ui.RenderState {
 	if e.viewport.WordWrap() {
 		totalVisualLines = e.viewport.CountVisualLines(lines)
 	}
+	cursorLine := e.activeDoc().cursor.Line()
+	cursorVisualLine := e.viewport.CursorVisualLine(lines, cursorLine, e.activeDoc().cursorRuneCol(lines))
+
+	// Only diff against the saved snapshot when there is something to show
+	var modifiedLines map[int]bool
+	if e.modifiedGutter.IsEnabled() && e.activeDoc().modified {
+		modifiedLines = e.activeDoc().modifiedLines(lines)
+	}
+
+	eolMarker, hideEndOfBuffer, indentGuides := "", false, false
+	var activeGuide *ui.IndentScope
+	indentGuide := ""
+	if e.asciiBoxes() {
+		indentGuide = "|"
+	}
+	if e.config != nil {
+		eolMarker = e.config.Editor.EOLMarker
+		hideEndOfBuffer = !e.config.Editor.EndOfBufferMarker
+		indentGuides = e.config.Editor.IndentGuides
+	}
+	if indentGuides {
+		if scope, ok := ui.ActiveIndentScope(lines, e.activeDoc().cursor.Line(), e.editorSettings().TabWidth); ok {
+			activeGuide = &scope
+		}
+	}
 
 	return &ui.RenderState{
 		Lines:            lines,
 		CursorLine:       e.activeDoc().cursor.Line(),
-		CursorCol:        e.activeDoc().cursor.Col(),
+		CursorCol:        e.activeDoc().cursor.Col() + e.activeDoc().cursor.Virtual(),
+		CursorShape:      e.cursorShape(),
 		ScrollY:          e.viewport.ScrollY(),
 		ScrollX:          e.viewport.ScrollX(),
 		Selection:        selectionMap,
 		LineColors:       lineColors,
+		HighlightLimit:   e.activeDoc().highlighter.MaxLineLength(),
 		WordWrap:         e.viewport.WordWrap(),
-		TabWidth:         e.config.Editor.TabWidth,
+		VirtualSpace:     e.virtualSpace(),
+		TabWidth:         e.editorSettings().TabWidth,
+		TextWidth:        e.compositor.FlexibleColumnWidth(),
+		ModifiedLines:    modifiedLines,
+		IndentGuides:     indentGuides,
+		ActiveGuide:      activeGuide,
+		IndentGuide:      indentGuide,
+		EOLMarker:        eolMarker,
+		HideEndOfBuffer:  hideEndOfBuffer,
 		TotalLines:       len(lines),
 		TotalVisualLines: totalVisualLines,
+		CursorVisualLine: cursorVisualLine,
 		Styles:           e.styles,
 	}
 }
 
+// visibleLines returns the range [first, end) of buffer lines with at least
+// one row in the text column.
+func (e *Editor) visibleLines(lines []string) (first, end int) {
+	first, last := ui.VisibleRange(e.viewport.Height(), &ui.RenderState{
+		Lines:     lines,
+		ScrollY:   e.viewport.ScrollY(),
+		WordWrap:  e.viewport.WordWrap(),
+		TabWidth:  e.editorSettings().TabWidth,
+		TextWidth: e.compositor.FlexibleColumnWidth(),
+	})
+	return first, max(first, last+1)
+}
+
+// addRainbowSpans colors the brackets of lines [startLine, endLine) by
+// nesting depth. Depths come from the document's rainbow cache, so only
+// lines that changed since the last frame are recounted.
+func (e *Editor) addRainbowSpans(lines []string, lineColors map[int][]syntax.ColorSpan, startLine, endLine int) {
+	doc := e.activeDoc()
+	doc.rainbow.update(doc.highlighter, lines, endLine)
+	for i := startLine; i < endLine; i++ {
+		if spans := doc.rainbow.spans(i); len(spans) > 0 {
+			// Bracket colors come first so they win over the lexer's
+			lineColors[i] = append(spans, lineColors[i]...)
+		}
+	}
+}
+
+// highlightMatchingTag underlines the name of the tag under the cursor and
+// of its partner in HTML and XML documents. Only the visible lines and the
+// cursor's line are scanned, so a partner off screen is not looked for.
+func (e *Editor) highlightMatchingTag(lines []string, lineColors map[int][]syntax.ColorSpan) {
+	h := e.activeDoc().highlighter
+	if !h.IsMarkup() {
+		return
+	}
+	line := e.activeDoc().cursor.Line()
+	col := utf8.RuneCountInString(lines[line][:e.activeDoc().cursor.Col()])
+	first, end := e.visibleLines(lines)
+	names := h.MatchingTagNames(lines, min(first, line), max(end, line+1), line, col)
+	for _, n := range names {
+		// Keep the tag name's own color under the underline
+		color := "\033[4m" + syntax.ColorAt(lineColors[n.Line], n.StartCol)
+		span := syntax.ColorSpan{Start: n.StartCol, End: n.EndCol, Color: color}
+		lineColors[n.Line] = append([]syntax.ColorSpan{span}, lineColors[n.Line]...)
+	}
+}
+
 // handleKey handles keyboard input
 func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Handle menu mode
@@ -1351,7 +1622,7 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyShiftHome:
 		e.moveWithSelection(func() bool {
-			e.activeDoc().cursor.MoveToLineStart()
+			e.activeDoc().cursor.MoveToSmartLineStart()
 			return true
 		})
 		return e, nil
@@ -1399,16 +1670,24 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyLeft:
 		e.activeDoc().selection.Clear()
 		e.activeDoc().cursor.MoveLeft()
-		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col()+e.activeDoc().cursor.Virtual())
 		return e, nil
 
 	case tea.KeyRight:
 		e.activeDoc().selection.Clear()
-		e.activeDoc().cursor.MoveRight()
-		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+		if e.virtualSpace() {
+			e.activeDoc().cursor.MoveRightVirtual()
+		} else {
+			e.activeDoc().cursor.MoveRight()
+		}
+		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col()+e.activeDoc().cursor.Virtual())
 		return e, nil
 
 	case tea.KeyUp:
+		if msg.Alt {
+			e.moveLines(true)
+			return e, nil
+		}
 		e.activeDoc().selection.Clear()
 		if e.viewport.WordWrap() {
 			newLine, newCol := e.viewport.MoveUpVisual(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
@@ -1420,6 +1699,10 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return e, nil
 
 	case tea.KeyDown:
+		if msg.Alt {
+			e.moveLines(false)
+			return e, nil
+		}
 		e.activeDoc().selection.Clear()
 		if e.viewport.WordWrap() {
 			newLine, newCol := e.viewport.MoveDownVisual(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
@@ -1432,7 +1715,7 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyHome:
 		e.activeDoc().selection.Clear()
-		e.activeDoc().cursor.MoveToLineStart()
+		e.activeDoc().cursor.MoveToSmartLineStart()
 		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
 		return e, nil
 
@@ -1449,14 +1732,11 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		e.lastPageKey = time.Now()
 
-		// Move cursor up by one page
-		pageSize := e.viewport.Height() - 1 // Keep 1 line of context
-		for i := 0; i < pageSize; i++ {
-			if !e.activeDoc().cursor.MoveUp() {
-				break
-			}
+		if msg.Alt {
+			e.pageScroll(-ui.HalfPageStep(e.viewport.Height()), e.viewport.HalfPageUp)
+			return e, nil
 		}
-		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+		e.pageScroll(-ui.PageStep(e.viewport.Height()), e.viewport.PageUp)
 		return e, nil
 
 	case tea.KeyPgDown:
@@ -1466,19 +1746,16 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		e.lastPageKey = time.Now()
 
-		// Move cursor down by one page
-		pageSize := e.viewport.Height() - 1 // Keep 1 line of context
-		for i := 0; i < pageSize; i++ {
-			if !e.activeDoc().cursor.MoveDown() {
-				break
-			}
+		if msg.Alt {
+			e.pageScroll(ui.HalfPageStep(e.viewport.Height()), e.viewport.HalfPageDown)
+			return e, nil
 		}
-		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+		e.pageScroll(ui.PageStep(e.viewport.Height()), e.viewport.PageDown)
 		return e, nil
 
 	// Text editing keys
 	case tea.KeyEnter:
-		e.insertChar('\n')
+		e.insertNewline()
 		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
 		return e, nil
 
@@ -1514,6 +1791,15 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return e, nil
 
 	case tea.KeyRunes:
+		// Bracketed paste: insert verbatim, bypassing auto-close and
+		// the control character filter so newlines and tabs survive.
+		// Terminals send line breaks as CR, some as CRLF.
+		if msg.Paste {
+			text := strings.ReplaceAll(string(msg.Runes), "\r\n", "\n")
+			e.insertText(strings.ReplaceAll(text, "\r", "\n"))
+			e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+			return e, nil
+		}
 		// Check for Alt+letter combinations first
 		if msg.Alt && len(msg.Runes) == 1 {
 			switch msg.Runes[0] {
@@ -1547,6 +1833,21 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				e.menubar.OpenMenu(5) // Help
 				e.updateViewportSize()
 				return e, nil
+			case 'l', 'L':
+				e.scrollToCursor(e.viewport.CenterOn)
+				return e, nil
+			case 't', 'T':
+				e.scrollToCursor(e.viewport.CursorToTop)
+				return e, nil
+			case 'z', 'Z':
+				e.scrollToCursor(e.viewport.CursorToBottom)
+				return e, nil
+			case '=':
+				e.expandSelection(false)
+				return e, nil
+			case '-':
+				e.expandSelection(true)
+				return e, nil
 			case '<': // Alt+< (same as nano)
 				if e.bufferCount() > 1 {
 					e.prevBuffer()
@@ -1560,9 +1861,13 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		// Regular character input - skip control characters (ASCII 0-31 except tab)
-		for _, r := range msg.Runes {
-			if r >= 32 || r == '\t' {
-				e.insertChar(r)
+		if len(msg.Runes) == 1 && e.config != nil && e.config.Editor.AutoClose {
+			e.typeCharAutoClose(msg.Runes[0])
+		} else {
+			for _, r := range msg.Runes {
+				if r >= 32 || r == '\t' {
+					e.insertChar(r)
+				}
 			}
 		}
 		if len(msg.Runes) > 0 {
@@ -1648,7 +1953,7 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return e, nil
 	case "shift+home":
 		e.moveWithSelection(func() bool {
-			e.activeDoc().cursor.MoveToLineStart()
+			e.activeDoc().cursor.MoveToSmartLineStart()
 			return true
 		})
 		return e, nil
@@ -1683,6 +1988,33 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return e, nil
 }
 
+// scrollToCursor scrolls the view with scroll, which places the cursor's
+// visual line at a fixed row
+func (e *Editor) scrollToCursor(scroll func(cursorVisualLine, viewportHeight, totalVisualLines int)) {
+	lines := e.activeDoc().buffer.Lines()
+	line := e.activeDoc().cursor.Line()
+	col := utf8.RuneCountInString(lines[line][:e.activeDoc().cursor.Col()])
+	scroll(e.viewport.CursorVisualLine(lines, line, col), e.viewport.Height(), e.viewport.CountVisualLines(lines))
+}
+
+// pageScroll moves the cursor by lines (negative is up) and scrolls the
+// view with scroll, so the cursor keeps its place on screen
+func (e *Editor) pageScroll(lines int, scroll func(viewportHeight, totalVisualLines int)) {
+	move := e.activeDoc().cursor.MoveDown
+	if lines < 0 {
+		move = e.activeDoc().cursor.MoveUp
+		lines = -lines
+	}
+	for i := 0; i < lines; i++ {
+		if !move() {
+			break
+		}
+	}
+	docLines := e.activeDoc().buffer.Lines()
+	scroll(e.viewport.Height(), e.viewport.CountVisualLines(docLines))
+	e.viewport.EnsureCursorVisibleWrapped(docLines, e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+}
+
 // moveWithSelection moves the cursor while extending the selection
 func (e *Editor) moveWithSelection(move func() bool) {
 	if !e.activeDoc().selection.Active {
@@ -1790,6 +2122,7 @@ func (e *Editor) executePrompt() {
 				e.mode = ModePrompt // Stay in prompt mode
 				return
 			}
+			e.forgetFile(e.activeDoc().filename)
 			e.activeDoc().filename = input
 			e.doSave()
 		} else {
@@ -1798,6 +2131,7 @@ func (e *Editor) executePrompt() {
 
 	case PromptConfirmOverwrite:
 		if strings.ToLower(input) == "y" || strings.ToLower(input) == "yes" {
+			e.forgetFile(e.activeDoc().filename)
 			e.activeDoc().filename = e.pendingFilename
 			e.doSave()
 		} else {
@@ -1880,25 +2214,15 @@ func (e *Editor) executePrompt() {
 			e.statusbar.SetMessage("Cancelled", "info")
 			return
 		}
-		lineNum, err := strconv.Atoi(input)
-		if err != nil {
+		line, ok := ResolveGotoTarget(input, e.activeDoc().cursor.Line(), e.activeDoc().buffer.LineCount())
+		if !ok {
 			e.statusbar.SetMessage("Invalid line number", "error")
 			return
 		}
-		totalLines := e.activeDoc().buffer.LineCount()
-		if lineNum < 1 {
-			e.statusbar.SetMessage("Line number must be at least 1", "error")
-			return
-		}
-		if lineNum > totalLines {
-			e.statusbar.SetMessage(fmt.Sprintf("Line %d exceeds file length (%d lines)", lineNum, totalLines), "error")
-			return
-		}
-		// Convert to 0-indexed
-		e.activeDoc().cursor.SetPosition(lineNum-1, 0)
+		e.activeDoc().cursor.SetPosition(line, 0)
 		e.activeDoc().selection.Clear()
 		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
-		e.statusbar.SetMessage(fmt.Sprintf("Jumped to line %d", lineNum), "info")
+		e.statusbar.SetMessage(fmt.Sprintf("Jumped to line %d", line+1), "info")
 
 	case PromptThemeCopyName:
 		if input == "" {
@@ -1927,23 +2251,41 @@ func (e *Editor) executePrompt() {
 func (e *Editor) handleFindKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEsc:
+		if e.isearch != nil {
+			e.cancelISearch()
+		}
 		e.mode = ModeNormal
 		e.findActive = false
 		e.updateViewportSize()
 
-	case tea.KeyEnter:
-		e.findNext()
+	case tea.KeyEnter, tea.KeyDown:
+		if e.isearch == nil {
+			e.findNext()
+			break
+		}
+		e.isearch.Next()
+		e.showISearchMatch()
+
+	case tea.KeyUp:
+		if e.isearch != nil {
+			e.isearch.Prev()
+			e.showISearchMatch()
+		}
 
 	case tea.KeyBackspace:
-		if len(e.findQuery) > 0 {
+		if e.isearch != nil {
+			e.isearch.Backspace()
+			e.findQuery = e.isearch.Query()
+			e.showISearchMatch()
+		} else if len(e.findQuery) > 0 {
 			e.findQuery = e.findQuery[:len(e.findQuery)-1]
 		}
 
 	case tea.KeyRunes:
-		e.findQuery += string(msg.Runes)
+		e.isearchType(msg.Runes)
 
 	case tea.KeySpace:
-		e.findQuery += " "
+		e.isearchType([]rune{' '})
 	}
 
 	return e, nil
@@ -2061,17 +2403,39 @@ func (e *Editor) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+			// Clicking a line number selects the whole line
+			if y >= 0 && y < e.viewport.Height() && e.inLineNumbers(msg.X) {
+				if line, ok := e.gutterRowLine(y); ok {
+					e.selectLines(line, line)
+					e.mouseDown = true
+					e.gutterDrag = true
+					e.gutterAnchor = line
+					return e, nil
+				}
+			}
+
 			// Handle click in editor area
 			if y >= 0 && y < e.viewport.Height() {
 				line, col := e.viewport.PositionFromClickWrapped(e.activeDoc().buffer.Lines(), msg.X, y)
 				e.activeDoc().cursor.SetPosition(line, col)
 				e.activeDoc().selection.Clear()
 				e.mouseDown = true
+				e.gutterDrag = false
 				e.mouseStartX = msg.X
 				e.mouseStartY = y
 			}
 		} else if msg.Action == tea.MouseActionRelease {
 			e.mouseDown = false
+			e.gutterDrag = false
+		} else if msg.Action == tea.MouseActionMotion && e.mouseDown && e.gutterDrag {
+			// Drag from a line number extends the selection line by line
+			if y >= 0 && y < e.viewport.Height() {
+				line, ok := e.gutterRowLine(y)
+				if !ok {
+					line = e.activeDoc().buffer.LineCount() - 1
+				}
+				e.selectLines(e.gutterAnchor, line)
+			}
 		} else if msg.Action == tea.MouseActionMotion && e.mouseDown {
 			// Drag selection
 			if y >= 0 && y < e.viewport.Height() {
@@ -2086,11 +2450,13 @@ func (e *Editor) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-	case tea.MouseButtonWheelUp:
-		e.viewport.ScrollUp()
-
-	case tea.MouseButtonWheelDown:
-		e.viewport.ScrollDownWrapped(e.activeDoc().buffer.Lines())
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		delta := 1
+		if msg.Button == tea.MouseButtonWheelUp {
+			delta = -1
+		}
+		lines := e.activeDoc().buffer.Lines()
+		e.viewport.ScrollByVisual(delta, e.viewport.CountVisualLines(lines), e.viewport.Height())
 	}
 
 	return e, nil
@@ -2129,12 +2495,21 @@ func (e *Editor) executeAction(action ui.MenuAction) (tea.Model, tea.Cmd) {
 		e.paste()
 	case ui.ActionCutLine:
 		e.cutLine()
+	case ui.ActionDuplicate:
+		e.duplicate()
+	case ui.ActionConvertIndent:
+		e.convertIndentation()
+	case ui.ActionSortLines:
+		e.sortLines()
+	case ui.ActionUniqueLines:
+		e.uniqueLines()
 	case ui.ActionSelectAll:
 		e.selectAll()
 	case ui.ActionFind:
 		e.mode = ModeFind
 		e.findQuery = ""
 		e.findActive = true
+		e.isearch = nil
 		e.updateViewportSize()
 	case ui.ActionFindNext:
 		e.findNext()
@@ -2211,7 +2586,9 @@ func (e *Editor) executeAction(action ui.MenuAction) (tea.Model, tea.Cmd) {
 // toggleWordWrap toggles word wrap on/off
 func (e *Editor) toggleWordWrap() {
 	wrap := !e.viewport.WordWrap()
-	e.viewport.SetWordWrap(wrap)
+	doc := e.activeDoc()
+	lines := doc.buffer.Lines()
+	e.viewport.SetWordWrapKeepingRow(wrap, lines, doc.cursor.Line(), doc.cursorRuneCol(lines))
 
 	// Update menu checkbox
 	if wrap {
@@ -2223,7 +2600,7 @@ func (e *Editor) toggleWordWrap() {
 	}
 
 	// Ensure cursor stays visible after toggle
-	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
 
 	// Save to config
 	e.saveConfig()
@@ -2235,7 +2612,7 @@ func (e *Editor) toggleLineNumbers() {
 	e.viewport.ShowLineNumbers(show)
 
 	// Update compositor columns
-	e.setupCompositorColumns()
+	e.compositor.EnableColumnByName(ui.ColumnLineNumbers, show)
 
 	// Update menu checkbox
 	if show {
@@ -2279,7 +2656,7 @@ func (e *Editor) toggleScrollbar() {
 	e.viewport.SetScrollbarWidth(e.scrollbar.Width())
 
 	// Update compositor columns
-	e.setupCompositorColumns()
+	e.compositor.EnableColumnByName(ui.ColumnScrollbar, enabled)
 
 	// Update menu checkbox
 	if enabled {
@@ -2302,7 +2679,7 @@ func (e *Editor) toggleMinimap() {
 	enabled := e.minimapRenderer.Toggle()
 
 	// Update compositor columns
-	e.setupCompositorColumns()
+	e.compositor.EnableColumnByName(ui.ColumnMinimap, enabled)
 
 	// Update menu checkbox
 	if enabled {
@@ -2339,7 +2716,7 @@ func (e *Editor) saveConfig() {
 // applyTheme changes the current theme and updates all UI components
 func (e *Editor) applyTheme(themeName string) {
 	// Load the theme
-	theme := config.LoadTheme(themeName)
+	theme, warning := config.LoadThemeChecked(themeName)
 
 	// Create new styles from the theme
 	styles := ui.NewStyles(theme)
@@ -2348,10 +2725,7 @@ func (e *Editor) applyTheme(themeName string) {
 	e.menubar.SetStyles(styles)
 	e.statusbar.SetStyles(styles)
 	e.viewport.SetStyles(styles)
-	e.scrollbar.SetStyles(styles)
-	e.lineNumRenderer.SetStyles(styles)
-	e.textRenderer.SetStyles(styles)
-	e.minimapRenderer.SetStyles(styles)
+	e.compositor.SetStyles(styles) // Column renderers, including the scrollbar
 	e.styles = styles
 
 	// Update syntax highlighter colors
@@ -2364,14 +2738,20 @@ func (e *Editor) applyTheme(themeName string) {
 		Function: theme.Syntax.Function,
 		Type:     theme.Syntax.Type,
 	})
+	e.activeDoc().highlighter.SetColorOverrides(syntaxOverrides(e.config))
 
 	// Update config and save
 	if e.config == nil {
 		e.config = config.DefaultConfig()
 	}
 	e.config.Theme.Name = themeName
+	e.config.Editor.ThemeFile = "" // The chosen theme replaces any theme file
 	go e.config.Save()
 
+	if warning != nil {
+		e.statusbar.SetMessage("Theme: "+warning.Error(), "warning")
+		return
+	}
 	e.statusbar.SetMessage("Theme: "+themeName, "info")
 }
 
@@ -2557,14 +2937,7 @@ func (e *Editor) pruneRecentFiles() {
 	if e.config == nil {
 		return
 	}
-	valid := make([]string, 0, len(e.config.RecentFiles))
-	for _, path := range e.config.RecentFiles {
-		if _, err := os.Stat(path); err == nil {
-			valid = append(valid, path)
-		}
-	}
-	if len(valid) != len(e.config.RecentFiles) {
-		e.config.RecentFiles = valid
+	if e.config.PruneRecentFiles() {
 		go e.config.Save()
 	}
 }
@@ -3768,6 +4141,9 @@ var reservedKeys = map[string]string{
 	"alt+h":  "Help menu",
 	"alt+<":  "Previous buffer",
 	"alt+>":  "Next buffer",
+	"alt+l":  "Center cursor line",
+	"alt+t":  "Cursor line to top",
+	"alt+z":  "Cursor line to bottom",
 	"f10":    "Open menu",
 	"escape": "Cancel/Close",
 	"esc":    "Cancel/Close",
@@ -3805,22 +4181,44 @@ func (e *Editor) showHelp() {
 // showAbout opens the About dialog with a random quote
 func (e *Editor) showAbout() {
 	e.mode = ModeAbout
-	e.aboutQuote = FestivusQuotes[rand.Intn(len(FestivusQuotes))]
+	if e.quotes == nil {
+		e.quotes = quotes.Load(config.QuotesPath(), nil)
+	}
+	e.aboutQuote = e.quotes.RandomQuote()
 }
 
 // Text manipulation methods
 
+// checkEditable reports whether the active document can be modified,
+// telling the user why not when it is read-only
+func (e *Editor) checkEditable() bool {
+	if e.activeDoc().CanEdit() {
+		return true
+	}
+	e.statusbar.SetMessage("Buffer is read-only", "error")
+	return false
+}
+
 func (e *Editor) insertChar(r rune) {
+	if !e.checkEditable() {
+		return
+	}
 	// Delete selection first if any
 	if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
 		e.deleteSelection()
 	}
+	var pad string
+	if r == '\n' {
+		e.activeDoc().cursor.ClearVirtual()
+	} else {
+		pad = e.fillVirtualSpace()
+	}
 
 	// Record for undo
 	entry := &UndoEntry{
-		Position:     e.activeDoc().cursor.ByteOffset(),
-		Inserted:     string(r),
-		CursorBefore: e.activeDoc().cursor.ByteOffset(),
+		Position:     e.activeDoc().cursor.ByteOffset() - len(pad),
+		Inserted:     pad + string(r),
+		CursorBefore: e.activeDoc().cursor.ByteOffset() - len(pad),
 	}
 
 	e.activeDoc().cursor.Sync()
@@ -3833,7 +4231,7 @@ func (e *Editor) insertChar(r rune) {
 }
 
 func (e *Editor) insertText(s string) {
-	if s == "" {
+	if s == "" || !e.checkEditable() {
 		return
 	}
 
@@ -3841,11 +4239,12 @@ func (e *Editor) insertText(s string) {
 	if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
 		e.deleteSelection()
 	}
+	pad := e.fillVirtualSpace()
 
 	entry := &UndoEntry{
-		Position:     e.activeDoc().cursor.ByteOffset(),
-		Inserted:     s,
-		CursorBefore: e.activeDoc().cursor.ByteOffset(),
+		Position:     e.activeDoc().cursor.ByteOffset() - len(pad),
+		Inserted:     pad + s,
+		CursorBefore: e.activeDoc().cursor.ByteOffset() - len(pad),
 	}
 
 	e.activeDoc().cursor.Sync()
@@ -3857,20 +4256,143 @@ func (e *Editor) insertText(s string) {
 	e.activeDoc().modified = true
 }
 
+// typeCharAutoClose inserts a typed character, pairing brackets and quotes
+// and stepping over closing characters that are already present
+func (e *Editor) typeCharAutoClose(r rune) {
+	if !e.checkEditable() {
+		return
+	}
+	if r < 32 && r != '\t' {
+		return
+	}
+	doc := e.activeDoc()
+	if doc.selection.Active && !doc.selection.IsEmpty() {
+		e.insertChar(r)
+		return
+	}
+
+	// Virtual space is filled by the insert below, in the same undo step
+	pos := doc.cursor.ByteOffset()
+	virtual := doc.cursor.Virtual()
+	var next rune
+	if pos < doc.buffer.Length() {
+		next, _ = doc.buffer.RuneAt(pos)
+		if next == '\n' {
+			next = 0
+		}
+	}
+
+	lineStart := doc.buffer.LineStartOffset(doc.cursor.Line())
+	line := doc.buffer.Substring(lineStart, doc.buffer.LineEndOffset(doc.cursor.Line()))
+	before := doc.buffer.Substring(lineStart, pos)
+	col := utf8.RuneCountInString(before)
+	inString := doc.highlighter.InString(line, col)
+	prev, _ := utf8.DecodeLastRuneInString(before)
+	if virtual > 0 {
+		prev = ' '
+	}
+
+	language := doc.highlighter.Language()
+	pairs := LanguagePairs(language, e.config.LanguageSettings(language))
+	insert, moveOver := AutoCloseWithPairs(r, prev, next, inString, pairs)
+	if moveOver {
+		doc.cursor.MoveRight()
+		return
+	}
+	if utf8.RuneCountInString(insert) <= 1 {
+		e.insertChar(r)
+		return
+	}
+	e.insertText(insert)
+	doc.cursor.SetByteOffset(pos + virtual + utf8.RuneLen(r))
+}
+
+// insertNewline inserts a line break, carrying indentation onto the new line
+// when auto-indent is enabled
+func (e *Editor) insertNewline() {
+	if !e.checkEditable() {
+		return
+	}
+	doc := e.activeDoc()
+	doc.cursor.ClearVirtual()
+	if doc.selection.Active && !doc.selection.IsEmpty() {
+		e.deleteSelection()
+	}
+	left := doc.cursor.Line()
+	e.breakLine()
+	e.trimTypedLines(left, doc.cursor.Line())
+}
+
+// breakLine inserts a newline at the cursor, indented for the new line
+// when auto-indent is on.
+func (e *Editor) breakLine() {
+	if e.config == nil || !e.config.Editor.AutoIndent {
+		e.insertChar('\n')
+		return
+	}
+
+	doc := e.activeDoc()
+	lineStart := doc.buffer.LineStartOffset(doc.cursor.Line())
+	before := doc.buffer.Substring(lineStart, doc.cursor.ByteOffset())
+	settings := e.editorSettings()
+	indent := AutoIndent(before, settings.TabWidth, settings.IndentWidth(), settings.TabsToSpaces, doc.highlighter.Language())
+	if indent == "" {
+		e.insertChar('\n')
+		return
+	}
+	e.insertText("\n" + indent)
+}
+
+// trimTypedLines trims trailing whitespace from lines [first, last] of the
+// active document, except the cursor line, when trim_trailing_whitespace
+// is on. Pressing Enter uses it to clean the line just left while leaving
+// an indent or space the user is typing alone; saving trims the cursor
+// line too. The cursor keeps its line and column.
+func (e *Editor) trimTypedLines(first, last int) {
+	if !e.editorSettings().TrimTrailingWhitespace {
+		return
+	}
+	doc := e.activeDoc()
+	lines := doc.buffer.Lines()
+	if last >= len(lines) {
+		return
+	}
+	line, col := doc.cursor.Line(), doc.cursor.Col()
+	e.replaceLines(first, last, TrimExceptLine(lines[first:last+1], line-first))
+	doc.cursor.SetPosition(line, col)
+}
+
+// editorSettings returns the editor config for the active document, with
+// the .editorconfig files that apply to its file taken into account. They
+// are read once per filename.
+func (e *Editor) editorSettings() config.EditorConfig {
+	doc := e.activeDoc()
+	if doc.filename != doc.editorConfigFor {
+		doc.editorConfig = editorconfig.Settings{}
+		if doc.filename != "" {
+			doc.editorConfig, _ = editorconfig.Resolve(doc.filename)
+		}
+		doc.editorConfigFor = doc.filename
+	}
+	if e.config == nil {
+		return config.DefaultConfig().Editor.WithEditorConfig(doc.editorConfig)
+	}
+	return e.config.Editor.WithEditorConfig(doc.editorConfig)
+}
+
 // getIndentString returns the string to use for one level of indentation
 func (e *Editor) getIndentString() string {
-	if e.config.Editor.TabsToSpaces {
-		width := e.config.Editor.TabWidth
-		if width <= 0 {
-			width = 4
-		}
-		return strings.Repeat(" ", width)
+	if settings := e.editorSettings(); settings.TabsToSpaces {
+		return strings.Repeat(" ", settings.IndentWidth())
 	}
 	return "\t"
 }
 
 // indentLines indents all lines in the current selection
 func (e *Editor) indentLines() {
+	if !e.checkEditable() {
+		return
+	}
 	doc := e.activeDoc()
 	sel := doc.selection
 
@@ -3952,6 +4474,9 @@ func (e *Editor) indentLines() {
 
 // dedentLines removes one level of indentation from all lines in the selection
 func (e *Editor) dedentLines() {
+	if !e.checkEditable() {
+		return
+	}
 	doc := e.activeDoc()
 	sel := doc.selection
 
@@ -3972,10 +4497,7 @@ func (e *Editor) dedentLines() {
 		endLine--
 	}
 
-	tabWidth := e.config.Editor.TabWidth
-	if tabWidth <= 0 {
-		tabWidth = 4
-	}
+	indentWidth := e.editorSettings().IndentWidth()
 
 	// Calculate the range we're modifying
 	rangeStart := doc.buffer.LineStartOffset(startLine)
@@ -4002,9 +4524,9 @@ func (e *Editor) dedentLines() {
 				newLine = line[1:]
 				changed = true
 			} else if line[0] == ' ' {
-				// Remove up to tabWidth spaces
+				// Remove up to one indent level of spaces
 				spacesToRemove := 0
-				for j := 0; j < len(line) && j < tabWidth && line[j] == ' '; j++ {
+				for j := 0; j < len(line) && j < indentWidth && line[j] == ' '; j++ {
 					spacesToRemove++
 				}
 				if spacesToRemove > 0 {
@@ -4053,12 +4575,45 @@ func (e *Editor) dedentLines() {
 	doc.modified = true
 }
 
+// virtualSpace reports whether the cursor may move past the end of lines.
+// It only applies without word wrap, where columns past the end are on screen.
+func (e *Editor) virtualSpace() bool {
+	return e.config != nil && e.config.Editor.VirtualSpace && !e.viewport.WordWrap()
+}
+
+// fillVirtualSpace turns any virtual space before the cursor into real
+// spaces, so text typed past the end of a line lands where the cursor is.
+// The spaces are returned rather than recorded, for the caller to fold into
+// the undo entry of the edit that needed them.
+func (e *Editor) fillVirtualSpace() string {
+	doc := e.activeDoc()
+	n := doc.cursor.Virtual()
+	if n == 0 {
+		return ""
+	}
+	pad := strings.Repeat(" ", n)
+	doc.cursor.ClearVirtual()
+	doc.cursor.Sync()
+	doc.buffer.Insert(pad)
+	doc.cursor.SetByteOffset(doc.cursor.ByteOffset() + n)
+	return pad
+}
+
 func (e *Editor) backspace() {
+	if !e.checkEditable() {
+		return
+	}
 	if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
 		e.deleteSelection()
 		return
 	}
 
+	// In virtual space there is nothing to delete; just step back
+	if e.activeDoc().cursor.Virtual() > 0 {
+		e.activeDoc().cursor.MoveLeft()
+		return
+	}
+
 	if e.activeDoc().cursor.ByteOffset() == 0 {
 		return
 	}
@@ -4089,6 +4644,9 @@ func (e *Editor) backspace() {
 }
 
 func (e *Editor) delete() {
+	if !e.checkEditable() {
+		return
+	}
 	if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
 		e.deleteSelection()
 		return
@@ -4103,11 +4661,16 @@ func (e *Editor) delete() {
 		return
 	}
 
+	// Deleting from virtual space pulls the next line up to the cursor, so
+	// the gap is filled first and undone along with the delete
+	pad := e.fillVirtualSpace()
+	pos := e.activeDoc().cursor.ByteOffset()
 	entry := &UndoEntry{
-		Position:     e.activeDoc().cursor.ByteOffset(),
-		Deleted:      e.activeDoc().buffer.Substring(e.activeDoc().cursor.ByteOffset(), e.activeDoc().cursor.ByteOffset()+size),
-		CursorBefore: e.activeDoc().cursor.ByteOffset(),
-		CursorAfter:  e.activeDoc().cursor.ByteOffset(),
+		Position:     pos - len(pad),
+		Deleted:      e.activeDoc().buffer.Substring(pos, pos+size),
+		Inserted:     pad,
+		CursorBefore: pos - len(pad),
+		CursorAfter:  pos,
 	}
 
 	e.activeDoc().cursor.Sync()
@@ -4117,6 +4680,9 @@ func (e *Editor) delete() {
 }
 
 func (e *Editor) deleteSelection() {
+	if !e.checkEditable() {
+		return
+	}
 	if !e.activeDoc().selection.Active || e.activeDoc().selection.IsEmpty() {
 		return
 	}
@@ -4139,50 +4705,39 @@ func (e *Editor) deleteSelection() {
 }
 
 func (e *Editor) undo() {
+	if !e.checkEditable() {
+		return
+	}
 	entry := e.activeDoc().undoStack.Undo()
 	if entry == nil {
 		return
 	}
 
 	// Reverse the operation
-	if entry.Inserted != "" {
-		// Was an insertion - delete it
-		e.activeDoc().buffer.Replace(entry.Position, entry.Position+len(entry.Inserted), "")
-	}
-	if entry.Deleted != "" {
-		// Was a deletion - insert it back
-		e.activeDoc().buffer.MoveCursor(entry.Position)
-		e.activeDoc().buffer.Insert(entry.Deleted)
-	}
-
-	e.activeDoc().cursor.SetByteOffset(entry.CursorBefore)
+	e.activeDoc().cursor.SetByteOffset(entry.Revert(e.activeDoc().buffer))
 	e.activeDoc().selection.Clear()
 	e.activeDoc().modified = true
 }
 
 func (e *Editor) redo() {
+	if !e.checkEditable() {
+		return
+	}
 	entry := e.activeDoc().undoStack.Redo()
 	if entry == nil {
 		return
 	}
 
 	// Replay the operation
-	if entry.Deleted != "" {
-		// Was a deletion - delete it again
-		e.activeDoc().buffer.Replace(entry.Position, entry.Position+len(entry.Deleted), "")
-	}
-	if entry.Inserted != "" {
-		// Was an insertion - insert it again
-		e.activeDoc().buffer.MoveCursor(entry.Position)
-		e.activeDoc().buffer.Insert(entry.Inserted)
-	}
-
-	e.activeDoc().cursor.SetByteOffset(entry.CursorAfter)
+	e.activeDoc().cursor.SetByteOffset(entry.Apply(e.activeDoc().buffer))
 	e.activeDoc().selection.Clear()
 	e.activeDoc().modified = true
 }
 
 func (e *Editor) cut() {
+	if !e.checkEditable() {
+		return
+	}
 	if !e.activeDoc().selection.Active || e.activeDoc().selection.IsEmpty() {
 		return
 	}
@@ -4194,6 +4749,9 @@ func (e *Editor) cut() {
 
 // cutLine cuts the entire current line (like nano's Ctrl+K)
 func (e *Editor) cutLine() {
+	if !e.checkEditable() {
+		return
+	}
 	line := e.activeDoc().cursor.Line()
 	lineStart := e.activeDoc().buffer.LineStartOffset(line)
 	lineEnd := e.activeDoc().buffer.LineEndOffset(line)
@@ -4245,6 +4803,9 @@ func (e *Editor) copy() {
 }
 
 func (e *Editor) paste() {
+	if !e.checkEditable() {
+		return
+	}
 	text, err := e.clipboard.Paste()
 	if err != nil || text == "" {
 		return
@@ -4287,7 +4848,9 @@ func (e *Editor) doNewFile() {
 		scrollY:     0,
 		highlighter: syntax.New(""),
 		encoding:    enc.GetEncodingByID("utf-8"), // Default to UTF-8
+		savedLines:  []string{""},
 	}
+	e.configureHighlighter(doc.highlighter)
 	e.documents = append(e.documents, doc)
 	e.activeIdx = len(e.documents) - 1
 
@@ -4305,7 +4868,20 @@ func (e *Editor) closeFile() {
 	e.doCloseFile()
 }
 
+// forgetFile stops watching path for external changes once the active
+// document no longer refers to it, unless another open document does.
+func (e *Editor) forgetFile(path string) {
+	for i, doc := range e.documents {
+		if i != e.activeIdx && doc.filename == path {
+			return
+		}
+	}
+	e.watcher.Forget(path)
+}
+
 func (e *Editor) doCloseFile() {
+	e.rememberFileMark(e.activeDoc())
+	e.forgetFile(e.activeDoc().filename)
 	if len(e.documents) > 1 {
 		// Multiple buffers - remove current and switch to another
 		e.documents = append(e.documents[:e.activeIdx], e.documents[e.activeIdx+1:]...)
@@ -4323,6 +4899,7 @@ func (e *Editor) doCloseFile() {
 		e.activeDoc().undoStack.Clear()
 		e.activeDoc().filename = ""
 		e.activeDoc().modified = false
+		e.activeDoc().savedLines = []string{""}
 		e.activeDoc().scrollY = 0
 		e.activeDoc().highlighter.SetFile("")
 		e.activeDoc().encoding = enc.GetEncodingByID("utf-8")
@@ -4416,6 +4993,9 @@ func (e *Editor) revertFile() {
 }
 
 func (e *Editor) insertLoremIpsum() {
+	if !e.checkEditable() {
+		return
+	}
 	lorem := `Lorem ipsum dolor sit amet, consectetur adipiscing elit. Sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris.
 
 Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur. Excepteur sint occaecat cupidatat non proident.
@@ -4539,6 +5119,9 @@ func (e *Editor) handleFindReplaceKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // replaceNext finds the next occurrence and replaces it
 func (e *Editor) replaceNext() {
+	if !e.checkEditable() {
+		return
+	}
 	if e.findQuery == "" {
 		e.statusbar.SetMessage("No search term", "error")
 		return
@@ -4582,6 +5165,9 @@ func (e *Editor) replaceNext() {
 
 // replaceAll replaces all occurrences with a single undo entry
 func (e *Editor) replaceAll() {
+	if !e.checkEditable() {
+		return
+	}
 	if e.findQuery == "" {
 		e.statusbar.SetMessage("No search term", "error")
 		return
@@ -4634,6 +5220,7 @@ func (e *Editor) View() string {
 		sb.WriteString(e.pendingEscapes)
 		e.pendingEscapes = ""
 	}
+	sb.WriteString(e.updateCursorStyle())
 
 	// Menu bar
 	sb.WriteString(e.menubar.View())
@@ -4651,7 +5238,7 @@ func (e *Editor) View() string {
 			// Overlay dropdown on viewport, preserving text on both sides
 			for i, dropLine := range dropdownLines {
 				if i < len(viewportLines) {
-					viewportLines[i] = overlayLineAt(dropLine, viewportLines[i], offset)
+					viewportLines[i] = ui.OverlayAt(dropLine, viewportLines[i], offset)
 				}
 			}
 			viewportContent = strings.Join(viewportLines, "\n")
@@ -4722,7 +5309,7 @@ func (e *Editor) View() string {
 	// Find bar if active
 	if e.mode == ModeFind {
 		findContent := "Find: " + e.findQuery
-		cursor := "▂" // Lower quarter block cursor
+		cursor := e.box.InputCursor
 		padding := e.width - len(findContent) - 1
 		if padding < 0 {
 			padding = 0
@@ -4736,7 +5323,7 @@ func (e *Editor) View() string {
 
 	// Find/Replace bar if active (two lines)
 	if e.mode == ModeFindReplace {
-		cursor := "▂" // Lower quarter block cursor
+		cursor := e.box.InputCursor
 
 		// Line 1: Find field
 		findLine := "Find: " + e.findQuery
@@ -4783,7 +5370,7 @@ func (e *Editor) View() string {
 	// Prompt bar if active
 	if e.mode == ModePrompt {
 		promptContent := e.promptText + e.promptInput
-		cursor := "▂" // Lower quarter block cursor
+		cursor := e.box.InputCursor
 		padding := e.width - len(promptContent) - 1
 		if padding < 0 {
 			padding = 0
@@ -4796,10 +5383,10 @@ func (e *Editor) View() string {
 	}
 
 	// Status bar
-	e.statusbar.SetPosition(e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+	e.statusbar.SetStatus(ui.StatusInfo(renderState))
 	e.statusbar.SetFilename(e.activeDoc().filename)
 	e.statusbar.SetModified(e.activeDoc().modified)
-	e.statusbar.SetTotalLines(e.activeDoc().buffer.LineCount())
+	e.statusbar.SetReadOnly(e.activeDoc().readOnly)
 	e.statusbar.SetCounts(e.activeDoc().buffer.WordCount(), e.activeDoc().buffer.RuneCount())
 	e.statusbar.SetBufferInfo(e.activeIdx, len(e.documents))
 	// Set encoding display
@@ -4835,10 +5422,28 @@ func (e *Editor) SetFilename(filename string) {
 	if err != nil {
 		absPath = filename // Fall back to original if Abs fails
 	}
+	e.forgetFile(e.activeDoc().filename)
 	e.activeDoc().filename = absPath
 	e.activeDoc().highlighter.SetFile(absPath) // Update syntax highlighter
 }
 
+// SetReadOnly makes the active document and every file opened afterwards
+// read-only (the --readonly flag)
+func (e *Editor) SetReadOnly(readOnly bool) {
+	e.readOnly = readOnly
+	e.activeDoc().readOnly = readOnly
+}
+
+// isWritable reports whether the file at path can be opened for writing
+func isWritable(path string) bool {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
 // SetConfigError sets the config error state and shows the error dialog
 func (e *Editor) SetConfigError(filePath, errMsg string) {
 	e.configErrorFile = filePath
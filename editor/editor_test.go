@@ -0,0 +1,133 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAltScrollToCursorKeys(t *testing.T) {
+	tests := []struct {
+		key  rune
+		want int // Viewport row the cursor line ends up on
+	}{
+		{'l', 4},
+		{'L', 4},
+		{'t', 0},
+		{'z', 9},
+	}
+
+	for _, tt := range tests {
+		e := New()
+		e.OnResize(40, 12) // 10 viewport rows below the menu and above the status bar
+		e.insertText(strings.Repeat("line\n", 99) + "last")
+		e.activeDoc().cursor.SetPosition(50, 0)
+		e.viewport.SetScrollY(45)
+
+		e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{tt.key}, Alt: true})
+		if got := e.activeDoc().buffer.LineCount(); got != 100 {
+			t.Errorf("Alt+%c changed the buffer to %d lines", tt.key, got)
+		}
+		if got := 50 - e.viewport.ScrollY(); got != tt.want {
+			t.Errorf("Alt+%c: cursor on row %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestAltPageKeysMoveHalfAPage(t *testing.T) {
+	e := New()
+	e.OnResize(40, 12) // 10 viewport rows
+	e.insertText(strings.Repeat("line\n", 99) + "last")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(20, 0)
+
+	e.Update(tea.KeyMsg{Type: tea.KeyPgDown, Alt: true})
+	if got := doc.cursor.Line(); got != 25 {
+		t.Errorf("Alt+PgDown: cursor on line %d, want 25", got)
+	}
+	e.lastPageKey = time.Time{}
+	e.Update(tea.KeyMsg{Type: tea.KeyPgUp, Alt: true})
+	if got := doc.cursor.Line(); got != 20 {
+		t.Errorf("Alt+PgUp: cursor on line %d, want 20", got)
+	}
+	e.lastPageKey = time.Time{}
+	e.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if got := doc.cursor.Line(); got == 25 {
+		t.Error("PgDown without Alt moved only half a page")
+	}
+}
+
+func TestRenderStateCursorVisualLineCountsRunes(t *testing.T) {
+	e := New()
+	e.OnResize(40, 12)
+	e.viewport.SetWordWrap(true)
+	w := e.viewport.TextWidth()
+	e.insertText(strings.Repeat("é", w+1))
+	e.activeDoc().cursor.SetPosition(0, 2*w)
+
+	// Rune column w starts the second row; taken as a column, its byte
+	// offset 2w would land on the third
+	if got := e.buildRenderState().CursorVisualLine; got != 1 {
+		t.Errorf("CursorVisualLine = %d, want 1", got)
+	}
+}
+
+func TestToggleWordWrapKeepsRowCountingRunes(t *testing.T) {
+	e := New()
+	e.OnResize(40, 12)
+	w := e.viewport.TextWidth()
+	line := strings.Repeat("é", 3*w-1) // Three rows once wrapped
+	e.insertText(strings.Repeat(line+"\n", 30))
+	e.activeDoc().cursor.SetPosition(5, 2*w)
+	e.viewport.SetScrollY(0)
+
+	// Rune column w is on the line's second row, so the cursor's row 5
+	// becomes visual line 16; its byte offset 2w would pick the third row
+	e.toggleWordWrap()
+	if got := e.viewport.ScrollY(); got != 11 {
+		t.Errorf("scrollY after wrapping = %d, want 11", got)
+	}
+}
+
+func TestNewFileHighlighterFollowsConfig(t *testing.T) {
+	e := New()
+	e.config.Editor.MaxHighlightLineLength = 50
+	e.doNewFile()
+	if got := e.activeDoc().highlighter.MaxLineLength(); got != 50 {
+		t.Errorf("new file highlighter MaxLineLength = %d, want 50", got)
+	}
+}
+
+func TestIndentGuideGlyphFollowsASCIIMode(t *testing.T) {
+	e := New()
+	e.setBoxStyle(BoxSingle)
+	if got := e.buildRenderState().IndentGuide; got != "" {
+		t.Errorf("Unicode boxes: IndentGuide = %q, want the default", got)
+	}
+	e.setBoxStyle(BoxASCII)
+	if got := e.buildRenderState().IndentGuide; got != "|" {
+		t.Errorf("ASCII boxes: IndentGuide = %q, want %q", got, "|")
+	}
+}
+
+func TestLineNumberWidthFollowsUpdate(t *testing.T) {
+	e := New()
+	e.OnResize(80, 24)
+	if !e.viewport.ShowLineNum() {
+		e.toggleLineNumbers()
+	}
+	before := e.viewport.LineNumberWidth()
+	e.insertText(strings.Repeat("x\n", 100000))
+
+	e.View()
+	if got := e.viewport.LineNumberWidth(); got != before {
+		t.Fatalf("View resized the gutter to %d, want it left at %d", got, before)
+	}
+
+	e.Update(fileCheckMsg{})
+	if got := e.viewport.LineNumberWidth(); got <= before {
+		t.Errorf("after Update gutter width = %d, want wider than %d", got, before)
+	}
+}
@@ -0,0 +1,370 @@
+package editor
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/syntax"
+)
+
+// expandBrackets are the bracket pairs selection expansion steps out through.
+var expandBrackets = map[rune]rune{'(': ')', '[': ']', '{': '}'}
+
+// expandQuotes are the string delimiters selection expansion recognizes.
+// Without a lexer, strings are taken to end on the line they start.
+const expandQuotes = "\"'`"
+
+// ExpandSelection returns the smallest syntactic unit that strictly
+// contains r. In growing order the units are: the word at the cursor, the
+// inside of the enclosing string or bracket pair, that pair with its
+// delimiters, the line without its indentation, the whole line, the
+// paragraph of non-blank lines around it, and finally the whole document.
+// Columns are rune indices. ok is false when r already covers everything.
+//
+// Brackets and quotes are matched on the raw text; use
+// ExpandSelectionLiterals when the string and comment tokens are known.
+func ExpandSelection(lines []string, r Range) (expanded Range, ok bool) {
+	return ExpandSelectionLiterals(lines, nil, r)
+}
+
+// ExpandSelectionLiterals is ExpandSelection with literals, the string and
+// comment runs of each line as returned by syntax.Highlighter.Literals.
+// Strings are then taken from the string tokens, which may span lines, and
+// brackets inside strings and comments are ignored. A nil literals falls
+// back to matching on the raw text.
+func ExpandSelectionLiterals(lines []string, literals [][]syntax.Literal, r Range) (expanded Range, ok bool) {
+	if len(lines) == 0 {
+		return r, false
+	}
+	r = normalizeRange(r)
+
+	var candidates []Range
+	if ln := r.Start.Line; ln == r.End.Line {
+		line := lines[ln]
+		start, end := WordBoundsAt(line, r.Start.Col)
+		candidates = append(candidates, Range{Position{ln, start}, Position{ln, end}})
+		if literals == nil {
+			if inner, outer, found := enclosingQuotes(line, ln, r.Start.Col, r.End.Col); found {
+				candidates = append(candidates, inner, outer)
+			}
+		}
+		if body := strings.TrimSpace(line); body != "" {
+			indent := utf8.RuneCountInString(leadingWhitespace(line))
+			candidates = append(candidates, Range{Position{ln, indent}, Position{ln, indent + utf8.RuneCountInString(body)}})
+		}
+	}
+	if literals != nil {
+		if inner, outer, found := enclosingString(lines, literals, r); found {
+			candidates = append(candidates, inner, outer)
+		}
+	}
+	if inner, outer, found := enclosingBrackets(lines, literals, r); found {
+		candidates = append(candidates, inner, outer)
+	}
+	candidates = append(candidates, Range{Position{r.Start.Line, 0}, Position{r.End.Line, utf8.RuneCountInString(lines[r.End.Line])}})
+	first, last := r.Start.Line, r.End.Line
+	for first > 0 && !isBlankLine(lines[first-1]) {
+		first--
+	}
+	for last < len(lines)-1 && !isBlankLine(lines[last+1]) {
+		last++
+	}
+	candidates = append(candidates,
+		Range{Position{first, 0}, Position{last, utf8.RuneCountInString(lines[last])}},
+		Range{Position{0, 0}, Position{len(lines) - 1, utf8.RuneCountInString(lines[len(lines)-1])}},
+	)
+
+	best, bestSize := r, -1
+	for _, c := range candidates {
+		if c == r || !rangeContains(c, r) {
+			continue
+		}
+		if size := rangeSize(lines, c); bestSize < 0 || size < bestSize {
+			best, bestSize = c, size
+		}
+	}
+	return best, bestSize >= 0
+}
+
+// enclosingQuotes finds the string on line around columns [start, end):
+// inner excludes the quotes and outer includes them. A quote is taken to
+// open a string when an even number of the same quote precedes it.
+func enclosingQuotes(line string, ln, start, end int) (inner, outer Range, ok bool) {
+	runes := []rune(line)
+	for _, q := range expandQuotes {
+		open := -1
+		for i := 0; i < len(runes); i++ {
+			if runes[i] != q || (i > 0 && runes[i-1] == '\\') {
+				continue
+			}
+			if open < 0 {
+				open = i
+				continue
+			}
+			if open < start && i >= end {
+				inner = Range{Position{ln, open + 1}, Position{ln, i}}
+				outer = Range{Position{ln, open}, Position{ln, i + 1}}
+				return inner, outer, true
+			}
+			open = -1
+		}
+	}
+	return Range{}, Range{}, false
+}
+
+// enclosingString finds the string token around r, which may span lines:
+// inner excludes the quotes and outer includes them.
+func enclosingString(lines []string, literals [][]syntax.Literal, r Range) (inner, outer Range, ok bool) {
+	first, found := stringRunAt(literals, r.Start)
+	if !found {
+		return Range{}, Range{}, false
+	}
+	last, found := stringRunAt(literals, r.End)
+	if !found {
+		return Range{}, Range{}, false
+	}
+	outer = Range{Position{r.Start.Line, first.Start}, Position{r.End.Line, last.End}}
+
+	// Follow a string that goes on across line breaks
+	for outer.Start.Col == 0 && outer.Start.Line > 0 {
+		prev := literals[outer.Start.Line-1]
+		if len(prev) == 0 {
+			break
+		}
+		run := prev[len(prev)-1]
+		if run.Category != syntax.CategoryString || run.End != utf8.RuneCountInString(lines[outer.Start.Line-1]) {
+			break
+		}
+		outer.Start = Position{outer.Start.Line - 1, run.Start}
+	}
+	for outer.End.Line < len(lines)-1 && outer.End.Col == utf8.RuneCountInString(lines[outer.End.Line]) {
+		next := literals[outer.End.Line+1]
+		if len(next) == 0 || next[0].Category != syntax.CategoryString || next[0].Start != 0 {
+			break
+		}
+		outer.End = Position{outer.End.Line + 1, next[0].End}
+	}
+	if !positionLess(outer.Start, r.Start) || !positionLess(r.End, outer.End) {
+		return Range{}, Range{}, false
+	}
+
+	// The quotes are the run of quote characters at the start, after any
+	// prefix such as r or b, and as many of the same at the end
+	open := []rune(lines[outer.Start.Line])[outer.Start.Col:]
+	closeLine := []rune(lines[outer.End.Line])
+	skip := 0
+	for skip < len(open) && !strings.ContainsRune(expandQuotes, open[skip]) && skip < 2 {
+		skip++
+	}
+	quotes := 0
+	for skip+quotes < len(open) && quotes < 3 && strings.ContainsRune(expandQuotes, open[skip+quotes]) && open[skip+quotes] == open[skip] {
+		quotes++
+	}
+	if quotes == 0 {
+		skip = 0
+	}
+	trailing := 0
+	for trailing < quotes && outer.End.Col-1-trailing >= 0 && closeLine[outer.End.Col-1-trailing] == open[skip] {
+		trailing++
+	}
+	inner = Range{
+		Position{outer.Start.Line, outer.Start.Col + skip + quotes},
+		Position{outer.End.Line, outer.End.Col - trailing},
+	}
+	if positionLess(inner.End, inner.Start) || !rangeContains(inner, r) {
+		inner = outer
+	}
+	return inner, outer, true
+}
+
+// stringRunAt returns the string run on p's line that p lies within or
+// borders.
+func stringRunAt(literals [][]syntax.Literal, p Position) (syntax.Literal, bool) {
+	for _, run := range literals[p.Line] {
+		if run.Category == syntax.CategoryString && run.Start <= p.Col && p.Col <= run.End {
+			return run, true
+		}
+	}
+	return syntax.Literal{}, false
+}
+
+// inLiteral reports whether the character at p is part of a string or
+// comment token.
+func inLiteral(literals [][]syntax.Literal, p Position) bool {
+	if literals == nil {
+		return false
+	}
+	for _, run := range literals[p.Line] {
+		if run.Start <= p.Col && p.Col < run.End {
+			return true
+		}
+	}
+	return false
+}
+
+// enclosingBrackets finds the innermost bracket pair around r, which may
+// span lines: inner excludes the brackets and outer includes them.
+// Brackets inside literals are skipped.
+func enclosingBrackets(lines []string, literals [][]syntax.Literal, r Range) (inner, outer Range, ok bool) {
+	// Walk backward from the start for an opener with no closer after it
+	depth := map[rune]int{}
+	for ln := r.Start.Line; ln >= 0; ln-- {
+		runes := []rune(lines[ln])
+		col := len(runes) - 1
+		if ln == r.Start.Line {
+			col = r.Start.Col - 1
+		}
+		for ; col >= 0; col-- {
+			ch := runes[col]
+			if inLiteral(literals, Position{ln, col}) {
+				continue
+			}
+			if closer, isOpen := expandBrackets[ch]; isOpen {
+				if depth[closer] > 0 {
+					depth[closer]--
+					continue
+				}
+				open := Position{ln, col}
+				if close, found := matchingCloser(lines, literals, open, ch, closer); found && !positionLess(close, r.End) {
+					return Range{Position{ln, col + 1}, close}, Range{open, Position{close.Line, close.Col + 1}}, true
+				}
+				continue
+			}
+			if isClosingBracket(ch) {
+				depth[ch]++
+			}
+		}
+	}
+	return Range{}, Range{}, false
+}
+
+// matchingCloser returns the position of the closer matching the opener at
+// open.
+func matchingCloser(lines []string, literals [][]syntax.Literal, open Position, opener, closer rune) (Position, bool) {
+	depth := 0
+	for ln := open.Line; ln < len(lines); ln++ {
+		runes := []rune(lines[ln])
+		col := 0
+		if ln == open.Line {
+			col = open.Col + 1
+		}
+		for ; col < len(runes); col++ {
+			if inLiteral(literals, Position{ln, col}) {
+				continue
+			}
+			switch runes[col] {
+			case opener:
+				depth++
+			case closer:
+				if depth == 0 {
+					return Position{ln, col}, true
+				}
+				depth--
+			}
+		}
+	}
+	return Position{}, false
+}
+
+// normalizeRange orders r so Start is not after End.
+func normalizeRange(r Range) Range {
+	if positionLess(r.End, r.Start) {
+		r.Start, r.End = r.End, r.Start
+	}
+	return r
+}
+
+// rangeContains reports whether outer covers all of inner.
+func rangeContains(outer, inner Range) bool {
+	return !positionLess(inner.Start, outer.Start) && !positionLess(outer.End, inner.End)
+}
+
+// rangeSize returns the number of runes in r, counting line breaks.
+func rangeSize(lines []string, r Range) int {
+	if r.Start.Line == r.End.Line {
+		return r.End.Col - r.Start.Col
+	}
+	size := utf8.RuneCountInString(lines[r.Start.Line]) - r.Start.Col + 1
+	for ln := r.Start.Line + 1; ln < r.End.Line; ln++ {
+		size += utf8.RuneCountInString(lines[ln]) + 1
+	}
+	return size + r.End.Col
+}
+
+// SelectionHistory remembers the ranges selection expansion grew from, so
+// shrinking steps back through them. It forgets them once the selection is
+// changed some other way.
+type SelectionHistory struct {
+	stack   []Range
+	current Range
+}
+
+// Expand grows r with ExpandSelectionLiterals, remembering r for Shrink.
+func (h *SelectionHistory) Expand(lines []string, literals [][]syntax.Literal, r Range) (Range, bool) {
+	r = normalizeRange(r)
+	if r != h.current {
+		h.stack = nil
+	}
+	expanded, ok := ExpandSelectionLiterals(lines, literals, r)
+	if !ok {
+		return r, false
+	}
+	h.stack = append(h.stack, r)
+	h.current = expanded
+	return expanded, true
+}
+
+// Shrink returns the range r was expanded from. ok is false when r was not
+// produced by Expand.
+func (h *SelectionHistory) Shrink(r Range) (Range, bool) {
+	r = normalizeRange(r)
+	if r != h.current || len(h.stack) == 0 {
+		h.stack = nil
+		return r, false
+	}
+	prev := h.stack[len(h.stack)-1]
+	h.stack = h.stack[:len(h.stack)-1]
+	h.current = prev
+	return prev, true
+}
+
+// expandSelection grows the selection, or the cursor position when nothing
+// is selected, to the next enclosing unit; with shrink it steps back to the
+// range the last expansion started from.
+func (e *Editor) expandSelection(shrink bool) {
+	doc := e.activeDoc()
+	lines := doc.buffer.Lines()
+	toPos := func(offset int) Position {
+		line, col := doc.buffer.PositionToLineCol(offset)
+		return Position{line, utf8.RuneCountInString(lines[line][:col])}
+	}
+	toOffset := func(p Position) int {
+		return doc.buffer.LineColToPosition(p.Line, runeColToByte(lines[p.Line], p.Col))
+	}
+
+	cur := Range{toPos(doc.cursor.ByteOffset()), toPos(doc.cursor.ByteOffset())}
+	if doc.selection.Active {
+		start, end := doc.selection.Normalize()
+		cur = Range{toPos(start), toPos(end)}
+	}
+
+	var next Range
+	var ok bool
+	if shrink {
+		next, ok = e.selectionHistory.Shrink(cur)
+	} else {
+		var literals [][]syntax.Literal
+		if doc.highlighter != nil {
+			literals = doc.highlighter.Literals(lines)
+		}
+		next, ok = e.selectionHistory.Expand(lines, literals, cur)
+	}
+	if !ok {
+		return
+	}
+	doc.selection.Active = true
+	doc.selection.Anchor = toOffset(next.Start)
+	doc.selection.Cursor = toOffset(next.End)
+	doc.cursor.SetByteOffset(doc.selection.Cursor)
+	e.viewport.EnsureCursorVisibleWrapped(lines, doc.cursor.Line(), doc.cursor.Col())
+}
@@ -0,0 +1,144 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/syntax"
+)
+
+func TestExpandSelection(t *testing.T) {
+	lines := []string{
+		"func f() {",
+		"\tx := g(alpha, beta(1))",
+		"\ts := \"a b c\"",
+		"}",
+	}
+	rng := func(l1, c1, l2, c2 int) Range { return Range{Position{l1, c1}, Position{l2, c2}} }
+
+	tests := []struct {
+		name string
+		from Range
+		want Range
+	}{
+		{"cursor to word", rng(1, 9, 1, 9), rng(1, 8, 1, 13)},
+		{"word to inside of parentheses", rng(1, 8, 1, 13), rng(1, 8, 1, 22)},
+		{"inside to the parentheses", rng(1, 8, 1, 22), rng(1, 7, 1, 23)},
+		{"parentheses to the line body", rng(1, 7, 1, 23), rng(1, 1, 1, 23)},
+		{"line body to the whole line", rng(1, 1, 1, 23), rng(1, 0, 1, 23)},
+		{"line to inside of the braces", rng(1, 0, 1, 23), rng(0, 10, 3, 0)},
+		{"inside the braces to the braces", rng(0, 10, 3, 0), rng(0, 9, 3, 1)},
+		{"word inside a string", rng(2, 9, 2, 9), rng(2, 9, 2, 10)},
+		{"string contents", rng(2, 9, 2, 10), rng(2, 7, 2, 12)},
+		{"string with quotes", rng(2, 7, 2, 12), rng(2, 6, 2, 13)},
+		{"reversed range is normalized", rng(1, 13, 1, 8), rng(1, 8, 1, 22)},
+	}
+
+	for _, tt := range tests {
+		got, ok := ExpandSelection(lines, tt.from)
+		if !ok || got != tt.want {
+			t.Errorf("%s: ExpandSelection(%v) = (%v, %v), want %v", tt.name, tt.from, got, ok, tt.want)
+		}
+	}
+
+	all := rng(0, 0, 3, 1)
+	if got, ok := ExpandSelection(lines, all); ok {
+		t.Errorf("ExpandSelection(whole document) = (%v, true), want false", got)
+	}
+}
+
+func TestSelectionHistoryShrinksBack(t *testing.T) {
+	lines := []string{"x = (a + (bc * d))"}
+	start := Range{Position{0, 10}, Position{0, 10}}
+
+	var h SelectionHistory
+	steps := []Range{start}
+	r := start
+	for i := 0; i < 4; i++ {
+		var ok bool
+		if r, ok = h.Expand(lines, nil, r); !ok {
+			t.Fatalf("expand %d failed", i)
+		}
+		steps = append(steps, r)
+	}
+	// bc, inside the inner parentheses, the inner parentheses, inside the outer ones
+	if want := (Range{Position{0, 5}, Position{0, 17}}); r != want {
+		t.Fatalf("after 4 expansions = %v, want %v", r, want)
+	}
+
+	for i := len(steps) - 2; i >= 0; i-- {
+		var ok bool
+		if r, ok = h.Shrink(r); !ok || r != steps[i] {
+			t.Fatalf("shrink to step %d = (%v, %v), want %v", i, r, ok, steps[i])
+		}
+	}
+	if _, ok := h.Shrink(r); ok {
+		t.Error("shrink past the starting range succeeded")
+	}
+
+	// A selection changed by other means is not shrunk
+	h.Expand(lines, nil, start)
+	if _, ok := h.Shrink(Range{Position{0, 0}, Position{0, 1}}); ok {
+		t.Error("shrink of an unrelated selection succeeded")
+	}
+}
+
+func TestExpandSelectionLiterals(t *testing.T) {
+	lines := []string{
+		"f(\"a) b\", c) // (x",
+		"s := `one",
+		"two`",
+	}
+	literals := syntax.New("x.go").Literals(lines)
+	rng := func(l1, c1, l2, c2 int) Range { return Range{Position{l1, c1}, Position{l2, c2}} }
+
+	tests := []struct {
+		name string
+		from Range
+		want Range
+	}{
+		{"word inside a string", rng(0, 3, 0, 3), rng(0, 3, 0, 4)},
+		{"string contents despite the bracket in it", rng(0, 3, 0, 4), rng(0, 3, 0, 7)},
+		{"string with quotes", rng(0, 3, 0, 7), rng(0, 2, 0, 8)},
+		{"call arguments skip bracketed text", rng(0, 2, 0, 8), rng(0, 2, 0, 11)},
+		{"the call's parentheses", rng(0, 2, 0, 11), rng(0, 1, 0, 12)},
+		{"raw string across lines", rng(1, 6, 1, 9), rng(1, 6, 2, 3)},
+		{"raw string with quotes", rng(1, 6, 2, 3), rng(1, 5, 2, 4)},
+	}
+
+	for _, tt := range tests {
+		got, ok := ExpandSelectionLiterals(lines, literals, tt.from)
+		if !ok || got != tt.want {
+			t.Errorf("%s: ExpandSelectionLiterals(%v) = (%v, %v), want %v", tt.name, tt.from, got, ok, tt.want)
+		}
+	}
+}
+
+func TestExpandSelectionKeys(t *testing.T) {
+	e := New()
+	e.OnResize(40, 12)
+	e.insertText("x = (a + (bc * d))")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(0, 10)
+
+	key := func(r rune) { e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: true}) }
+	selected := func() (int, int) {
+		start, end := doc.selection.Normalize()
+		return start, end
+	}
+
+	key('=')
+	key('=')
+	if start, end := selected(); start != 10 || end != 16 {
+		t.Fatalf("after expanding twice, selection = [%d, %d), want [10, 16)", start, end)
+	}
+	key('-')
+	if start, end := selected(); start != 10 || end != 12 {
+		t.Errorf("after shrinking, selection = [%d, %d), want [10, 12)", start, end)
+	}
+	key('-')
+	if start, end := selected(); start != 10 || end != 10 {
+		t.Errorf("after shrinking twice, selection = [%d, %d), want the cursor at 10", start, end)
+	}
+}
@@ -0,0 +1,73 @@
+package editor
+
+import (
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/filemarks"
+)
+
+// marks returns the remembered file positions, reading them from disk the
+// first time they are needed.
+func (e *Editor) marks() *filemarks.Store {
+	if e.fileMarks == nil {
+		e.fileMarks = filemarks.New(0)
+		if path, err := filemarks.DefaultPath(); err == nil {
+			e.fileMarks, _ = filemarks.Load(path, 0)
+		}
+	}
+	return e.fileMarks
+}
+
+// restoreFileMark moves a freshly opened document's cursor and scroll
+// position to where they were when the file was last closed, and makes the
+// file the most recently used. A mark past the end of a file that has since
+// shrunk is clamped to its last line, and one past the end of its line to
+// the line end. Marks hold rune columns, so the cursor always lands on a
+// character boundary.
+func (e *Editor) restoreFileMark(doc *Document) bool {
+	if doc.filename == "" {
+		return false
+	}
+	mark, ok := e.marks().Get(doc.filename)
+	if !ok {
+		return false
+	}
+	e.marks().Set(doc.filename, mark)
+	mark = mark.Clamp(doc.buffer.LineCount())
+	doc.cursor.SetPosition(mark.Line, runeColToByte(doc.buffer.Lines()[mark.Line], mark.Col))
+	doc.scrollY = mark.ScrollY
+	return true
+}
+
+// rememberFileMark records where doc's cursor and scroll position are.
+func (e *Editor) rememberFileMark(doc *Document) {
+	if doc.filename == "" {
+		return
+	}
+	scrollY := doc.scrollY
+	if doc == e.activeDoc() {
+		scrollY = e.viewport.ScrollY()
+	}
+	line := doc.buffer.Lines()[doc.cursor.Line()]
+	e.marks().Set(doc.filename, filemarks.Mark{
+		Line:    doc.cursor.Line(),
+		Col:     utf8.RuneCountInString(line[:min(doc.cursor.Col(), len(line))]),
+		ScrollY: scrollY,
+	})
+}
+
+// SaveFileMarks records the position in every open file and writes the
+// remembered positions to the config directory. It is called on exit.
+func (e *Editor) SaveFileMarks() error {
+	for _, doc := range e.documents {
+		e.rememberFileMark(doc)
+	}
+	if e.fileMarks == nil {
+		return nil // Nothing opened or closed, so nothing to write
+	}
+	path, err := filemarks.DefaultPath()
+	if err != nil {
+		return err
+	}
+	return e.fileMarks.Save(path)
+}
@@ -0,0 +1,74 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cornish/textivus-editor/filemarks"
+)
+
+func TestLoadFileRestoresFileMark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte(strings.Repeat("héllo\n", 5)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		mark     filemarks.Mark
+		wantLine int
+		wantCol  int
+	}{
+		{"in range", filemarks.Mark{Line: 3, Col: 2}, 3, 3}, // Rune column 2 is after "hé"
+		{"stale line past the end", filemarks.Mark{Line: 40, Col: 2, ScrollY: 35}, 5, 0},
+		{"stale column past the line end", filemarks.Mark{Line: 1, Col: 40}, 1, 6},
+	}
+
+	for _, tt := range tests {
+		e := New()
+		e.fileMarks = filemarks.New(0)
+		e.fileMarks.Set(path, tt.mark)
+		if err := e.LoadFile(path); err != nil {
+			t.Fatalf("%s: LoadFile: %v", tt.name, err)
+		}
+		doc := e.activeDoc()
+		if doc.cursor.Line() != tt.wantLine || doc.cursor.Col() != tt.wantCol {
+			t.Errorf("%s: cursor at %d:%d, want %d:%d",
+				tt.name, doc.cursor.Line(), doc.cursor.Col(), tt.wantLine, tt.wantCol)
+		}
+		if y := e.viewport.ScrollY(); y > tt.wantLine {
+			t.Errorf("%s: scrollY = %d, past the cursor line", tt.name, y)
+		}
+
+		// Closing remembers the new position, in runes
+		doc.cursor.SetPosition(1, 3)
+		e.doCloseFile()
+		if got, _ := e.fileMarks.Get(path); got.Line != 1 || got.Col != 2 {
+			t.Errorf("%s: mark after close = %+v, want line 1 col 2", tt.name, got)
+		}
+	}
+}
+
+func TestRestoreFileMarkMakesFileRecent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	e.fileMarks = filemarks.New(2)
+	e.fileMarks.Set(path, filemarks.Mark{})
+	e.fileMarks.Set(filepath.Join(dir, "b.txt"), filemarks.Mark{})
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The store is full; with a.txt used last, b.txt is the one forgotten
+	e.fileMarks.Set(filepath.Join(dir, "c.txt"), filemarks.Mark{})
+	if _, ok := e.fileMarks.Get(path); !ok {
+		t.Error("the restored file was evicted as least recently used")
+	}
+}
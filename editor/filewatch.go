@@ -0,0 +1,80 @@
+package editor
+
+import (
+	"os"
+	"time"
+)
+
+// FileInfoSnapshot records what a file looked like on disk at some point,
+// so later external changes can be detected.
+type FileInfoSnapshot struct {
+	ModTime time.Time
+	Size    int64
+	Exists  bool
+}
+
+// SnapshotFile records the current state of path on disk.
+func SnapshotFile(path string) FileInfoSnapshot {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileInfoSnapshot{}
+	}
+	return FileInfoSnapshot{ModTime: info.ModTime(), Size: info.Size(), Exists: true}
+}
+
+// Equal reports whether two snapshots describe the same file state.
+func (s FileInfoSnapshot) Equal(o FileInfoSnapshot) bool {
+	return s.Exists == o.Exists && s.Size == o.Size && s.ModTime.Equal(o.ModTime)
+}
+
+// HasChangedOnDisk reports whether path differs from the known snapshot:
+// modified, resized, deleted, or created since the snapshot was taken.
+func HasChangedOnDisk(path string, known FileInfoSnapshot) bool {
+	return !SnapshotFile(path).Equal(known)
+}
+
+// FileWatcher tracks open files and reports each external change once
+// through OnChange, which the editor uses to warn about or offer to reload
+// the file.
+type FileWatcher struct {
+	known map[string]FileInfoSnapshot
+
+	// OnChange is called from Check for every watched file that changed.
+	// now.Exists is false if the file was deleted.
+	OnChange func(path string, now FileInfoSnapshot)
+}
+
+// NewFileWatcher creates a watcher that reports changes to onChange.
+func NewFileWatcher(onChange func(path string, now FileInfoSnapshot)) *FileWatcher {
+	return &FileWatcher{known: make(map[string]FileInfoSnapshot), OnChange: onChange}
+}
+
+// Watch starts tracking path, or refreshes its snapshot after the editor
+// itself wrote the file.
+func (w *FileWatcher) Watch(path string) {
+	if path == "" {
+		return
+	}
+	w.known[path] = SnapshotFile(path)
+}
+
+// Forget stops tracking path.
+func (w *FileWatcher) Forget(path string) {
+	delete(w.known, path)
+}
+
+// Check compares every watched file against its snapshot and reports the
+// ones that changed. The new state is recorded, so a change is reported
+// only once.
+func (w *FileWatcher) Check() {
+	for path, known := range w.known {
+		now := SnapshotFile(path)
+		if now.Equal(known) {
+			continue
+		}
+		w.known[path] = now
+		if w.OnChange != nil {
+			w.OnChange(path, now)
+		}
+	}
+}
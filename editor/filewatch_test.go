@@ -0,0 +1,91 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHasChangedOnDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	known := SnapshotFile(path)
+	if !known.Exists {
+		t.Fatal("SnapshotFile of existing file: Exists = false")
+	}
+	if HasChangedOnDisk(path, known) {
+		t.Error("unchanged file reported as changed")
+	}
+
+	// Same size, newer mtime
+	later := known.ModTime.Add(2 * time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if !HasChangedOnDisk(path, known) {
+		t.Error("mtime change not detected")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if !HasChangedOnDisk(path, known) {
+		t.Error("deletion not detected")
+	}
+	if SnapshotFile(path).Exists {
+		t.Error("SnapshotFile of deleted file: Exists = true")
+	}
+}
+
+func TestFileWatcherReportsChangeOnce(t *testing.T) {
+	dir := t.TempDir()
+	changed := filepath.Join(dir, "changed.txt")
+	deleted := filepath.Join(dir, "deleted.txt")
+	for _, p := range []string{changed, deleted} {
+		if err := os.WriteFile(p, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := make(map[string]FileInfoSnapshot)
+	w := NewFileWatcher(func(path string, now FileInfoSnapshot) { got[path] = now })
+	w.Watch(changed)
+	w.Watch(deleted)
+	w.Check()
+	if len(got) != 0 {
+		t.Fatalf("Check with no changes reported %v", got)
+	}
+
+	if err := os.WriteFile(changed, []byte("longer"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(deleted); err != nil {
+		t.Fatal(err)
+	}
+	w.Check()
+	if now, ok := got[changed]; !ok || !now.Exists || now.Size != 6 {
+		t.Errorf("changed file: reported %+v (ok=%v), want existing with size 6", now, ok)
+	}
+	if now, ok := got[deleted]; !ok || now.Exists {
+		t.Errorf("deleted file: reported %+v (ok=%v), want Exists=false", now, ok)
+	}
+
+	clear(got)
+	w.Check()
+	if len(got) != 0 {
+		t.Errorf("second Check reported %v again, want nothing", got)
+	}
+
+	// Forgotten files are no longer checked
+	w.Forget(changed)
+	if err := os.WriteFile(changed, []byte("again!!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w.Check()
+	if len(got) != 0 {
+		t.Errorf("forgotten file reported: %v", got)
+	}
+}
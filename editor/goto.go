@@ -0,0 +1,39 @@
+package editor
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ResolveGotoTarget parses "Go to line" input and returns the 0-indexed
+// target line. Input is a 1-indexed line number, a +N/-N offset relative to
+// currentLine (0-indexed), or "$" for the last line. Targets outside the
+// document are clamped to the first or last line; ok is false for input that
+// does not parse.
+func ResolveGotoTarget(input string, currentLine, totalLines int) (line int, ok bool) {
+	input = strings.TrimSpace(input)
+	if input == "" || totalLines <= 0 {
+		return 0, false
+	}
+
+	switch {
+	case input == "$":
+		line = totalLines - 1
+	case input[0] == '+' || input[0] == '-':
+		n, err := strconv.Atoi(input[1:])
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		if input[0] == '-' {
+			n = -n
+		}
+		line = currentLine + n
+	default:
+		n, err := strconv.Atoi(input)
+		if err != nil {
+			return 0, false
+		}
+		line = n - 1
+	}
+	return max(0, min(line, totalLines-1)), true
+}
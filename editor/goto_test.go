@@ -0,0 +1,39 @@
+package editor
+
+import "testing"
+
+func TestResolveGotoTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		current  int
+		total    int
+		wantLine int
+		wantOK   bool
+	}{
+		{"absolute", "10", 0, 100, 9, true},
+		{"absolute first line", "1", 50, 100, 0, true},
+		{"surrounding spaces", " 7 ", 0, 100, 6, true},
+		{"relative forward", "+5", 10, 100, 15, true},
+		{"relative backward", "-3", 10, 100, 7, true},
+		{"last line", "$", 3, 100, 99, true},
+		{"absolute past end clamps", "500", 0, 100, 99, true},
+		{"absolute zero clamps", "0", 20, 100, 0, true},
+		{"relative past end clamps", "+50", 80, 100, 99, true},
+		{"relative before start clamps", "-50", 10, 100, 0, true},
+		{"empty", "", 0, 100, 0, false},
+		{"garbage", "abc", 0, 100, 0, false},
+		{"bare sign", "+", 0, 100, 0, false},
+		{"double sign", "+-3", 10, 100, 0, false},
+		{"trailing junk", "12x", 0, 100, 0, false},
+		{"dollar with junk", "$1", 0, 100, 0, false},
+	}
+
+	for _, tt := range tests {
+		line, ok := ResolveGotoTarget(tt.input, tt.current, tt.total)
+		if line != tt.wantLine || ok != tt.wantOK {
+			t.Errorf("%s: ResolveGotoTarget(%q, %d, %d) = (%d, %v), want (%d, %v)",
+				tt.name, tt.input, tt.current, tt.total, line, ok, tt.wantLine, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package editor
+
+import (
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// NextGrapheme returns the rune column just past the grapheme cluster that
+// starts at col, so flags, ZWJ emoji sequences and combining marks are
+// stepped over as one character. Returns col unchanged at the end of line.
+func NextGrapheme(line string, col int) int {
+	start := runeColToByte(line, col)
+	if start >= len(line) {
+		return utf8.RuneCountInString(line)
+	}
+	n := firstGraphemeLen(line[start:])
+	return utf8.RuneCountInString(line[:start+n])
+}
+
+// PrevGrapheme returns the rune column where the grapheme cluster ending at
+// col starts. Returns 0 at the start of the line.
+func PrevGrapheme(line string, col int) int {
+	end := runeColToByte(line, col)
+	if end <= 0 {
+		return 0
+	}
+	n := lastGraphemeLen(line[:end])
+	return utf8.RuneCountInString(line[:end-n])
+}
+
+// firstGraphemeLen returns the byte length of the first grapheme cluster in s.
+func firstGraphemeLen(s string) int {
+	cluster, _, _, _ := uniseg.FirstGraphemeClusterInString(s, -1)
+	return len(cluster)
+}
+
+// lastGraphemeLen returns the byte length of the last grapheme cluster in s.
+func lastGraphemeLen(s string) int {
+	last := 0
+	state := -1
+	for len(s) > 0 {
+		var cluster string
+		cluster, s, _, state = uniseg.FirstGraphemeClusterInString(s, state)
+		last = len(cluster)
+	}
+	return last
+}
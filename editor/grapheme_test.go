@@ -0,0 +1,87 @@
+package editor
+
+import "testing"
+
+const (
+	flagJP   = "\U0001F1EF\U0001F1F5"             // 🇯🇵, two regional indicators
+	family   = "\U0001F468‍\U0001F469‍\U0001F467" // 👨‍👩‍👧, five runes joined by ZWJ
+	eAccent  = "é"                               // e + combining acute
+	mixedRow = "a" + flagJP + family + eAccent + "z"
+)
+
+func TestNextGrapheme(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		col  int
+		want int
+	}{
+		{"ascii", "abc", 0, 1},
+		{"flag", flagJP + "x", 0, 2},
+		{"family emoji", family + "x", 0, 5},
+		{"combining accent", eAccent + "x", 0, 2},
+		{"walks mixed line", mixedRow, 1, 3},
+		{"end of line", "ab", 2, 2},
+		{"past end clamps", "ab", 9, 2},
+	}
+
+	for _, tt := range tests {
+		if got := NextGrapheme(tt.line, tt.col); got != tt.want {
+			t.Errorf("%s: NextGrapheme(%q, %d) = %d, want %d", tt.name, tt.line, tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestPrevGrapheme(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		col  int
+		want int
+	}{
+		{"ascii", "abc", 2, 1},
+		{"flag", "x" + flagJP, 3, 1},
+		{"family emoji", "x" + family, 6, 1},
+		{"combining accent", "x" + eAccent, 3, 1},
+		{"start of line", "ab", 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := PrevGrapheme(tt.line, tt.col); got != tt.want {
+			t.Errorf("%s: PrevGrapheme(%q, %d) = %d, want %d", tt.name, tt.line, tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestCursorMovesByGrapheme(t *testing.T) {
+	buf := NewBufferFromString(mixedRow + "\nq")
+	c := NewCursor(buf)
+
+	// a | flag | family | e+accent | z | newline | q
+	wantRight := []int{
+		1,
+		1 + len(flagJP),
+		1 + len(flagJP) + len(family),
+		1 + len(flagJP) + len(family) + len(eAccent),
+		len(mixedRow),
+		len(mixedRow) + 1,
+		len(mixedRow) + 2,
+	}
+	for i, want := range wantRight {
+		c.MoveRight()
+		if got := c.ByteOffset(); got != want {
+			t.Fatalf("MoveRight step %d: pos = %d, want %d", i, got, want)
+		}
+	}
+
+	for i := len(wantRight) - 2; i >= 0; i-- {
+		c.MoveLeft()
+		if got := c.ByteOffset(); got != wantRight[i] {
+			t.Fatalf("MoveLeft back to step %d: pos = %d, want %d", i, got, wantRight[i])
+		}
+	}
+	c.MoveLeft()
+	if got := c.ByteOffset(); got != 0 {
+		t.Errorf("MoveLeft to start: pos = %d, want 0", got)
+	}
+}
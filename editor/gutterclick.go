@@ -0,0 +1,46 @@
+package editor
+
+import "github.com/cornish/textivus-editor/ui"
+
+// inLineNumbers reports whether screen column x falls in the line number
+// column.
+func (e *Editor) inLineNumbers(x int) bool {
+	index, _, ok := e.compositor.ColumnAtX(x)
+	return ok && e.compositor.GetColumns()[index].Name == ui.ColumnLineNumbers
+}
+
+// gutterRowLine returns the buffer line whose number is on viewport row y,
+// counting the continuation rows of a wrapped line as that line. ok is
+// false for rows past the end of the document.
+func (e *Editor) gutterRowLine(y int) (line int, ok bool) {
+	state := &ui.RenderState{
+		Lines:     e.activeDoc().buffer.Lines(),
+		ScrollY:   e.viewport.ScrollY(),
+		WordWrap:  e.viewport.WordWrap(),
+		TabWidth:  e.editorSettings().TabWidth,
+		TextWidth: e.compositor.FlexibleColumnWidth(),
+	}
+	return e.lineNumRenderer.LineAtRow(y, state)
+}
+
+// selectLines selects whole lines from anchor through line, line breaks
+// included, leaving the cursor on line's side of the selection.
+func (e *Editor) selectLines(anchor, line int) {
+	doc := e.activeDoc()
+	lines := doc.buffer.Lines()
+	start := func(i int) int { return doc.buffer.LineColToPosition(i, 0) }
+	end := func(i int) int {
+		if i+1 < len(lines) {
+			return start(i + 1)
+		}
+		return doc.buffer.LineColToPosition(i, len(lines[i]))
+	}
+
+	doc.selection.Active = true
+	if line >= anchor {
+		doc.selection.Anchor, doc.selection.Cursor = start(anchor), end(line)
+	} else {
+		doc.selection.Anchor, doc.selection.Cursor = end(anchor), start(line)
+	}
+	doc.cursor.SetByteOffset(doc.selection.Cursor)
+}
@@ -0,0 +1,63 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLineNumberClickSelectsLines(t *testing.T) {
+	tests := []struct {
+		name       string
+		wrap       bool
+		press      int // Viewport row pressed in the line numbers
+		drag       int // Row dragged to (-1 = no drag)
+		wantStart  Position
+		wantEnd    Position
+		wantCursor Position
+	}{
+		{"click selects the line", false, 1, -1, Position{1, 0}, Position{2, 0}, Position{2, 0}},
+		{"drag down extends by lines", false, 1, 2, Position{1, 0}, Position{3, 0}, Position{3, 0}},
+		{"drag up keeps the pressed line", false, 2, 0, Position{0, 0}, Position{3, 0}, Position{0, 0}},
+		{"drag past the end takes the last line", false, 2, 6, Position{2, 0}, Position{3, 4}, Position{3, 4}},
+		// Line 1 wraps onto 3 rows in a 10-column text area
+		{"wrapped continuation row selects its line", true, 3, -1, Position{1, 0}, Position{2, 0}, Position{2, 0}},
+		{"wrapped drag", true, 4, 1, Position{1, 0}, Position{3, 0}, Position{1, 0}},
+	}
+
+	for _, tt := range tests {
+		e := New()
+		e.OnResize(16, 12)
+		e.insertText("one\n" + "xxxxxxxxxxxxxxxxxxxxxxxxx\n" + "two\n" + "last")
+		e.viewport.ShowLineNumbers(true)
+		e.viewport.SetWordWrap(tt.wrap)
+		e.setupCompositorColumns()
+		e.viewport.SetScrollY(0)
+
+		// Row 0 of the viewport is screen row 1, below the menu bar
+		e.handleMouse(tea.MouseMsg{X: 1, Y: tt.press + 1, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+		release := tt.press
+		if tt.drag >= 0 {
+			e.handleMouse(tea.MouseMsg{X: 1, Y: tt.drag + 1, Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion})
+			release = tt.drag
+		}
+		e.handleMouse(tea.MouseMsg{X: 1, Y: release + 1, Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease})
+
+		doc := e.activeDoc()
+		if !doc.selection.Active {
+			t.Errorf("%s: no selection", tt.name)
+			continue
+		}
+		start, end := doc.selection.Normalize()
+		pos := func(offset int) Position {
+			line, col := doc.buffer.PositionToLineCol(offset)
+			return Position{line, col}
+		}
+		if pos(start) != tt.wantStart || pos(end) != tt.wantEnd {
+			t.Errorf("%s: selection %v-%v, want %v-%v", tt.name, pos(start), pos(end), tt.wantStart, tt.wantEnd)
+		}
+		if got := (Position{doc.cursor.Line(), doc.cursor.Col()}); got != tt.wantCursor {
+			t.Errorf("%s: cursor %v, want %v", tt.name, got, tt.wantCursor)
+		}
+	}
+}
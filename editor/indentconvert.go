@@ -0,0 +1,134 @@
+package editor
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ExpandTabsToSpaces replaces every tab with the spaces up to its next tab
+// stop, so the lines look the same but hold no tabs. The input slice is not
+// modified.
+func ExpandTabsToSpaces(lines []string, tabWidth int) []string {
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if !strings.Contains(line, "\t") {
+			out[i] = line
+			continue
+		}
+		var sb strings.Builder
+		col := 0
+		for _, r := range line {
+			if r == '\t' {
+				n := tabWidth - col%tabWidth
+				sb.WriteString(strings.Repeat(" ", n))
+				col += n
+				continue
+			}
+			sb.WriteRune(r)
+			col += runewidth.RuneWidth(r)
+		}
+		out[i] = sb.String()
+	}
+	return out
+}
+
+// ExpandLeadingTabs rewrites the indentation of each line as spaces. Only
+// the leading whitespace changes; tabs after the first other character are
+// left alone. The input slice is not modified.
+func ExpandLeadingTabs(lines []string, tabWidth int) []string {
+	return reindent(lines, tabWidth, func(width int) string {
+		return strings.Repeat(" ", width)
+	})
+}
+
+// UnexpandLeadingSpaces rewrites the indentation of each line as tabs, with
+// any remainder narrower than a tab kept as spaces. Only the leading
+// whitespace changes; tabs and spaces after the first other character are
+// left alone. The input slice is not modified.
+func UnexpandLeadingSpaces(lines []string, tabWidth int) []string {
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	return reindent(lines, tabWidth, func(width int) string {
+		return strings.Repeat("\t", width/tabWidth) + strings.Repeat(" ", width%tabWidth)
+	})
+}
+
+// reindent replaces the leading whitespace of each line with indent(width),
+// where width is the columns the old indentation spanned.
+func reindent(lines []string, tabWidth int, indent func(width int) string) []string {
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		old := leadingWhitespace(line)
+		out[i] = indent(indentColumns(old, tabWidth)) + line[len(old):]
+	}
+	return out
+}
+
+// indentColumns returns the columns spanned by indent, which holds only
+// tabs and spaces.
+func indentColumns(indent string, tabWidth int) int {
+	width := 0
+	for _, r := range indent {
+		if r == '\t' {
+			width += tabWidth - width%tabWidth
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// reindentedCol returns the byte column in newLine matching col in oldLine,
+// when the two differ only in their indentation: the same column of text
+// past the indentation, or the nearest column within it.
+func reindentedCol(oldLine, newLine string, col, tabWidth int) int {
+	oldIndent, newIndent := leadingWhitespace(oldLine), leadingWhitespace(newLine)
+	if col >= len(oldIndent) {
+		return col - len(oldIndent) + len(newIndent)
+	}
+	want := indentColumns(oldIndent[:col], tabWidth)
+	for i := range newIndent {
+		if indentColumns(newIndent[:i], tabWidth) >= want {
+			return i
+		}
+	}
+	return len(newIndent)
+}
+
+// convertIndentation switches the indentation of the whole document to
+// match the tabs-to-spaces setting: indenting tabs are expanded to spaces
+// when it is on, and leading spaces become tabs when it is off. The cursor
+// keeps its place in the text.
+func (e *Editor) convertIndentation() {
+	if !e.checkEditable() {
+		return
+	}
+	doc := e.activeDoc()
+	settings := e.editorSettings()
+	lines := doc.buffer.Lines()
+
+	converted, to := UnexpandLeadingSpaces(lines, settings.TabWidth), "tabs"
+	if settings.TabsToSpaces {
+		converted, to = ExpandLeadingTabs(lines, settings.TabWidth), "spaces"
+	}
+	if strings.Join(converted, "\n") == strings.Join(lines, "\n") {
+		e.statusbar.SetMessage("Indentation already uses "+to, "info")
+		return
+	}
+
+	line := doc.cursor.Line()
+	col := reindentedCol(lines[line], converted[line], doc.cursor.Col(), settings.TabWidth)
+	e.replaceLines(0, len(lines)-1, converted)
+	doc.cursor.SetPosition(line, col)
+	doc.selection.Clear()
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	e.statusbar.SetMessage("Converted indentation to "+to, "info")
+}
@@ -0,0 +1,129 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandTabsToSpaces(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"leading tabs", "\t\tx", "        x"},
+		{"tab after text stops at the next stop", "ab\tc", "ab  c"},
+		{"mixed indentation", "  \tx", "    x"},
+		{"wide characters count two columns", "日\tx", "日  x"},
+		{"no tabs", "plain", "plain"},
+	}
+
+	for _, tt := range tests {
+		got := ExpandTabsToSpaces([]string{tt.line}, 4)
+		if got[0] != tt.want {
+			t.Errorf("%s: ExpandTabsToSpaces(%q) = %q, want %q", tt.name, tt.line, got[0], tt.want)
+		}
+	}
+}
+
+func TestExpandLeadingTabs(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"leading tabs", "\t\tx", "        x"},
+		{"mixed indentation", "  \tx", "    x"},
+		{"mid-line tab left alone", "\ta\tb", "    a\tb"},
+		{"no indentation", "x\ty", "x\ty"},
+	}
+
+	for _, tt := range tests {
+		got := ExpandLeadingTabs([]string{tt.line}, 4)
+		if got[0] != tt.want {
+			t.Errorf("%s: ExpandLeadingTabs(%q) = %q, want %q", tt.name, tt.line, got[0], tt.want)
+		}
+	}
+}
+
+func TestConvertIndentationKeepsCursor(t *testing.T) {
+	e := New()
+	e.config.Editor.TabWidth = 4
+	e.config.Editor.TabsToSpaces = true
+	e.insertText("x\n\t\treturn a\t// c")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(1, 4) // On the t of return
+
+	e.convertIndentation()
+	if got, want := doc.buffer.String(), "x\n        return a\t// c"; got != want {
+		t.Fatalf("converted to spaces = %q, want %q", got, want)
+	}
+	if line, col := doc.cursor.Line(), doc.cursor.Col(); line != 1 || col != 10 {
+		t.Errorf("cursor at %d:%d after converting to spaces, want 1:10", line, col)
+	}
+
+	e.config.Editor.TabsToSpaces = false
+	doc.cursor.SetPosition(1, 5) // Inside the indentation, one column into the second level
+	e.convertIndentation()
+	if got, want := doc.buffer.String(), "x\n\t\treturn a\t// c"; got != want {
+		t.Fatalf("converted to tabs = %q, want %q", got, want)
+	}
+	if line, col := doc.cursor.Line(), doc.cursor.Col(); line != 1 || col != 2 {
+		t.Errorf("cursor at %d:%d after converting to tabs, want 1:2", line, col)
+	}
+}
+
+func TestUnexpandLeadingSpaces(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"whole tabs", "        x", "\t\tx"},
+		{"remainder stays spaces", "      x", "\t  x"},
+		{"mixed indentation", "  \t  x", "\t  x"},
+		{"mid-line spaces left alone", "    a    b\tc", "\ta    b\tc"},
+		{"no indentation", "x    y", "x    y"},
+		{"whitespace-only line", "     ", "\t "},
+	}
+
+	for _, tt := range tests {
+		got := UnexpandLeadingSpaces([]string{tt.line}, 4)
+		if got[0] != tt.want {
+			t.Errorf("%s: UnexpandLeadingSpaces(%q) = %q, want %q", tt.name, tt.line, got[0], tt.want)
+		}
+	}
+}
+
+func TestIndentConversionRoundTrip(t *testing.T) {
+	tabbed := []string{
+		"func f() {",
+		"\tif x {",
+		"\t\treturn a\t// tab before comment",
+		"\t}",
+		"",
+	}
+
+	spaced := ExpandTabsToSpaces(tabbed, 4)
+	for i, line := range spaced {
+		if strings.Contains(line, "\t") {
+			t.Errorf("expanded line %d still has a tab: %q", i, line)
+		}
+	}
+
+	// Unexpanding restores the indentation; the mid-line tab stays expanded
+	back := UnexpandLeadingSpaces(spaced, 4)
+	want := []string{
+		"func f() {",
+		"\tif x {",
+		"\t\treturn a    // tab before comment",
+		"\t}",
+		"",
+	}
+	if strings.Join(back, "\n") != strings.Join(want, "\n") {
+		t.Errorf("round trip = %q, want %q", back, want)
+	}
+	if again := ExpandTabsToSpaces(back, 4); strings.Join(again, "\n") != strings.Join(spaced, "\n") {
+		t.Errorf("expanding again = %q, want %q", again, spaced)
+	}
+}
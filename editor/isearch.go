@@ -0,0 +1,186 @@
+package editor
+
+import "unicode/utf8"
+
+// ISearch is the state of an incremental search: the query typed so far,
+// the match it currently lands on and the direction of travel. Every change
+// searches again from the current match, wrapping around the document, and
+// the position the search started from is kept so cancelling can go back
+// to it. Columns are rune indices.
+type ISearch struct {
+	lines         []string
+	origin        Position
+	caseSensitive bool
+
+	query   []rune
+	match   Range
+	found   bool
+	forward bool
+
+	// history holds the match before each AddRune so Backspace can return
+	// to it
+	history []isearchStep
+}
+
+// isearchStep is the match state saved before a query character was added.
+type isearchStep struct {
+	match Range
+	found bool
+}
+
+// NewISearch starts an incremental search over lines from origin.
+func NewISearch(lines []string, origin Position, caseSensitive bool) *ISearch {
+	return &ISearch{
+		lines:         lines,
+		origin:        origin,
+		caseSensitive: caseSensitive,
+		match:         Range{Start: origin, End: origin},
+		forward:       true,
+	}
+}
+
+// Query returns the query typed so far.
+func (s *ISearch) Query() string {
+	return string(s.query)
+}
+
+// Forward reports whether the last search went forward.
+func (s *ISearch) Forward() bool {
+	return s.forward
+}
+
+// Match returns the range of the current match. ok is false when the query
+// has no match, so the UI can show the search as failing; the range is then
+// the last successful match (or the origin).
+func (s *ISearch) Match() (r Range, ok bool) {
+	return s.match, s.found
+}
+
+// Origin returns the position the search started from, where the cursor
+// goes back to when the search is cancelled.
+func (s *ISearch) Origin() Position {
+	return s.origin
+}
+
+// AddRune extends the query by r. The current match is kept if it still
+// matches; otherwise the search moves on in the current direction.
+func (s *ISearch) AddRune(r rune) {
+	s.history = append(s.history, isearchStep{match: s.match, found: s.found})
+	s.query = append(s.query, r)
+	s.search(s.match.Start, true)
+}
+
+// Backspace removes the last character of the query and returns to the
+// match that was current before it was typed.
+func (s *ISearch) Backspace() {
+	if len(s.query) == 0 {
+		return
+	}
+	s.query = s.query[:len(s.query)-1]
+	last := s.history[len(s.history)-1]
+	s.history = s.history[:len(s.history)-1]
+	s.match, s.found = last.match, last.found
+}
+
+// Next moves to the following match, wrapping past the end of the document.
+func (s *ISearch) Next() {
+	s.forward = true
+	s.search(s.match.Start, false)
+}
+
+// Prev moves to the preceding match, wrapping past the start of the
+// document.
+func (s *ISearch) Prev() {
+	s.forward = false
+	s.search(s.match.Start, false)
+}
+
+// search looks for the query from from in the current direction. When
+// inclusive, a match starting exactly at from counts.
+func (s *ISearch) search(from Position, inclusive bool) {
+	if len(s.query) == 0 {
+		s.match, s.found = Range{Start: s.origin, End: s.origin}, false
+		return
+	}
+	query := string(s.query)
+	var line, col int
+	var ok bool
+	switch {
+	case s.forward && inclusive:
+		line, col, ok = FindNext(s.lines, from.Line, from.Col, query, s.caseSensitive)
+	case s.forward:
+		line, col, ok = FindNext(s.lines, from.Line, from.Col+1, query, s.caseSensitive)
+	case inclusive:
+		line, col, ok = FindPrev(s.lines, from.Line, from.Col+1, query, s.caseSensitive)
+	default:
+		line, col, ok = FindPrev(s.lines, from.Line, from.Col, query, s.caseSensitive)
+	}
+	s.found = ok
+	if ok {
+		start := Position{Line: line, Col: col}
+		s.match = Range{Start: start, End: Position{Line: line, Col: col + utf8.RuneCountInString(query)}}
+	}
+}
+
+// isearchType adds typed characters to the find query, starting an
+// incremental search from the cursor on the first one, and jumps to the
+// match.
+func (e *Editor) isearchType(runes []rune) {
+	doc := e.activeDoc()
+	if e.isearch == nil {
+		lines := doc.buffer.Lines()
+		line := doc.cursor.Line()
+		col := utf8.RuneCountInString(lines[line][:doc.cursor.Col()])
+		e.isearch = NewISearch(lines, Position{Line: line, Col: col}, true)
+		for _, r := range e.findQuery {
+			e.isearch.AddRune(r)
+		}
+	}
+	for _, r := range runes {
+		e.isearch.AddRune(r)
+	}
+	e.findQuery = e.isearch.Query()
+	e.showISearchMatch()
+}
+
+// cancelISearch ends the incremental search, putting the cursor back where
+// it started and dropping the match's selection. The query is kept, so
+// find next still goes to the match.
+func (e *Editor) cancelISearch() {
+	doc := e.activeDoc()
+	lines := doc.buffer.Lines()
+	origin := e.isearch.Origin()
+	e.isearch = nil
+	doc.selection.Clear()
+	if origin.Line < len(lines) {
+		doc.cursor.SetByteOffset(doc.buffer.LineColToPosition(origin.Line, runeColToByte(lines[origin.Line], origin.Col)))
+	}
+	e.viewport.EnsureCursorVisibleWrapped(lines, doc.cursor.Line(), doc.cursor.Col())
+}
+
+// showISearchMatch selects the incremental search's current match. With an
+// empty query the cursor goes back to where the search started.
+func (e *Editor) showISearchMatch() {
+	doc := e.activeDoc()
+	lines := doc.buffer.Lines()
+	toOffset := func(p Position) int {
+		return doc.buffer.LineColToPosition(p.Line, runeColToByte(lines[p.Line], p.Col))
+	}
+
+	r, ok := e.isearch.Match()
+	switch {
+	case e.isearch.Query() == "":
+		doc.selection.Clear()
+		doc.cursor.SetByteOffset(toOffset(e.isearch.Origin()))
+	case !ok:
+		e.statusbar.SetMessage("Not found", "error")
+		return
+	default:
+		start, end := toOffset(r.Start), toOffset(r.End)
+		doc.cursor.SetByteOffset(start)
+		doc.selection.Active = true
+		doc.selection.Anchor = start
+		doc.selection.Cursor = end
+	}
+	e.viewport.EnsureCursorVisibleWrapped(lines, doc.cursor.Line(), doc.cursor.Col())
+}
@@ -0,0 +1,111 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestISearch(t *testing.T) {
+	lines := []string{
+		"alpha beta",
+		"gamma alps",
+		"日本 alpha",
+	}
+
+	type step struct {
+		action  func(s *ISearch)
+		query   string
+		want    Range
+		wantOK  bool
+		comment string
+	}
+	typeRune := func(r rune) func(s *ISearch) { return func(s *ISearch) { s.AddRune(r) } }
+	rng := func(line, start, end int) Range {
+		return Range{Start: Position{line, start}, End: Position{line, end}}
+	}
+
+	steps := []step{
+		{typeRune('a'), "a", rng(0, 4, 5), true, "first match after the origin"},
+		{typeRune('l'), "al", rng(1, 6, 8), true, "moves on when the match no longer fits"},
+		{typeRune('p'), "alp", rng(1, 6, 9), true, "keeps a match that still fits"},
+		{(*ISearch).Next, "alp", rng(2, 3, 6), true, "next"},
+		{(*ISearch).Next, "alp", rng(0, 0, 3), true, "next wraps past the end"},
+		{(*ISearch).Prev, "alp", rng(2, 3, 6), true, "prev wraps past the start"},
+		{typeRune('z'), "alpz", rng(2, 3, 6), false, "failing search keeps the last match"},
+		{(*ISearch).Backspace, "alp", rng(2, 3, 6), true, "backspace recovers"},
+		{(*ISearch).Backspace, "al", rng(1, 6, 8), true, "backspace returns to the earlier match"},
+	}
+
+	s := NewISearch(lines, Position{Line: 0, Col: 2}, true)
+	for _, st := range steps {
+		st.action(s)
+		got, ok := s.Match()
+		if s.Query() != st.query || got != st.want || ok != st.wantOK {
+			t.Fatalf("%s: query %q match %v ok %v; want %q %v %v",
+				st.comment, s.Query(), got, ok, st.query, st.want, st.wantOK)
+		}
+	}
+	if s.Origin() != (Position{Line: 0, Col: 2}) {
+		t.Errorf("Origin() = %v, want the starting position", s.Origin())
+	}
+}
+
+func TestFindAsYouType(t *testing.T) {
+	e := New()
+	e.insertText("one two\nthree two")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(0, 1)
+	e.mode = ModeFind
+	e.findActive = true
+
+	for _, r := range "two" {
+		e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if doc.cursor.Line() != 0 || doc.cursor.Col() != 4 || doc.selection.GetText(doc.buffer) != "two" {
+		t.Fatalf("after typing: cursor %d:%d selection %q, want the first \"two\"",
+			doc.cursor.Line(), doc.cursor.Col(), doc.selection.GetText(doc.buffer))
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if doc.cursor.Line() != 1 || doc.cursor.Col() != 6 {
+		t.Errorf("after enter: cursor %d:%d, want 1:6", doc.cursor.Line(), doc.cursor.Col())
+	}
+
+	// Erasing the query puts the cursor back where the search started
+	for range "two" {
+		e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	if doc.cursor.Line() != 0 || doc.cursor.Col() != 1 || doc.selection.Active {
+		t.Errorf("after erasing the query: cursor %d:%d selection %v, want 0:1 and none",
+			doc.cursor.Line(), doc.cursor.Col(), doc.selection.Active)
+	}
+}
+
+func TestFindAsYouTypeCancelRestoresCursor(t *testing.T) {
+	e := New()
+	e.insertText("one two\nthree two")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(0, 1)
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if e.mode != ModeFind {
+		t.Fatalf("mode = %d after Ctrl+F, want find", e.mode)
+	}
+
+	for _, r := range "two" {
+		e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if doc.cursor.Line() != 1 {
+		t.Fatalf("after enter: cursor on line %d, want the second match on line 1", doc.cursor.Line())
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if e.mode != ModeNormal || e.isearch != nil {
+		t.Errorf("after Esc: mode %d, isearch %v; want normal mode and no search", e.mode, e.isearch)
+	}
+	if doc.cursor.Line() != 0 || doc.cursor.Col() != 1 || doc.selection.Active {
+		t.Errorf("after Esc: cursor %d:%d selection %v, want 0:1 and none",
+			doc.cursor.Line(), doc.cursor.Col(), doc.selection.Active)
+	}
+}
@@ -0,0 +1,394 @@
+package editor
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MoveLinesUp moves the block of lines [start, end] (inclusive) up by one
+// line, returning the new lines and the block's new first line. A block
+// already at the top is returned unchanged. The input slice is not modified.
+func MoveLinesUp(lines []string, start, end int) ([]string, int) {
+	out := make([]string, len(lines))
+	copy(out, lines)
+	if start <= 0 || start > end || end >= len(lines) {
+		return out, start
+	}
+	above := out[start-1]
+	copy(out[start-1:end], lines[start:end+1])
+	out[end] = above
+	return out, start - 1
+}
+
+// MoveLinesDown moves the block of lines [start, end] (inclusive) down by
+// one line, returning the new lines and the block's new first line. A block
+// already at the bottom is returned unchanged. The input slice is not
+// modified.
+func MoveLinesDown(lines []string, start, end int) ([]string, int) {
+	out := make([]string, len(lines))
+	copy(out, lines)
+	if start < 0 || start > end || end >= len(lines)-1 {
+		return out, start
+	}
+	below := out[end+1]
+	copy(out[start+1:end+2], lines[start:end+1])
+	out[start] = below
+	return out, start + 1
+}
+
+// selectedLines returns the first and last line touched by the selection,
+// or the cursor line when nothing is selected. A selection ending at the
+// start of a line does not include that line.
+func (e *Editor) selectedLines() (first, last int) {
+	doc := e.activeDoc()
+	if !doc.selection.Active || doc.selection.IsEmpty() {
+		line := doc.cursor.Line()
+		return line, line
+	}
+	start, end := doc.selection.Normalize()
+	first, _ = doc.buffer.PositionToLineCol(start)
+	last, endCol := doc.buffer.PositionToLineCol(end)
+	if endCol == 0 && last > first {
+		last--
+	}
+	return first, last
+}
+
+// replaceLines replaces lines [first, last] of the active document with
+// newLines as one undoable edit. The cursor is left at the start of first.
+func (e *Editor) replaceLines(first, last int, newLines []string) {
+	doc := e.activeDoc()
+	start := doc.buffer.LineStartOffset(first)
+	end := doc.buffer.LineEndOffset(last)
+	before := doc.cursor.ByteOffset()
+	deleted := doc.buffer.Substring(start, end)
+	inserted := strings.Join(newLines, "\n")
+	if deleted == inserted {
+		return
+	}
+
+	doc.buffer.Replace(start, end, inserted)
+	doc.cursor.SetByteOffset(start)
+	doc.undoStack.Push(&UndoEntry{
+		Position:     start,
+		Deleted:      deleted,
+		Inserted:     inserted,
+		CursorBefore: before,
+		CursorAfter:  start,
+	})
+	doc.modified = true
+}
+
+// moveLines moves the selected lines, or the cursor line, up or down by one
+// line. The cursor and selection move with them.
+func (e *Editor) moveLines(up bool) {
+	if !e.checkEditable() {
+		return
+	}
+	doc := e.activeDoc()
+	first, last := e.selectedLines()
+	lines := doc.buffer.Lines()
+	move := MoveLinesDown
+	if up {
+		move = MoveLinesUp
+	}
+	moved, newFirst := move(lines, first, last)
+	delta := newFirst - first
+	if delta == 0 {
+		return
+	}
+
+	// Remember the cursor and selection as line/col so they can follow
+	cursorLine, cursorCol := doc.cursor.Line(), doc.cursor.Col()
+	sel := *doc.selection
+	anchorLine, anchorCol := doc.buffer.PositionToLineCol(sel.Anchor)
+	selLine, selCol := doc.buffer.PositionToLineCol(sel.Cursor)
+
+	lo, hi := min(first, newFirst), max(last, last+delta)
+	e.replaceLines(lo, hi, moved[lo:hi+1])
+
+	doc.cursor.SetPosition(cursorLine+delta, cursorCol)
+	if sel.Active {
+		doc.selection.Anchor = doc.buffer.LineColToPosition(anchorLine+delta, anchorCol)
+		doc.selection.Cursor = doc.buffer.LineColToPosition(selLine+delta, selCol)
+	}
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
+
+// DuplicateLines inserts a copy of the block of lines [start, end]
+// (inclusive) directly below it and returns the new lines. The input slice
+// is not modified.
+func DuplicateLines(lines []string, start, end int) []string {
+	if start < 0 || start > end || end >= len(lines) {
+		out := make([]string, len(lines))
+		copy(out, lines)
+		return out
+	}
+	out := make([]string, 0, len(lines)+end-start+1)
+	out = append(out, lines[:end+1]...)
+	out = append(out, lines[start:end+1]...)
+	return append(out, lines[end+1:]...)
+}
+
+// DuplicateSelection inserts a copy of the text in r directly after it and
+// returns the new lines along with the range of the copy, so it can be
+// selected. An empty range duplicates the lines it is on instead, and the
+// returned range is the copied lines. Columns are rune indices.
+func DuplicateSelection(lines []string, r Range) ([]string, Range) {
+	if positionLess(r.End, r.Start) {
+		r.Start, r.End = r.End, r.Start
+	}
+	if r.Start == r.End {
+		out := DuplicateLines(lines, r.Start.Line, r.Start.Line)
+		copied := r.Start.Line + 1
+		return out, Range{
+			Start: Position{Line: copied},
+			End:   Position{Line: copied, Col: utf8.RuneCountInString(out[copied])},
+		}
+	}
+
+	store := &SliceLineStore{lines: append([]string(nil), lines...)}
+	text := rangeText(lines, r)
+	store.Insert(r.End, text)
+	return store.lines, Range{Start: r.End, End: insertEnd(r.End, text)}
+}
+
+// rangeText returns the text between r.Start and r.End, which must be in
+// order.
+func rangeText(lines []string, r Range) string {
+	first := []rune(lines[r.Start.Line])
+	if r.Start.Line == r.End.Line {
+		return string(first[r.Start.Col:r.End.Col])
+	}
+	parts := []string{string(first[r.Start.Col:])}
+	parts = append(parts, lines[r.Start.Line+1:r.End.Line]...)
+	parts = append(parts, string([]rune(lines[r.End.Line])[:r.End.Col]))
+	return strings.Join(parts, "\n")
+}
+
+// duplicate copies the selection to just after it and selects the copy, or
+// copies the cursor line below itself when nothing is selected. The cursor
+// keeps its column on the new line.
+func (e *Editor) duplicate() {
+	if !e.checkEditable() {
+		return
+	}
+	doc := e.activeDoc()
+	if !doc.selection.Active || doc.selection.IsEmpty() {
+		line, col := doc.cursor.Line(), doc.cursor.Col()
+		lines := doc.buffer.Lines()
+		e.replaceLines(line, line, DuplicateLines(lines, line, line)[line:line+2])
+		doc.cursor.SetPosition(line+1, col)
+		doc.selection.Clear()
+		e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+		return
+	}
+
+	start, end := doc.selection.Normalize()
+	text := doc.buffer.Substring(start, end)
+	doc.buffer.Replace(end, end, text)
+	doc.undoStack.BreakMerge() // A one-character copy is not more typing
+	doc.undoStack.Push(&UndoEntry{
+		Position:     end,
+		Inserted:     text,
+		CursorBefore: doc.cursor.ByteOffset(),
+		CursorAfter:  end + len(text),
+	})
+	doc.modified = true
+	doc.selection.Anchor = end
+	doc.selection.Cursor = end + len(text)
+	doc.cursor.SetByteOffset(end + len(text))
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
+
+// JoinLines merges the lines [start, end] (inclusive) into one. The
+// whitespace around each line break collapses to a single space, and no
+// space is added next to an empty line or before closing punctuation. The
+// returned cursorCol is the rune column of the last join, where Vim's J
+// leaves the cursor. A range of fewer than two lines is left unchanged.
+func JoinLines(lines []string, start, end int) (joined []string, cursorCol int) {
+	if start < 0 || start >= end || end >= len(lines) {
+		out := make([]string, len(lines))
+		copy(out, lines)
+		if start >= 0 && start < len(lines) {
+			cursorCol = utf8.RuneCountInString(lines[start])
+		}
+		return out, cursorCol
+	}
+
+	line := lines[start]
+	for _, next := range lines[start+1 : end+1] {
+		left := strings.TrimRight(line, " \t")
+		right := strings.TrimLeft(next, " \t")
+		cursorCol = utf8.RuneCountInString(left)
+		if left == "" || right == "" || strings.ContainsRune(joinNoSpaceBefore, []rune(right)[0]) {
+			line = left + right
+			continue
+		}
+		line = left + " " + right
+	}
+
+	joined = make([]string, 0, len(lines)-(end-start))
+	joined = append(joined, lines[:start]...)
+	joined = append(joined, line)
+	return append(joined, lines[end+1:]...), cursorCol
+}
+
+// joinNoSpaceBefore lists characters JoinLines does not put a space before.
+const joinNoSpaceBefore = ".,;:!?)]}"
+
+// joinLines joins the selected lines, or the cursor line and the one below
+// it when nothing is selected.
+func (e *Editor) joinLines() {
+	if !e.checkEditable() {
+		return
+	}
+	doc := e.activeDoc()
+	first, last := e.selectedLines()
+	if first == last {
+		last++
+	}
+	if last >= doc.buffer.LineCount() {
+		return
+	}
+	joined, col := JoinLines(doc.buffer.Lines(), first, last)
+	e.replaceLines(first, last, joined[first:first+1])
+	doc.selection.Clear()
+	doc.cursor.SetPosition(first, runeColToByte(joined[first], col))
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
+
+// SortOptions control how SortLines orders lines.
+type SortOptions struct {
+	CaseInsensitive bool // Compare letters ignoring case
+	Numeric         bool // Compare by the number each line starts with
+	Reverse         bool // Largest first
+}
+
+// SortLines sorts the lines [start, end] (inclusive), leaving the rest of
+// the document in place. With Numeric, lines are ordered by their leading
+// number so "10" sorts after "9"; lines without one come first, in text
+// order. Equal lines keep their order. The input slice is not modified.
+func SortLines(lines []string, start, end int, opts SortOptions) []string {
+	out := make([]string, len(lines))
+	copy(out, lines)
+	if start < 0 || start >= end || end >= len(lines) {
+		return out
+	}
+
+	key := func(s string) string {
+		if opts.CaseInsensitive {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+	less := func(a, b string) bool {
+		if opts.Numeric {
+			na, okA := leadingNumber(a)
+			nb, okB := leadingNumber(b)
+			if okA != okB {
+				return okB
+			}
+			if okA && na != nb {
+				return na < nb
+			}
+		}
+		return key(a) < key(b)
+	}
+
+	block := out[start : end+1]
+	sort.SliceStable(block, func(i, j int) bool {
+		if opts.Reverse {
+			return less(block[j], block[i])
+		}
+		return less(block[i], block[j])
+	})
+	return out
+}
+
+// leadingNumber parses the number at the start of s, after any leading
+// whitespace.
+func leadingNumber(s string) (float64, bool) {
+	s = strings.TrimLeft(s, " \t")
+	end := 0
+	if end < len(s) && (s[end] == '-' || s[end] == '+') {
+		end++
+	}
+	digits := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.') {
+		end++
+		digits++
+	}
+	if digits == 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(s[:end], 64)
+	return n, err == nil
+}
+
+// UniqueLines removes duplicate lines from [start, end] (inclusive), keeping
+// the first of each. By default only runs of adjacent duplicates collapse,
+// like uniq; with all set every repeat in the range is removed. The input
+// slice is not modified.
+func UniqueLines(lines []string, start, end int, all bool) []string {
+	if start < 0 || start >= end || end >= len(lines) {
+		out := make([]string, len(lines))
+		copy(out, lines)
+		return out
+	}
+
+	out := make([]string, 0, len(lines))
+	out = append(out, lines[:start]...)
+	seen := make(map[string]bool)
+	for i, line := range lines[start : end+1] {
+		if all && seen[line] || !all && i > 0 && line == lines[start+i-1] {
+			continue
+		}
+		seen[line] = true
+		out = append(out, line)
+	}
+	return append(out, lines[end+1:]...)
+}
+
+// lineBlock returns the lines sortLines and uniqueLines work on: the
+// selected lines, or the whole document when the selection (or just the
+// cursor) covers a single line.
+func (e *Editor) lineBlock() (first, last int) {
+	first, last = e.selectedLines()
+	if first == last {
+		return 0, e.activeDoc().buffer.LineCount() - 1
+	}
+	return first, last
+}
+
+// sortLines sorts the selected lines, or the whole document, as one
+// undoable edit.
+func (e *Editor) sortLines() {
+	if !e.checkEditable() {
+		return
+	}
+	doc := e.activeDoc()
+	first, last := e.lineBlock()
+	sorted := SortLines(doc.buffer.Lines(), first, last, SortOptions{})
+	e.replaceLines(first, last, sorted[first:last+1])
+	doc.selection.Clear()
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
+
+// uniqueLines removes every repeat of a line among the selected lines, or
+// the whole document, as one undoable edit.
+func (e *Editor) uniqueLines() {
+	if !e.checkEditable() {
+		return
+	}
+	doc := e.activeDoc()
+	first, last := e.lineBlock()
+	lines := doc.buffer.Lines()
+	unique := UniqueLines(lines, first, last, true)
+	removed := len(lines) - len(unique)
+	e.replaceLines(first, last, unique[first:last+1-removed])
+	doc.selection.Clear()
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
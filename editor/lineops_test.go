@@ -0,0 +1,312 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cornish/textivus-editor/config"
+	"github.com/cornish/textivus-editor/ui"
+)
+
+func TestMoveLines(t *testing.T) {
+	lines := []string{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		name      string
+		move      func([]string, int, int) ([]string, int)
+		start     int
+		end       int
+		want      string
+		wantStart int
+	}{
+		{"two lines up from the middle", MoveLinesUp, 2, 3, "a c d b e", 1},
+		{"two lines down from the middle", MoveLinesDown, 1, 2, "a d b c e", 2},
+		{"up at the top is a no-op", MoveLinesUp, 0, 1, "a b c d e", 0},
+		{"down at the bottom is a no-op", MoveLinesDown, 3, 4, "a b c d e", 3},
+		{"single line up", MoveLinesUp, 4, 4, "a b c e d", 3},
+		{"whole document", MoveLinesDown, 0, 4, "a b c d e", 0},
+	}
+
+	for _, tt := range tests {
+		got, start := tt.move(lines, tt.start, tt.end)
+		if strings.Join(got, " ") != tt.want || start != tt.wantStart {
+			t.Errorf("%s: got %q at %d, want %q at %d",
+				tt.name, strings.Join(got, " "), start, tt.want, tt.wantStart)
+		}
+	}
+	if strings.Join(lines, " ") != "a b c d e" {
+		t.Errorf("input modified: %q", lines)
+	}
+}
+
+func TestMoveLinesKeys(t *testing.T) {
+	e := New()
+	e.insertText("one\ntwo\nthree\nfour")
+	doc := e.activeDoc()
+
+	// Select "two" and "three" from the middle of "two" to the middle of "three"
+	doc.cursor.SetPosition(1, 1)
+	doc.selection.Start(doc.cursor.ByteOffset())
+	doc.cursor.SetPosition(2, 2)
+	doc.selection.Update(doc.cursor.ByteOffset())
+
+	e.Update(tea.KeyMsg{Type: tea.KeyUp, Alt: true})
+	if got := doc.buffer.String(); got != "two\nthree\none\nfour" {
+		t.Fatalf("after alt+up: %q", got)
+	}
+	if doc.cursor.Line() != 1 || doc.cursor.Col() != 2 {
+		t.Errorf("cursor at %d:%d, want 1:2", doc.cursor.Line(), doc.cursor.Col())
+	}
+	if got := doc.selection.GetText(doc.buffer); got != "wo\nth" {
+		t.Errorf("selection = %q, want %q", got, "wo\nth")
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyUp, Alt: true})
+	if got := doc.buffer.String(); got != "two\nthree\none\nfour" {
+		t.Errorf("alt+up at the top changed the text to %q", got)
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	if got := doc.buffer.String(); got != "one\ntwo\nthree\nfour" {
+		t.Errorf("after undo: %q", got)
+	}
+}
+
+func TestDuplicateLines(t *testing.T) {
+	lines := []string{"a", "b", "c"}
+
+	tests := []struct {
+		name  string
+		start int
+		end   int
+		want  string
+	}{
+		{"single line", 1, 1, "a b b c"},
+		{"multiple lines", 0, 1, "a b a b c"},
+		{"last line", 2, 2, "a b c c"},
+		{"whole document", 0, 2, "a b c a b c"},
+		{"out of range", 2, 5, "a b c"},
+	}
+
+	for _, tt := range tests {
+		got := DuplicateLines(lines, tt.start, tt.end)
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s: DuplicateLines(%d, %d) = %q, want %q", tt.name, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestDuplicateSelection(t *testing.T) {
+	lines := []string{"foo bar", "日本 baz"}
+
+	tests := []struct {
+		name      string
+		sel       Range
+		want      []string
+		wantRange Range
+	}{
+		{
+			"word",
+			Range{Position{0, 4}, Position{0, 7}},
+			[]string{"foo barbar", "日本 baz"},
+			Range{Position{0, 7}, Position{0, 10}},
+		},
+		{
+			"across lines, backwards",
+			Range{Position{1, 2}, Position{0, 4}},
+			[]string{"foo bar", "日本bar", "日本 baz"},
+			Range{Position{1, 2}, Position{2, 2}},
+		},
+		{
+			"empty selection duplicates the line",
+			Range{Position{1, 1}, Position{1, 1}},
+			[]string{"foo bar", "日本 baz", "日本 baz"},
+			Range{Position{2, 0}, Position{2, 6}},
+		},
+	}
+
+	for _, tt := range tests {
+		got, r := DuplicateSelection(lines, tt.sel)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") || r != tt.wantRange {
+			t.Errorf("%s: DuplicateSelection = %q, %v; want %q, %v", tt.name, got, r, tt.want, tt.wantRange)
+		}
+	}
+}
+
+func TestDuplicateKey(t *testing.T) {
+	e := New()
+	e.insertText("one\ntwo")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(1, 2)
+
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if got := doc.buffer.String(); got != "one\ntwo\ntwo" {
+		t.Fatalf("duplicating the last line: %q", got)
+	}
+	if doc.cursor.Line() != 2 || doc.cursor.Col() != 2 {
+		t.Errorf("cursor at %d:%d, want 2:2", doc.cursor.Line(), doc.cursor.Col())
+	}
+
+	// Select "ne" and duplicate it
+	doc.selection.Start(1)
+	doc.selection.Update(3)
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if got := doc.buffer.String(); got != "onene\ntwo\ntwo" {
+		t.Errorf("duplicating a selection: %q", got)
+	}
+	if got := doc.selection.GetText(doc.buffer); got != "ne" || doc.selection.StartPos() != 3 {
+		t.Errorf("selection = %q at %d, want the copy at 3", got, doc.selection.StartPos())
+	}
+}
+
+func TestJoinLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		start   int
+		end     int
+		want    []string
+		wantCol int
+	}{
+		{"two sentences", []string{"The end.", "A new start."}, 0, 1, []string{"The end. A new start."}, 8},
+		{"leading whitespace on the next line", []string{"if x {", "\t\treturn y", "}"}, 0, 1, []string{"if x { return y", "}"}, 6},
+		{"trailing whitespace collapses", []string{"a  ", "  b"}, 0, 1, []string{"a b"}, 1},
+		{"no space before punctuation", []string{"call(arg", ")", ";"}, 0, 2, []string{"call(arg);"}, 9},
+		{"no space before a comma", []string{"one", ", two"}, 0, 1, []string{"one, two"}, 3},
+		{"empty next line", []string{"text", "", "more"}, 0, 1, []string{"text", "more"}, 4},
+		{"empty first line", []string{"", "  text"}, 0, 1, []string{"text"}, 0},
+		{"three lines", []string{"a", "b", "c", "d"}, 1, 3, []string{"a", "b c d"}, 3},
+		{"single line unchanged", []string{"abc", "d"}, 0, 0, []string{"abc", "d"}, 3},
+	}
+
+	for _, tt := range tests {
+		got, col := JoinLines(tt.lines, tt.start, tt.end)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") || col != tt.wantCol {
+			t.Errorf("%s: JoinLines(%d, %d) = %q, col %d; want %q, col %d",
+				tt.name, tt.start, tt.end, got, col, tt.want, tt.wantCol)
+		}
+	}
+}
+
+func TestJoinLinesKey(t *testing.T) {
+	e := New()
+	e.insertText("first\n    second\nthird")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(0, 0)
+
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}, Alt: true})
+	if got := doc.buffer.String(); got != "first second\nthird" {
+		t.Fatalf("after alt+j: %q", got)
+	}
+	if doc.cursor.Line() != 0 || doc.cursor.Col() != 5 {
+		t.Errorf("cursor at %d:%d, want 0:5", doc.cursor.Line(), doc.cursor.Col())
+	}
+
+	doc.cursor.SetPosition(1, 0)
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}, Alt: true})
+	if got := doc.buffer.String(); got != "first second\nthird" {
+		t.Errorf("alt+j on the last line changed the text to %q", got)
+	}
+}
+
+func TestJoinLinesRebound(t *testing.T) {
+	e := New()
+	e.keybindings.SetBinding("join_lines", config.KeyBinding{Primary: "f7"})
+	e.insertText("first\nsecond")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(0, 0)
+
+	e.Update(tea.KeyMsg{Type: tea.KeyF7})
+	if got := doc.buffer.String(); got != "first second" {
+		t.Errorf("after f7: %q", got)
+	}
+}
+
+func TestSortLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		start int
+		end   int
+		opts  SortOptions
+		want  []string
+	}{
+		{"alphabetical", []string{"pear", "apple", "Banana", "fig"}, 0, 3, SortOptions{}, []string{"Banana", "apple", "fig", "pear"}},
+		{"case insensitive", []string{"pear", "apple", "Banana"}, 0, 2, SortOptions{CaseInsensitive: true}, []string{"apple", "Banana", "pear"}},
+		{"numeric", []string{"10 ten", "9 nine", "100", "-1", "x"}, 0, 4, SortOptions{Numeric: true}, []string{"x", "-1", "9 nine", "10 ten", "100"}},
+		{"text order puts 10 before 9", []string{"10", "9"}, 0, 1, SortOptions{}, []string{"10", "9"}},
+		{"reverse", []string{"b", "c", "a"}, 0, 2, SortOptions{Reverse: true}, []string{"c", "b", "a"}},
+		{"reverse numeric", []string{"2", "10", "1"}, 0, 2, SortOptions{Numeric: true, Reverse: true}, []string{"10", "2", "1"}},
+		{"only the block", []string{"z", "c", "b", "a"}, 1, 2, SortOptions{}, []string{"z", "b", "c", "a"}},
+	}
+
+	for _, tt := range tests {
+		got := SortLines(tt.lines, tt.start, tt.end, tt.opts)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: SortLines = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueLines(t *testing.T) {
+	lines := []string{"top", "a", "a", "b", "a", "b", "b", "top"}
+
+	tests := []struct {
+		name  string
+		start int
+		end   int
+		all   bool
+		want  []string
+	}{
+		{"adjacent", 1, 6, false, []string{"top", "a", "b", "a", "b", "top"}},
+		{"all", 1, 6, true, []string{"top", "a", "b", "top"}},
+		{"outside the block is untouched", 0, 3, true, []string{"top", "a", "b", "a", "b", "b", "top"}},
+	}
+
+	for _, tt := range tests {
+		got := UniqueLines(lines, tt.start, tt.end, tt.all)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: UniqueLines = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSortAndUniqueLinesActions(t *testing.T) {
+	e := New()
+	e.insertText("top\npear\napple\npear\nfig\nend")
+	doc := e.activeDoc()
+
+	// Select "pear" through "fig"
+	doc.cursor.SetPosition(1, 2)
+	doc.selection.Start(doc.cursor.ByteOffset())
+	doc.cursor.SetPosition(4, 1)
+	doc.selection.Update(doc.cursor.ByteOffset())
+
+	e.executeAction(ui.ActionSortLines)
+	if got := doc.buffer.String(); got != "top\napple\nfig\npear\npear\nend" {
+		t.Fatalf("after sorting: %q", got)
+	}
+
+	doc.cursor.SetPosition(1, 0)
+	doc.selection.Start(doc.cursor.ByteOffset())
+	doc.cursor.SetPosition(5, 0)
+	doc.selection.Update(doc.cursor.ByteOffset())
+	e.executeAction(ui.ActionUniqueLines)
+	if got := doc.buffer.String(); got != "top\napple\nfig\npear\nend" {
+		t.Fatalf("after removing repeats: %q", got)
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	if got := doc.buffer.String(); got != "top\npear\napple\npear\nfig\nend" {
+		t.Errorf("after undoing both: %q", got)
+	}
+
+	// Without a selection the whole document is sorted
+	doc.selection.Clear()
+	e.executeAction(ui.ActionSortLines)
+	if got := doc.buffer.String(); got != "apple\nend\nfig\npear\npear\ntop" {
+		t.Errorf("sorting without a selection: %q", got)
+	}
+}
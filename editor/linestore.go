@@ -0,0 +1,224 @@
+package editor
+
+import "strings"
+
+// LineStore is a line-oriented text backend. Columns are rune indices.
+// Renderers work on a []string snapshot obtained from Lines().
+type LineStore interface {
+	// LineCount returns the number of lines (always at least 1).
+	LineCount() int
+	// Line returns the text of line i without its trailing newline.
+	Line(i int) string
+	// Lines returns a snapshot of all lines.
+	Lines() []string
+	// Insert inserts text (which may contain newlines) at pos.
+	Insert(pos Position, text string)
+	// Delete removes the text in r, joining lines as needed.
+	Delete(r Range)
+	// String returns the full text joined with newlines.
+	String() string
+}
+
+// lineOps are the primitive line operations the shared editing logic is
+// built on.
+type lineOps interface {
+	LineCount() int
+	Line(i int) string
+	setLine(i int, s string)
+	insertLines(i int, lines []string)
+	deleteLines(i, n int)
+}
+
+// SliceLineStore is the straightforward []string backend. Inserting or
+// deleting lines shifts everything after them.
+type SliceLineStore struct {
+	lines []string
+}
+
+// NewSliceLineStore creates a slice-backed store holding text.
+func NewSliceLineStore(text string) *SliceLineStore {
+	return &SliceLineStore{lines: strings.Split(text, "\n")}
+}
+
+// LineCount returns the number of lines.
+func (s *SliceLineStore) LineCount() int { return len(s.lines) }
+
+// Line returns the text of line i.
+func (s *SliceLineStore) Line(i int) string { return s.lines[i] }
+
+// Lines returns a snapshot of all lines.
+func (s *SliceLineStore) Lines() []string {
+	out := make([]string, len(s.lines))
+	copy(out, s.lines)
+	return out
+}
+
+// Insert inserts text at pos.
+func (s *SliceLineStore) Insert(pos Position, text string) { insertText(s, pos, text) }
+
+// Delete removes the text in r.
+func (s *SliceLineStore) Delete(r Range) { deleteRange(s, r) }
+
+// String returns the full text.
+func (s *SliceLineStore) String() string { return strings.Join(s.lines, "\n") }
+
+func (s *SliceLineStore) setLine(i int, line string) { s.lines[i] = line }
+
+func (s *SliceLineStore) insertLines(i int, lines []string) {
+	s.lines = append(s.lines[:i], append(lines, s.lines[i:]...)...)
+}
+
+func (s *SliceLineStore) deleteLines(i, n int) {
+	s.lines = append(s.lines[:i], s.lines[i+n:]...)
+}
+
+// GapLineStore keeps lines in a gap buffer. Edits clustered around the same
+// place - the common case while typing - insert and delete lines in amortized
+// constant time, and line access is always constant time.
+type GapLineStore struct {
+	buf      []string
+	gapStart int
+	gapEnd   int
+}
+
+// NewGapLineStore creates a gap-buffer-backed store holding text.
+func NewGapLineStore(text string) *GapLineStore {
+	lines := strings.Split(text, "\n")
+	g := &GapLineStore{
+		buf:      make([]string, len(lines)+initialGapSize),
+		gapStart: len(lines),
+		gapEnd:   len(lines) + initialGapSize,
+	}
+	copy(g.buf, lines)
+	return g
+}
+
+// LineCount returns the number of lines.
+func (g *GapLineStore) LineCount() int {
+	return len(g.buf) - (g.gapEnd - g.gapStart)
+}
+
+// Line returns the text of line i.
+func (g *GapLineStore) Line(i int) string {
+	if i >= g.gapStart {
+		i += g.gapEnd - g.gapStart
+	}
+	return g.buf[i]
+}
+
+// Lines returns a snapshot of all lines.
+func (g *GapLineStore) Lines() []string {
+	out := make([]string, 0, g.LineCount())
+	out = append(out, g.buf[:g.gapStart]...)
+	return append(out, g.buf[g.gapEnd:]...)
+}
+
+// Insert inserts text at pos.
+func (g *GapLineStore) Insert(pos Position, text string) { insertText(g, pos, text) }
+
+// Delete removes the text in r.
+func (g *GapLineStore) Delete(r Range) { deleteRange(g, r) }
+
+// String returns the full text.
+func (g *GapLineStore) String() string { return strings.Join(g.Lines(), "\n") }
+
+func (g *GapLineStore) setLine(i int, line string) {
+	if i >= g.gapStart {
+		i += g.gapEnd - g.gapStart
+	}
+	g.buf[i] = line
+}
+
+// moveGap moves the gap so that it starts at line index i.
+func (g *GapLineStore) moveGap(i int) {
+	if i < g.gapStart {
+		n := g.gapStart - i
+		copy(g.buf[g.gapEnd-n:g.gapEnd], g.buf[i:g.gapStart])
+		g.gapStart -= n
+		g.gapEnd -= n
+	} else if i > g.gapStart {
+		n := i - g.gapStart
+		copy(g.buf[g.gapStart:g.gapStart+n], g.buf[g.gapEnd:g.gapEnd+n])
+		g.gapStart += n
+		g.gapEnd += n
+	}
+}
+
+// growGap ensures the gap can hold at least n more lines.
+func (g *GapLineStore) growGap(n int) {
+	if g.gapEnd-g.gapStart >= n {
+		return
+	}
+	newGap := n + len(g.buf)/2 + initialGapSize
+	newBuf := make([]string, len(g.buf)+newGap-(g.gapEnd-g.gapStart))
+	copy(newBuf, g.buf[:g.gapStart])
+	tail := len(g.buf) - g.gapEnd
+	copy(newBuf[len(newBuf)-tail:], g.buf[g.gapEnd:])
+	g.gapEnd = len(newBuf) - tail
+	g.buf = newBuf
+}
+
+func (g *GapLineStore) insertLines(i int, lines []string) {
+	g.growGap(len(lines))
+	g.moveGap(i)
+	copy(g.buf[g.gapStart:], lines)
+	g.gapStart += len(lines)
+}
+
+func (g *GapLineStore) deleteLines(i, n int) {
+	g.moveGap(i)
+	for j := g.gapEnd; j < g.gapEnd+n; j++ {
+		g.buf[j] = ""
+	}
+	g.gapEnd += n
+}
+
+// insertText splits the line at pos and splices in text, which may span lines.
+func insertText(s lineOps, pos Position, text string) {
+	if text == "" {
+		return
+	}
+	line := s.Line(pos.Line)
+	split := runeColToByte(line, pos.Col)
+	before, after := line[:split], line[split:]
+
+	parts := strings.Split(text, "\n")
+	if len(parts) == 1 {
+		s.setLine(pos.Line, before+text+after)
+		return
+	}
+	s.setLine(pos.Line, before+parts[0])
+	parts[len(parts)-1] += after
+	s.insertLines(pos.Line+1, parts[1:])
+}
+
+// deleteRange removes the text between r.Start and r.End.
+func deleteRange(s lineOps, r Range) {
+	start, end := r.Start, r.End
+	if end.Line < start.Line || (end.Line == start.Line && end.Col < start.Col) {
+		start, end = end, start
+	}
+	first := s.Line(start.Line)
+	last := s.Line(end.Line)
+	joined := first[:runeColToByte(first, start.Col)] + last[runeColToByte(last, end.Col):]
+	s.setLine(start.Line, joined)
+	if end.Line > start.Line {
+		s.deleteLines(start.Line+1, end.Line-start.Line)
+	}
+}
+
+// runeColToByte converts a rune column to a byte offset within line,
+// clamping to the end of the line.
+func runeColToByte(line string, col int) int {
+	if col <= 0 {
+		return 0
+	}
+	i := 0
+	for b := range line {
+		if i == col {
+			return b
+		}
+		i++
+	}
+	return len(line)
+}
@@ -0,0 +1,91 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineStoreEdits(t *testing.T) {
+	type edit struct {
+		insert bool
+		pos    Position
+		end    Position
+		text   string
+	}
+	edits := []edit{
+		{insert: true, pos: Position{0, 5}, text: ", world"},
+		{insert: true, pos: Position{1, 0}, text: "new\nlines\n"},
+		{insert: true, pos: Position{3, 3}, text: "日本"},
+		{insert: false, pos: Position{0, 5}, end: Position{1, 1}},
+		{insert: true, pos: Position{0, 0}, text: "\n"},
+		{insert: false, pos: Position{2, 2}, end: Position{3, 0}},
+		{insert: true, pos: Position{2, 99}, text: "!"},
+		{insert: false, pos: Position{1, 0}, end: Position{0, 0}},
+	}
+
+	stores := map[string]LineStore{
+		"slice": NewSliceLineStore("hello\nsecond\nthird"),
+		"gap":   NewGapLineStore("hello\nsecond\nthird"),
+	}
+	for _, e := range edits {
+		for _, s := range stores {
+			if e.insert {
+				s.Insert(e.pos, e.text)
+			} else {
+				s.Delete(Range{Start: e.pos, End: e.end})
+			}
+		}
+	}
+
+	want := stores["slice"].String()
+	got := stores["gap"].String()
+	if got != want {
+		t.Errorf("gap store = %q, slice store = %q", got, want)
+	}
+	if want != "helloew\nlisec日本ond!\nthird" {
+		t.Errorf("slice store = %q, want %q", want, "helloew\nlisec日本ond!\nthird")
+	}
+	for i := 0; i < stores["gap"].LineCount(); i++ {
+		if g, s := stores["gap"].Line(i), stores["slice"].Line(i); g != s {
+			t.Errorf("Line(%d): gap = %q, slice = %q", i, g, s)
+		}
+	}
+}
+
+func TestGapLineStoreGrowth(t *testing.T) {
+	g := NewGapLineStore("")
+	s := NewSliceLineStore("")
+	// Enough newlines to force the gap to grow, inserted at alternating ends
+	for i := 0; i < 3000; i++ {
+		pos := Position{Line: 0, Col: 0}
+		if i%2 == 1 {
+			pos = Position{Line: g.LineCount() - 1, Col: 0}
+		}
+		g.Insert(pos, "x\n")
+		s.Insert(pos, "x\n")
+	}
+	if g.String() != s.String() {
+		t.Error("gap store diverged from slice store after growth")
+	}
+	if g.LineCount() != 3001 {
+		t.Errorf("LineCount() = %d, want 3001", g.LineCount())
+	}
+}
+
+func benchmarkTopInserts(b *testing.B, newStore func(string) LineStore) {
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog\n", 100000)
+	s := newStore(text)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Insert(Position{Line: 10, Col: 0}, "line\n")
+		s.Delete(Range{Start: Position{Line: 10, Col: 2}, End: Position{Line: 11, Col: 0}})
+	}
+}
+
+func BenchmarkSliceLineStoreTopInserts(b *testing.B) {
+	benchmarkTopInserts(b, func(s string) LineStore { return NewSliceLineStore(s) })
+}
+
+func BenchmarkGapLineStoreTopInserts(b *testing.B) {
+	benchmarkTopInserts(b, func(s string) LineStore { return NewGapLineStore(s) })
+}
@@ -0,0 +1,74 @@
+package editor
+
+import (
+	"strings"
+
+	"github.com/cornish/textivus-editor/config"
+)
+
+// Severity ranks how serious a diagnostic is. Higher values are worse.
+type Severity int
+
+// Diagnostic severities
+const (
+	SeverityNone Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+// LintOptions selects which whitespace checks Lint runs.
+type LintOptions struct {
+	TrailingWhitespace bool // Lines ending in spaces or tabs
+	MixedIndent        bool // Leading indentation mixing tabs and spaces
+	FinalNewline       bool // Last line not ended by a newline
+}
+
+// LintOptionsFromConfig returns the checks enabled in cfg.
+func LintOptionsFromConfig(cfg config.EditorConfig) LintOptions {
+	return LintOptions{
+		TrailingWhitespace: cfg.LintTrailingWhitespace,
+		MixedIndent:        cfg.LintMixedIndent,
+		FinalNewline:       cfg.LintFinalNewline,
+	}
+}
+
+// Lint checks lines for whitespace problems and returns the severity of the
+// worst one on each flagged line, keyed by 0-based line index. Lines follow
+// strings.Split semantics, so a document ending in a newline has a final
+// empty element; when it does not, the last line is flagged.
+func Lint(lines []string, opts LintOptions) map[int]Severity {
+	diags := make(map[int]Severity)
+	flag := func(line int, sev Severity) {
+		if sev > diags[line] {
+			diags[line] = sev
+		}
+	}
+
+	for i, line := range lines {
+		if opts.TrailingWhitespace && line != strings.TrimRight(line, " \t") {
+			flag(i, SeverityWarning)
+		}
+		if opts.MixedIndent {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			if strings.Contains(indent, "\t") && strings.Contains(indent, " ") {
+				flag(i, SeverityWarning)
+			}
+		}
+	}
+
+	if opts.FinalNewline && len(lines) > 0 {
+		if last := len(lines) - 1; lines[last] != "" {
+			flag(last, SeverityInfo)
+		}
+	}
+	return diags
+}
+
+// Diagnostics lints the active document with the checks enabled in config.
+func (e *Editor) Diagnostics() map[int]Severity {
+	if e.config == nil {
+		return nil
+	}
+	return Lint(e.activeDoc().buffer.Lines(), LintOptionsFromConfig(e.config.Editor))
+}
@@ -0,0 +1,46 @@
+package editor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	text := strings.Join([]string{
+		"func f() {",
+		"\tx := 1 ",      // trailing space
+		"\t  y := 2",     // tab then spaces
+		"  \tz := 3\t",   // spaces then tab, trailing tab
+		"    ok := true", // spaces only
+		"\tdone()",       // tab only
+		"}",
+	}, "\n")
+	lines := strings.Split(text, "\n")
+	all := LintOptions{TrailingWhitespace: true, MixedIndent: true, FinalNewline: true}
+
+	tests := []struct {
+		name  string
+		lines []string
+		opts  LintOptions
+		want  map[int]Severity
+	}{
+		{"all checks", lines, all, map[int]Severity{
+			1: SeverityWarning, 2: SeverityWarning, 3: SeverityWarning, 6: SeverityInfo,
+		}},
+		{"trailing whitespace only", lines, LintOptions{TrailingWhitespace: true}, map[int]Severity{
+			1: SeverityWarning, 3: SeverityWarning,
+		}},
+		{"mixed indentation only", lines, LintOptions{MixedIndent: true}, map[int]Severity{
+			2: SeverityWarning, 3: SeverityWarning,
+		}},
+		{"final newline present", strings.Split("a\nb\n", "\n"), all, map[int]Severity{}},
+		{"nothing enabled", lines, LintOptions{}, map[int]Severity{}},
+	}
+
+	for _, tt := range tests {
+		if got := Lint(tt.lines, tt.opts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Lint = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
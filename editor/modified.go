@@ -0,0 +1,69 @@
+package editor
+
+// ModifiedLines compares current against the saved snapshot and returns the
+// indices of current lines that differ from it. Lines matching the start or
+// end of the saved text are clean; everything between the common prefix and
+// the common suffix is marked, which covers edits, insertions and the line
+// where a deletion joined its neighbours.
+func ModifiedLines(saved, current []string) map[int]bool {
+	dirty := make(map[int]bool)
+
+	prefix := 0
+	for prefix < len(saved) && prefix < len(current) && saved[prefix] == current[prefix] {
+		prefix++
+	}
+	if prefix == len(saved) && prefix == len(current) {
+		return dirty
+	}
+
+	suffix := 0
+	for suffix < len(saved)-prefix && suffix < len(current)-prefix &&
+		saved[len(saved)-1-suffix] == current[len(current)-1-suffix] {
+		suffix++
+	}
+
+	end := len(current) - suffix
+	if end == prefix {
+		// Pure deletion: mark the line the removed text collapsed onto
+		if prefix < len(current) {
+			dirty[prefix] = true
+		} else if prefix > 0 {
+			dirty[prefix-1] = true
+		}
+		return dirty
+	}
+	for i := prefix; i < end; i++ {
+		dirty[i] = true
+	}
+	return dirty
+}
+
+// modifiedCache holds the ModifiedLines result for one version of a buffer
+// against one saved snapshot, so the diff runs only after an edit or save.
+type modifiedCache struct {
+	buf     *Buffer
+	version int
+	saved   []string
+	dirty   map[int]bool
+}
+
+// modifiedLines returns ModifiedLines for the document's current lines,
+// reusing the last result while neither the text nor the snapshot changed.
+func (d *Document) modifiedLines(lines []string) map[int]bool {
+	c := &d.dirtyLines
+	if c.dirty == nil || c.buf != d.buffer || c.version != d.buffer.Version() || !sameLines(c.saved, d.savedLines) {
+		*c = modifiedCache{
+			buf:     d.buffer,
+			version: d.buffer.Version(),
+			saved:   d.savedLines,
+			dirty:   ModifiedLines(d.savedLines, lines),
+		}
+	}
+	return c.dirty
+}
+
+// sameLines reports whether a and b are the same slice, not just equal ones;
+// a new snapshot is always a fresh slice.
+func sameLines(a, b []string) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
@@ -0,0 +1,81 @@
+package editor
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestModifiedLines(t *testing.T) {
+	saved := []string{"one", "two", "three", "four"}
+
+	tests := []struct {
+		name    string
+		current []string
+		want    []int
+	}{
+		{"unchanged", []string{"one", "two", "three", "four"}, nil},
+		{"edited line", []string{"one", "TWO", "three", "four"}, []int{1}},
+		{"inserted lines", []string{"one", "two", "new", "newer", "three", "four"}, []int{2, 3}},
+		{"two separate edits", []string{"ONE", "two", "three", "FOUR"}, []int{0, 1, 2, 3}},
+		{"deleted line", []string{"one", "three", "four"}, []int{1}},
+		{"deleted last line", []string{"one", "two", "three"}, []int{2}},
+		{"appended line", []string{"one", "two", "three", "four", "five"}, []int{4}},
+	}
+
+	for _, tt := range tests {
+		got := ModifiedLines(saved, tt.current)
+		want := make(map[int]bool)
+		for _, i := range tt.want {
+			want[i] = true
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: ModifiedLines = %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestModifiedLinesClearedAfterSave(t *testing.T) {
+	e := New()
+	e.modifiedGutter.SetEnabled(true)
+	doc := e.activeDoc()
+
+	doc.buffer.InsertRune('x')
+	doc.modified = true
+	if got := e.buildRenderState().ModifiedLines; !got[0] {
+		t.Fatalf("before save: ModifiedLines = %v, want line 0 marked", got)
+	}
+
+	doc.filename = filepath.Join(t.TempDir(), "saved.txt")
+	if !e.doSave() {
+		t.Fatal("doSave failed")
+	}
+	if got := e.buildRenderState().ModifiedLines; len(got) != 0 {
+		t.Errorf("after save: ModifiedLines = %v, want none", got)
+	}
+
+	doc.buffer.InsertRune('y')
+	doc.modified = true
+	if got := e.buildRenderState().ModifiedLines; !got[0] {
+		t.Errorf("edit after save: ModifiedLines = %v, want line 0 marked", got)
+	}
+}
+
+func TestModifiedLinesCached(t *testing.T) {
+	e := New()
+	e.modifiedGutter.SetEnabled(true)
+	doc := e.activeDoc()
+	doc.buffer.InsertRune('x')
+	doc.modified = true
+
+	first := e.buildRenderState().ModifiedLines
+	first[99] = true // Visible only if the next frame reuses the same map
+	if got := e.buildRenderState().ModifiedLines; !got[99] {
+		t.Error("unchanged buffer: ModifiedLines was recomputed")
+	}
+
+	doc.buffer.Insert("\ny")
+	if got := e.buildRenderState().ModifiedLines; got[99] || !got[1] {
+		t.Errorf("after an edit: ModifiedLines = %v, want lines 0 and 1", got)
+	}
+}
@@ -0,0 +1,218 @@
+package editor
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// MultiCursor holds a primary cursor plus any number of secondary cursors.
+// Positions use rune columns, like LineStore. Edits are applied at every
+// cursor in document order, and each edit shifts the cursors after it so
+// all of them stay on the text they were placed on.
+type MultiCursor struct {
+	cursors []Position // cursors[0] is the primary
+}
+
+// NewMultiCursor creates a cursor set containing only primary.
+func NewMultiCursor(primary Position) *MultiCursor {
+	return &MultiCursor{cursors: []Position{primary}}
+}
+
+// Primary returns the primary cursor.
+func (m *MultiCursor) Primary() Position {
+	return m.cursors[0]
+}
+
+// Secondary returns the secondary cursors in the order they were added.
+func (m *MultiCursor) Secondary() []Position {
+	out := make([]Position, len(m.cursors)-1)
+	copy(out, m.cursors[1:])
+	return out
+}
+
+// Positions returns every cursor, primary first.
+func (m *MultiCursor) Positions() []Position {
+	out := make([]Position, len(m.cursors))
+	copy(out, m.cursors)
+	return out
+}
+
+// Count returns the number of cursors including the primary.
+func (m *MultiCursor) Count() int {
+	return len(m.cursors)
+}
+
+// Clear drops all secondary cursors.
+func (m *MultiCursor) Clear() {
+	m.cursors = m.cursors[:1]
+}
+
+// Add places a secondary cursor at pos. Returns false if a cursor is
+// already there.
+func (m *MultiCursor) Add(pos Position) bool {
+	for _, c := range m.cursors {
+		if c == pos {
+			return false
+		}
+	}
+	m.cursors = append(m.cursors, pos)
+	return true
+}
+
+// AddCursorBelow adds a cursor on the line below the lowest cursor, at the
+// primary cursor's column clamped to that line's length. Returns false on
+// the last line.
+func (m *MultiCursor) AddCursorBelow(lines []string) bool {
+	lowest := m.cursors[0].Line
+	for _, c := range m.cursors[1:] {
+		if c.Line > lowest {
+			lowest = c.Line
+		}
+	}
+	below := lowest + 1
+	if below >= len(lines) {
+		return false
+	}
+	col := m.cursors[0].Col
+	if n := utf8.RuneCountInString(lines[below]); col > n {
+		col = n
+	}
+	return m.Add(Position{Line: below, Col: col})
+}
+
+// AddCursorAtNextMatch adds a cursor at the start of the next occurrence of
+// query after the most recently added cursor, wrapping around the document.
+// Returns false if there is no match without a cursor on it already.
+func (m *MultiCursor) AddCursorAtNextMatch(lines []string, query string) bool {
+	last := m.cursors[len(m.cursors)-1]
+	line, col, ok := FindNext(lines, last.Line, last.Col+1, query, true)
+	if !ok {
+		return false
+	}
+	return m.Add(Position{Line: line, Col: col})
+}
+
+// Insert inserts text at every cursor and leaves each cursor after its
+// inserted text.
+func (m *MultiCursor) Insert(store LineStore, text string) {
+	if text == "" {
+		return
+	}
+	order := m.documentOrder()
+	for k, i := range order {
+		pos := m.cursors[i]
+		store.Insert(pos, text)
+		end := insertEnd(pos, text)
+		m.cursors[i] = end
+		for _, j := range order[k+1:] {
+			m.cursors[j] = shiftForInsert(m.cursors[j], pos, end)
+		}
+	}
+}
+
+// DeleteBefore deletes the character before every cursor, joining with the
+// previous line at column 0, like Backspace. Cursors that end up on the same
+// position are merged.
+func (m *MultiCursor) DeleteBefore(store LineStore) {
+	order := m.documentOrder()
+	for k, i := range order {
+		pos := m.cursors[i]
+		var r Range
+		switch {
+		case pos.Col > 0:
+			r = Range{Start: Position{Line: pos.Line, Col: pos.Col - 1}, End: pos}
+		case pos.Line > 0:
+			prev := utf8.RuneCountInString(store.Line(pos.Line - 1))
+			r = Range{Start: Position{Line: pos.Line - 1, Col: prev}, End: pos}
+		default:
+			continue
+		}
+		store.Delete(r)
+		m.cursors[i] = r.Start
+		for _, j := range order[k+1:] {
+			m.cursors[j] = shiftForDelete(m.cursors[j], r)
+		}
+	}
+	m.dedupe()
+}
+
+// SecondaryCursorMap returns the secondary cursors grouped by line, in the
+// form RenderState.SecondaryCursors expects.
+func (m *MultiCursor) SecondaryCursorMap() map[int][]int {
+	out := make(map[int][]int)
+	for _, c := range m.cursors[1:] {
+		out[c.Line] = append(out[c.Line], c.Col)
+	}
+	return out
+}
+
+// documentOrder returns cursor indices sorted by position in the document.
+func (m *MultiCursor) documentOrder() []int {
+	order := make([]int, len(m.cursors))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return positionLess(m.cursors[order[a]], m.cursors[order[b]])
+	})
+	return order
+}
+
+// dedupe removes cursors that share a position, keeping the first.
+func (m *MultiCursor) dedupe() {
+	seen := make(map[Position]bool, len(m.cursors))
+	kept := m.cursors[:0]
+	for _, c := range m.cursors {
+		if !seen[c] {
+			seen[c] = true
+			kept = append(kept, c)
+		}
+	}
+	m.cursors = kept
+}
+
+// positionLess reports whether a comes before b in the document.
+func positionLess(a, b Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Col < b.Col
+}
+
+// insertEnd returns the position just after text inserted at pos.
+func insertEnd(pos Position, text string) Position {
+	nl := strings.Count(text, "\n")
+	if nl == 0 {
+		return Position{Line: pos.Line, Col: pos.Col + utf8.RuneCountInString(text)}
+	}
+	last := text[strings.LastIndex(text, "\n")+1:]
+	return Position{Line: pos.Line + nl, Col: utf8.RuneCountInString(last)}
+}
+
+// shiftForInsert moves p to account for text inserted between at and end.
+// Positions before the insertion point are unaffected.
+func shiftForInsert(p, at, end Position) Position {
+	if positionLess(p, at) {
+		return p
+	}
+	if p.Line == at.Line {
+		return Position{Line: end.Line, Col: end.Col + p.Col - at.Col}
+	}
+	return Position{Line: p.Line + end.Line - at.Line, Col: p.Col}
+}
+
+// shiftForDelete moves p to account for the text in r being removed.
+// Positions inside the deleted range collapse to its start.
+func shiftForDelete(p Position, r Range) Position {
+	if !positionLess(r.Start, p) {
+		return p
+	}
+	if positionLess(p, r.End) {
+		return r.Start
+	}
+	if p.Line == r.End.Line {
+		return Position{Line: r.Start.Line, Col: r.Start.Col + p.Col - r.End.Col}
+	}
+	return Position{Line: p.Line - (r.End.Line - r.Start.Line), Col: p.Col}
+}
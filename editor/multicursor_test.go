@@ -0,0 +1,106 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMultiCursorInsert(t *testing.T) {
+	store := NewSliceLineStore("foo bar\nbaz\nqux quux")
+	m := NewMultiCursor(Position{Line: 0, Col: 4})
+	m.Add(Position{Line: 0, Col: 0})
+	m.Add(Position{Line: 2, Col: 3})
+
+	m.Insert(store, "X")
+
+	if got, want := store.String(), "Xfoo Xbar\nbaz\nquxX quux"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	want := []Position{{0, 6}, {0, 1}, {2, 4}}
+	if got := m.Positions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("cursors = %v, want %v", got, want)
+	}
+}
+
+func TestMultiCursorInsertNewline(t *testing.T) {
+	store := NewSliceLineStore("ab cd\nef")
+	m := NewMultiCursor(Position{Line: 0, Col: 1})
+	m.Add(Position{Line: 0, Col: 4})
+	m.Add(Position{Line: 1, Col: 1})
+
+	m.Insert(store, "\n")
+
+	if got, want := store.String(), "a\nb c\nd\ne\nf"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	want := []Position{{1, 0}, {2, 0}, {4, 0}}
+	if got := m.Positions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("cursors = %v, want %v", got, want)
+	}
+}
+
+func TestMultiCursorDeleteBefore(t *testing.T) {
+	store := NewSliceLineStore("abc\ndef\nghi")
+	m := NewMultiCursor(Position{Line: 1, Col: 0})
+	m.Add(Position{Line: 0, Col: 2})
+	m.Add(Position{Line: 2, Col: 3})
+
+	m.DeleteBefore(store)
+
+	if got, want := store.String(), "acdef\ngh"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	want := []Position{{0, 2}, {0, 1}, {1, 2}}
+	if got := m.Positions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("cursors = %v, want %v", got, want)
+	}
+}
+
+func TestMultiCursorDeleteBeforeMergesCursors(t *testing.T) {
+	store := NewSliceLineStore("abc")
+	m := NewMultiCursor(Position{Line: 0, Col: 1})
+	m.Add(Position{Line: 0, Col: 2})
+	m.DeleteBefore(store)
+	m.DeleteBefore(store)
+
+	if got := store.String(); got != "c" {
+		t.Errorf("text = %q, want %q", got, "c")
+	}
+	if m.Count() != 1 {
+		t.Errorf("Count() = %d, want 1", m.Count())
+	}
+}
+
+func TestAddCursorBelow(t *testing.T) {
+	lines := []string{"long line", "ab", "another"}
+	m := NewMultiCursor(Position{Line: 0, Col: 5})
+
+	if !m.AddCursorBelow(lines) || !m.AddCursorBelow(lines) {
+		t.Fatal("AddCursorBelow failed before the last line")
+	}
+	if m.AddCursorBelow(lines) {
+		t.Error("AddCursorBelow succeeded on the last line")
+	}
+	want := []Position{{1, 2}, {2, 5}}
+	if got := m.Secondary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("secondary = %v, want %v", got, want)
+	}
+}
+
+func TestAddCursorAtNextMatch(t *testing.T) {
+	lines := []string{"foo bar foo", "foo"}
+	m := NewMultiCursor(Position{Line: 0, Col: 0})
+
+	for i := 0; i < 2; i++ {
+		if !m.AddCursorAtNextMatch(lines, "foo") {
+			t.Fatalf("AddCursorAtNextMatch #%d failed", i+1)
+		}
+	}
+	if m.AddCursorAtNextMatch(lines, "foo") {
+		t.Error("AddCursorAtNextMatch added a duplicate after wrapping")
+	}
+	want := []Position{{0, 0}, {0, 8}, {1, 0}}
+	if got := m.Positions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("cursors = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package editor
+
+import (
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/syntax"
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// MatchRange is the rune column range [Start, End) of a match on one line.
+type MatchRange struct {
+	Start int
+	End   int
+}
+
+// WordOccurrences finds the whole-word occurrences of word in lines, which
+// hold the visible lines starting at document line firstLine. Results are
+// keyed by document line. The occurrence the cursor is in is left out, and
+// so are matches inside longer words. word must be an identifier (letters,
+// digits and underscores); anything else finds nothing.
+func WordOccurrences(lines []string, firstLine int, word string, cursor Position) map[int][]MatchRange {
+	needle := []rune(word)
+	if len(needle) == 0 {
+		return nil
+	}
+	for _, r := range needle {
+		if !isWordChar(r) {
+			return nil
+		}
+	}
+
+	var matches map[int][]MatchRange
+	for i, line := range lines {
+		ln := firstLine + i
+		hay := []rune(line)
+		for c := indexRunes(hay, needle, 0, len(hay)); c >= 0; c = indexRunes(hay, needle, c+1, len(hay)) {
+			end := c + len(needle)
+			if (c > 0 && isWordChar(hay[c-1])) || (end < len(hay) && isWordChar(hay[end])) {
+				continue // Part of a longer word
+			}
+			if ln == cursor.Line && cursor.Col >= c && cursor.Col <= end {
+				continue
+			}
+			if matches == nil {
+				matches = make(map[int][]MatchRange)
+			}
+			matches[ln] = append(matches[ln], MatchRange{Start: c, End: end})
+		}
+	}
+	return matches
+}
+
+// highlightWordOccurrences marks the other visible occurrences of the word
+// under the cursor with the selection background, keeping their syntax
+// colors. Nothing is marked while a selection is active.
+func (e *Editor) highlightWordOccurrences(lines []string, lineColors map[int][]syntax.ColorSpan) {
+	doc := e.activeDoc()
+	if doc.selection.Active && !doc.selection.IsEmpty() {
+		return
+	}
+	line := doc.cursor.Line()
+	col := utf8.RuneCountInString(lines[line][:doc.cursor.Col()])
+	start, end := WordBoundsAt(lines[line], col)
+	word := string([]rune(lines[line])[start:end])
+
+	first, last := e.visibleLines(lines)
+	if first >= last {
+		return
+	}
+
+	bg := ui.ColorToANSIBg(e.styles.Theme.UI.SelectionBg)
+	for ln, ranges := range WordOccurrences(lines[first:last], first, word, Position{Line: line, Col: col}) {
+		for _, m := range ranges {
+			span := syntax.ColorSpan{Start: m.Start, End: m.End, Color: syntax.ColorAt(lineColors[ln], m.Start) + bg}
+			lineColors[ln] = append([]syntax.ColorSpan{span}, lineColors[ln]...)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWordOccurrences(t *testing.T) {
+	lines := []string{
+		"count := 0",
+		"for _, x := range items {",
+		"\tcount += x // recount counter",
+		"}",
+		"return count, count_total",
+	}
+
+	tests := []struct {
+		name      string
+		firstLine int
+		word      string
+		cursor    Position
+		want      map[int][]MatchRange
+	}{
+		{
+			"several occurrences, cursor one excluded",
+			0, "count", Position{Line: 0, Col: 2},
+			map[int][]MatchRange{
+				2: {{Start: 1, End: 6}},
+				4: {{Start: 7, End: 12}},
+			},
+		},
+		{
+			"cursor at the end of the word",
+			0, "count", Position{Line: 4, Col: 12},
+			map[int][]MatchRange{
+				0: {{Start: 0, End: 5}},
+				2: {{Start: 1, End: 6}},
+			},
+		},
+		{
+			"keyed by document line",
+			10, "x", Position{Line: 0, Col: 0},
+			map[int][]MatchRange{
+				11: {{Start: 7, End: 8}},
+				12: {{Start: 10, End: 11}},
+			},
+		},
+		{"no other occurrences", 0, "items", Position{Line: 1, Col: 20}, nil},
+		{"not an identifier", 0, ":=", Position{Line: 0, Col: 6}, nil},
+		{"empty word", 0, "", Position{}, nil},
+	}
+
+	for _, tt := range tests {
+		got := WordOccurrences(lines, tt.firstLine, tt.word, tt.cursor)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: WordOccurrences(%q) = %v, want %v", tt.name, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestRenderStateMarksWordOccurrences(t *testing.T) {
+	e := New()
+	e.OnResize(80, 24)
+	e.config.Editor.HighlightOccurrences = true
+	e.insertText("total = 1\ntotal += subtotal")
+	e.activeDoc().cursor.SetPosition(0, 2)
+
+	colors := e.buildRenderState().LineColors
+	if len(colors[0]) != 0 {
+		t.Errorf("line 0 = %v, want the cursor's own word left unmarked", colors[0])
+	}
+	if len(colors[1]) != 1 || colors[1][0].Start != 0 || colors[1][0].End != 5 {
+		t.Errorf("line 1 = %v, want one span over columns 0-5", colors[1])
+	}
+
+	e.config.Editor.HighlightOccurrences = false
+	if got := e.buildRenderState().LineColors; len(got[1]) != 0 {
+		t.Errorf("disabled: line 1 = %v, want no spans", got[1])
+	}
+}
@@ -0,0 +1,84 @@
+package editor
+
+import (
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/syntax"
+)
+
+// rainbowCache keeps the bracket depth at the start of each line, and the
+// string and comment runs the brackets were counted around, so rainbow
+// brackets don't rescan the document every frame. Entries hold for the
+// lines they were computed from; the first line that differs and
+// everything below it are recounted.
+type rainbowCache struct {
+	language string
+	lines    []string           // Text the entries were computed from
+	literals [][]syntax.Literal // String and comment runs of each line
+	depths   []int              // Bracket depth at the start of each line
+}
+
+// update makes the cache cover lines [0, end).
+func (c *rainbowCache) update(h *syntax.Highlighter, lines []string, end int) {
+	if lang := h.Language(); lang != c.language {
+		*c = rainbowCache{language: lang}
+	}
+	valid := min(len(c.lines), len(lines))
+	for i := 0; i < valid; i++ {
+		if c.lines[i] != lines[i] {
+			valid = i
+			break
+		}
+	}
+	if valid >= end {
+		c.truncate(valid)
+		return
+	}
+
+	// Relex from a line that begins outside any string or comment, so the
+	// lexer sees the whole construct
+	anchor := valid
+	for anchor > 0 && !c.startsClean(anchor) {
+		anchor--
+	}
+	c.truncate(anchor)
+	literals := h.Literals(lines[anchor:end])
+	depth := 0
+	if anchor > 0 {
+		_, depth = syntax.RainbowSpans(c.lines[anchor-1], c.literals[anchor-1], c.depths[anchor-1])
+	}
+	for i := anchor; i < end; i++ {
+		var runs []syntax.Literal
+		if literals != nil {
+			runs = literals[i-anchor]
+		}
+		c.lines = append(c.lines, lines[i])
+		c.literals = append(c.literals, runs)
+		c.depths = append(c.depths, depth)
+		_, depth = syntax.RainbowSpans(lines[i], runs, depth)
+	}
+}
+
+// truncate drops the entries for line n and below.
+func (c *rainbowCache) truncate(n int) {
+	c.lines = c.lines[:n]
+	c.literals = c.literals[:n]
+	c.depths = c.depths[:n]
+}
+
+// startsClean reports whether line i is known to begin outside any string
+// or comment. A blank line above tells nothing, since it may sit inside a
+// block comment, and a run to the end of the line above may go on.
+func (c *rainbowCache) startsClean(i int) bool {
+	prev, runs := c.lines[i-1], c.literals[i-1]
+	if prev == "" {
+		return false
+	}
+	return len(runs) == 0 || runs[len(runs)-1].End < utf8.RuneCountInString(prev)
+}
+
+// spans colors the brackets of line i, which update must have covered.
+func (c *rainbowCache) spans(i int) []syntax.ColorSpan {
+	spans, _ := syntax.RainbowSpans(c.lines[i], c.literals[i], c.depths[i])
+	return spans
+}
@@ -0,0 +1,41 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cornish/textivus-editor/syntax"
+)
+
+func TestRainbowCache(t *testing.T) {
+	h := syntax.New("x.go")
+	lines := []string{
+		"func f() {",
+		"\ts := `(",
+		"[`",
+		"\tg(x)",
+		"}",
+	}
+	var c rainbowCache
+	c.update(h, lines, len(lines))
+	if want := []int{0, 1, 1, 1, 1}; !reflect.DeepEqual(c.depths, want) {
+		t.Fatalf("depths = %v, want %v", c.depths, want)
+	}
+
+	// Edit the first line and the rest is recounted from there; scroll
+	// past the end of what was cached and it is extended
+	edits := [][]string{
+		{"func f() { {", "\ts := `(", "[`", "\tg(x)", "}"},
+		{"func f() { {", "\ts := `(", "[`", "\tg(x)", "}", "}", "h()"},
+		{"func f() { {", "\ts := `(", "\tg(x)", "}", "}", "h()"},
+	}
+	for i, edited := range edits {
+		c.update(h, edited, 3)
+		c.update(h, edited, len(edited))
+		var fresh rainbowCache
+		fresh.update(h, edited, len(edited))
+		if !reflect.DeepEqual(c.depths, fresh.depths) || !reflect.DeepEqual(c.literals, fresh.literals) {
+			t.Errorf("edit %d: cached depths %v, want %v", i, c.depths, fresh.depths)
+		}
+	}
+}
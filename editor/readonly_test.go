@@ -0,0 +1,107 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadOnlyBlocksEdits(t *testing.T) {
+	edits := []struct {
+		name string
+		edit func(e *Editor)
+	}{
+		{"insertChar", func(e *Editor) { e.insertChar('x') }},
+		{"insertText", func(e *Editor) { e.insertText("xyz") }},
+		{"insertNewline", func(e *Editor) { e.insertNewline() }},
+		{"backspace", func(e *Editor) { e.backspace() }},
+		{"delete", func(e *Editor) { e.delete() }},
+		{"cutLine", func(e *Editor) { e.cutLine() }},
+		{"indentLines", func(e *Editor) { e.indentLines() }},
+	}
+
+	for _, readOnly := range []bool{true, false} {
+		for _, tt := range edits {
+			e := New()
+			e.insertText("hello")
+			e.activeDoc().cursor.SetByteOffset(2)
+			e.activeDoc().modified = false
+			e.SetReadOnly(readOnly)
+
+			tt.edit(e)
+			changed := e.activeDoc().buffer.String() != "hello"
+			if readOnly && (changed || e.activeDoc().modified) {
+				t.Errorf("%s in read-only mode changed the buffer to %q", tt.name, e.activeDoc().buffer.String())
+			}
+			if !readOnly && !changed {
+				t.Errorf("%s in writable mode left the buffer unchanged", tt.name)
+			}
+		}
+	}
+}
+
+func TestReadOnlyBlocksUndo(t *testing.T) {
+	e := New()
+	e.insertText("hello")
+	e.SetReadOnly(true)
+	e.undo()
+	if got := e.activeDoc().buffer.String(); got != "hello" {
+		t.Errorf("undo in read-only mode: buffer = %q, want %q", got, "hello")
+	}
+	if e.SaveFile() {
+		t.Error("SaveFile in read-only mode = true, want false")
+	}
+
+	e.SetReadOnly(false)
+	e.undo()
+	if got := e.activeDoc().buffer.String(); got != "" {
+		t.Errorf("undo after leaving read-only mode: buffer = %q, want empty", got)
+	}
+}
+
+func TestLoadFileDetectsUnwritableFile(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to any file")
+	}
+
+	dir := t.TempDir()
+	locked := filepath.Join(dir, "locked.txt")
+	open := filepath.Join(dir, "open.txt")
+	for path, mode := range map[string]os.FileMode{locked: 0444, open: 0644} {
+		if err := os.WriteFile(path, []byte("text"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := New()
+	e.config = nil // Keep LoadFile from saving recent files
+	if err := e.LoadFile(locked); err != nil {
+		t.Fatal(err)
+	}
+	if e.activeDoc().CanEdit() {
+		t.Error("unwritable file: CanEdit() = true, want false")
+	}
+	if err := e.LoadFile(open); err != nil {
+		t.Fatal(err)
+	}
+	if !e.activeDoc().CanEdit() {
+		t.Error("writable file: CanEdit() = false, want true")
+	}
+}
+
+func TestBinaryFileOpensReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "data.bin")
+	if err := os.WriteFile(bin, []byte{0x7f, 'E', 'L', 'F', 0, 1, 2}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	if err := e.LoadFile(bin); err != nil {
+		t.Fatal(err)
+	}
+	if !e.activeDoc().IsBinary() || e.activeDoc().CanEdit() {
+		t.Errorf("binary file: IsBinary() = %v, CanEdit() = %v, want true, false",
+			e.activeDoc().IsBinary(), e.activeDoc().CanEdit())
+	}
+}
@@ -0,0 +1,115 @@
+package editor
+
+// ReplaceAnswer is the user's reply when a replace session asks about a
+// match.
+type ReplaceAnswer int
+
+const (
+	ReplaceYes       ReplaceAnswer = iota // Replace this match and move to the next
+	ReplaceNo                             // Skip this match
+	ReplaceRemaining                      // "all": replace this match and every one after it
+	ReplaceQuit                           // Stop, leaving the remaining matches alone
+)
+
+// ReplaceSession walks through the matches of a query asking whether to
+// replace each one, like query-replace. The matches are found up front, so
+// after each replacement the ones still to come are shifted by the change
+// in length to keep their positions valid. Columns are rune indices.
+type ReplaceSession struct {
+	store       *SliceLineStore
+	matches     []Range
+	next        int
+	replacement string
+	replaced    int
+}
+
+// NewReplaceSession finds the non-overlapping matches of query in lines at
+// or after from. The input slice is not modified.
+func NewReplaceSession(lines []string, query, replacement string, from Position, caseSensitive bool) *ReplaceSession {
+	s := &ReplaceSession{
+		store:       &SliceLineStore{lines: append([]string(nil), lines...)},
+		replacement: replacement,
+	}
+	needle := searchRunes(query, caseSensitive)
+	if len(needle) == 0 {
+		return s
+	}
+	for ln := max(from.Line, 0); ln < len(lines); ln++ {
+		hay := searchRunes(lines[ln], caseSensitive)
+		lo := 0
+		if ln == from.Line {
+			lo = max(from.Col, 0)
+		}
+		for c := indexRunes(hay, needle, lo, len(hay)); c >= 0; c = indexRunes(hay, needle, c+len(needle), len(hay)) {
+			s.matches = append(s.matches, Range{
+				Start: Position{Line: ln, Col: c},
+				End:   Position{Line: ln, Col: c + len(needle)},
+			})
+		}
+	}
+	return s
+}
+
+// Current returns the match waiting for an answer. ok is false once the
+// session is done.
+func (s *ReplaceSession) Current() (r Range, ok bool) {
+	if s.Done() {
+		return Range{}, false
+	}
+	return s.matches[s.next], true
+}
+
+// Remaining returns the number of matches not yet answered.
+func (s *ReplaceSession) Remaining() int {
+	return len(s.matches) - s.next
+}
+
+// Done reports whether every match has been answered or the session quit.
+func (s *ReplaceSession) Done() bool {
+	return s.next >= len(s.matches)
+}
+
+// Replaced returns the number of replacements made so far.
+func (s *ReplaceSession) Replaced() int {
+	return s.replaced
+}
+
+// Lines returns the text with the replacements made so far.
+func (s *ReplaceSession) Lines() []string {
+	return s.store.Lines()
+}
+
+// Answer applies the reply for the current match and moves to the next.
+func (s *ReplaceSession) Answer(a ReplaceAnswer) {
+	if s.Done() {
+		return
+	}
+	switch a {
+	case ReplaceYes:
+		s.replaceCurrent()
+	case ReplaceNo:
+		s.next++
+	case ReplaceRemaining:
+		for !s.Done() {
+			s.replaceCurrent()
+		}
+	case ReplaceQuit:
+		s.next = len(s.matches)
+	}
+}
+
+// replaceCurrent replaces the current match and shifts the later ones.
+func (s *ReplaceSession) replaceCurrent() {
+	r := s.matches[s.next]
+	s.store.Delete(r)
+	s.store.Insert(r.Start, s.replacement)
+	end := insertEnd(r.Start, s.replacement)
+	for i := s.next + 1; i < len(s.matches); i++ {
+		m := s.matches[i]
+		m.Start = shiftForInsert(shiftForDelete(m.Start, r), r.Start, end)
+		m.End = shiftForInsert(shiftForDelete(m.End, r), r.Start, end)
+		s.matches[i] = m
+	}
+	s.next++
+	s.replaced++
+}
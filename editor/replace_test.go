@@ -0,0 +1,101 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplaceSession(t *testing.T) {
+	lines := []string{"cat cat", "a cat and a Cat", "no match"}
+
+	tests := []struct {
+		name         string
+		replacement  string
+		from         Position
+		answers      []ReplaceAnswer
+		want         []string
+		wantReplaced int
+	}{
+		{
+			"confirm some, skip others",
+			"tiger",
+			Position{},
+			[]ReplaceAnswer{ReplaceYes, ReplaceNo, ReplaceYes},
+			[]string{"tiger cat", "a tiger and a Cat", "no match"},
+			2,
+		},
+		{
+			"all replaces the rest",
+			"ox",
+			Position{},
+			[]ReplaceAnswer{ReplaceNo, ReplaceRemaining},
+			[]string{"cat ox", "a ox and a Cat", "no match"},
+			2,
+		},
+		{
+			"quit leaves the rest",
+			"dog",
+			Position{},
+			[]ReplaceAnswer{ReplaceYes, ReplaceQuit},
+			[]string{"dog cat", "a cat and a Cat", "no match"},
+			1,
+		},
+		{
+			"starts from a position",
+			"dog",
+			Position{Line: 0, Col: 1},
+			[]ReplaceAnswer{ReplaceRemaining},
+			[]string{"cat dog", "a dog and a Cat", "no match"},
+			2,
+		},
+		{
+			"replacement spanning lines",
+			"c\nt",
+			Position{},
+			[]ReplaceAnswer{ReplaceYes, ReplaceYes, ReplaceNo},
+			[]string{"c", "t c", "t", "a cat and a Cat", "no match"},
+			2,
+		},
+	}
+
+	for _, tt := range tests {
+		s := NewReplaceSession(lines, "cat", tt.replacement, tt.from, true)
+		for _, a := range tt.answers {
+			// The current match must always cover the query in the updated text
+			r, ok := s.Current()
+			if !ok {
+				t.Fatalf("%s: session ended early", tt.name)
+			}
+			if got := []rune(s.Lines()[r.Start.Line])[r.Start.Col:r.End.Col]; string(got) != "cat" {
+				t.Errorf("%s: current match %v covers %q", tt.name, r, string(got))
+			}
+			s.Answer(a)
+		}
+		if !s.Done() {
+			t.Errorf("%s: %d matches left unanswered", tt.name, s.Remaining())
+		}
+		if got := s.Lines(); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: lines = %q, want %q", tt.name, got, tt.want)
+		}
+		if s.Replaced() != tt.wantReplaced {
+			t.Errorf("%s: Replaced() = %d, want %d", tt.name, s.Replaced(), tt.wantReplaced)
+		}
+	}
+	if lines[0] != "cat cat" {
+		t.Errorf("input modified: %q", lines)
+	}
+}
+
+func TestReplaceSessionCaseInsensitive(t *testing.T) {
+	s := NewReplaceSession([]string{"Cat CAT cat"}, "cat", "x", Position{}, false)
+	if s.Remaining() != 3 {
+		t.Fatalf("Remaining() = %d, want 3", s.Remaining())
+	}
+	s.Answer(ReplaceRemaining)
+	if got := s.Lines()[0]; got != "x x x" {
+		t.Errorf("lines = %q, want %q", got, "x x x")
+	}
+	if _, ok := s.Current(); ok {
+		t.Error("Current() reported a match after all were replaced")
+	}
+}
@@ -0,0 +1,54 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOnResizeClampsScroll(t *testing.T) {
+	tests := []struct {
+		name    string
+		scrollY int
+		height  int // Terminal height; the viewport is 2 rows shorter
+		want    int
+	}{
+		{"shrinking clamps an out-of-range scroll", 500, 12, 40},
+		{"growing past the document scrolls to the top", 30, 60, 0},
+		{"in-range scroll is kept", 15, 12, 15},
+	}
+
+	for _, tt := range tests {
+		e := New()
+		e.insertText(strings.Repeat("line\n", 49)) // 50 lines
+		e.OnResize(80, 24)
+
+		other := &Document{buffer: NewBufferFromString(strings.Repeat("x\n", 49)), scrollY: tt.scrollY}
+		e.documents = append(e.documents, other)
+		e.viewport.SetScrollY(tt.scrollY)
+
+		e.OnResize(80, tt.height)
+		if got := e.viewport.ScrollY(); got != tt.want {
+			t.Errorf("%s: active scrollY = %d, want %d", tt.name, got, tt.want)
+		}
+		if other.scrollY != tt.want {
+			t.Errorf("%s: background document scrollY = %d, want %d", tt.name, other.scrollY, tt.want)
+		}
+	}
+}
+
+func TestOnResizeReapportionsWidth(t *testing.T) {
+	e := New()
+	e.OnResize(100, 24)
+	wide := e.compositor.FlexibleColumnWidth()
+	if e.compositor.Width() != 100 || e.viewport.Width() != 100 {
+		t.Fatalf("after resize to 100: compositor width %d, viewport width %d", e.compositor.Width(), e.viewport.Width())
+	}
+
+	e.OnResize(60, 24)
+	if got := e.compositor.FlexibleColumnWidth(); got != wide-40 {
+		t.Errorf("text column width after shrinking by 40 = %d, want %d", got, wide-40)
+	}
+	if got := e.compositor.Height(); got != 22 {
+		t.Errorf("compositor height = %d, want 22", got)
+	}
+}
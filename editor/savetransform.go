@@ -0,0 +1,86 @@
+package editor
+
+import "strings"
+
+// TrimTrailingWhitespace removes trailing spaces and tabs from every line.
+// Lines are trimmed uniformly regardless of cursor or selection. A
+// carriage return ending a line is part of its CRLF line break and stays.
+func TrimTrailingWhitespace(lines []string) []string {
+	return TrimExceptLine(lines, -1)
+}
+
+// TrimExceptLine removes trailing spaces and tabs from every line except
+// exceptLine, which is copied unchanged. Trimming while the user types uses
+// it to leave a space they just typed on the cursor line alone; pass -1 to
+// trim every line, as saving does. The input slice is not modified.
+func TrimExceptLine(lines []string, exceptLine int) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if i == exceptLine {
+			out[i] = line
+			continue
+		}
+		body, cr := strings.CutSuffix(line, "\r")
+		out[i] = strings.TrimRight(body, " \t")
+		if cr {
+			out[i] += "\r"
+		}
+	}
+	return out
+}
+
+// EnsureFinalNewline makes the text represented by lines end with a
+// newline, adding one when the last line has none. Lines follow
+// strings.Split semantics, so a trailing newline is a final empty element.
+// The added break matches the document's own: CRLF when its first line
+// ends in a carriage return. Blank lines are left alone, and an empty
+// document stays empty.
+func EnsureFinalNewline(lines []string) []string {
+	out := append([]string(nil), lines...)
+	n := len(out)
+	if n == 0 || out[n-1] == "" {
+		return out
+	}
+	if n > 1 && strings.HasSuffix(out[0], "\r") {
+		out[n-1] += "\r"
+	}
+	return append(out, "")
+}
+
+// ConvertLineEndings rewrites every line break in content as the one eol
+// names: "lf", "crlf" or "cr", as end_of_line does. Any other value leaves
+// content as it is.
+func ConvertLineEndings(content, eol string) string {
+	var sep string
+	switch eol {
+	case "lf":
+		sep = "\n"
+	case "crlf":
+		sep = "\r\n"
+	case "cr":
+		sep = "\r"
+	default:
+		return content
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	if sep == "\n" {
+		return content
+	}
+	return strings.ReplaceAll(content, "\n", sep)
+}
+
+// ApplySaveTransforms runs the enabled save-time transforms over content.
+func ApplySaveTransforms(content string, trimWhitespace, finalNewline bool) string {
+	if !trimWhitespace && !finalNewline {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	if trimWhitespace {
+		lines = TrimTrailingWhitespace(lines)
+	}
+	if finalNewline {
+		lines = EnsureFinalNewline(lines)
+	}
+	return strings.Join(lines, "\n")
+}
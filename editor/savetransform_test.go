@@ -0,0 +1,110 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestApplySaveTransforms(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		trim         bool
+		finalNewline bool
+		want         string
+	}{
+		{"disabled", "a  \nb\t", false, false, "a  \nb\t"},
+		{"trim spaces", "a  \nb", true, false, "a\nb"},
+		{"trim mixed spaces and tabs", "a \t \nb\t\t\n  c \t", true, false, "a\nb\n  c"},
+		{"trim keeps leading whitespace", "\tx  ", true, false, "\tx"},
+		{"trim whitespace-only line", "a\n   \nb", true, false, "a\n\nb"},
+		{"add final newline", "a\nb", false, true, "a\nb\n"},
+		{"keep single final newline", "a\nb\n", false, true, "a\nb\n"},
+		{"keep blank lines before the final newline", "a\nb\n\n\n", false, true, "a\nb\n\n\n"},
+		{"empty document stays empty", "", false, true, ""},
+		{"both", "a \nb\t\n  \n", true, true, "a\nb\n\n"},
+		{"trim before a CRLF line break", "a  \r\nb\t\r\nc \r", true, false, "a\r\nb\r\nc\r"},
+		{"final newline matches CRLF", "a\r\nb", false, true, "a\r\nb\r\n"},
+		{"CRLF document already ending in one", "a\r\nb\r\n", false, true, "a\r\nb\r\n"},
+		{"both with CRLF", "a \r\n\t\r\nb  ", true, true, "a\r\n\r\nb\r\n"},
+	}
+
+	for _, tt := range tests {
+		got := ApplySaveTransforms(tt.content, tt.trim, tt.finalNewline)
+		if got != tt.want {
+			t.Errorf("%s: ApplySaveTransforms(%q, %v, %v) = %q, want %q",
+				tt.name, tt.content, tt.trim, tt.finalNewline, got, tt.want)
+		}
+	}
+}
+
+func TestTrimExceptLine(t *testing.T) {
+	lines := []string{"a  ", "typing ", "\tb\t", "  "}
+
+	tests := []struct {
+		name       string
+		exceptLine int
+		want       []string
+	}{
+		{"cursor line keeps its trailing space", 1, []string{"a", "typing ", "\tb", ""}},
+		{"whitespace-only cursor line kept", 3, []string{"a", "typing", "\tb", "  "}},
+		{"no exception trims everything", -1, []string{"a", "typing", "\tb", ""}},
+		{"exception past the end", 10, []string{"a", "typing", "\tb", ""}},
+	}
+
+	for _, tt := range tests {
+		got := TrimExceptLine(lines, tt.exceptLine)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: TrimExceptLine(%d) = %q, want %q", tt.name, tt.exceptLine, got, tt.want)
+		}
+	}
+	if got := TrimTrailingWhitespace(lines); strings.Join(got, "\n") != "a\ntyping\n\tb\n" {
+		t.Errorf("TrimTrailingWhitespace = %q, want every line trimmed", got)
+	}
+	if lines[0] != "a  " {
+		t.Errorf("TrimExceptLine modified its input: %q", lines)
+	}
+}
+
+func TestEnterTrimsLineLeft(t *testing.T) {
+	e := New()
+	e.config.Editor.TrimTrailingWhitespace = true
+	e.config.Editor.AutoIndent = true
+	e.insertText("\tfoo  ")
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	doc := e.activeDoc()
+	if got, want := doc.buffer.String(), "\tfoo\n\t"; got != want {
+		t.Errorf("after Enter: buffer = %q, want %q", got, want)
+	}
+	if line, col := doc.cursor.Line(), doc.cursor.Col(); line != 1 || col != 1 {
+		t.Errorf("cursor = %d:%d, want 1:1 after the kept indent", line, col)
+	}
+
+	// Saving still trims the cursor line
+	if got, want := ApplySaveTransforms(doc.buffer.String(), true, false), "\tfoo\n"; got != want {
+		t.Errorf("save transform = %q, want %q", got, want)
+	}
+}
+
+func TestConvertLineEndings(t *testing.T) {
+	tests := []struct {
+		content string
+		eol     string
+		want    string
+	}{
+		{"a\r\nb\nc\rd", "lf", "a\nb\nc\nd"},
+		{"a\r\nb\nc", "crlf", "a\r\nb\r\nc"},
+		{"a\nb\r\n", "cr", "a\rb\r"},
+		{"a\r\nb\n", "", "a\r\nb\n"},
+		{"a\r\nb\n", "native", "a\r\nb\n"},
+	}
+
+	for _, tt := range tests {
+		if got := ConvertLineEndings(tt.content, tt.eol); got != tt.want {
+			t.Errorf("ConvertLineEndings(%q, %q) = %q, want %q", tt.content, tt.eol, got, tt.want)
+		}
+	}
+}
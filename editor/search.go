@@ -0,0 +1,208 @@
+package editor
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Range is a span of text between two positions, End exclusive.
+type Range struct {
+	Start Position
+	End   Position
+}
+
+// FindNext searches forward for query starting at (fromLine, fromCol) and
+// wraps around past the end of the document. Columns are rune indices.
+// A match starting exactly at fromCol is returned, so callers wanting the
+// following occurrence should pass fromCol+1.
+func FindNext(lines []string, fromLine, fromCol int, query string, caseSensitive bool) (line, col int, ok bool) {
+	needle := searchRunes(query, caseSensitive)
+	if len(needle) == 0 || len(lines) == 0 {
+		return 0, 0, false
+	}
+	fromLine = clampLine(fromLine, len(lines))
+	if fromCol < 0 {
+		fromCol = 0
+	}
+
+	n := len(lines)
+	for i := 0; i <= n; i++ {
+		ln := (fromLine + i) % n
+		hay := searchRunes(lines[ln], caseSensitive)
+		lo, hi := 0, len(hay)
+		if i == 0 {
+			lo = fromCol
+		} else if i == n {
+			// Back on the starting line: only matches before the start remain
+			hi = fromCol
+		}
+		if c := indexRunes(hay, needle, lo, hi); c >= 0 {
+			return ln, c, true
+		}
+	}
+	return 0, 0, false
+}
+
+// FindPrev searches backward for the last occurrence of query starting
+// before (fromLine, fromCol), wrapping around past the start of the document.
+func FindPrev(lines []string, fromLine, fromCol int, query string, caseSensitive bool) (line, col int, ok bool) {
+	needle := searchRunes(query, caseSensitive)
+	if len(needle) == 0 || len(lines) == 0 {
+		return 0, 0, false
+	}
+	fromLine = clampLine(fromLine, len(lines))
+	if fromCol < 0 {
+		fromCol = 0
+	}
+
+	n := len(lines)
+	for i := 0; i <= n; i++ {
+		ln := ((fromLine-i)%n + n) % n
+		hay := searchRunes(lines[ln], caseSensitive)
+		lo, hi := 0, len(hay)
+		if i == 0 {
+			hi = fromCol
+		} else if i == n {
+			// Back on the starting line: only matches at or after the start remain
+			lo = fromCol
+		}
+		if c := lastIndexRunes(hay, needle, lo, hi); c >= 0 {
+			return ln, c, true
+		}
+	}
+	return 0, 0, false
+}
+
+// searchRunes converts s to runes, folding case when the search is case-insensitive.
+func searchRunes(s string, caseSensitive bool) []rune {
+	runes := []rune(s)
+	if !caseSensitive {
+		for i, r := range runes {
+			runes[i] = unicode.ToLower(r)
+		}
+	}
+	return runes
+}
+
+// indexRunes returns the first index in [lo, hi) where needle starts, or -1.
+func indexRunes(hay, needle []rune, lo, hi int) int {
+	if hi > len(hay)-len(needle) {
+		hi = len(hay) - len(needle) + 1
+	}
+	for i := lo; i < hi; i++ {
+		if runesEqualAt(hay, needle, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastIndexRunes returns the last index in [lo, hi) where needle starts, or -1.
+func lastIndexRunes(hay, needle []rune, lo, hi int) int {
+	if hi > len(hay)-len(needle) {
+		hi = len(hay) - len(needle) + 1
+	}
+	if lo < 0 {
+		lo = 0
+	}
+	for i := hi - 1; i >= lo; i-- {
+		if runesEqualAt(hay, needle, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// runesEqualAt reports whether needle occurs in hay at index i.
+func runesEqualAt(hay, needle []rune, i int) bool {
+	for j, r := range needle {
+		if hay[i+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// clampLine clamps line into [0, count).
+func clampLine(line, count int) int {
+	if line < 0 {
+		return 0
+	}
+	if line >= count {
+		return count - 1
+	}
+	return line
+}
+
+// FindNextRegex finds the next match of re at or after from, wrapping around
+// past the end of the document. Matching is done per line, so patterns never
+// span line breaks. Zero-width matches are skipped since they cannot be
+// selected and would leave the cursor stuck in place.
+func FindNextRegex(lines []string, from Position, re *regexp.Regexp) (match Range, ok bool) {
+	if re == nil || len(lines) == 0 {
+		return Range{}, false
+	}
+	fromLine := clampLine(from.Line, len(lines))
+	fromCol := from.Col
+	if fromCol < 0 {
+		fromCol = 0
+	}
+
+	n := len(lines)
+	for i := 0; i <= n; i++ {
+		ln := (fromLine + i) % n
+		line := lines[ln]
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			startCol := utf8.RuneCountInString(line[:loc[0]])
+			if i == 0 && startCol < fromCol {
+				continue
+			}
+			if i == n && startCol >= fromCol {
+				break
+			}
+			return Range{
+				Start: Position{Line: ln, Col: startCol},
+				End:   Position{Line: ln, Col: startCol + utf8.RuneCountInString(line[loc[0]:loc[1]])},
+			}, true
+		}
+	}
+	return Range{}, false
+}
+
+// ReplaceAll replaces every match of re in lines with repl, expanding $1-style
+// group references. Lines are matched independently. Returns the new lines
+// and the number of replacements made; the input slice is not modified.
+func ReplaceAll(lines []string, re *regexp.Regexp, repl string) (newLines []string, count int) {
+	newLines = make([]string, len(lines))
+	if re == nil {
+		copy(newLines, lines)
+		return newLines, 0
+	}
+
+	for i, line := range lines {
+		matches := re.FindAllStringSubmatchIndex(line, -1)
+		if len(matches) == 0 {
+			newLines[i] = line
+			continue
+		}
+
+		var sb strings.Builder
+		var dst []byte
+		last := 0
+		for _, m := range matches {
+			sb.WriteString(line[last:m[0]])
+			dst = re.ExpandString(dst[:0], repl, line, m)
+			sb.Write(dst)
+			last = m[1]
+		}
+		sb.WriteString(line[last:])
+		newLines[i] = sb.String()
+		count += len(matches)
+	}
+	return newLines, count
+}
@@ -0,0 +1,168 @@
+package editor
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFindNext(t *testing.T) {
+	lines := []string{
+		"foo bar",
+		"Bar baz",
+		"日本語 bar",
+	}
+
+	tests := []struct {
+		name          string
+		fromLine      int
+		fromCol       int
+		query         string
+		caseSensitive bool
+		wantLine      int
+		wantCol       int
+		wantOK        bool
+	}{
+		{"same line", 0, 0, "bar", true, 0, 4, true},
+		{"next line", 0, 5, "bar", true, 2, 4, true},
+		{"rune columns", 1, 0, "bar", true, 2, 4, true},
+		{"case insensitive", 0, 5, "bar", false, 1, 0, true},
+		{"wraps past EOF", 2, 5, "foo", true, 0, 0, true},
+		{"wraps to earlier on start line", 0, 1, "foo", true, 0, 0, true},
+		{"no match", 0, 0, "qux", true, 0, 0, false},
+		{"empty query", 0, 0, "", true, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		line, col, ok := FindNext(lines, tt.fromLine, tt.fromCol, tt.query, tt.caseSensitive)
+		if ok != tt.wantOK || line != tt.wantLine || col != tt.wantCol {
+			t.Errorf("%s: FindNext(%d, %d, %q) = (%d, %d, %v), want (%d, %d, %v)",
+				tt.name, tt.fromLine, tt.fromCol, tt.query, line, col, ok, tt.wantLine, tt.wantCol, tt.wantOK)
+		}
+	}
+}
+
+func TestFindPrev(t *testing.T) {
+	lines := []string{
+		"foo bar bar",
+		"Bar baz",
+		"日本語 bar",
+	}
+
+	tests := []struct {
+		name          string
+		fromLine      int
+		fromCol       int
+		query         string
+		caseSensitive bool
+		wantLine      int
+		wantCol       int
+		wantOK        bool
+	}{
+		{"same line", 0, 11, "bar", true, 0, 8, true},
+		{"earlier on same line", 0, 8, "bar", true, 0, 4, true},
+		{"previous line case insensitive", 2, 4, "bar", false, 1, 0, true},
+		{"wraps past start", 0, 0, "bar", true, 2, 4, true},
+		{"no match", 1, 0, "qux", false, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		line, col, ok := FindPrev(lines, tt.fromLine, tt.fromCol, tt.query, tt.caseSensitive)
+		if ok != tt.wantOK || line != tt.wantLine || col != tt.wantCol {
+			t.Errorf("%s: FindPrev(%d, %d, %q) = (%d, %d, %v), want (%d, %d, %v)",
+				tt.name, tt.fromLine, tt.fromCol, tt.query, line, col, ok, tt.wantLine, tt.wantCol, tt.wantOK)
+		}
+	}
+}
+
+func TestFindNextRegex(t *testing.T) {
+	lines := []string{
+		"abxc",
+		"日本 x123",
+		"nothing",
+	}
+
+	tests := []struct {
+		name   string
+		from   Position
+		expr   string
+		want   Range
+		wantOK bool
+	}{
+		{"simple", Position{0, 0}, `x`, Range{Position{0, 2}, Position{0, 3}}, true},
+		{"skips zero-width matches", Position{0, 0}, `x*`, Range{Position{0, 2}, Position{0, 3}}, true},
+		{"rune columns", Position{1, 0}, `x\d+`, Range{Position{1, 3}, Position{1, 7}}, true},
+		{"wraps past EOF", Position{2, 0}, `b`, Range{Position{0, 1}, Position{0, 2}}, true},
+		{"no match", Position{0, 0}, `zzz`, Range{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := FindNextRegex(lines, tt.from, regexp.MustCompile(tt.expr))
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("%s: FindNextRegex(%v, %q) = (%v, %v), want (%v, %v)",
+				tt.name, tt.from, tt.expr, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		expr      string
+		repl      string
+		want      []string
+		wantCount int
+	}{
+		{
+			"grouped replacement",
+			[]string{"john smith", "jane doe"},
+			`(\w+) (\w+)`,
+			"$2, $1",
+			[]string{"smith, john", "doe, jane"},
+			2,
+		},
+		{
+			"count across lines",
+			[]string{"a.a.a", "b", "a"},
+			`a`,
+			"x",
+			[]string{"x.x.x", "b", "x"},
+			4,
+		},
+		{
+			"zero-width matches",
+			[]string{"abc"},
+			`x*`,
+			"-",
+			[]string{"-a-b-c-"},
+			4,
+		},
+		{
+			"line start anchor",
+			[]string{"one", "two"},
+			`^`,
+			"// ",
+			[]string{"// one", "// two"},
+			2,
+		},
+		{
+			"no match",
+			[]string{"abc"},
+			`z`,
+			"y",
+			[]string{"abc"},
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		got, count := ReplaceAll(tt.lines, regexp.MustCompile(tt.expr), tt.repl)
+		if count != tt.wantCount {
+			t.Errorf("%s: ReplaceAll count = %d, want %d", tt.name, count, tt.wantCount)
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: ReplaceAll = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package editor
 
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
 // Selection represents a text selection in the buffer.
 // The selection spans from Anchor to Cursor, where Anchor is where the selection
 // started and Cursor is the current position (and can be before or after Anchor).
@@ -177,6 +182,57 @@ func (s *Selection) SelectLine(buf *Buffer, pos int) {
 	s.Cursor = end
 }
 
+// WordBoundsAt returns the rune range [start, end) of the word under col in
+// line, for double-click selection. A run of word characters (letters, digits,
+// underscore) is a word, and so is a run of punctuation, matching SelectWord.
+// Whitespace yields an empty range at col. A click past the end of the line
+// picks the word it ends with.
+func WordBoundsAt(line string, col int) (start, end int) {
+	runes := []rune(line)
+	if col < 0 {
+		col = 0
+	}
+	if col >= len(runes) {
+		if len(runes) == 0 || unicode.IsSpace(runes[len(runes)-1]) {
+			return col, col
+		}
+		col = len(runes) - 1
+	}
+
+	r := runes[col]
+	if unicode.IsSpace(r) {
+		return col, col
+	}
+	inWord := isWordChar
+	if !isWordChar(r) {
+		inWord = func(r rune) bool { return !isWordChar(r) && !unicode.IsSpace(r) }
+	}
+
+	start, end = col, col
+	for start > 0 && inWord(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && inWord(runes[end]) {
+		end++
+	}
+	return start, end
+}
+
+// LineBoundsAt returns the column range covering the whole of lines[line],
+// for triple-click selection, in the form of ui.SelectionRange. Lines followed
+// by another line select through their newline, reported as endCol -1 (end of
+// line). The last line has no newline, so its range ends at its rune length.
+func LineBoundsAt(lines []string, line int) (startCol, endCol int) {
+	if len(lines) == 0 {
+		return 0, 0
+	}
+	line = clampLine(line, len(lines))
+	if line < len(lines)-1 {
+		return 0, -1
+	}
+	return 0, utf8.RuneCountInString(lines[line])
+}
+
 // Normalize returns the selection with start <= end.
 func (s *Selection) Normalize() (start, end int) {
 	start, end = s.StartPos(), s.EndPos()
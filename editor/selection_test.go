@@ -0,0 +1,60 @@
+package editor
+
+import "testing"
+
+func TestWordBoundsAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		col       int
+		wantStart int
+		wantEnd   int
+	}{
+		{"inside a word", "foo bar_baz qux", 6, 4, 11},
+		{"start of a word", "foo bar_baz qux", 4, 4, 11},
+		{"end of a word", "foo bar_baz qux", 10, 4, 11},
+		{"digits are word chars", "x = 12345;", 6, 4, 9},
+		{"punctuation run", "a := b", 3, 2, 4},
+		{"whitespace is empty", "foo  bar", 4, 4, 4},
+		{"unicode letters", "héllo wörld", 8, 6, 11},
+		{"cjk", "日本語 text", 1, 0, 3},
+		{"past end of line", "foo bar", 20, 4, 7},
+		{"past end after space", "foo ", 9, 9, 9},
+		{"empty line", "", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		start, end := WordBoundsAt(tt.line, tt.col)
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("%s: WordBoundsAt(%q, %d) = (%d, %d), want (%d, %d)",
+				tt.name, tt.line, tt.col, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+func TestLineBoundsAt(t *testing.T) {
+	lines := []string{"first", "middle 日本", "last"}
+
+	tests := []struct {
+		name      string
+		lines     []string
+		line      int
+		wantStart int
+		wantEnd   int
+	}{
+		{"first line includes newline", lines, 0, 0, -1},
+		{"middle line includes newline", lines, 1, 0, -1},
+		{"final line ends at its length", lines, 2, 0, 4},
+		{"single line document", []string{"only 日本"}, 0, 0, 7},
+		{"line past end clamps to final line", lines, 9, 0, 4},
+		{"empty document", nil, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		start, end := LineBoundsAt(tt.lines, tt.line)
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("%s: LineBoundsAt(%d) = (%d, %d), want (%d, %d)",
+				tt.name, tt.line, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package editor
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Stats holds document counts for the document info popup.
+type Stats struct {
+	Lines int
+	Words int
+	Chars int // Runes, including newlines
+	Bytes int // UTF-8 bytes, including newlines
+
+	// Selection holds the same counts for the selected text, or nil when
+	// no selection was given.
+	Selection *Stats
+}
+
+// DocumentStats counts lines, words, characters and bytes in lines. Words
+// are runs of non-whitespace, split with Unicode-aware whitespace rules as in
+// Buffer.WordCount. If a selection range is passed, its counts are reported
+// in Stats.Selection; only the first range is used.
+func DocumentStats(lines []string, selection ...Range) Stats {
+	stats := countLines(lines)
+	if len(selection) > 0 {
+		sel := countLines(rangeLines(lines, selection[0]))
+		stats.Selection = &sel
+	}
+	return stats
+}
+
+// countLines computes Stats for lines joined with newlines.
+func countLines(lines []string) Stats {
+	if len(lines) == 0 {
+		return Stats{}
+	}
+	s := Stats{Lines: len(lines)}
+	// Newlines between lines count as characters and separate words
+	s.Chars = len(lines) - 1
+	s.Bytes = len(lines) - 1
+	for _, line := range lines {
+		s.Chars += utf8.RuneCountInString(line)
+		s.Bytes += len(line)
+		s.Words += len(strings.FieldsFunc(line, isWordSeparator))
+	}
+	return s
+}
+
+// rangeLines returns the text covered by r as lines, clamped to the document.
+func rangeLines(lines []string, r Range) []string {
+	start, end := r.Start, r.End
+	if end.Line < start.Line || (end.Line == start.Line && end.Col < start.Col) {
+		start, end = end, start
+	}
+	if len(lines) == 0 || start == end {
+		return nil
+	}
+	start.Line = clampLine(start.Line, len(lines))
+	end.Line = clampLine(end.Line, len(lines))
+
+	out := make([]string, 0, end.Line-start.Line+1)
+	for i := start.Line; i <= end.Line; i++ {
+		line := lines[i]
+		lo, hi := 0, len(line)
+		if i == start.Line {
+			lo = runeColToByte(line, start.Col)
+		}
+		if i == end.Line {
+			hi = runeColToByte(line, end.Col)
+		}
+		out = append(out, line[lo:hi])
+	}
+	return out
+}
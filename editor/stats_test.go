@@ -0,0 +1,56 @@
+package editor
+
+import "testing"
+
+func TestDocumentStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  Stats
+	}{
+		{"nil document", nil, Stats{}},
+		{"empty document", []string{""}, Stats{Lines: 1}},
+		{"single line", []string{"hello world"}, Stats{Lines: 1, Words: 2, Chars: 11, Bytes: 11}},
+		{
+			"multi-line with unicode",
+			[]string{"日本語 text", "", "  two\twords  "},
+			Stats{Lines: 3, Words: 4, Chars: 23, Bytes: 29},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := DocumentStats(tt.lines); got != tt.want {
+			t.Errorf("%s: DocumentStats = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDocumentStatsSelection(t *testing.T) {
+	lines := []string{"one two three", "日本語 four", "five"}
+
+	tests := []struct {
+		name string
+		sel  Range
+		want Stats
+	}{
+		{"within a line", Range{Position{0, 4}, Position{0, 13}}, Stats{Lines: 1, Words: 2, Chars: 9, Bytes: 9}},
+		{"across lines", Range{Position{0, 8}, Position{1, 3}}, Stats{Lines: 2, Words: 2, Chars: 9, Bytes: 15}},
+		{"reversed range", Range{Position{1, 3}, Position{0, 8}}, Stats{Lines: 2, Words: 2, Chars: 9, Bytes: 15}},
+		{"empty selection", Range{Position{2, 1}, Position{2, 1}}, Stats{}},
+	}
+
+	for _, tt := range tests {
+		got := DocumentStats(lines, tt.sel)
+		if got.Selection == nil {
+			t.Errorf("%s: Selection = nil, want %+v", tt.name, tt.want)
+			continue
+		}
+		if *got.Selection != tt.want {
+			t.Errorf("%s: Selection = %+v, want %+v", tt.name, *got.Selection, tt.want)
+		}
+	}
+
+	if got := DocumentStats(lines); got.Selection != nil {
+		t.Errorf("without a selection: Selection = %+v, want nil", *got.Selection)
+	}
+}
@@ -0,0 +1,101 @@
+package editor
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CaseMode selects how TransformCase changes letters.
+type CaseMode int
+
+const (
+	CaseUpper  CaseMode = iota // ALL UPPER CASE
+	CaseLower                  // all lower case
+	CaseTitle                  // First Letter Of Each Word
+	CaseToggle                 // sWAP eACH lETTER
+)
+
+// TransformCase changes the case of the letters in text. Title case
+// capitalizes the first letter of each word and lowercases the rest; an
+// apostrophe between letters does not start a new word, so "don't" becomes
+// "Don't".
+func TransformCase(text string, mode CaseMode) string {
+	switch mode {
+	case CaseUpper:
+		return strings.ToUpper(text)
+	case CaseLower:
+		return strings.ToLower(text)
+	case CaseToggle:
+		return strings.Map(func(r rune) rune {
+			if unicode.IsUpper(r) {
+				return unicode.ToLower(r)
+			}
+			return unicode.ToUpper(r)
+		}, text)
+	case CaseTitle:
+		runes := []rune(text)
+		for i, r := range runes {
+			if startsWord(runes, i) {
+				runes[i] = unicode.ToTitle(r)
+			} else {
+				runes[i] = unicode.ToLower(r)
+			}
+		}
+		return string(runes)
+	}
+	return text
+}
+
+// startsWord reports whether runes[i] begins a word for title case.
+func startsWord(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := runes[i-1]
+	if prev == '\'' || prev == '’' {
+		// Part of a contraction when the apostrophe follows a letter
+		return i < 2 || !unicode.IsLetter(runes[i-2])
+	}
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
+
+// nextCaseMode picks the mode Alt+U applies next, cycling selected text
+// from lower to title to upper case and back to lower.
+func nextCaseMode(text string) CaseMode {
+	switch text {
+	case strings.ToUpper(text):
+		return CaseLower
+	case strings.ToLower(text):
+		return CaseTitle
+	}
+	return CaseUpper
+}
+
+// cycleSelectionCase changes the case of the selected text, keeping it
+// selected so repeated presses cycle through upper, lower and title case.
+func (e *Editor) cycleSelectionCase() {
+	doc := e.activeDoc()
+	if !doc.selection.Active || doc.selection.IsEmpty() || !e.checkEditable() {
+		return
+	}
+	start, end := doc.selection.Normalize()
+	text := doc.buffer.Substring(start, end)
+	changed := TransformCase(text, nextCaseMode(text))
+	if changed == text {
+		return
+	}
+
+	doc.buffer.Replace(start, end, changed)
+	doc.undoStack.Push(&UndoEntry{
+		Position:     start,
+		Deleted:      text,
+		Inserted:     changed,
+		CursorBefore: doc.cursor.ByteOffset(),
+		CursorAfter:  start + len(changed),
+	})
+	doc.modified = true
+	// Case changes can alter byte lengths (e.g. "ſ" to "S")
+	doc.selection.Anchor = start
+	doc.selection.Cursor = start + len(changed)
+	doc.cursor.SetByteOffset(start + len(changed))
+}
@@ -0,0 +1,68 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cornish/textivus-editor/config"
+)
+
+func TestTransformCase(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		mode CaseMode
+		want string
+	}{
+		{"upper", "Hello wOrld", CaseUpper, "HELLO WORLD"},
+		{"upper non-ASCII", "naïve émile", CaseUpper, "NAÏVE ÉMILE"},
+		{"lower", "Hello wOrld", CaseLower, "hello world"},
+		{"lower non-ASCII", "ÀÉÎ ΣΟΦΙΑ", CaseLower, "àéî σοφια"},
+		{"title", "the QUICK brown-fox", CaseTitle, "The Quick Brown-Fox"},
+		{"title keeps contractions", "don't stop, it's fine", CaseTitle, "Don't Stop, It's Fine"},
+		{"title after a quote", "say 'hello there'", CaseTitle, "Say 'Hello There'"},
+		{"title non-ASCII", "élan vital über", CaseTitle, "Élan Vital Über"},
+		{"title digits join words", "3rd place", CaseTitle, "3rd Place"},
+		{"toggle", "Hello wOrld", CaseToggle, "hELLO WoRLD"},
+		{"toggle non-ASCII", "Ünïcode", CaseToggle, "üNÏCODE"},
+	}
+
+	for _, tt := range tests {
+		if got := TransformCase(tt.text, tt.mode); got != tt.want {
+			t.Errorf("%s: TransformCase(%q) = %q, want %q", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCycleSelectionCase(t *testing.T) {
+	e := New()
+	e.insertText("say hello world")
+	doc := e.activeDoc()
+	doc.selection.Start(4)
+	doc.selection.Update(15)
+
+	altU := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}, Alt: true}
+	for _, want := range []string{"say Hello World", "say HELLO WORLD", "say hello world", "say Hello World"} {
+		e.Update(altU)
+		if got := doc.buffer.String(); got != want {
+			t.Fatalf("after alt+u: %q, want %q", got, want)
+		}
+	}
+	if got := doc.selection.GetText(doc.buffer); got != "Hello World" {
+		t.Errorf("selection = %q, want it kept on the changed text", got)
+	}
+}
+
+func TestCycleCaseRebound(t *testing.T) {
+	e := New()
+	e.keybindings.SetBinding("cycle_case", config.KeyBinding{Primary: "f8"})
+	e.insertText("hello")
+	doc := e.activeDoc()
+	doc.selection.Start(0)
+	doc.selection.Update(5)
+
+	e.Update(tea.KeyMsg{Type: tea.KeyF8})
+	if got := doc.buffer.String(); got != "Hello" {
+		t.Errorf("after f8: %q", got)
+	}
+}
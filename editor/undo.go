@@ -69,6 +69,11 @@ func (u *UndoStack) shouldMerge(entry *UndoEntry) bool {
 
 	last := u.undoStack[len(u.undoStack)-1]
 
+	// BreakMerge forces a group boundary
+	if u.lastChange.IsZero() {
+		return false
+	}
+
 	// Check if within grouping interval
 	if time.Since(last.Timestamp) > u.groupingInterval {
 		return false
@@ -177,3 +182,33 @@ func (u *UndoStack) BreakMerge() {
 func (u *UndoStack) SetGroupingInterval(d time.Duration) {
 	u.groupingInterval = d
 }
+
+// Revert undoes the entry's change in buf and returns the cursor position
+// to restore.
+func (entry *UndoEntry) Revert(buf *Buffer) int {
+	if entry.Inserted != "" {
+		// Was an insertion - delete it
+		buf.Replace(entry.Position, entry.Position+len(entry.Inserted), "")
+	}
+	if entry.Deleted != "" {
+		// Was a deletion - insert it back
+		buf.MoveCursor(entry.Position)
+		buf.Insert(entry.Deleted)
+	}
+	return entry.CursorBefore
+}
+
+// Apply replays the entry's change in buf and returns the cursor position
+// to restore.
+func (entry *UndoEntry) Apply(buf *Buffer) int {
+	if entry.Deleted != "" {
+		// Was a deletion - delete it again
+		buf.Replace(entry.Position, entry.Position+len(entry.Deleted), "")
+	}
+	if entry.Inserted != "" {
+		// Was an insertion - insert it again
+		buf.MoveCursor(entry.Position)
+		buf.Insert(entry.Inserted)
+	}
+	return entry.CursorAfter
+}
@@ -0,0 +1,129 @@
+package editor
+
+import (
+	"testing"
+	"time"
+)
+
+// typeText inserts s one character at a time at the end of buf, pushing an
+// undo entry per keystroke the way the editor does.
+func typeText(buf *Buffer, u *UndoStack, s string) {
+	for _, r := range s {
+		pos := buf.Length()
+		buf.MoveCursor(pos)
+		buf.InsertRune(r)
+		u.Push(&UndoEntry{
+			Position:     pos,
+			Inserted:     string(r),
+			CursorBefore: pos,
+			CursorAfter:  buf.Length(),
+		})
+	}
+}
+
+func TestUndoRestoresTextAndCursor(t *testing.T) {
+	buf := NewBufferFromString("x")
+	u := NewUndoStack(100)
+	typeText(buf, u, "hello")
+
+	entry := u.Undo()
+	if entry == nil {
+		t.Fatal("Undo() = nil, want entry")
+	}
+	cursor := entry.Revert(buf)
+	if got := buf.String(); got != "x" {
+		t.Errorf("after undo: text = %q, want %q", got, "x")
+	}
+	if cursor != 1 {
+		t.Errorf("after undo: cursor = %d, want 1", cursor)
+	}
+	if u.CanUndo() {
+		t.Error("after undo: CanUndo() = true, want false")
+	}
+}
+
+func TestRedoReappliesEdit(t *testing.T) {
+	buf := NewBuffer()
+	u := NewUndoStack(100)
+	typeText(buf, u, "abc")
+
+	u.Undo().Revert(buf)
+	entry := u.Redo()
+	if entry == nil {
+		t.Fatal("Redo() = nil, want entry")
+	}
+	cursor := entry.Apply(buf)
+	if got := buf.String(); got != "abc" {
+		t.Errorf("after redo: text = %q, want %q", got, "abc")
+	}
+	if cursor != 3 {
+		t.Errorf("after redo: cursor = %d, want 3", cursor)
+	}
+	if u.CanRedo() {
+		t.Error("after redo: CanRedo() = true, want false")
+	}
+}
+
+func TestUndoDeletionRestoresText(t *testing.T) {
+	buf := NewBufferFromString("hello")
+	u := NewUndoStack(100)
+
+	// Two backspaces at the end
+	for i := 0; i < 2; i++ {
+		pos := buf.Length()
+		buf.MoveCursor(pos)
+		deleted := buf.DeleteRuneBefore()
+		u.Push(&UndoEntry{
+			Position:     pos - len(deleted),
+			Deleted:      deleted,
+			CursorBefore: pos,
+			CursorAfter:  pos - len(deleted),
+		})
+	}
+	if got := buf.String(); got != "hel" {
+		t.Fatalf("after backspace: text = %q, want %q", got, "hel")
+	}
+
+	cursor := u.Undo().Revert(buf)
+	if got := buf.String(); got != "hello" {
+		t.Errorf("after undo: text = %q, want %q", got, "hello")
+	}
+	if cursor != 5 {
+		t.Errorf("after undo: cursor = %d, want 5", cursor)
+	}
+}
+
+func TestUndoCoalescing(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		breakAt   int // Call BreakMerge before this index (-1 = never)
+		interval  time.Duration
+		wantCount int
+	}{
+		{"consecutive chars merge", "hello", -1, time.Second, 1},
+		{"space starts a new group", "ab cd", -1, time.Second, 2},
+		{"BreakMerge starts a new group", "abcd", 2, time.Second, 2},
+		{"expired interval never merges", "abc", -1, -1, 3},
+	}
+
+	for _, tt := range tests {
+		buf := NewBuffer()
+		u := NewUndoStack(100)
+		u.SetGroupingInterval(tt.interval)
+		for i, r := range tt.text {
+			if i == tt.breakAt {
+				u.BreakMerge()
+			}
+			typeText(buf, u, string(r))
+		}
+
+		count := 0
+		for u.Undo() != nil {
+			count++
+		}
+		if count != tt.wantCount {
+			t.Errorf("%s: %d undo groups, want %d", tt.name, count, tt.wantCount)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package editor
+
+import "unicode"
+
+// WordMotion moves between word boundaries within a single line. Runs of
+// letters, digits and underscores are words, runs of any other non-space
+// characters (punctuation) are words of their own, and whitespace is skipped.
+// Columns are rune indices.
+type WordMotion struct {
+	// CamelCase additionally splits words at lower-to-upper case changes,
+	// so "parseHTTPRequest" stops at "parse", "HTTP" and "Request".
+	CamelCase bool
+}
+
+// NextWordStart returns the column of the next word start after col, or the
+// end of the line if there is none. Equivalent to WordMotion{}.NextWordStart.
+func NextWordStart(line string, col int) int {
+	return WordMotion{}.NextWordStart(line, col)
+}
+
+// PrevWordStart returns the column of the closest word start before col, or 0.
+func PrevWordStart(line string, col int) int {
+	return WordMotion{}.PrevWordStart(line, col)
+}
+
+// NextWordEnd returns the column just past the end of the next word ending
+// after col, or the end of the line.
+func NextWordEnd(line string, col int) int {
+	return WordMotion{}.NextWordEnd(line, col)
+}
+
+// NextWordStart returns the column of the next word start after col.
+func (m WordMotion) NextWordStart(line string, col int) int {
+	runes := []rune(line)
+	for i := max(col+1, 1); i < len(runes); i++ {
+		if m.isWordStart(runes, i) {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+// PrevWordStart returns the column of the closest word start before col.
+func (m WordMotion) PrevWordStart(line string, col int) int {
+	runes := []rune(line)
+	for i := min(col, len(runes)) - 1; i > 0; i-- {
+		if m.isWordStart(runes, i) {
+			return i
+		}
+	}
+	return 0
+}
+
+// NextWordEnd returns the column just past the end of the next word ending
+// after col.
+func (m WordMotion) NextWordEnd(line string, col int) int {
+	runes := []rune(line)
+	for i := max(col+1, 1); i < len(runes); i++ {
+		if m.isWordEnd(runes, i) {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+// isWordStart reports whether a word begins at runes[i].
+func (m WordMotion) isWordStart(runes []rune, i int) bool {
+	if wordClass(runes[i]) == wordClassSpace {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	return m.isBoundary(runes, i)
+}
+
+// isWordEnd reports whether a word ends just before runes[i].
+func (m WordMotion) isWordEnd(runes []rune, i int) bool {
+	if wordClass(runes[i-1]) == wordClassSpace {
+		return false
+	}
+	return m.isBoundary(runes, i)
+}
+
+// isBoundary reports whether runes[i-1] and runes[i] belong to different words.
+func (m WordMotion) isBoundary(runes []rune, i int) bool {
+	prev, cur := runes[i-1], runes[i]
+	if wordClass(prev) != wordClass(cur) {
+		return true
+	}
+	if !m.CamelCase || wordClass(cur) != wordClassWord {
+		return false
+	}
+	// fooBar: a capital after a lowercase letter starts a new word
+	if unicode.IsLower(prev) && unicode.IsUpper(cur) {
+		return true
+	}
+	// HTTPServer: the last capital of an acronym starts the next word
+	return unicode.IsUpper(prev) && unicode.IsUpper(cur) &&
+		i+1 < len(runes) && unicode.IsLower(runes[i+1])
+}
+
+const (
+	wordClassSpace = iota
+	wordClassWord
+	wordClassPunct
+)
+
+// wordClass groups runes for word motion.
+func wordClass(r rune) int {
+	switch {
+	case unicode.IsSpace(r):
+		return wordClassSpace
+	case isWordChar(r):
+		return wordClassWord
+	default:
+		return wordClassPunct
+	}
+}
@@ -0,0 +1,59 @@
+package editor
+
+import "testing"
+
+func TestWordMotion(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		col       int
+		camelCase bool
+		wantNext  int
+		wantPrev  int
+		wantEnd   int
+	}{
+		{"plain words", "foo bar baz", 4, false, 8, 0, 7},
+		{"punctuation run is its own word", "foo.->bar", 0, false, 3, 0, 3},
+		{"from punctuation to next word", "foo.->bar", 3, false, 6, 0, 6},
+		{"leading whitespace", "   foo", 0, false, 3, 0, 6},
+		{"trailing whitespace", "foo   ", 0, false, 6, 0, 3},
+		{"prev skips whitespace", "foo   bar", 6, false, 9, 0, 9},
+		{"camelCase off", "parseHTTPRequest x", 0, false, 17, 0, 16},
+		{"camelCase splits lower to upper", "parseHTTPRequest x", 0, true, 5, 0, 5},
+		{"camelCase keeps acronym together", "parseHTTPRequest x", 5, true, 9, 0, 9},
+		{"camelCase prev", "parseHTTPRequest x", 12, true, 17, 9, 16},
+		{"unicode letters", "日本語 test", 0, false, 4, 0, 3},
+		{"end of line", "foo", 3, false, 3, 0, 3},
+	}
+
+	for _, tt := range tests {
+		m := WordMotion{CamelCase: tt.camelCase}
+		if got := m.NextWordStart(tt.line, tt.col); got != tt.wantNext {
+			t.Errorf("%s: NextWordStart(%q, %d) = %d, want %d", tt.name, tt.line, tt.col, got, tt.wantNext)
+		}
+		if got := m.PrevWordStart(tt.line, tt.col); got != tt.wantPrev {
+			t.Errorf("%s: PrevWordStart(%q, %d) = %d, want %d", tt.name, tt.line, tt.col, got, tt.wantPrev)
+		}
+		if got := m.NextWordEnd(tt.line, tt.col); got != tt.wantEnd {
+			t.Errorf("%s: NextWordEnd(%q, %d) = %d, want %d", tt.name, tt.line, tt.col, got, tt.wantEnd)
+		}
+	}
+}
+
+func TestCursorWordMovementCrossesLines(t *testing.T) {
+	buf := NewBufferFromString("foo.bar\n  baz")
+	c := NewCursor(buf)
+
+	for i, want := range []int{3, 4, 7, 10, 13} {
+		c.MoveWordRight()
+		if got := c.ByteOffset(); got != want {
+			t.Fatalf("MoveWordRight step %d: pos = %d, want %d", i, got, want)
+		}
+	}
+	for i, want := range []int{10, 8, 4, 3, 0} {
+		c.MoveWordLeft()
+		if got := c.ByteOffset(); got != want {
+			t.Fatalf("MoveWordLeft step %d: pos = %d, want %d", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package editorconfig reads .editorconfig files (https://editorconfig.org)
+// and resolves the settings that apply to a file.
+package editorconfig
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FileName is the name of the files Resolve looks for
+const FileName = ".editorconfig"
+
+// Settings are the supported properties that apply to one file. Zero
+// values mean the property was not set.
+type Settings struct {
+	IndentStyle            string // "tab" or "space"
+	IndentSize             int    // Columns per indentation level
+	TabWidth               int    // Columns a tab character spans
+	EndOfLine              string // "lf", "crlf" or "cr"
+	InsertFinalNewline     *bool
+	TrimTrailingWhitespace *bool
+}
+
+// file is one parsed .editorconfig
+type file struct {
+	dir      string // Directory the file is in; globs are relative to it
+	root     bool
+	sections []section
+}
+
+type section struct {
+	glob  string
+	props map[string]string
+}
+
+// Resolve returns the settings for filename from the .editorconfig files in
+// its directory and each parent, stopping at one with root = true. Files
+// nearer to filename win, as do later sections within a file. A missing
+// file is not an error; an unreadable one is.
+func Resolve(filename string) (Settings, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	// Collect files from the nearest upward, then apply farthest first
+	var files []*file
+	for dir := filepath.Dir(abs); ; {
+		f, err := parseFile(filepath.Join(dir, FileName))
+		if err != nil && !os.IsNotExist(err) {
+			return Settings{}, err
+		}
+		if f != nil {
+			files = append(files, f)
+			if f.root {
+				break
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	props := make(map[string]string)
+	for i := len(files) - 1; i >= 0; i-- {
+		f := files[i]
+		rel, err := filepath.Rel(f.dir, abs)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, s := range f.sections {
+			if matchGlob(s.glob, rel) {
+				for k, v := range s.props {
+					props[k] = v
+				}
+			}
+		}
+	}
+	return settingsFrom(props), nil
+}
+
+// parseFile reads an .editorconfig. Keys and values are lowercased; globs
+// keep their case.
+func parseFile(path string) (*file, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	f := &file{dir: filepath.Dir(path)}
+	var cur *section
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			f.sections = append(f.sections, section{glob: line[1 : len(line)-1], props: make(map[string]string)})
+			cur = &f.sections[len(f.sections)-1]
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		if cur == nil {
+			// Preamble: only root is meaningful
+			if key == "root" {
+				f.root = value == "true"
+			}
+			continue
+		}
+		cur.props[key] = value
+	}
+	return f, scanner.Err()
+}
+
+// settingsFrom converts raw properties, ignoring unknown or invalid values
+func settingsFrom(props map[string]string) Settings {
+	var s Settings
+	switch v := props["indent_style"]; v {
+	case "tab", "space":
+		s.IndentStyle = v
+	}
+	if n, err := strconv.Atoi(props["tab_width"]); err == nil && n > 0 {
+		s.TabWidth = n
+	}
+	switch v := props["indent_size"]; v {
+	case "tab":
+		s.IndentSize = s.TabWidth
+	default:
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			s.IndentSize = n
+		}
+	}
+	// tab_width defaults to indent_size when only the latter is given
+	if s.TabWidth == 0 {
+		s.TabWidth = s.IndentSize
+	}
+	switch v := props["end_of_line"]; v {
+	case "lf", "crlf", "cr":
+		s.EndOfLine = v
+	}
+	s.InsertFinalNewline = parseBool(props["insert_final_newline"])
+	s.TrimTrailingWhitespace = parseBool(props["trim_trailing_whitespace"])
+	return s
+}
+
+func parseBool(v string) *bool {
+	switch v {
+	case "true":
+		b := true
+		return &b
+	case "false":
+		b := false
+		return &b
+	}
+	return nil
+}
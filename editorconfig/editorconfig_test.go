@@ -0,0 +1,129 @@
+package editorconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeTree writes files (slash-separated path to content) under a new temp
+// directory and returns its path.
+func makeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestResolve(t *testing.T) {
+	root := makeTree(t, map[string]string{
+		".editorconfig": `# top-most
+root = true
+
+[*]
+indent_style = space
+indent_size = 4
+end_of_line = lf
+insert_final_newline = true
+
+[*.go]
+indent_style = tab
+tab_width = 8
+
+[Makefile]
+indent_style = tab
+
+[{docs,notes}/**.md]
+trim_trailing_whitespace = false
+
+[test{1..3}.txt]
+indent_size = 2
+`,
+		"sub/.editorconfig": `
+[*]
+indent_size = 2
+TRIM_TRAILING_WHITESPACE = TRUE
+
+[*.go]
+tab_width = 3
+`,
+	})
+	yes, no := true, false
+
+	tests := []struct {
+		name string
+		path string
+		want Settings
+	}{
+		{"root defaults", "main.py",
+			Settings{IndentStyle: "space", IndentSize: 4, TabWidth: 4, EndOfLine: "lf", InsertFinalNewline: &yes}},
+		{"extension glob", "cmd/main.go",
+			Settings{IndentStyle: "tab", IndentSize: 4, TabWidth: 8, EndOfLine: "lf", InsertFinalNewline: &yes}},
+		{"bare name matches at any depth", "a/b/Makefile",
+			Settings{IndentStyle: "tab", IndentSize: 4, TabWidth: 4, EndOfLine: "lf", InsertFinalNewline: &yes}},
+		{"brace alternatives and **", "notes/2024/todo.md",
+			Settings{IndentStyle: "space", IndentSize: 4, TabWidth: 4, EndOfLine: "lf", InsertFinalNewline: &yes, TrimTrailingWhitespace: &no}},
+		{"brace glob with a slash is anchored", "x/docs/readme.md",
+			Settings{IndentStyle: "space", IndentSize: 4, TabWidth: 4, EndOfLine: "lf", InsertFinalNewline: &yes}},
+		{"numeric range", "test2.txt",
+			Settings{IndentStyle: "space", IndentSize: 2, TabWidth: 2, EndOfLine: "lf", InsertFinalNewline: &yes}},
+		{"outside numeric range", "test4.txt",
+			Settings{IndentStyle: "space", IndentSize: 4, TabWidth: 4, EndOfLine: "lf", InsertFinalNewline: &yes}},
+		{"nested file overrides root", "sub/x.py",
+			Settings{IndentStyle: "space", IndentSize: 2, TabWidth: 2, EndOfLine: "lf", InsertFinalNewline: &yes, TrimTrailingWhitespace: &yes}},
+		{"nested glob overrides root glob", "sub/pkg/x.go",
+			Settings{IndentStyle: "tab", IndentSize: 2, TabWidth: 3, EndOfLine: "lf", InsertFinalNewline: &yes, TrimTrailingWhitespace: &yes}},
+	}
+
+	for _, tt := range tests {
+		got, err := Resolve(filepath.Join(root, filepath.FromSlash(tt.path)))
+		if err != nil {
+			t.Fatalf("%s: Resolve: %v", tt.name, err)
+		}
+		if !equalSettings(got, tt.want) {
+			t.Errorf("%s: Resolve(%q) = %s, want %s", tt.name, tt.path, format(got), format(tt.want))
+		}
+	}
+}
+
+func TestResolveStopsAtRoot(t *testing.T) {
+	root := makeTree(t, map[string]string{
+		".editorconfig":        "[*]\nindent_style = tab\n",
+		"proj/.editorconfig":   "root = true\n[*]\nindent_size = 2\n",
+		"proj/src/placeholder": "",
+	})
+	got, err := Resolve(filepath.Join(root, "proj", "src", "a.c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.IndentStyle != "" || got.IndentSize != 2 {
+		t.Errorf("Resolve = %s, want only indent_size from the root file", format(got))
+	}
+}
+
+func equalSettings(a, b Settings) bool {
+	eqBool := func(x, y *bool) bool { return (x == nil) == (y == nil) && (x == nil || *x == *y) }
+	return a.IndentStyle == b.IndentStyle && a.IndentSize == b.IndentSize && a.TabWidth == b.TabWidth &&
+		a.EndOfLine == b.EndOfLine && eqBool(a.InsertFinalNewline, b.InsertFinalNewline) &&
+		eqBool(a.TrimTrailingWhitespace, b.TrimTrailingWhitespace)
+}
+
+func format(s Settings) string {
+	b := func(p *bool) string {
+		if p == nil {
+			return "unset"
+		}
+		return fmt.Sprint(*p)
+	}
+	return fmt.Sprintf("{style=%q size=%d tab=%d eol=%q final=%s trim=%s}",
+		s.IndentStyle, s.IndentSize, s.TabWidth, s.EndOfLine, b(s.InsertFinalNewline), b(s.TrimTrailingWhitespace))
+}
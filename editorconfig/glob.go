@@ -0,0 +1,114 @@
+package editorconfig
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// numRange is a {n1..n2} group in a glob, checked after the regexp matches
+type numRange struct{ lo, hi int }
+
+var numRangeRe = regexp.MustCompile(`^\{(-?\d+)\.\.(-?\d+)\}`)
+
+// matchGlob reports whether rel, a slash-separated path relative to the
+// .editorconfig's directory, matches an EditorConfig section glob. A glob
+// without a slash matches file names at any depth.
+func matchGlob(glob, rel string) bool {
+	re, ranges := compileGlob(glob)
+	if re == nil {
+		return false
+	}
+	m := re.FindStringSubmatch(rel)
+	if m == nil {
+		return false
+	}
+	for i, r := range ranges {
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil || n < r.lo || n > r.hi {
+			return false
+		}
+	}
+	return true
+}
+
+// compileGlob translates glob into an anchored regexp, or nil if glob is
+// malformed. Each {n1..n2} range becomes a capture group, returned in order
+// so matchGlob can check it.
+func compileGlob(glob string) (*regexp.Regexp, []numRange) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	switch {
+	case strings.HasPrefix(glob, "/"):
+		glob = glob[1:]
+	case !strings.Contains(glob, "/"):
+		sb.WriteString("(?:.*/)?")
+	}
+
+	var ranges []numRange
+	braceDepth := 0
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '\\' && i+1 < len(glob):
+			_, size := utf8.DecodeRuneInString(glob[i+1:])
+			sb.WriteString(regexp.QuoteMeta(glob[i+1 : i+1+size]))
+			i += size
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			i++
+			sb.WriteString(".*")
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '{':
+			if m := numRangeRe.FindStringSubmatch(glob[i:]); m != nil {
+				lo, _ := strconv.Atoi(m[1])
+				hi, _ := strconv.Atoi(m[2])
+				if lo > hi {
+					lo, hi = hi, lo
+				}
+				ranges = append(ranges, numRange{lo, hi})
+				sb.WriteString(`([+-]?\d+)`)
+				i += len(m[0]) - 1
+				continue
+			}
+			if strings.IndexByte(glob[i:], '}') < 0 {
+				sb.WriteString(`\{`)
+				continue
+			}
+			braceDepth++
+			sb.WriteString("(?:")
+		case c == '}' && braceDepth > 0:
+			braceDepth--
+			sb.WriteString(")")
+		case c == ',' && braceDepth > 0:
+			sb.WriteString("|")
+		default:
+			// Copy the whole rune, so names outside ASCII match
+			_, size := utf8.DecodeRuneInString(glob[i:])
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+size]))
+			i += size - 1
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, nil
+	}
+	return re, ranges
+}
@@ -0,0 +1,41 @@
+package editorconfig
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob string
+		rel  string
+		want bool
+	}{
+		{"*", "a.go", true},
+		{"*", "dir/a.go", true},
+		{"*.go", "dir/sub/a.go", true},
+		{"*.go", "a.go.txt", false},
+		{"dir/*.go", "dir/a.go", true},
+		{"dir/*.go", "dir/sub/a.go", false},
+		{"dir/**.go", "dir/sub/a.go", true},
+		{"/a.go", "a.go", true},
+		{"/a.go", "dir/a.go", false},
+		{"?.c", "x.c", true},
+		{"?.c", "xy.c", false},
+		{"[abc].txt", "b.txt", true},
+		{"[!abc].txt", "b.txt", false},
+		{"*.{js,ts}", "src/app.ts", true},
+		{"*.{js,ts}", "src/app.go", false},
+		{"v{1..10}.log", "v10.log", true},
+		{"v{1..10}.log", "v11.log", false},
+		{`a\*b`, "a*b", true},
+		{`a\*b`, "axb", false},
+		{"*.café", "menu.café", true},
+		{"日本/*.txt", "日本/a.txt", true},
+		{"日本/*.txt", "日付/a.txt", false},
+		{`\é.md`, "é.md", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.glob, tt.rel); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.glob, tt.rel, got, tt.want)
+		}
+	}
+}
@@ -192,6 +192,13 @@ func Detect(data []byte) *DetectionResult {
 		return result
 	}
 
+	// UTF-16 without a BOM; its NUL bytes would pass as UTF-8
+	if utf16 := DetectUTF16(data); utf16 != nil {
+		result.Encoding = utf16
+		result.Confidence = 80
+		return result
+	}
+
 	// Check if valid UTF-8
 	if isValidUTF8(data) {
 		result.Encoding = GetEncodingByID("utf-8")
@@ -227,6 +234,40 @@ func Detect(data []byte) *DetectionResult {
 	return result
 }
 
+// utf16SniffLen is how many leading bytes DetectUTF16 looks at
+const utf16SniffLen = 8 * 1024
+
+// DetectUTF16 recognises UTF-16 text written without a byte order mark by
+// the zero high bytes of its ASCII characters, which fall at odd offsets in
+// little-endian text and at even ones in big-endian text. It returns nil
+// unless one side is mostly zeros and the other has almost none, as binary
+// data with NUL bytes scattered on both sides does.
+func DetectUTF16(data []byte) *Encoding {
+	data = data[:min(len(data), utf16SniffLen)]
+	units := len(data) / 2
+	if units < 2 {
+		return nil
+	}
+	evenZeros, oddZeros := 0, 0
+	for i := 0; i+1 < len(data); i += 2 {
+		if data[i] == 0 {
+			evenZeros++
+		}
+		if data[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	mostly := func(n int) bool { return n*10 >= units*7 }
+	hardly := func(n int) bool { return n*100 <= units }
+	switch {
+	case mostly(oddZeros) && hardly(evenZeros):
+		return GetEncodingByID("utf-16-le")
+	case mostly(evenZeros) && hardly(oddZeros):
+		return GetEncodingByID("utf-16-be")
+	}
+	return nil
+}
+
 // isValidUTF8 checks if data is valid UTF-8
 func isValidUTF8(data []byte) bool {
 	// Check for invalid UTF-8 sequences
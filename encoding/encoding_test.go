@@ -111,6 +111,35 @@ func TestDetectBOM(t *testing.T) {
 	}
 }
 
+func TestDetectUTF16WithoutBOM(t *testing.T) {
+	le := []byte{'h', 0, 'i', 0, '\n', 0, 0xe9, 0}
+	be := []byte{0, 'h', 0, 'i', 0, '\n', 0, 0xe9}
+	tests := []struct {
+		name   string
+		data   []byte
+		wantID string
+	}{
+		{"UTF-16 LE", le, "utf-16-le"},
+		{"UTF-16 BE", be, "utf-16-be"},
+		{"NULs on both sides", []byte{0, 0, 'a', 0, 0, 'b', 0, 0}, "utf-8"},
+		{"ASCII", []byte("hello there"), "utf-8"},
+	}
+	for _, tt := range tests {
+		result := Detect(tt.data)
+		if result.Encoding.ID != tt.wantID {
+			t.Errorf("%s: Detect().Encoding.ID = %q, want %q", tt.name, result.Encoding.ID, tt.wantID)
+		}
+		if result.HasBOM {
+			t.Errorf("%s: Detect().HasBOM = true", tt.name)
+		}
+	}
+
+	decoded, err := DecodeToUTF8(le, GetEncodingByID("utf-16-le"))
+	if err != nil || string(decoded) != "hi\né" {
+		t.Errorf("DecodeToUTF8 = %q, %v, want %q", decoded, err, "hi\né")
+	}
+}
+
 func TestDecodeToUTF8(t *testing.T) {
 	tests := []struct {
 		name     string
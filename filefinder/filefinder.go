@@ -0,0 +1,130 @@
+// Package filefinder lists the files under a directory and fuzzy-matches
+// their paths, for an "open file by name" picker.
+package filefinder
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/palette"
+)
+
+// DefaultIgnore lists directory names that are never descended into.
+var DefaultIgnore = []string{".git", ".hg", ".svn", "node_modules"}
+
+// basenameBonus favors paths whose file name alone matches the query, so
+// "main" ranks cmd/main.go above main/other/file.go.
+const basenameBonus = 20
+
+// Result is a path that matched the query.
+type Result struct {
+	Path      string // Slash-separated, relative to the root
+	Score     int
+	Positions []int // Rune indices in Path of the matched characters
+}
+
+// Walk returns the slash-separated paths of all regular files under root,
+// relative to root and sorted. Directories named in ignore are skipped, as
+// are entries excluded by .gitignore files; a nested .gitignore applies to
+// its own subtree. The walk stops early with ctx.Err() if ctx is cancelled.
+func Walk(ctx context.Context, root string, ignore []string) ([]string, error) {
+	var paths []string
+	var gi gitignore
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			// Skip unreadable entries rather than failing the whole walk
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if path != root {
+				if slices.Contains(ignore, d.Name()) || gi.ignored(rel, true) {
+					return fs.SkipDir
+				}
+			} else {
+				rel = ""
+			}
+			gi.load(path, rel)
+			return nil
+		}
+		if !d.Type().IsRegular() || gi.ignored(rel, false) {
+			return nil
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Rank fuzzy-matches query against paths and returns the matches best first.
+// Matches that fall entirely within the file name score higher than ones
+// spread across directories. Ties go to the shorter path. An empty query
+// returns every path in order with no positions.
+func Rank(paths []string, query string) []Result {
+	var results []Result
+	for _, p := range paths {
+		if r, ok := matchPath(p, query); ok {
+			results = append(results, r)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return len(results[i].Path) < len(results[j].Path)
+	})
+	return results
+}
+
+// Find walks root and ranks its files against query.
+func Find(ctx context.Context, root, query string, ignore []string) ([]Result, error) {
+	paths, err := Walk(ctx, root, ignore)
+	if err != nil {
+		return nil, err
+	}
+	return Rank(paths, query), nil
+}
+
+// matchPath scores path against query, trying the file name first.
+func matchPath(path, query string) (Result, bool) {
+	if query == "" {
+		return Result{Path: path}, true
+	}
+	dir := ""
+	base := path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		dir, base = path[:i+1], path[i+1:]
+	}
+	if score, pos, ok := palette.Match(query, base); ok {
+		offset := utf8.RuneCountInString(dir)
+		for i := range pos {
+			pos[i] += offset
+		}
+		return Result{Path: path, Score: score + basenameBonus, Positions: pos}, true
+	}
+	score, pos, ok := palette.Match(query, path)
+	if !ok {
+		return Result{}, false
+	}
+	return Result{Path: path, Score: score, Positions: pos}, true
+}
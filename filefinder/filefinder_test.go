@@ -0,0 +1,115 @@
+package filefinder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// makeTree creates empty files at the given slash-separated paths under a
+// new temp directory and returns its path.
+func makeTree(t *testing.T, paths ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, p := range paths {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func resultPaths(results []Result) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.Path
+	}
+	return out
+}
+
+func TestWalkIgnoresDirectories(t *testing.T) {
+	root := makeTree(t,
+		"main.go",
+		"editor/editor.go",
+		".git/config",
+		"web/node_modules/lib/index.js",
+		"web/app.js",
+	)
+
+	got, err := Walk(context.Background(), root, DefaultIgnore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"editor/editor.go", "main.go", "web/app.js"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk = %v, want %v", got, want)
+	}
+
+	got, err = Walk(context.Background(), root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 5 {
+		t.Errorf("Walk with no ignore list = %v, want all 5 files", got)
+	}
+}
+
+func TestFindRanking(t *testing.T) {
+	root := makeTree(t,
+		"cmd/textivus/main.go",
+		"main/other/file.go",
+		"editor/editor.go",
+		"editor/editor_test.go",
+		"ui/textrenderer.go",
+		"README.md",
+	)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"main", []string{"cmd/textivus/main.go", "main/other/file.go"}},
+		{"editor", []string{"editor/editor.go", "editor/editor_test.go"}},
+		{"edtest", []string{"editor/editor_test.go"}},
+		{"uitr", []string{"ui/textrenderer.go"}},
+		{"zzz", nil},
+	}
+
+	for _, tt := range tests {
+		results, err := Find(context.Background(), root, tt.query, DefaultIgnore)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resultPaths(results); !slices.Equal(got, tt.want) {
+			t.Errorf("Find(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRankPositions(t *testing.T) {
+	results := Rank([]string{"cmd/textivus/main.go"}, "main")
+	if len(results) != 1 {
+		t.Fatalf("Rank = %v, want one result", results)
+	}
+	// Matched in the file name, so positions point past "cmd/textivus/"
+	if want := []int{13, 14, 15, 16}; !reflect.DeepEqual(results[0].Positions, want) {
+		t.Errorf("Positions = %v, want %v", results[0].Positions, want)
+	}
+}
+
+func TestWalkCancelled(t *testing.T) {
+	root := makeTree(t, "a.go", "b/c.go")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Walk(ctx, root, DefaultIgnore); err != context.Canceled {
+		t.Errorf("Walk with cancelled context: err = %v, want %v", err, context.Canceled)
+	}
+}
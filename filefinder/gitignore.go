@@ -0,0 +1,122 @@
+package filefinder
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is one pattern line from a .gitignore file.
+type ignoreRule struct {
+	base     string // Directory holding the .gitignore, relative to the root ("" for the root)
+	segments []string
+	negate   bool // "!pattern" re-includes a path
+	dirOnly  bool // "pattern/" only matches directories
+	anchored bool // Contains a slash: matched from base rather than at any depth
+}
+
+// gitignore collects the rules of every .gitignore seen during a walk.
+// Rules are kept in walk order, so a nested file's rules come after its
+// ancestors' and take precedence over them.
+type gitignore struct {
+	rules []ignoreRule
+}
+
+// load reads dir/.gitignore, if present. rel is dir relative to the root.
+func (g *gitignore) load(dir, rel string) {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	g.rules = append(g.rules, parseGitignore(rel, string(data))...)
+}
+
+// parseGitignore parses the lines of a .gitignore file located at base.
+func parseGitignore(base, data string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		r := ignoreRule{base: base}
+		if line[0] == '!' {
+			r.negate = true
+			line = line[1:]
+		} else if line[0] == '\\' {
+			// "\#" and "\!" escape a leading special character
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.segments = strings.Split(line, "/")
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// ignored reports whether the slash-separated path rel (relative to the
+// root) is excluded. The last matching rule wins.
+func (g *gitignore) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range g.rules {
+		if r.matches(rel, isDir) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the rule applies to rel.
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	sub := rel
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		sub = rel[len(r.base)+1:]
+	}
+	if !r.anchored {
+		return len(r.segments) == 1 && matchSegment(r.segments[0], path.Base(sub))
+	}
+	return matchSegments(r.segments, strings.Split(sub, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches any number of path segments, including none.
+func matchSegments(pattern, segs []string) bool {
+	if len(pattern) == 0 {
+		return len(segs) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if matchSegments(pattern[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 || !matchSegment(pattern[0], segs[0]) {
+		return false
+	}
+	return matchSegments(pattern[1:], segs[1:])
+}
+
+// matchSegment matches one path segment against a glob such as "*.go".
+func matchSegment(pattern, name string) bool {
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
@@ -0,0 +1,85 @@
+package filefinder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGitignoreRules(t *testing.T) {
+	rules := parseGitignore("", `
+# comment
+*.log
+build/
+/root-only.txt
+docs/**/*.tmp
+!keep.log
+\#hash
+`)
+	g := gitignore{rules: rules}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"deep/nested/app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"src/build", true, true},
+		{"build", false, false}, // Trailing slash only matches directories
+		{"root-only.txt", false, true},
+		{"sub/root-only.txt", false, false}, // Anchored to the .gitignore's directory
+		{"docs/a.tmp", false, true},
+		{"docs/x/y/a.tmp", false, true},
+		{"other/a.tmp", false, false},
+		{"#hash", false, true},
+		{"main.go", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := g.ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, dir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestWalkHonorsNestedGitignore(t *testing.T) {
+	root := makeTree(t,
+		"main.go",
+		"debug.log",
+		"build/out.bin",
+		"web/app.js",
+		"web/trace.log",
+		"web/dist/bundle.js",
+		"lib/util.go",
+		"lib/util.gen.go",
+	)
+	write := func(rel, content string) {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(rel)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".gitignore", "*.log\nbuild/\n*.gen.go\n")
+	// The nested file re-includes logs in its subtree and ignores dist/
+	write("web/.gitignore", "!*.log\ndist/\n")
+
+	got, err := Walk(context.Background(), root, DefaultIgnore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		".gitignore",
+		"lib/util.go",
+		"main.go",
+		"web/.gitignore",
+		"web/app.js",
+		"web/trace.log",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk = %v, want %v", got, want)
+	}
+}
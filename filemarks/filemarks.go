@@ -0,0 +1,131 @@
+// Package filemarks remembers the cursor and scroll position of each file
+// the user has edited, so reopening a file puts them back where they were.
+package filemarks
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/cornish/textivus-editor/config"
+)
+
+// DefaultCapacity is the number of files remembered before the least
+// recently used ones are evicted.
+const DefaultCapacity = 500
+
+// Mark is the remembered position in one file.
+type Mark struct {
+	Line    int `json:"line"`
+	Col     int `json:"col"` // Rune column, so it can't split a character
+	ScrollY int `json:"scroll_y"`
+}
+
+// Clamp fits the mark to a file that now has lineCount lines. A line past
+// the end moves to the last line. The column and scroll position are only
+// kept non-negative; the editor clamps them against the line length and the
+// viewport.
+func (m Mark) Clamp(lineCount int) Mark {
+	m.Line = min(max(m.Line, 0), max(lineCount-1, 0))
+	m.Col = max(m.Col, 0)
+	m.ScrollY = max(m.ScrollY, 0)
+	return m
+}
+
+// entry is one file's mark as saved to disk.
+type entry struct {
+	Path string `json:"path"`
+	Mark
+}
+
+// Store holds marks keyed by absolute path, most recently set first.
+type Store struct {
+	capacity int
+	entries  []entry
+}
+
+// New creates an empty store that keeps at most capacity files. A capacity
+// of 0 or less uses DefaultCapacity.
+func New(capacity int) *Store {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Store{capacity: capacity}
+}
+
+// DefaultPath returns the filemarks file path inside the config directory.
+func DefaultPath() (string, error) {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "filemarks.json"), nil
+}
+
+// Load reads the marks saved at path into a store of the given capacity. A
+// missing file gives an empty store.
+func Load(path string, capacity int) (*Store, error) {
+	s := New(capacity)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return s, err
+	}
+	if len(s.entries) > s.capacity {
+		s.entries = s.entries[:s.capacity]
+	}
+	return s, nil
+}
+
+// Save writes the store to path as JSON, creating the directory if needed.
+func (s *Store) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	entries := s.entries
+	if entries == nil {
+		entries = []entry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return config.AtomicWriteFile(path, append(data, '\n'), 0644)
+}
+
+// Get returns the mark remembered for path. It leaves the recency order
+// alone; Set makes a path the most recently used.
+func (s *Store) Get(path string) (Mark, bool) {
+	for _, e := range s.entries {
+		if e.Path == path {
+			return e.Mark, true
+		}
+	}
+	return Mark{}, false
+}
+
+// Set remembers mark for path, making it the most recently used. When the
+// store is full the least recently used file is forgotten.
+func (s *Store) Set(path string, mark Mark) {
+	for i, e := range s.entries {
+		if e.Path == path {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			break
+		}
+	}
+	s.entries = append([]entry{{Path: path, Mark: mark}}, s.entries...)
+	if len(s.entries) > s.capacity {
+		s.entries = s.entries[:s.capacity]
+	}
+}
+
+// Len returns the number of files remembered.
+func (s *Store) Len() int {
+	return len(s.entries)
+}
@@ -0,0 +1,101 @@
+package filemarks
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetGet(t *testing.T) {
+	s := New(10)
+	s.Set("/a.go", Mark{Line: 12, Col: 4, ScrollY: 3})
+	s.Set("/b.go", Mark{Line: 1})
+	s.Set("/a.go", Mark{Line: 20, Col: 1, ScrollY: 10})
+
+	if got, ok := s.Get("/a.go"); !ok || got != (Mark{Line: 20, Col: 1, ScrollY: 10}) {
+		t.Errorf("Get(/a.go) = %+v, %v, want the latest mark", got, ok)
+	}
+	if _, ok := s.Get("/missing.go"); ok {
+		t.Error("Get(/missing.go) found a mark")
+	}
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", s.Len())
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "filemarks.json")
+	s := New(10)
+	s.Set("/a.go", Mark{Line: 12, Col: 4, ScrollY: 3})
+	s.Set("/b.go", Mark{Line: 40, ScrollY: 30})
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := Load(path, 10)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(got.entries, s.entries) {
+		t.Errorf("Load = %+v, want %+v", got.entries, s.entries)
+	}
+
+	empty, err := Load(filepath.Join(t.TempDir(), "none.json"), 10)
+	if err != nil || empty.Len() != 0 {
+		t.Errorf("Load of a missing file = %d marks, %v; want an empty store", empty.Len(), err)
+	}
+}
+
+func TestMarkClamp(t *testing.T) {
+	tests := []struct {
+		name      string
+		mark      Mark
+		lineCount int
+		want      Mark
+	}{
+		{"in range", Mark{Line: 5, Col: 3, ScrollY: 2}, 10, Mark{Line: 5, Col: 3, ScrollY: 2}},
+		{"file got shorter", Mark{Line: 50, Col: 7, ScrollY: 40}, 10, Mark{Line: 9, Col: 7, ScrollY: 40}},
+		{"file emptied", Mark{Line: 3}, 1, Mark{Line: 0}},
+		{"negative values", Mark{Line: -1, Col: -2, ScrollY: -3}, 10, Mark{}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.mark.Clamp(tt.lineCount); got != tt.want {
+			t.Errorf("%s: Clamp(%d) = %+v, want %+v", tt.name, tt.lineCount, got, tt.want)
+		}
+	}
+}
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	s := New(2)
+	s.Set("/a.go", Mark{Line: 1})
+	s.Set("/b.go", Mark{Line: 2})
+	s.Set("/a.go", Mark{Line: 3}) // a is now the most recent
+	s.Set("/c.go", Mark{Line: 4})
+
+	if _, ok := s.Get("/b.go"); ok {
+		t.Error("least recently used /b.go was not evicted")
+	}
+	for _, path := range []string{"/a.go", "/c.go"} {
+		if _, ok := s.Get(path); !ok {
+			t.Errorf("%s was evicted", path)
+		}
+	}
+
+	// A file saved by a larger store is cut down to capacity on load
+	path := filepath.Join(t.TempDir(), "filemarks.json")
+	big := New(5)
+	for _, p := range []string{"/1", "/2", "/3", "/4"} {
+		big.Set(p, Mark{})
+	}
+	if err := big.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	small, err := Load(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := small.Get("/4"); !ok || small.Len() != 2 {
+		t.Errorf("Load with capacity 2 kept %d marks, want the 2 most recent", small.Len())
+	}
+}
@@ -0,0 +1,182 @@
+// Package grep searches the files under a directory for a pattern, for
+// project-wide search.
+package grep
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/filefinder"
+	"github.com/cornish/textivus-editor/progress"
+	"github.com/cornish/textivus-editor/textio"
+)
+
+// Options controls a search.
+type Options struct {
+	Regex         bool     // Treat the pattern as a regular expression instead of plain text
+	CaseSensitive bool     // Match case exactly
+	Include       string   // Only search files whose name matches this glob, e.g. "*.go"
+	ContextLines  int      // Lines of context to return before and after each match
+	Workers       int      // Files searched in parallel (default: number of CPUs)
+	IgnoreDirs    []string // Directory names to skip (default: filefinder.DefaultIgnore)
+
+	// Progress, if set, is told how many files have been searched out of
+	// the number to search
+	Progress progress.Progress
+}
+
+// Result is one match. Line and Col are 0-indexed, Col in runes, matching
+// the editor's positions.
+type Result struct {
+	Path   string // Slash-separated, relative to the search root
+	Line   int
+	Col    int
+	Match  string   // The matched text
+	Text   string   // The whole matching line
+	Before []string // Up to ContextLines lines before the match
+	After  []string // Up to ContextLines lines after the match
+}
+
+// Search finds every match of pattern in the files under root. See
+// SearchContext.
+func Search(root, pattern string, opts Options) ([]Result, error) {
+	return SearchContext(context.Background(), root, pattern, opts)
+}
+
+// SearchContext finds every match of pattern in the files under root,
+// skipping ignored directories, .gitignore'd files and binary files.
+// Results are ordered by path, line and column. Patterns never match across
+// lines. The search stops with ctx.Err() if ctx is cancelled.
+func SearchContext(ctx context.Context, root, pattern string, opts Options) ([]Result, error) {
+	re, err := compile(pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+	ignore := opts.IgnoreDirs
+	if ignore == nil {
+		ignore = filefinder.DefaultIgnore
+	}
+	paths, err := filefinder.Walk(ctx, root, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Include != "" {
+		var included []string
+		for _, rel := range paths {
+			if ok, _ := path.Match(opts.Include, path.Base(rel)); ok {
+				included = append(included, rel)
+			}
+		}
+		paths = included
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	prog := progress.OrNop(opts.Progress)
+	prog.Start(int64(len(paths)))
+	defer prog.Done()
+
+	jobs := make(chan string)
+	var (
+		mu      sync.Mutex
+		results []Result
+		done    int64
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rel := range jobs {
+				found := searchFile(root, rel, re, opts.ContextLines)
+				mu.Lock()
+				results = append(results, found...)
+				// Updated under the lock so counts arrive in order
+				done++
+				prog.Update(done)
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, rel := range paths {
+		select {
+		case jobs <- rel:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+	return results, nil
+}
+
+// compile turns the pattern into a regexp honoring the options.
+func compile(pattern string, opts Options) (*regexp.Regexp, error) {
+	if !opts.Regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if !opts.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// searchFile returns the matches in one file, or nil if it is unreadable or
+// binary.
+func searchFile(root, rel string, re *regexp.Regexp, contextLines int) []Result {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil || textio.IsBinary(data) {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	var results []Result
+	for i, line := range lines {
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			if loc[0] == loc[1] {
+				continue // Zero-width matches are not useful search hits
+			}
+			r := Result{
+				Path:  rel,
+				Line:  i,
+				Col:   utf8.RuneCountInString(line[:loc[0]]),
+				Match: line[loc[0]:loc[1]],
+				Text:  line,
+			}
+			if contextLines > 0 {
+				r.Before = lines[max(0, i-contextLines):i]
+				r.After = lines[i+1 : min(len(lines), i+1+contextLines)]
+			}
+			results = append(results, r)
+		}
+	}
+	return results
+}
@@ -0,0 +1,219 @@
+package grep
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// makeTree writes files (slash-separated path to content) under a new temp
+// directory and returns its path.
+func makeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+type hit struct {
+	Path  string
+	Line  int
+	Col   int
+	Match string
+}
+
+func hits(results []Result) []hit {
+	out := make([]hit, len(results))
+	for i, r := range results {
+		out[i] = hit{r.Path, r.Line, r.Col, r.Match}
+	}
+	return out
+}
+
+func testTree(t *testing.T) string {
+	return makeTree(t, map[string]string{
+		"main.go":         "package main\n\nfunc main() {\n\tprintln(\"TODO\")\n}\n",
+		"lib/util.go":     "package lib\n// todo: tidy\nfunc Helper() {}\n",
+		"notes.txt":       "日本語 TODO later\n",
+		"image.bin":       "TODO\x00\x01\x02",
+		".git/HEAD":       "TODO",
+		"vendor/x/x.go":   "// TODO vendored\n",
+		"lib/helper_test": "func Helper2() {}\n",
+	})
+}
+
+func TestSearchPlain(t *testing.T) {
+	root := testTree(t)
+
+	tests := []struct {
+		name    string
+		pattern string
+		opts    Options
+		want    []hit
+	}{
+		{
+			"case sensitive",
+			"TODO",
+			Options{CaseSensitive: true},
+			[]hit{
+				{"main.go", 3, 10, "TODO"},
+				{"notes.txt", 0, 4, "TODO"},
+				{"vendor/x/x.go", 0, 3, "TODO"},
+			},
+		},
+		{
+			"case insensitive",
+			"todo",
+			Options{},
+			[]hit{
+				{"lib/util.go", 1, 3, "todo"},
+				{"main.go", 3, 10, "TODO"},
+				{"notes.txt", 0, 4, "TODO"},
+				{"vendor/x/x.go", 0, 3, "TODO"},
+			},
+		},
+		{
+			"include glob",
+			"todo",
+			Options{Include: "*.go", IgnoreDirs: []string{".git", "vendor"}},
+			[]hit{
+				{"lib/util.go", 1, 3, "todo"},
+				{"main.go", 3, 10, "TODO"},
+			},
+		},
+		{
+			"regex",
+			`func \w+\(`,
+			Options{Regex: true, CaseSensitive: true},
+			[]hit{
+				{"lib/helper_test", 0, 0, "func Helper2("},
+				{"lib/util.go", 2, 0, "func Helper("},
+				{"main.go", 2, 0, "func main("},
+			},
+		},
+		{
+			"plain text is not a regex",
+			"main()",
+			Options{},
+			[]hit{{"main.go", 2, 5, "main()"}},
+		},
+	}
+
+	for _, tt := range tests {
+		results, err := Search(root, tt.pattern, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := hits(results); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Search(%q) = %v, want %v", tt.name, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestSearchContextLines(t *testing.T) {
+	root := makeTree(t, map[string]string{
+		"a.txt": "one\ntwo\nthree MATCH\nfour\nfive\n",
+		"b.txt": "MATCH first\nsecond\n",
+	})
+
+	results, err := Search(root, "MATCH", Options{CaseSensitive: true, ContextLines: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if got, want := results[0].Before, []string{"two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("a.txt Before = %q, want %q", got, want)
+	}
+	if got, want := results[0].After, []string{"four"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("a.txt After = %q, want %q", got, want)
+	}
+	if got := results[1].Before; len(got) != 0 {
+		t.Errorf("b.txt Before = %q, want none at start of file", got)
+	}
+	if got, want := results[1].Text, "MATCH first"; got != want {
+		t.Errorf("b.txt Text = %q, want %q", got, want)
+	}
+}
+
+func TestSearchSkipsBinary(t *testing.T) {
+	root := testTree(t)
+	results, err := Search(root, "TODO", Options{CaseSensitive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Path == "image.bin" {
+			t.Errorf("binary file searched: %+v", r)
+		}
+	}
+}
+
+func TestSearchCancelled(t *testing.T) {
+	root := testTree(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SearchContext(ctx, root, "TODO", Options{}); err != context.Canceled {
+		t.Errorf("SearchContext with cancelled context: err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSearchBadRegex(t *testing.T) {
+	if _, err := Search(t.TempDir(), "(", Options{Regex: true}); err == nil {
+		t.Error("Search with invalid regex: err = nil, want error")
+	}
+}
+
+// recorder is a progress sink that keeps every call it receives
+type recorder struct {
+	mu      sync.Mutex
+	total   int64
+	updates []int64
+	done    int
+}
+
+func (r *recorder) Start(total int64) { r.total = total }
+
+func (r *recorder) Update(done int64) {
+	r.mu.Lock()
+	r.updates = append(r.updates, done)
+	r.mu.Unlock()
+}
+
+func (r *recorder) Done() { r.done++ }
+
+func TestSearchReportsProgress(t *testing.T) {
+	root := testTree(t)
+	rec := &recorder{}
+	if _, err := Search(root, "TODO", Options{Include: "*.go", Workers: 4, Progress: rec}); err != nil {
+		t.Fatal(err)
+	}
+
+	// main.go, lib/util.go and vendor/x/x.go
+	if rec.total != 3 {
+		t.Errorf("Start total = %d, want 3", rec.total)
+	}
+	for i, done := range rec.updates {
+		if done != int64(i+1) {
+			t.Errorf("update %d = %d, want %d", i, done, i+1)
+		}
+	}
+	if len(rec.updates) != 3 {
+		t.Errorf("got %d updates, want 3", len(rec.updates))
+	}
+	if rec.done != 1 {
+		t.Errorf("Done called %d times, want 1", rec.done)
+	}
+}
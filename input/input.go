@@ -0,0 +1,253 @@
+// Package input parses raw terminal input bytes into key events.
+package input
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Key identifies a key. Printable characters are KeyRune with the character
+// in KeyEvent.Rune.
+type Key int
+
+// Keys
+const (
+	KeyUnknown Key = iota
+	KeyRune
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDown
+	KeyInsert
+	KeyDelete
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyPasteStart // Bracketed paste begins: ESC [ 200 ~
+	KeyPasteEnd   // Bracketed paste ends: ESC [ 201 ~
+)
+
+var keyNames = map[Key]string{
+	KeyEnter: "enter", KeyTab: "tab", KeyBackspace: "backspace", KeyEscape: "esc",
+	KeyUp: "up", KeyDown: "down", KeyRight: "right", KeyLeft: "left",
+	KeyHome: "home", KeyEnd: "end", KeyPgUp: "pgup", KeyPgDown: "pgdown",
+	KeyInsert: "insert", KeyDelete: "delete",
+	KeyF1: "f1", KeyF2: "f2", KeyF3: "f3", KeyF4: "f4", KeyF5: "f5", KeyF6: "f6",
+	KeyF7: "f7", KeyF8: "f8", KeyF9: "f9", KeyF10: "f10", KeyF11: "f11", KeyF12: "f12",
+	KeyPasteStart: "paste-start", KeyPasteEnd: "paste-end",
+}
+
+// Modifiers is a set of modifier keys held with a key.
+type Modifiers uint8
+
+// Modifier keys
+const (
+	ModShift Modifiers = 1 << iota
+	ModAlt
+	ModCtrl
+)
+
+// KeyEvent is one key press.
+type KeyEvent struct {
+	Key       Key
+	Modifiers Modifiers
+	Rune      rune // The character, for KeyRune
+}
+
+// String formats the event the way the editor's key bindings name keys,
+// e.g. "ctrl+right", "alt+x" or "ctrl+shift+home".
+func (k KeyEvent) String() string {
+	var sb strings.Builder
+	if k.Modifiers&ModCtrl != 0 {
+		sb.WriteString("ctrl+")
+	}
+	if k.Modifiers&ModAlt != 0 {
+		sb.WriteString("alt+")
+	}
+	if k.Modifiers&ModShift != 0 {
+		sb.WriteString("shift+")
+	}
+	switch {
+	case k.Key == KeyRune && k.Rune == ' ':
+		sb.WriteString("space")
+	case k.Key == KeyRune:
+		sb.WriteRune(k.Rune)
+	case keyNames[k.Key] != "":
+		sb.WriteString(keyNames[k.Key])
+	default:
+		sb.WriteString("unknown")
+	}
+	return sb.String()
+}
+
+// csiTildeKeys maps the number in ESC [ n ~ sequences to keys
+var csiTildeKeys = map[int]Key{
+	1: KeyHome, 2: KeyInsert, 3: KeyDelete, 4: KeyEnd, 5: KeyPgUp, 6: KeyPgDown,
+	7: KeyHome, 8: KeyEnd,
+	11: KeyF1, 12: KeyF2, 13: KeyF3, 14: KeyF4, 15: KeyF5,
+	17: KeyF6, 18: KeyF7, 19: KeyF8, 20: KeyF9, 21: KeyF10, 23: KeyF11, 24: KeyF12,
+	200: KeyPasteStart, 201: KeyPasteEnd,
+}
+
+// csiLetterKeys maps the final byte of ESC [ ... X and ESC O X sequences
+var csiLetterKeys = map[byte]Key{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft,
+	'H': KeyHome, 'F': KeyEnd,
+	'P': KeyF1, 'Q': KeyF2, 'R': KeyF3, 'S': KeyF4,
+}
+
+// Parse reads the first key event from b and returns it with the number of
+// bytes it used. ok is false when b is empty or ends partway through a
+// sequence, in which case the caller should wait for more input. Sequences
+// that are complete but not understood give KeyUnknown.
+func Parse(b []byte) (ev KeyEvent, n int, ok bool) {
+	if len(b) == 0 {
+		return KeyEvent{}, 0, false
+	}
+	c := b[0]
+	switch {
+	case c == 0x1b:
+		return parseEscape(b)
+	case c == '\r' || c == '\n':
+		return KeyEvent{Key: KeyEnter}, 1, true
+	case c == '\t':
+		return KeyEvent{Key: KeyTab}, 1, true
+	case c == 0x7f:
+		return KeyEvent{Key: KeyBackspace}, 1, true
+	case c == 0:
+		return KeyEvent{Key: KeyRune, Rune: ' ', Modifiers: ModCtrl}, 1, true
+	case c <= 0x1a:
+		// Ctrl+A through Ctrl+Z
+		return KeyEvent{Key: KeyRune, Rune: rune(c) + 0x60, Modifiers: ModCtrl}, 1, true
+	case c < 0x20:
+		// Ctrl+\ ] ^ _
+		return KeyEvent{Key: KeyRune, Rune: rune(c) + 0x40, Modifiers: ModCtrl}, 1, true
+	}
+	if !utf8.FullRune(b) {
+		return KeyEvent{}, 0, false
+	}
+	r, size := utf8.DecodeRune(b)
+	if r == utf8.RuneError {
+		return KeyEvent{Key: KeyUnknown}, size, true
+	}
+	return KeyEvent{Key: KeyRune, Rune: r}, size, true
+}
+
+// parseEscape handles input starting with ESC
+func parseEscape(b []byte) (KeyEvent, int, bool) {
+	if len(b) == 1 {
+		return KeyEvent{Key: KeyEscape}, 1, true
+	}
+	switch b[1] {
+	case '[':
+		return parseCSI(b)
+	case 'O':
+		// SS3: application-mode arrows and F1-F4
+		if len(b) < 3 {
+			return KeyEvent{}, 0, false
+		}
+		if k, ok := csiLetterKeys[b[2]]; ok {
+			return KeyEvent{Key: k}, 3, true
+		}
+		return KeyEvent{Key: KeyUnknown}, 3, true
+	case 0x1b:
+		// ESC ESC is Escape followed by whatever comes next
+		return KeyEvent{Key: KeyEscape}, 1, true
+	}
+
+	// ESC then a key is that key with Alt
+	ev, n, ok := Parse(b[1:])
+	if !ok {
+		return KeyEvent{}, 0, false
+	}
+	ev.Modifiers |= ModAlt
+	return ev, n + 1, true
+}
+
+// parseCSI handles ESC [ params final
+func parseCSI(b []byte) (KeyEvent, int, bool) {
+	i := 2
+	for i < len(b) && (b[i] >= '0' && b[i] <= '9' || b[i] == ';') {
+		i++
+	}
+	if i == len(b) {
+		return KeyEvent{}, 0, false
+	}
+	final := b[i]
+	n := i + 1
+	params := strings.Split(string(b[2:i]), ";")
+
+	var mods Modifiers
+	if len(params) > 1 {
+		mods = parseModifiers(params[1])
+	}
+
+	var key Key
+	switch {
+	case final == '~':
+		num, _ := strconv.Atoi(params[0])
+		key = csiTildeKeys[num]
+	case final == 'Z':
+		key = KeyTab
+		mods |= ModShift
+	default:
+		key = csiLetterKeys[final]
+	}
+	if key == 0 {
+		return KeyEvent{Key: KeyUnknown}, n, true
+	}
+	return KeyEvent{Key: key, Modifiers: mods}, n, true
+}
+
+// parseModifiers decodes the xterm modifier parameter, which is 1 plus a
+// bit mask of shift (1), alt (2) and ctrl (4)
+func parseModifiers(param string) Modifiers {
+	m, err := strconv.Atoi(param)
+	if err != nil || m < 1 {
+		return 0
+	}
+	m--
+	var mods Modifiers
+	if m&1 != 0 {
+		mods |= ModShift
+	}
+	if m&2 != 0 {
+		mods |= ModAlt
+	}
+	if m&4 != 0 {
+		mods |= ModCtrl
+	}
+	return mods
+}
+
+// ParseAll parses every complete event in b. It returns the events and the
+// bytes left over from an incomplete trailing sequence.
+func ParseAll(b []byte) (events []KeyEvent, rest []byte) {
+	for len(b) > 0 {
+		ev, n, ok := Parse(b)
+		if !ok {
+			break
+		}
+		events = append(events, ev)
+		b = b[n:]
+	}
+	return events, b
+}
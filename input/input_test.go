@@ -0,0 +1,118 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		want  KeyEvent
+		wantN int
+	}{
+		{"plain rune", "a", KeyEvent{Key: KeyRune, Rune: 'a'}, 1},
+		{"multibyte rune", "é", KeyEvent{Key: KeyRune, Rune: 'é'}, 2},
+		{"enter", "\r", KeyEvent{Key: KeyEnter}, 1},
+		{"tab", "\t", KeyEvent{Key: KeyTab}, 1},
+		{"backspace", "\x7f", KeyEvent{Key: KeyBackspace}, 1},
+		{"ctrl+s", "\x13", KeyEvent{Key: KeyRune, Rune: 's', Modifiers: ModCtrl}, 1},
+		{"ctrl+]", "\x1d", KeyEvent{Key: KeyRune, Rune: ']', Modifiers: ModCtrl}, 1},
+		{"escape alone", "\x1b", KeyEvent{Key: KeyEscape}, 1},
+		{"alt+x", "\x1bx", KeyEvent{Key: KeyRune, Rune: 'x', Modifiers: ModAlt}, 2},
+		{"alt+ctrl+a", "\x1b\x01", KeyEvent{Key: KeyRune, Rune: 'a', Modifiers: ModAlt | ModCtrl}, 2},
+		{"up", "\x1b[A", KeyEvent{Key: KeyUp}, 3},
+		{"application-mode left", "\x1bOD", KeyEvent{Key: KeyLeft}, 3},
+		{"ctrl+right", "\x1b[1;5C", KeyEvent{Key: KeyRight, Modifiers: ModCtrl}, 6},
+		{"shift+up", "\x1b[1;2A", KeyEvent{Key: KeyUp, Modifiers: ModShift}, 6},
+		{"ctrl+shift+home", "\x1b[1;6H", KeyEvent{Key: KeyHome, Modifiers: ModCtrl | ModShift}, 6},
+		{"alt+pgdown", "\x1b[6;3~", KeyEvent{Key: KeyPgDown, Modifiers: ModAlt}, 6},
+		{"delete", "\x1b[3~", KeyEvent{Key: KeyDelete}, 4},
+		{"shift+tab", "\x1b[Z", KeyEvent{Key: KeyTab, Modifiers: ModShift}, 3},
+		{"f1 ss3", "\x1bOP", KeyEvent{Key: KeyF1}, 3},
+		{"f5", "\x1b[15~", KeyEvent{Key: KeyF5}, 5},
+		{"f12", "\x1b[24~", KeyEvent{Key: KeyF12}, 5},
+		{"ctrl+f10", "\x1b[21;5~", KeyEvent{Key: KeyF10, Modifiers: ModCtrl}, 7},
+		{"paste start", "\x1b[200~", KeyEvent{Key: KeyPasteStart}, 6},
+		{"paste end", "\x1b[201~", KeyEvent{Key: KeyPasteEnd}, 6},
+		{"unknown csi", "\x1b[99~", KeyEvent{Key: KeyUnknown}, 5},
+	}
+
+	for _, tt := range tests {
+		got, n, ok := Parse([]byte(tt.in))
+		if !ok || got != tt.want || n != tt.wantN {
+			t.Errorf("%s: Parse(%q) = (%+v, %d, %v), want (%+v, %d, true)",
+				tt.name, tt.in, got, n, ok, tt.want, tt.wantN)
+		}
+	}
+}
+
+func TestParseIncomplete(t *testing.T) {
+	for _, in := range []string{"", "\x1b[", "\x1b[1;5", "\x1bO", "\xc3"} {
+		if _, n, ok := Parse([]byte(in)); ok || n != 0 {
+			t.Errorf("Parse(%q) = (n=%d, ok=%v), want incomplete", in, n, ok)
+		}
+	}
+}
+
+func TestKeyEventString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"\x1b[1;5C", "ctrl+right"},
+		{"\x1b[1;6H", "ctrl+shift+home"},
+		{"\x1bx", "alt+x"},
+		{"\x00", "ctrl+space"},
+		{"\x1b[6;3~", "alt+pgdown"},
+		{"\x1bOP", "f1"},
+	}
+	for _, tt := range tests {
+		ev, _, _ := Parse([]byte(tt.in))
+		if got := ev.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	events, rest := ParseAll([]byte("a\x1b[200~hi\x1b[201~\x1b[1;"))
+	want := []KeyEvent{
+		{Key: KeyRune, Rune: 'a'},
+		{Key: KeyPasteStart},
+		{Key: KeyRune, Rune: 'h'},
+		{Key: KeyRune, Rune: 'i'},
+		{Key: KeyPasteEnd},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("ParseAll events = %+v, want %+v", events, want)
+	}
+	if string(rest) != "\x1b[1;" {
+		t.Errorf("ParseAll rest = %q, want the incomplete sequence", rest)
+	}
+}
+
+func TestParseEventsPaste(t *testing.T) {
+	in := "x\x1b[200~func f() {\n\treturn a[0] ~ b\x1b[A\n}\x1b[201~\x1b[1;5C"
+	events, rest := ParseEvents([]byte(in))
+	want := []Event{
+		KeyEvent{Key: KeyRune, Rune: 'x'},
+		PasteEvent{Text: "func f() {\n\treturn a[0] ~ b\x1b[A\n}"},
+		KeyEvent{Key: KeyRight, Modifiers: ModCtrl},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("ParseEvents = %#v, want %#v", events, want)
+	}
+	if len(rest) != 0 {
+		t.Errorf("rest = %q, want empty", rest)
+	}
+}
+
+func TestParseEventIncompletePaste(t *testing.T) {
+	for _, in := range []string{"\x1b[20", "\x1b[200~partial", "\x1b[200~text\x1b[201"} {
+		if _, n, ok := ParseEvent([]byte(in)); ok || n != 0 {
+			t.Errorf("ParseEvent(%q) = (n=%d, ok=%v), want incomplete", in, n, ok)
+		}
+	}
+}
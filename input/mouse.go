@@ -0,0 +1,116 @@
+package input
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Escape sequences that turn SGR (1006) mouse reporting on and off, with
+// button and drag tracking (1002).
+const (
+	EnableMouse  = "\x1b[?1002h\x1b[?1006h"
+	DisableMouse = "\x1b[?1006l\x1b[?1002l"
+)
+
+var mouseStart = []byte("\x1b[<")
+
+// MouseButton identifies the button in a mouse event.
+type MouseButton int
+
+// Mouse buttons
+const (
+	MouseNone MouseButton = iota
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseWheelUp
+	MouseWheelDown
+	MouseWheelLeft
+	MouseWheelRight
+)
+
+// MouseAction is what happened to the button.
+type MouseAction int
+
+// Mouse actions. Wheel events are always MousePress.
+const (
+	MousePress MouseAction = iota
+	MouseRelease
+	MouseDrag // Moved with the button held
+	MouseMove // Moved with no button held
+)
+
+// MouseEvent is one mouse report. X and Y are 0-based cell coordinates.
+type MouseEvent struct {
+	Button    MouseButton
+	Action    MouseAction
+	X, Y      int
+	Modifiers Modifiers
+}
+
+func (MouseEvent) isEvent() {}
+
+// ParseMouse reads an SGR mouse report, ESC [ < b ; x ; y M (press) or m
+// (release), from the start of b. ok is false when b does not start with a
+// complete report; n is 0 in that case unless the report was malformed.
+func ParseMouse(b []byte) (ev MouseEvent, n int, ok bool) {
+	if !bytes.HasPrefix(b, mouseStart) {
+		return MouseEvent{}, 0, false
+	}
+	i := len(mouseStart)
+	for i < len(b) && (b[i] >= '0' && b[i] <= '9' || b[i] == ';') {
+		i++
+	}
+	if i == len(b) {
+		return MouseEvent{}, 0, false
+	}
+	n = i + 1
+	final := b[i]
+	params := strings.Split(string(b[len(mouseStart):i]), ";")
+	if final != 'M' && final != 'm' || len(params) != 3 {
+		return MouseEvent{}, n, false
+	}
+	var nums [3]int
+	for j, p := range params {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return MouseEvent{}, n, false
+		}
+		nums[j] = v
+	}
+	code := nums[0]
+
+	ev = MouseEvent{X: nums[1] - 1, Y: nums[2] - 1}
+	if code&4 != 0 {
+		ev.Modifiers |= ModShift
+	}
+	if code&8 != 0 {
+		ev.Modifiers |= ModAlt
+	}
+	if code&16 != 0 {
+		ev.Modifiers |= ModCtrl
+	}
+
+	if code&64 != 0 {
+		ev.Button = MouseWheelUp + MouseButton(code&3)
+		return ev, n, true
+	}
+	switch code & 3 {
+	case 0:
+		ev.Button = MouseLeft
+	case 1:
+		ev.Button = MouseMiddle
+	case 2:
+		ev.Button = MouseRight
+	}
+	switch {
+	case final == 'm':
+		ev.Action = MouseRelease
+	case code&32 != 0 && ev.Button == MouseNone:
+		ev.Action = MouseMove
+	case code&32 != 0:
+		ev.Action = MouseDrag
+	}
+	return ev, n, true
+}
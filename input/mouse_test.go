@@ -0,0 +1,66 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMouse(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want MouseEvent
+	}{
+		{"left press", "\x1b[<0;10;5M", MouseEvent{Button: MouseLeft, Action: MousePress, X: 9, Y: 4}},
+		{"left release", "\x1b[<0;10;5m", MouseEvent{Button: MouseLeft, Action: MouseRelease, X: 9, Y: 4}},
+		{"middle press", "\x1b[<1;1;1M", MouseEvent{Button: MouseMiddle, Action: MousePress}},
+		{"right press", "\x1b[<2;3;4M", MouseEvent{Button: MouseRight, Action: MousePress, X: 2, Y: 3}},
+		{"left drag", "\x1b[<32;12;5M", MouseEvent{Button: MouseLeft, Action: MouseDrag, X: 11, Y: 4}},
+		{"move without a button", "\x1b[<35;7;2M", MouseEvent{Button: MouseNone, Action: MouseMove, X: 6, Y: 1}},
+		{"wheel up", "\x1b[<64;20;8M", MouseEvent{Button: MouseWheelUp, X: 19, Y: 7}},
+		{"wheel down", "\x1b[<65;20;8M", MouseEvent{Button: MouseWheelDown, X: 19, Y: 7}},
+		{"ctrl+shift click", "\x1b[<20;2;2M", MouseEvent{Button: MouseLeft, X: 1, Y: 1, Modifiers: ModShift | ModCtrl}},
+		{"large coordinates", "\x1b[<0;300;120M", MouseEvent{Button: MouseLeft, X: 299, Y: 119}},
+	}
+
+	for _, tt := range tests {
+		got, n, ok := ParseMouse([]byte(tt.in))
+		if !ok || n != len(tt.in) || got != tt.want {
+			t.Errorf("%s: ParseMouse(%q) = (%+v, %d, %v), want (%+v, %d, true)",
+				tt.name, tt.in, got, n, ok, tt.want, len(tt.in))
+		}
+	}
+}
+
+func TestParseMouseIncomplete(t *testing.T) {
+	for _, in := range []string{"\x1b[<", "\x1b[<0;10", "\x1b[<0;10;5"} {
+		if _, n, ok := ParseMouse([]byte(in)); ok || n != 0 {
+			t.Errorf("ParseMouse(%q) = (n=%d, ok=%v), want incomplete", in, n, ok)
+		}
+	}
+}
+
+func TestParseEventsDrag(t *testing.T) {
+	// Press, drag two cells right, release
+	in := "\x1b[<0;5;3M\x1b[<32;6;3M\x1b[<32;7;3M\x1b[<0;7;3m"
+	events, rest := ParseEvents([]byte(in))
+	want := []Event{
+		MouseEvent{Button: MouseLeft, Action: MousePress, X: 4, Y: 2},
+		MouseEvent{Button: MouseLeft, Action: MouseDrag, X: 5, Y: 2},
+		MouseEvent{Button: MouseLeft, Action: MouseDrag, X: 6, Y: 2},
+		MouseEvent{Button: MouseLeft, Action: MouseRelease, X: 6, Y: 2},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("ParseEvents = %+v, want %+v", events, want)
+	}
+	if len(rest) != 0 {
+		t.Errorf("rest = %q, want empty", rest)
+	}
+}
+
+func TestParseEventMalformedMouse(t *testing.T) {
+	ev, n, ok := ParseEvent([]byte("\x1b[<0;5Mx"))
+	if !ok || n != 7 || ev != (KeyEvent{Key: KeyUnknown}) {
+		t.Errorf("ParseEvent = (%+v, %d, %v), want (unknown key, 7, true)", ev, n, ok)
+	}
+}
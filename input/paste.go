@@ -0,0 +1,70 @@
+package input
+
+import "bytes"
+
+// Escape sequences that turn bracketed paste mode on and off. With it on,
+// the terminal wraps pasted text in ESC [ 200 ~ and ESC [ 201 ~.
+const (
+	EnableBracketedPaste  = "\x1b[?2004h"
+	DisableBracketedPaste = "\x1b[?2004l"
+)
+
+var (
+	pasteStart = []byte("\x1b[200~")
+	pasteEnd   = []byte("\x1b[201~")
+)
+
+// Event is a KeyEvent, MouseEvent or PasteEvent.
+type Event interface {
+	isEvent()
+}
+
+// PasteEvent is a block of text pasted in bracketed paste mode. The text is
+// exactly what the terminal sent, escape sequences included.
+type PasteEvent struct {
+	Text string
+}
+
+func (KeyEvent) isEvent()   {}
+func (PasteEvent) isEvent() {}
+
+// ParseEvent reads the first event from b like Parse, except that a
+// bracketed paste is returned whole as a PasteEvent rather than as its
+// start marker, keys and end marker, and SGR mouse reports as MouseEvents. Nothing inside the paste is parsed,
+// so a "~" or escape sequence in the pasted text is kept as is. ok is false
+// until the end marker has arrived.
+func ParseEvent(b []byte) (ev Event, n int, ok bool) {
+	if bytes.HasPrefix(b, mouseStart) {
+		mouse, n, ok := ParseMouse(b)
+		if !ok && n > 0 {
+			return KeyEvent{Key: KeyUnknown}, n, true
+		}
+		return mouse, n, ok
+	}
+	if !bytes.HasPrefix(b, pasteStart) {
+		if len(b) < len(pasteStart) && bytes.HasPrefix(pasteStart, b) {
+			return nil, 0, false // Could still become a paste start
+		}
+		return Parse(b)
+	}
+	payload := b[len(pasteStart):]
+	end := bytes.Index(payload, pasteEnd)
+	if end < 0 {
+		return nil, 0, false
+	}
+	return PasteEvent{Text: string(payload[:end])}, len(pasteStart) + end + len(pasteEnd), true
+}
+
+// ParseEvents parses every complete event in b. It returns the events and
+// the bytes left over from an incomplete trailing sequence or paste.
+func ParseEvents(b []byte) (events []Event, rest []byte) {
+	for len(b) > 0 {
+		ev, n, ok := ParseEvent(b)
+		if !ok {
+			break
+		}
+		events = append(events, ev)
+		b = b[n:]
+	}
+	return events, b
+}
@@ -0,0 +1,136 @@
+// Package largefile provides lazy, read-only access to files too large to
+// load into memory at once.
+package largefile
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cornish/textivus-editor/progress"
+)
+
+// chunkSize is the read size used while scanning for line starts
+const chunkSize = 64 * 1024
+
+// Reader serves windows of lines from a file on demand. Only a byte-offset
+// index of line starts is held in memory; line content is read from disk
+// when requested.
+type Reader struct {
+	file       *os.File
+	size       int64
+	lineStarts []int64 // Byte offset of the start of each line
+}
+
+// Open indexes the file at path and returns a Reader for it.
+func Open(path string) (*Reader, error) {
+	return OpenProgress(path, nil)
+}
+
+// OpenProgress is Open, reporting indexing progress in bytes to p.
+// p may be nil.
+func OpenProgress(path string, p progress.Progress) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reader{file: f, lineStarts: []int64{0}}
+	if err := r.buildIndex(progress.OrNop(p)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// buildIndex scans the file once, recording the offset after each newline
+func (r *Reader) buildIndex(p progress.Progress) error {
+	var total int64
+	if info, err := r.file.Stat(); err == nil {
+		total = info.Size()
+	}
+	p.Start(total)
+	defer p.Done()
+
+	buf := make([]byte, chunkSize)
+	var offset int64
+	for {
+		n, err := r.file.ReadAt(buf, offset)
+		chunk := buf[:n]
+		for i := 0; ; {
+			j := bytes.IndexByte(chunk[i:], '\n')
+			if j < 0 {
+				break
+			}
+			i += j + 1
+			r.lineStarts = append(r.lineStarts, offset+int64(i))
+		}
+		offset += int64(n)
+		p.Update(offset)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	r.size = offset
+	return nil
+}
+
+// Close releases the underlying file.
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+// Size returns the file size in bytes.
+func (r *Reader) Size() int64 {
+	return r.size
+}
+
+// LineCount returns the number of lines in the file.
+// Like strings.Split, a trailing newline yields a final empty line.
+func (r *Reader) LineCount() int {
+	return len(r.lineStarts)
+}
+
+// Line returns a single line without its line ending.
+func (r *Reader) Line(i int) (string, error) {
+	lines, err := r.Lines(i, i+1)
+	if err != nil || len(lines) == 0 {
+		return "", err
+	}
+	return lines[0], nil
+}
+
+// Lines returns lines [start, end), clamped to the file. Only the bytes
+// covering the requested window are read.
+func (r *Reader) Lines(start, end int) ([]string, error) {
+	if start < 0 {
+		start = 0
+	}
+	if end > len(r.lineStarts) {
+		end = len(r.lineStarts)
+	}
+	if start >= end {
+		return nil, nil
+	}
+
+	from := r.lineStarts[start]
+	to := r.size
+	if end < len(r.lineStarts) {
+		to = r.lineStarts[end] - 1 // Exclude the final newline
+	}
+
+	data := make([]byte, to-from)
+	if _, err := r.file.ReadAt(data, from); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines, nil
+}
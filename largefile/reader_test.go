@@ -0,0 +1,168 @@
+package largefile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// lineText is the deterministic content of line i in the synthetic file
+func lineText(i int) string {
+	return fmt.Sprintf("line %07d: the quick brown fox jumps over the lazy dog", i)
+}
+
+func writeSyntheticFile(t *testing.T, lines int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "big.log")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := bufio.NewWriter(f)
+	for i := 0; i < lines; i++ {
+		fmt.Fprintln(w, lineText(i))
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReaderLinesAtOffsets(t *testing.T) {
+	const total = 200000
+	r, err := Open(writeSyntheticFile(t, total))
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer r.Close()
+
+	// Trailing newline yields a final empty line
+	if got := r.LineCount(); got != total+1 {
+		t.Errorf("LineCount() = %d, want %d", got, total+1)
+	}
+
+	for _, start := range []int{0, 1, 4095, 65536, 123457, total - 3} {
+		lines, err := r.Lines(start, start+3)
+		if err != nil {
+			t.Fatalf("Lines(%d, %d) error: %v", start, start+3, err)
+		}
+		for i, got := range lines {
+			if want := lineText(start + i); got != want {
+				t.Errorf("Lines(%d, %d)[%d] = %q, want %q", start, start+3, i, got, want)
+			}
+		}
+	}
+
+	last, err := r.Line(total)
+	if err != nil || last != "" {
+		t.Errorf("Line(%d) = %q, %v, want empty line", total, last, err)
+	}
+	if lines, _ := r.Lines(total+5, total+10); lines != nil {
+		t.Errorf("Lines past EOF = %q, want nil", lines)
+	}
+}
+
+func TestReaderMemoryBounded(t *testing.T) {
+	const total = 200000
+	path := writeSyntheticFile(t, total)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer r.Close()
+	if _, err := r.Lines(100000, 100050); err != nil {
+		t.Fatal(err)
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	// The index is 8 bytes per line; content is never fully loaded
+	var grown int64
+	if after.HeapAlloc > before.HeapAlloc {
+		grown = int64(after.HeapAlloc - before.HeapAlloc)
+	}
+	if grown > info.Size()/4 {
+		t.Errorf("heap grew by %d bytes for a %d byte file", grown, info.Size())
+	}
+}
+
+func TestReaderCRLFAndNoTrailingNewline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crlf.txt")
+	if err := os.WriteFile(path, []byte("one\r\ntwo\r\nthree"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	lines, err := r.Lines(0, r.LineCount())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"one", "two", "three"}
+	if len(lines) != len(want) {
+		t.Fatalf("Lines() = %q, want %q", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Lines()[%d] = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+// recorder is a progress sink that keeps every call it receives
+type recorder struct {
+	total   int64
+	updates []int64
+	done    int
+}
+
+func (r *recorder) Start(total int64) { r.total = total }
+func (r *recorder) Update(done int64) { r.updates = append(r.updates, done) }
+func (r *recorder) Done()             { r.done++ }
+
+func TestOpenProgress(t *testing.T) {
+	path := writeSyntheticFile(t, 10000)
+	rec := &recorder{}
+	r, err := OpenProgress(path, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if rec.total != r.Size() {
+		t.Errorf("Start total = %d, want file size %d", rec.total, r.Size())
+	}
+	if len(rec.updates) < 2 {
+		t.Fatalf("got %d updates, want several for a multi-chunk file", len(rec.updates))
+	}
+	for i := 1; i < len(rec.updates); i++ {
+		if rec.updates[i] < rec.updates[i-1] {
+			t.Errorf("update %d = %d went backwards from %d", i, rec.updates[i], rec.updates[i-1])
+		}
+	}
+	if last := rec.updates[len(rec.updates)-1]; last != r.Size() {
+		t.Errorf("last update = %d, want %d", last, r.Size())
+	}
+	if rec.done != 1 {
+		t.Errorf("Done called %d times, want 1", rec.done)
+	}
+}
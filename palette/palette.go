@@ -0,0 +1,184 @@
+// Package palette implements the command palette: a registry of named
+// editor commands and a fuzzy matcher for finding them by typing part of
+// their name.
+package palette
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Command is an entry in the palette.
+type Command struct {
+	ID       string // Stable identifier the editor dispatches on
+	Name     string // Display name matched against the query, e.g. "Toggle Word Wrap"
+	Shortcut string // Key binding shown next to the name, if any
+}
+
+// Scoring weights for fuzzy matches
+const (
+	scoreMatch      = 1  // Each matched character
+	scoreContiguous = 10 // Matched character directly follows the previous match
+	scoreWordStart  = 6  // Matched character starts a word in the name
+	scorePrefix     = 10 // Query matches from the first character of the name
+)
+
+// Registry holds the available commands in registration order.
+type Registry struct {
+	commands []Command
+}
+
+// NewRegistry creates a registry holding commands.
+func NewRegistry(commands ...Command) *Registry {
+	r := &Registry{}
+	for _, c := range commands {
+		r.Register(c)
+	}
+	return r
+}
+
+// Register adds a command, replacing any existing command with the same ID.
+func (r *Registry) Register(cmd Command) {
+	for i, c := range r.commands {
+		if c.ID == cmd.ID {
+			r.commands[i] = cmd
+			return
+		}
+	}
+	r.commands = append(r.commands, cmd)
+}
+
+// Commands returns all commands in registration order.
+func (r *Registry) Commands() []Command {
+	out := make([]Command, len(r.commands))
+	copy(out, r.commands)
+	return out
+}
+
+// Filter returns the commands whose names contain the query as a
+// case-insensitive subsequence, best match first. Contiguous runs, matches
+// at word starts and matches from the start of the name score higher; ties
+// go to the shorter name, then to registration order. An empty query
+// returns every command.
+func (r *Registry) Filter(query string) []Command {
+	if query == "" {
+		return r.Commands()
+	}
+
+	type scored struct {
+		cmd   Command
+		score int
+	}
+	var matches []scored
+	for _, c := range r.commands {
+		if s, ok := Score(query, c.Name); ok {
+			matches = append(matches, scored{c, s})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].cmd.Name) < len(matches[j].cmd.Name)
+	})
+
+	out := make([]Command, len(matches))
+	for i, m := range matches {
+		out[i] = m.cmd
+	}
+	return out
+}
+
+// Score rates how well query fuzzy-matches name. ok is false unless every
+// query character appears in name in order.
+func Score(query, name string) (score int, ok bool) {
+	score, _, ok = Match(query, name)
+	return score, ok
+}
+
+// Match is Score that also returns the rune indices of name matched by each
+// query character, for highlighting. Of all the ways the query can be
+// matched it picks the highest scoring, so "ww" lands on "Word Wrap" rather
+// than "Wow", and an early mid-word match can't use up a character a later
+// one needed. Ties go to the earliest positions.
+func Match(query, name string) (score int, positions []int, ok bool) {
+	q := []rune(query)
+	n := []rune(name)
+	if len(q) == 0 {
+		return 0, nil, true
+	}
+	if len(q) > len(n) {
+		return 0, nil, false
+	}
+
+	// best[qi][ni] is the top score for q[:qi+1] with q[qi] matched at
+	// n[ni], or noMatch; from[qi][ni] is where q[qi-1] was matched for it
+	const noMatch = -1
+	best := make([][]int, len(q))
+	from := make([][]int, len(q))
+	for qi := range q {
+		best[qi] = make([]int, len(n))
+		from[qi] = make([]int, len(n))
+		prevBest, prevAt := noMatch, -1 // Best for q[qi-1] at least two back
+		for ni := range n {
+			if qi > 0 && ni >= 2 && best[qi-1][ni-2] > prevBest {
+				prevBest, prevAt = best[qi-1][ni-2], ni-2
+			}
+			best[qi][ni] = noMatch
+			if !equalFold(q[qi], n[ni]) {
+				continue
+			}
+			gain := scoreMatch
+			if isWordStart(n, ni) {
+				gain += scoreWordStart
+			}
+			if ni == 0 {
+				gain += scorePrefix
+			}
+			if qi == 0 {
+				best[qi][ni] = gain
+				continue
+			}
+			if prevBest != noMatch {
+				best[qi][ni], from[qi][ni] = prevBest+gain, prevAt
+			}
+			if ni >= 1 && best[qi-1][ni-1] != noMatch && best[qi-1][ni-1]+scoreContiguous+gain > best[qi][ni] {
+				best[qi][ni], from[qi][ni] = best[qi-1][ni-1]+scoreContiguous+gain, ni-1
+			}
+		}
+	}
+
+	last := len(q) - 1
+	end := -1
+	for ni := range n {
+		if best[last][ni] != noMatch && (end < 0 || best[last][ni] > best[last][end]) {
+			end = ni
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	positions = make([]int, len(q))
+	for qi, ni := last, end; qi >= 0; qi-- {
+		positions[qi] = ni
+		ni = from[qi][ni]
+	}
+	return best[last][end], positions, true
+}
+
+// isWordStart reports whether n[i] begins a word: the first character, one
+// after a separator, or an upper-case letter following a lower-case one.
+func isWordStart(n []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := n[i-1], n[i]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return unicode.IsLetter(cur) || unicode.IsDigit(cur)
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+func equalFold(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
@@ -0,0 +1,127 @@
+package palette
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testRegistry() *Registry {
+	return NewRegistry(
+		Command{ID: "wrap", Name: "Toggle Word Wrap"},
+		Command{ID: "save", Name: "Save File", Shortcut: "Ctrl+S"},
+		Command{ID: "saveas", Name: "Save As"},
+		Command{ID: "sel", Name: "Select All"},
+		Command{ID: "theme", Name: "Change Theme"},
+		Command{ID: "find", Name: "Find and Replace"},
+		Command{ID: "lines", Name: "Toggle Line Numbers"},
+	)
+}
+
+func ids(cmds []Command) []string {
+	out := make([]string, len(cmds))
+	for i, c := range cmds {
+		out[i] = c.ID
+	}
+	return out
+}
+
+func TestFilterRanking(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"save", []string{"saveas", "save"}},
+		{"sa", []string{"saveas", "save", "sel"}},
+		{"ww", []string{"wrap"}},
+		{"tog", []string{"wrap", "lines"}},
+		{"tln", []string{"lines"}},
+		{"THEME", []string{"theme"}},
+		{"fr", []string{"find"}},
+	}
+
+	r := testRegistry()
+	for _, tt := range tests {
+		if got := ids(r.Filter(tt.query)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Filter(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestFilterExcludesNonMatches(t *testing.T) {
+	r := testRegistry()
+	for _, q := range []string{"xyz", "zz", "saev", "Toggle Word Wrapx"} {
+		if got := r.Filter(q); len(got) != 0 {
+			t.Errorf("Filter(%q) = %v, want no matches", q, ids(got))
+		}
+	}
+}
+
+func TestFilterEmptyQueryReturnsAll(t *testing.T) {
+	r := testRegistry()
+	if got, want := ids(r.Filter("")), ids(r.Commands()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter(\"\") = %v, want all commands %v", got, want)
+	}
+}
+
+func TestRegisterReplacesByID(t *testing.T) {
+	r := testRegistry()
+	n := len(r.Commands())
+	r.Register(Command{ID: "save", Name: "Save Document"})
+	if got := len(r.Commands()); got != n {
+		t.Errorf("after re-register: %d commands, want %d", got, n)
+	}
+	if got := r.Filter("document"); len(got) != 1 || got[0].ID != "save" {
+		t.Errorf("Filter(\"document\") = %v, want [save]", ids(got))
+	}
+}
+
+func TestScorePrefersContiguousAndPrefix(t *testing.T) {
+	prefix, _ := Score("sav", "Save File")
+	scattered, _ := Score("sav", "Select Advanced View")
+	if prefix <= scattered {
+		t.Errorf("Score(sav): prefix %d <= scattered %d", prefix, scattered)
+	}
+	contiguous, _ := Score("line", "Toggle Line Numbers")
+	split, _ := Score("line", "A Lock In Every Nothing")
+	if contiguous <= split {
+		t.Errorf("Score(line): contiguous %d <= split %d", contiguous, split)
+	}
+}
+
+func TestMatchPositions(t *testing.T) {
+	tests := []struct {
+		query string
+		name  string
+		want  []int
+	}{
+		{"ww", "Toggle Word Wrap", []int{7, 12}},
+		{"save", "Save File", []int{0, 1, 2, 3}},
+		{"sf", "Save File", []int{0, 5}},
+		{"ab", "xab Apple", []int{1, 2}},
+		{"ac", "bac Alpha", []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		_, got, ok := Match(tt.query, tt.name)
+		if !ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q, %q) positions = %v (ok=%v), want %v", tt.query, tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestScoreBacktracks(t *testing.T) {
+	// The first 'a' is mid-word and the later word start has no 'b' or 'c'
+	// after it, so a greedy matcher that jumps to the word start fails
+	for _, tt := range []struct{ query, name string }{
+		{"ab", "xab Apple"},
+		{"ac", "bac Alpha"},
+		{"fil", "Profile Fill"},
+	} {
+		if _, ok := Score(tt.query, tt.name); !ok {
+			t.Errorf("Score(%q, %q) did not match", tt.query, tt.name)
+		}
+	}
+	if _, ok := Score("ba", "ab"); ok {
+		t.Error("Score(ba, ab) matched out of order")
+	}
+}
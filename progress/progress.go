@@ -0,0 +1,31 @@
+// Package progress lets long-running operations report how far along they
+// are, so the UI can show it.
+package progress
+
+// Progress receives updates from a long operation. Start is called once
+// with the total amount of work (0 if unknown), Update with the amount done so far (never
+// decreasing), and Done once when the operation finishes, whether or not
+// it succeeded. Units are up to the operation, e.g. bytes or files.
+type Progress interface {
+	Start(total int64)
+	Update(done int64)
+	Done()
+}
+
+// Nop is a Progress that ignores every update.
+var Nop Progress = nop{}
+
+type nop struct{}
+
+func (nop) Start(int64)  {}
+func (nop) Update(int64) {}
+func (nop) Done()        {}
+
+// OrNop returns p, or Nop if p is nil, so operations can take an optional
+// Progress without nil checks.
+func OrNop(p Progress) Progress {
+	if p == nil {
+		return Nop
+	}
+	return p
+}
@@ -0,0 +1,78 @@
+// Package quotes supplies the random quote shown in the About dialog.
+// Quotes are read from newline-delimited files: a bundled default set plus
+// an optional user file, one quote per line. Blank lines and lines
+// starting with # are ignored.
+package quotes
+
+import (
+	_ "embed"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+// Fallback is shown when no quotes could be loaded.
+const Fallback = "A Festivus for the rest of us!"
+
+//go:embed quotes.txt
+var bundled string
+
+// Provider picks random quotes from a fixed set.
+type Provider struct {
+	quotes []string
+	rng    *rand.Rand
+}
+
+// New creates a provider for quotes. A nil src is seeded from the clock;
+// tests pass a fixed source for repeatable picks.
+func New(quotes []string, src rand.Source) *Provider {
+	if src == nil {
+		src = rand.NewSource(time.Now().UnixNano())
+	}
+	return &Provider{quotes: quotes, rng: rand.New(src)}
+}
+
+// Load creates a provider holding the bundled quotes plus those in the
+// user file at userPath. A missing or unreadable user file is skipped.
+func Load(userPath string, src rand.Source) *Provider {
+	quotes := Bundled()
+	if userPath != "" {
+		if data, err := os.ReadFile(userPath); err == nil {
+			quotes = append(quotes, Parse(string(data))...)
+		}
+	}
+	return New(quotes, src)
+}
+
+// Bundled returns the quotes shipped with the editor.
+func Bundled() []string {
+	return Parse(bundled)
+}
+
+// Parse splits newline-delimited text into quotes, trimming whitespace and
+// dropping blank and # comment lines.
+func Parse(text string) []string {
+	var quotes []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		quotes = append(quotes, line)
+	}
+	return quotes
+}
+
+// Len returns the number of quotes available.
+func (p *Provider) Len() int {
+	return len(p.quotes)
+}
+
+// RandomQuote returns a randomly chosen quote, or Fallback if there are none.
+func (p *Provider) RandomQuote() string {
+	if len(p.quotes) == 0 {
+		return Fallback
+	}
+	return p.quotes[p.rng.Intn(len(p.quotes))]
+}
@@ -0,0 +1,82 @@
+package quotes
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRandomQuoteFromKnownSet(t *testing.T) {
+	known := []string{"one", "two", "three"}
+	p := New(known, rand.NewSource(1))
+
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		q := p.RandomQuote()
+		if q != "one" && q != "two" && q != "three" {
+			t.Fatalf("RandomQuote() = %q, not in %q", q, known)
+		}
+		seen[q] = true
+	}
+	if len(seen) != len(known) {
+		t.Errorf("100 picks covered %d of %d quotes", len(seen), len(known))
+	}
+
+	// The same source gives the same sequence
+	a, b := New(known, rand.NewSource(42)), New(known, rand.NewSource(42))
+	for i := 0; i < 10; i++ {
+		if qa, qb := a.RandomQuote(), b.RandomQuote(); qa != qb {
+			t.Fatalf("pick %d: %q != %q with the same seed", i, qa, qb)
+		}
+	}
+}
+
+func TestRandomQuoteFallback(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(empty, []byte("\n  \n# just a comment\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		p    *Provider
+	}{
+		{"no quotes", New(nil, nil)},
+		{"empty file", New(Parse(mustRead(t, empty)), nil)},
+	}
+	for _, tt := range tests {
+		if got := tt.p.RandomQuote(); got != Fallback {
+			t.Errorf("%s: RandomQuote() = %q, want %q", tt.name, got, Fallback)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "quotes.txt")
+	if err := os.WriteFile(user, []byte("Serenity now!\n\n  Hoochie mama!  \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundledCount := len(Bundled())
+	if bundledCount == 0 {
+		t.Fatal("no bundled quotes")
+	}
+	if got := Load(user, nil).Len(); got != bundledCount+2 {
+		t.Errorf("Load with user file: %d quotes, want %d", got, bundledCount+2)
+	}
+	if got := Load(filepath.Join(dir, "missing.txt"), nil).Len(); got != bundledCount {
+		t.Errorf("Load with missing user file: %d quotes, want %d", got, bundledCount)
+	}
+}
+
+func mustRead(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,118 @@
+// Package session saves and restores the editor's open documents, pane
+// layout, and per-pane cursor and scroll positions so a restart can pick up
+// where the user left off.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/cornish/textivus-editor/config"
+)
+
+// Split layouts
+const (
+	SplitNone       = ""
+	SplitHorizontal = "horizontal" // Panes stacked top to bottom
+	SplitVertical   = "vertical"   // Panes side by side
+)
+
+// Pane is the view state of one pane.
+type Pane struct {
+	Document   int `json:"document"` // Index into Session.Documents
+	CursorLine int `json:"cursor_line"`
+	CursorCol  int `json:"cursor_col"`
+	ScrollY    int `json:"scroll_y"`
+	ScrollX    int `json:"scroll_x"`
+}
+
+// Session is the saved editor state.
+type Session struct {
+	Documents  []string `json:"documents"` // Absolute paths of open documents
+	Panes      []Pane   `json:"panes"`
+	Split      string   `json:"split,omitempty"`
+	ActivePane int      `json:"active_pane"`
+
+	// Skipped lists documents that no longer existed when the session was
+	// loaded. It is not saved.
+	Skipped []string `json:"-"`
+}
+
+// DefaultPath returns the session file path inside the config directory.
+func DefaultPath() (string, error) {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "session.json"), nil
+}
+
+// SaveSession writes s to path as JSON, creating the directory if needed.
+func SaveSession(path string, s *Session) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadSession reads the session saved at path. A missing session file gives
+// an empty session. Documents that no longer exist are dropped and listed in
+// Skipped; panes showing them are dropped too and the remaining pane indices
+// are renumbered to match.
+func LoadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Session{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	s.dropMissing()
+	return &s, nil
+}
+
+// dropMissing removes documents whose files are gone, along with any panes
+// that showed them or referred to an out-of-range document.
+func (s *Session) dropMissing() {
+	newIndex := make([]int, len(s.Documents))
+	docs := s.Documents[:0]
+	for i, path := range s.Documents {
+		if _, err := os.Stat(path); err != nil {
+			s.Skipped = append(s.Skipped, path)
+			newIndex[i] = -1
+			continue
+		}
+		newIndex[i] = len(docs)
+		docs = append(docs, path)
+	}
+	s.Documents = docs
+
+	panes := s.Panes[:0]
+	active := 0
+	for i, p := range s.Panes {
+		if p.Document < 0 || p.Document >= len(newIndex) || newIndex[p.Document] < 0 {
+			continue
+		}
+		if i == s.ActivePane {
+			active = len(panes)
+		}
+		p.Document = newIndex[p.Document]
+		panes = append(panes, p)
+	}
+	s.Panes = panes
+	s.ActivePane = active
+	if len(s.Panes) < 2 {
+		s.Split = SplitNone
+	}
+}
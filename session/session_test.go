@@ -0,0 +1,96 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates empty files with the given names in dir and returns
+// their paths.
+func writeFiles(t *testing.T, dir string, names ...string) []string {
+	t.Helper()
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name)
+		if err := os.WriteFile(paths[i], []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestSessionRoundTripTwoPanes(t *testing.T) {
+	dir := t.TempDir()
+	docs := writeFiles(t, dir, "a.go", "b.go")
+	want := &Session{
+		Documents: docs,
+		Panes: []Pane{
+			{Document: 0, CursorLine: 12, CursorCol: 4, ScrollY: 3},
+			{Document: 1, CursorLine: 40, CursorCol: 0, ScrollY: 30, ScrollX: 2},
+		},
+		Split:      SplitVertical,
+		ActivePane: 1,
+	}
+
+	path := filepath.Join(dir, "state", "session.json")
+	if err := SaveSession(path, want); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	got, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadSession = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadSessionSkipsDeletedFile(t *testing.T) {
+	dir := t.TempDir()
+	docs := writeFiles(t, dir, "a.go", "gone.go", "c.go")
+	s := &Session{
+		Documents: docs,
+		Panes: []Pane{
+			{Document: 1, CursorLine: 5},
+			{Document: 2, CursorLine: 9},
+		},
+		Split:      SplitHorizontal,
+		ActivePane: 1,
+	}
+	path := filepath.Join(dir, "session.json")
+	if err := SaveSession(path, s); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	if err := os.Remove(docs[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if want := []string{docs[0], docs[2]}; !reflect.DeepEqual(got.Documents, want) {
+		t.Errorf("Documents = %v, want %v", got.Documents, want)
+	}
+	if want := []string{docs[1]}; !reflect.DeepEqual(got.Skipped, want) {
+		t.Errorf("Skipped = %v, want %v", got.Skipped, want)
+	}
+	if want := []Pane{{Document: 1, CursorLine: 9}}; !reflect.DeepEqual(got.Panes, want) {
+		t.Errorf("Panes = %+v, want %+v", got.Panes, want)
+	}
+	if got.ActivePane != 0 || got.Split != SplitNone {
+		t.Errorf("ActivePane, Split = %d, %q, want 0, %q", got.ActivePane, got.Split, SplitNone)
+	}
+}
+
+func TestLoadSessionMissingFile(t *testing.T) {
+	got, err := LoadSession(filepath.Join(t.TempDir(), "none.json"))
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if len(got.Documents) != 0 || len(got.Panes) != 0 {
+		t.Errorf("LoadSession of missing file = %+v, want empty session", got)
+	}
+}
@@ -36,25 +36,53 @@ func DefaultSyntaxColors() SyntaxColors {
 	}
 }
 
+// TokenCategory names a group of token types that share a color
+type TokenCategory string
+
+// Token categories, as used in the [theme.syntax] config section
+const (
+	CategoryKeyword  TokenCategory = "keyword"
+	CategoryString   TokenCategory = "string"
+	CategoryComment  TokenCategory = "comment"
+	CategoryNumber   TokenCategory = "number"
+	CategoryOperator TokenCategory = "operator"
+	CategoryFunction TokenCategory = "function"
+	CategoryType     TokenCategory = "type"
+	CategoryError    TokenCategory = "error"
+	CategoryMarker   TokenCategory = "marker" // TODO, FIXME and friends inside comments
+)
+
 // ColorSpan represents a colored region of text
 type ColorSpan struct {
-	Start int    // Start column (rune index)
-	End   int    // End column (rune index, exclusive)
-	Color string // ANSI color code
+	Start    int           // Start column (rune index)
+	End      int           // End column (rune index, exclusive)
+	Color    string        // ANSI color code
+	Category TokenCategory // Category the color is for; "" for spans not from a token
 }
 
 // Highlighter provides syntax highlighting for source code
 type Highlighter struct {
-	lexer   chroma.Lexer
-	enabled bool
-	colors  SyntaxColors
+	lexer     chroma.Lexer
+	enabled   bool
+	colors    SyntaxColors
+	overrides map[TokenCategory]string // Per-category colors that win over colors
+	markers   []string                 // Keywords highlighted inside comments
+	cache     []lineState              // Per-line highlighting kept by RecolorFrom
+	maxLine   int                      // Lines longer than this many bytes are not highlighted
 }
 
+// DefaultMaxLineLength is the length in bytes past which a line is left
+// unhighlighted, so a huge line such as minified JavaScript can't stall
+// the lexer.
+const DefaultMaxLineLength = 10000
+
 // New creates a new Highlighter for the given filename
 func New(filename string) *Highlighter {
 	h := &Highlighter{
 		enabled: true,
 		colors:  DefaultSyntaxColors(),
+		markers: DefaultMarkers,
+		maxLine: DefaultMaxLineLength,
 	}
 	h.SetFile(filename)
 	return h
@@ -62,6 +90,7 @@ func New(filename string) *Highlighter {
 
 // SetFile updates the lexer based on the filename
 func (h *Highlighter) SetFile(filename string) {
+	h.cache = nil
 	if filename == "" {
 		h.lexer = nil
 		return
@@ -87,15 +116,54 @@ func (h *Highlighter) HasLexer() bool {
 	return h.lexer != nil
 }
 
+// Language returns the name of the current lexer, or "" if there is none
+func (h *Highlighter) Language() string {
+	if h.lexer == nil {
+		return ""
+	}
+	return h.lexer.Config().Name
+}
+
+// SetMaxLineLength sets the length in bytes past which lines are left
+// unhighlighted. 0 or less restores DefaultMaxLineLength.
+func (h *Highlighter) SetMaxLineLength(n int) {
+	if n <= 0 {
+		n = DefaultMaxLineLength
+	}
+	h.maxLine = n
+	h.cache = nil
+}
+
+// MaxLineLength returns the length in bytes past which lines are left
+// unhighlighted.
+func (h *Highlighter) MaxLineLength() int {
+	return h.maxLine
+}
+
+// tooLong reports whether line is past the highlighting cap.
+func (h *Highlighter) tooLong(line string) bool {
+	return h.maxLine > 0 && len(line) > h.maxLine
+}
+
 // SetColors sets the syntax highlighting colors
 func (h *Highlighter) SetColors(colors SyntaxColors) {
 	h.colors = colors
+	h.cache = nil
+}
+
+// SetColorOverrides sets colors for individual token categories that take
+// precedence over the theme colors. Categories not in the map keep their
+// theme color; nil clears all overrides.
+func (h *Highlighter) SetColorOverrides(overrides map[TokenCategory]string) {
+	h.overrides = overrides
+	h.cache = nil
 }
 
 // GetLineColors returns color spans for a line
-// Returns nil if highlighting is disabled or no lexer is available
+// Returns nil if highlighting is disabled, no lexer is available, or the
+// line is longer than MaxLineLength
 func (h *Highlighter) GetLineColors(line string) []ColorSpan {
-	if !h.enabled || h.lexer == nil {
+	if !h.enabled || h.lexer == nil || h.tooLong(line) {
 		return nil
 	}
 
@@ -109,12 +177,10 @@ func (h *Highlighter) GetLineColors(line string) []ColorSpan {
 	for _, token := range iterator.Tokens() {
 		color := h.tokenColor(token.Type)
 		tokenLen := utf8.RuneCountInString(token.Value)
-		if color != "" && tokenLen > 0 {
-			spans = append(spans, ColorSpan{
-				Start: pos,
-				End:   pos + tokenLen,
-				Color: color,
-			})
+		// Categorized tokens get a span even without a color, as under
+		// NO_COLOR, so exports can still tell what they are
+		if tokenCategory(token.Type) != "" && tokenLen > 0 {
+			spans = h.appendSpan(spans, token.Type, token.Value, pos, pos+tokenLen, color)
 		}
 		pos += tokenLen
 	}
@@ -122,6 +188,29 @@ func (h *Highlighter) GetLineColors(line string) []ColorSpan {
 	return spans
 }
 
+// InString reports whether the character at rune column col of line is part
+// of a string literal. Returns false when no lexer is available.
+func (h *Highlighter) InString(line string, col int) bool {
+	if h.lexer == nil {
+		return false
+	}
+
+	iterator, err := h.lexer.Tokenise(nil, line)
+	if err != nil {
+		return false
+	}
+
+	pos := 0
+	for _, token := range iterator.Tokens() {
+		tokenLen := utf8.RuneCountInString(token.Value)
+		if col >= pos && col < pos+tokenLen {
+			return token.Type.InCategory(chroma.LiteralString)
+		}
+		pos += tokenLen
+	}
+	return false
+}
+
 // ColorAt returns the color for a specific column position
 // Returns empty string if no color applies
 func ColorAt(spans []ColorSpan, col int) string {
@@ -133,6 +222,22 @@ func ColorAt(spans []ColorSpan, col int) string {
 	return ""
 }
 
+// CategoryAt returns the token category for a specific column position
+// Returns "" if no span with a category covers it
+func CategoryAt(spans []ColorSpan, col int) TokenCategory {
+	for _, span := range spans {
+		if col >= span.Start && col < span.End && span.Category != "" {
+			return span.Category
+		}
+	}
+	return ""
+}
+
+// ColorConverter turns a theme color into a foreground escape sequence.
+// The default always emits 24-bit color for hex values; the editor points it
+// at ui.ColorToANSIFg so syntax colors follow the terminal's color depth.
+var ColorConverter = colorToANSI
+
 // colorToANSI converts a theme color string to an ANSI foreground escape sequence
 func colorToANSI(color string) string {
 	if strings.HasPrefix(color, "#") {
@@ -172,6 +277,50 @@ func parseHexColor(hex string) (int, int, int) {
 
 // tokenColor returns the ANSI color code for a token type
 func (h *Highlighter) tokenColor(t chroma.TokenType) string {
+	category := tokenCategory(t)
+	if category == "" {
+		return "" // Default terminal color
+	}
+	return ColorConverter(h.CategoryColor(category))
+}
+
+// CategoryColor returns the theme color for a token category, as written
+// in the config ("#rrggbb" or a palette index), with overrides applied
+func (h *Highlighter) CategoryColor(category TokenCategory) string {
+	if color, ok := h.overrides[category]; ok && color != "" {
+		return color
+	}
+	return h.categoryColor(category)
+}
+
+// categoryColor returns the theme color for a token category
+func (h *Highlighter) categoryColor(category TokenCategory) string {
+	switch category {
+	case CategoryKeyword:
+		return h.colors.Keyword
+	case CategoryString:
+		return h.colors.String
+	case CategoryComment:
+		return h.colors.Comment
+	case CategoryNumber:
+		return h.colors.Number
+	case CategoryOperator:
+		return h.colors.Operator
+	case CategoryFunction:
+		return h.colors.Function
+	case CategoryType:
+		return h.colors.Type
+	case CategoryError:
+		return h.colors.Error
+	case CategoryMarker:
+		return h.colors.Number
+	}
+	return ""
+}
+
+// tokenCategory returns the color category of a token type, or "" for
+// tokens drawn in the default color
+func tokenCategory(t chroma.TokenType) TokenCategory {
 	switch {
 	// Keywords
 	case t == chroma.Keyword,
@@ -181,7 +330,7 @@ func (h *Highlighter) tokenColor(t chroma.TokenType) string {
 		t == chroma.KeywordPseudo,
 		t == chroma.KeywordReserved,
 		t == chroma.KeywordType:
-		return colorToANSI(h.colors.Keyword)
+		return CategoryKeyword
 
 	// Strings
 	case t == chroma.String,
@@ -198,7 +347,7 @@ func (h *Highlighter) tokenColor(t chroma.TokenType) string {
 		t == chroma.StringRegex,
 		t == chroma.StringSingle,
 		t == chroma.StringSymbol:
-		return colorToANSI(h.colors.String)
+		return CategoryString
 
 	// Comments
 	case t == chroma.Comment,
@@ -208,7 +357,7 @@ func (h *Highlighter) tokenColor(t chroma.TokenType) string {
 		t == chroma.CommentPreprocFile,
 		t == chroma.CommentSingle,
 		t == chroma.CommentSpecial:
-		return colorToANSI(h.colors.Comment)
+		return CategoryComment
 
 	// Numbers
 	case t == chroma.Number,
@@ -218,39 +367,39 @@ func (h *Highlighter) tokenColor(t chroma.TokenType) string {
 		t == chroma.NumberInteger,
 		t == chroma.NumberIntegerLong,
 		t == chroma.NumberOct:
-		return colorToANSI(h.colors.Number)
+		return CategoryNumber
 
 	// Operators
 	case t == chroma.Operator,
 		t == chroma.OperatorWord:
-		return colorToANSI(h.colors.Operator)
+		return CategoryOperator
 
 	// Functions
 	case t == chroma.NameFunction,
 		t == chroma.NameFunctionMagic:
-		return colorToANSI(h.colors.Function)
+		return CategoryFunction
 
 	// Types/Classes
 	case t == chroma.NameClass,
 		t == chroma.NameBuiltin,
 		t == chroma.NameBuiltinPseudo:
-		return colorToANSI(h.colors.Type)
+		return CategoryType
 
 	// Constants
 	case t == chroma.NameConstant:
-		return colorToANSI(h.colors.Number) // Same as numbers
+		return CategoryNumber // Same as numbers
 
 	// Preprocessor
 	case t == chroma.GenericHeading,
 		t == chroma.GenericSubheading:
-		return colorToANSI(h.colors.Type)
+		return CategoryType
 
 	// Errors
 	case t == chroma.Error,
 		t == chroma.GenericError:
-		return colorToANSI(h.colors.Error)
+		return CategoryError
 
 	default:
-		return "" // Default terminal color
+		return ""
 	}
 }
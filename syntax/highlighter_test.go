@@ -0,0 +1,101 @@
+package syntax
+
+import (
+	"strings"
+	"testing"
+)
+
+// colorsByCategory highlights line and returns the color of the first span
+// found for each token category.
+func colorsByCategory(h *Highlighter, line string) map[TokenCategory]string {
+	iterator, err := h.lexer.Tokenise(nil, line)
+	if err != nil {
+		return nil
+	}
+	out := make(map[TokenCategory]string)
+	for _, token := range iterator.Tokens() {
+		if c := tokenCategory(token.Type); c != "" {
+			if _, seen := out[c]; !seen {
+				out[c] = h.tokenColor(token.Type)
+			}
+		}
+	}
+	return out
+}
+
+func TestSetColorOverrides(t *testing.T) {
+	const line = `func main() { return "s" + 1 } // note`
+	h := New("main.go")
+	defaults := colorsByCategory(h, line)
+	for _, c := range []TokenCategory{CategoryKeyword, CategoryString, CategoryComment, CategoryNumber} {
+		if defaults[c] == "" {
+			t.Fatalf("test line has no %s token", c)
+		}
+	}
+
+	h.SetColorOverrides(map[TokenCategory]string{CategoryComment: "#ff0000"})
+	got := colorsByCategory(h, line)
+	if want := ColorConverter("#ff0000"); got[CategoryComment] != want {
+		t.Errorf("comment color = %q, want override %q", got[CategoryComment], want)
+	}
+	for c, want := range defaults {
+		if c != CategoryComment && got[c] != want {
+			t.Errorf("%s color = %q, want default %q", c, got[c], want)
+		}
+	}
+
+	// The spans GetLineColors returns carry the override too
+	spans := h.GetLineColors(line)
+	last := spans[len(spans)-1]
+	if last.Color != ColorConverter("#ff0000") {
+		t.Errorf("comment span color = %q, want override", last.Color)
+	}
+
+	h.SetColorOverrides(nil)
+	if got := colorsByCategory(h, line); got[CategoryComment] != defaults[CategoryComment] {
+		t.Errorf("after clearing overrides: comment color = %q, want default %q", got[CategoryComment], defaults[CategoryComment])
+	}
+}
+
+func TestMaxLineLength(t *testing.T) {
+	const short = `x := "s"`
+	long := `x := "` + strings.Repeat("s", 40) + `"`
+
+	tests := []struct {
+		name   string
+		max    int
+		line   string
+		colors bool
+	}{
+		{"under the cap highlights", 20, short, true},
+		{"over the cap is plain", 20, long, false},
+		{"default cap allows ordinary lines", 0, long, true},
+	}
+
+	for _, tt := range tests {
+		h := New("main.go")
+		h.SetMaxLineLength(tt.max)
+		if got := len(h.GetLineColors(tt.line)) > 0; got != tt.colors {
+			t.Errorf("%s: GetLineColors has colors = %v, want %v", tt.name, got, tt.colors)
+		}
+	}
+}
+
+func TestRecolorFromSkipsLongLines(t *testing.T) {
+	h := New("main.c")
+	h.SetMaxLineLength(20)
+	lines := []string{"int a = 1;", "int b = " + strings.Repeat("1", 40) + ";", "/* one", "two */"}
+
+	colors, _ := h.RecolorFrom(lines, 0)
+	if len(colors[0]) == 0 {
+		t.Error("line 0 under the cap has no colors")
+	}
+	if len(colors[1]) != 0 {
+		t.Errorf("line 1 over the cap has colors %v", colors[1])
+	}
+	for _, i := range []int{2, 3} {
+		if !isComment(h, lines, i) {
+			t.Errorf("line %d after the long line is not highlighted as a comment", i)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package syntax
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+// lineState is the cached highlighting of one line.
+type lineState struct {
+	spans []ColorSpan
+	// entry and open are the type of a comment or string token still open
+	// at the start and end of the line, or "" outside any multi-line token.
+	entry string
+	open  string
+	valid bool
+}
+
+// RecolorFrom re-highlights lines starting at startLine, carrying
+// multi-line comments and strings across line breaks, and caches the
+// result. Lines are retokenized until the next line's cached highlighting
+// started in the same open-token state, since everything below it is then
+// unaffected.
+// It returns the recolored lines and stableLine, the first line whose cached
+// colors were still valid (len(lines) if recoloring ran to the end).
+//
+// Lines longer than MaxLineLength are left uncolored and lexed as if empty.
+//
+// Callers pass the first edited line. If the edit changed the line count,
+// the cache is shifted to match, so a single-line edit that inserts or
+// removes lines still stops as soon as the lines below it line up again.
+func (h *Highlighter) RecolorFrom(lines []string, startLine int) (colors map[int][]ColorSpan, stableLine int) {
+	if !h.enabled || h.lexer == nil || len(lines) == 0 {
+		h.cache = nil
+		return nil, len(lines)
+	}
+	startLine = max(0, min(startLine, len(lines)-1))
+	h.resizeCache(len(lines), startLine)
+
+	// Start from a line that begins outside any multi-line token, so the
+	// lexer sees the whole construct
+	anchor := startLine
+	for anchor > 0 && (!h.cache[anchor-1].valid || h.cache[anchor-1].open != "") {
+		anchor--
+	}
+
+	source := make([]string, 0, len(lines)-anchor)
+	for _, l := range lines[anchor:] {
+		if h.tooLong(l) {
+			l = ""
+		}
+		source = append(source, l)
+	}
+	iterator, err := h.lexer.Tokenise(nil, strings.Join(source, "\n"))
+	if err != nil {
+		return nil, len(lines)
+	}
+
+	colors = make(map[int][]ColorSpan)
+	line, col := anchor, 0
+	entry := ""
+	var spans []ColorSpan
+	// endLine finishes the current line; it reports whether the lines below
+	// are known to be unaffected
+	endLine := func(open string) bool {
+		h.cache[line] = lineState{spans: spans, entry: entry, open: open, valid: true}
+		colors[line] = spans
+		next := line + 1
+		stable := line >= startLine && next < len(h.cache) &&
+			h.cache[next].valid && h.cache[next].entry == open
+		line = next
+		col = 0
+		entry = open
+		spans = nil
+		return stable
+	}
+
+	for token := iterator(); token != chroma.EOF; token = iterator() {
+		color := h.tokenColor(token.Type)
+		open := ""
+		if token.Type.InCategory(chroma.Comment) || token.Type.InCategory(chroma.LiteralString) {
+			open = token.Type.String()
+		}
+		value := token.Value
+		for value != "" && line < len(lines) {
+			part, rest, hasNewline := strings.Cut(value, "\n")
+			n := utf8.RuneCountInString(part)
+			if tokenCategory(token.Type) != "" && n > 0 {
+				spans = h.appendSpan(spans, token.Type, part, col, col+n, color)
+			}
+			col += n
+			value = rest
+			if !hasNewline {
+				break
+			}
+			// The token is open at the end of this line only if it goes on
+			// past the newline
+			lineOpen := ""
+			if rest != "" {
+				lineOpen = open
+			}
+			if endLine(lineOpen) {
+				return colors, line
+			}
+		}
+	}
+	if line < len(lines) {
+		endLine("")
+	}
+	return colors, len(lines)
+}
+
+// CachedLineColors returns the spans RecolorFrom cached for line i.
+func (h *Highlighter) CachedLineColors(i int) ([]ColorSpan, bool) {
+	if i < 0 || i >= len(h.cache) || !h.cache[i].valid {
+		return nil, false
+	}
+	return h.cache[i].spans, true
+}
+
+// resizeCache grows or shrinks the cache to n lines. Lines were inserted or
+// deleted just below startLine, so entries after it are shifted and any
+// new lines are left invalid.
+func (h *Highlighter) resizeCache(n, startLine int) {
+	delta := n - len(h.cache)
+	if len(h.cache) == 0 || delta == 0 {
+		if len(h.cache) != n {
+			h.cache = make([]lineState, n)
+		}
+		return
+	}
+	keep := min(startLine+1, len(h.cache))
+	resized := make([]lineState, 0, n)
+	resized = append(resized, h.cache[:keep]...)
+	if delta > 0 {
+		resized = append(resized, make([]lineState, delta)...)
+		resized = append(resized, h.cache[keep:]...)
+	} else if keep-delta < len(h.cache) {
+		resized = append(resized, h.cache[keep-delta:]...)
+	}
+	for len(resized) < n {
+		resized = append(resized, lineState{})
+	}
+	h.cache = resized[:n]
+}
@@ -0,0 +1,129 @@
+package syntax
+
+import (
+	"sort"
+	"testing"
+)
+
+// commentColor is the color RecolorFrom gives comment tokens.
+func commentColor(h *Highlighter) string {
+	return ColorConverter(h.colors.Comment)
+}
+
+// isComment reports whether the whole of line i is colored as a comment.
+func isComment(h *Highlighter, lines []string, i int) bool {
+	spans, ok := h.CachedLineColors(i)
+	if !ok || len(spans) == 0 {
+		return lines[i] == ""
+	}
+	for _, s := range spans {
+		if s.Color != commentColor(h) {
+			return false
+		}
+	}
+	return spans[0].Start == 0 && spans[len(spans)-1].End == len([]rune(lines[i]))
+}
+
+func sortedKeys(m map[int][]ColorSpan) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func TestRecolorFromFullPass(t *testing.T) {
+	h := New("main.c")
+	lines := []string{"int a = 1;", "/* one", "two */", "int b = 2;"}
+
+	colors, stable := h.RecolorFrom(lines, 0)
+	if stable != len(lines) {
+		t.Errorf("first pass stableLine = %d, want %d", stable, len(lines))
+	}
+	if len(colors) != len(lines) {
+		t.Errorf("first pass recolored %v, want all lines", sortedKeys(colors))
+	}
+	for i, want := range []bool{false, true, true, false} {
+		if got := isComment(h, lines, i); got != want {
+			t.Errorf("line %d %q comment = %v, want %v", i, lines[i], got, want)
+		}
+	}
+}
+
+func TestRecolorFromOpeningComment(t *testing.T) {
+	h := New("main.c")
+	lines := []string{
+		"int a = 1;",
+		"int b = 2;",
+		"int c = 3;",
+		"int d = 4; */",
+		"int e = 5;",
+		"int f = 6;",
+	}
+	h.RecolorFrom(lines, 0)
+
+	// Opening a comment on line 1 turns lines 1-3 into a comment, up to the
+	// existing "*/"; lines after it keep their colors
+	lines[1] = "/* int b = 2;"
+	colors, stable := h.RecolorFrom(lines, 1)
+	if stable != 4 {
+		t.Errorf("stableLine = %d, want 4", stable)
+	}
+	if got := sortedKeys(colors); len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("recolored lines %v, want [1 2 3]", got)
+	}
+	for i, want := range map[int]bool{0: false, 1: true, 2: true, 4: false, 5: false} {
+		if got := isComment(h, lines, i); got != want {
+			t.Errorf("line %d %q comment = %v, want %v", i, lines[i], got, want)
+		}
+	}
+	if spans, _ := h.CachedLineColors(3); len(spans) == 0 || spans[0].Color != commentColor(h) {
+		t.Errorf("line 3 should start inside the comment, spans = %v", spans)
+	}
+
+	// Removing the opener restores the code coloring down to the same line
+	lines[1] = "int b = 2;"
+	if _, stable := h.RecolorFrom(lines, 1); stable != 4 {
+		t.Errorf("after removing opener: stableLine = %d, want 4", stable)
+	}
+	for _, i := range []int{1, 2} {
+		if isComment(h, lines, i) {
+			t.Errorf("after removing opener: line %d still colored as comment", i)
+		}
+	}
+}
+
+func TestRecolorFromStopsAtUnaffectedEdit(t *testing.T) {
+	h := New("main.go")
+	lines := []string{"package main", "var a = 1", "var b = 2", "var c = 3"}
+	h.RecolorFrom(lines, 0)
+
+	lines[1] = "var a = 10"
+	colors, stable := h.RecolorFrom(lines, 1)
+	if stable != 2 {
+		t.Errorf("stableLine = %d, want 2", stable)
+	}
+	if len(colors) != 1 {
+		t.Errorf("recolored lines %v, want only [1]", sortedKeys(colors))
+	}
+}
+
+func TestRecolorFromInsertedLines(t *testing.T) {
+	h := New("main.go")
+	lines := []string{"package main", "var a = 1", "var b = 2"}
+	h.RecolorFrom(lines, 0)
+
+	// Split line 1 into two lines
+	lines = []string{"package main", "var a = 1", "var x = 0", "var b = 2"}
+	colors, stable := h.RecolorFrom(lines, 1)
+	if stable != 3 {
+		t.Errorf("stableLine = %d, want 3", stable)
+	}
+	if got := sortedKeys(colors); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("recolored lines %v, want [1 2]", got)
+	}
+	if _, ok := h.CachedLineColors(3); !ok {
+		t.Error("line 3 lost its cached colors after the shift")
+	}
+}
@@ -0,0 +1,71 @@
+package syntax
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+// Literal is a run of string or comment text on one line. Columns are rune
+// indices and End is exclusive.
+type Literal struct {
+	Start, End int
+	Category   TokenCategory // CategoryString or CategoryComment
+}
+
+// Literals lexes lines as one document and returns, for each line, the
+// runs of string and comment tokens on it, so callers can tell brackets
+// and quotes in code from those in text. Adjacent tokens of the same
+// category are merged, and a string or comment that spans lines has a run
+// on each of them. Lines longer than MaxLineLength are lexed as if empty.
+// Returns nil when no lexer is available.
+func (h *Highlighter) Literals(lines []string) [][]Literal {
+	if h.lexer == nil || len(lines) == 0 {
+		return nil
+	}
+	source := make([]string, len(lines))
+	for i, l := range lines {
+		if !h.tooLong(l) {
+			source[i] = l
+		}
+	}
+	iterator, err := h.lexer.Tokenise(nil, strings.Join(source, "\n"))
+	if err != nil {
+		return nil
+	}
+
+	literals := make([][]Literal, len(lines))
+	line, col := 0, 0
+	for token := iterator(); token != chroma.EOF; token = iterator() {
+		category := tokenCategory(token.Type)
+		if category != CategoryString && category != CategoryComment {
+			category = ""
+		}
+		value := token.Value
+		for value != "" && line < len(lines) {
+			part, rest, hasNewline := strings.Cut(value, "\n")
+			n := utf8.RuneCountInString(part)
+			if category != "" && n > 0 {
+				literals[line] = appendLiteral(literals[line], Literal{col, col + n, category})
+			}
+			col += n
+			value = rest
+			if !hasNewline {
+				break
+			}
+			line++
+			col = 0
+		}
+	}
+	return literals
+}
+
+// appendLiteral adds l to runs, extending the last run when l continues it
+func appendLiteral(runs []Literal, l Literal) []Literal {
+	if n := len(runs); n > 0 && runs[n-1].End == l.Start && runs[n-1].Category == l.Category {
+		runs[n-1].End = l.End
+		return runs
+	}
+	return append(runs, l)
+}
@@ -0,0 +1,27 @@
+package syntax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLiterals(t *testing.T) {
+	h := New("x.go")
+	lines := []string{
+		`s := "a(b" // c)`,
+		"r := `x",
+		"y` + 1",
+	}
+	want := [][]Literal{
+		{{5, 10, CategoryString}, {11, 16, CategoryComment}},
+		{{5, 7, CategoryString}},
+		{{0, 2, CategoryString}},
+	}
+	if got := h.Literals(lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("Literals = %v, want %v", got, want)
+	}
+
+	if got := New("notes.unknownext").Literals(lines); got != nil {
+		t.Errorf("Literals without a lexer = %v, want nil", got)
+	}
+}
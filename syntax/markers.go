@@ -0,0 +1,100 @@
+package syntax
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+// DefaultMarkers are the keywords picked out inside comments when no list
+// is configured
+var DefaultMarkers = []string{"TODO", "FIXME", "HACK", "XXX", "NOTE"}
+
+// markerStyle is added to the marker color so markers stand out from the
+// surrounding comment
+const markerStyle = "\033[1m"
+
+// SetMarkers sets the keywords highlighted inside comments. nil restores
+// DefaultMarkers; an empty, non-nil slice turns marker highlighting off.
+func (h *Highlighter) SetMarkers(markers []string) {
+	if markers == nil {
+		markers = DefaultMarkers
+	}
+	h.markers = markers
+	h.cache = nil
+}
+
+// appendSpan adds the span for a token of text at rune columns [start, end)
+// to spans. Comments containing markers are split into several spans.
+func (h *Highlighter) appendSpan(spans []ColorSpan, t chroma.TokenType, text string, start, end int, color string) []ColorSpan {
+	if tokenCategory(t) == CategoryComment && h.hasMarker(text) {
+		return append(spans, h.commentSpans(text, start, color)...)
+	}
+	return append(spans, ColorSpan{Start: start, End: end, Color: color, Category: tokenCategory(t)})
+}
+
+// commentSpans returns the spans for a comment token of text starting at
+// rune column start, splitting out any markers so they get their own color
+func (h *Highlighter) commentSpans(text string, start int, color string) []ColorSpan {
+	runes := []rune(text)
+	var spans []ColorSpan
+	last := 0
+	for i := 0; i < len(runes); {
+		n := h.markerAt(runes, i)
+		if n == 0 {
+			i++
+			continue
+		}
+		if i > last {
+			spans = append(spans, ColorSpan{Start: start + last, End: start + i, Color: color, Category: CategoryComment})
+		}
+		spans = append(spans, ColorSpan{Start: start + i, End: start + i + n, Color: h.markerColor(), Category: CategoryMarker})
+		i += n
+		last = i
+	}
+	if last < len(runes) {
+		spans = append(spans, ColorSpan{Start: start + last, End: start + len(runes), Color: color, Category: CategoryComment})
+	}
+	return spans
+}
+
+// markerAt returns the rune length of the marker starting at runes[i], or 0
+// if none does. Markers only match as whole words.
+func (h *Highlighter) markerAt(runes []rune, i int) int {
+	if i > 0 && isMarkerWordRune(runes[i-1]) {
+		return 0
+	}
+	for _, m := range h.markers {
+		mr := []rune(m)
+		end := i + len(mr)
+		if len(mr) == 0 || end > len(runes) || string(runes[i:end]) != m {
+			continue
+		}
+		if end < len(runes) && isMarkerWordRune(runes[end]) {
+			continue
+		}
+		return len(mr)
+	}
+	return 0
+}
+
+// markerColor returns the escape sequence used for comment markers
+func (h *Highlighter) markerColor() string {
+	return markerStyle + ColorConverter(h.CategoryColor(CategoryMarker))
+}
+
+func isMarkerWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// hasMarker reports whether text may contain a marker, so plain comments
+// skip the rune-by-rune scan
+func (h *Highlighter) hasMarker(text string) bool {
+	for _, m := range h.markers {
+		if m != "" && strings.Contains(text, m) {
+			return true
+		}
+	}
+	return false
+}
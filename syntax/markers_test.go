@@ -0,0 +1,81 @@
+package syntax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommentMarkers(t *testing.T) {
+	h := New("main.go")
+	comment := ColorConverter(h.colors.Comment)
+	marker := h.markerColor()
+
+	tests := []struct {
+		name string
+		line string
+		want []ColorSpan
+	}{
+		{
+			"marker mid-comment",
+			"// fix TODO: later",
+			[]ColorSpan{
+				{Start: 0, End: 7, Color: comment, Category: CategoryComment},
+				{Start: 7, End: 11, Color: marker, Category: CategoryMarker},
+				{Start: 11, End: 18, Color: comment, Category: CategoryComment},
+			},
+		},
+		{
+			"no marker",
+			"// nothing to see",
+			[]ColorSpan{{Start: 0, End: 17, Color: comment, Category: CategoryComment}},
+		},
+		{
+			"marker inside a word is ignored",
+			"// TODOS and XXXL",
+			[]ColorSpan{{Start: 0, End: 17, Color: comment, Category: CategoryComment}},
+		},
+		{
+			"marker at the end",
+			"// FIXME",
+			[]ColorSpan{
+				{Start: 0, End: 3, Color: comment, Category: CategoryComment},
+				{Start: 3, End: 8, Color: marker, Category: CategoryMarker},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := h.GetLineColors(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: GetLineColors(%q) = %v, want %v", tt.name, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSetMarkers(t *testing.T) {
+	h := New("main.go")
+	comment := ColorConverter(h.colors.Comment)
+
+	h.SetMarkers([]string{"BUG"})
+	got := h.GetLineColors("// TODO BUG")
+	want := []ColorSpan{
+		{Start: 0, End: 8, Color: comment, Category: CategoryComment},
+		{Start: 8, End: 11, Color: h.markerColor(), Category: CategoryMarker},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("custom markers: spans = %v, want %v", got, want)
+	}
+
+	h.SetMarkers([]string{})
+	if got := h.GetLineColors("// TODO"); len(got) != 1 {
+		t.Errorf("empty marker list: spans = %v, want a single comment span", got)
+	}
+}
+
+func TestMarkersNotInCode(t *testing.T) {
+	h := New("main.go")
+	for _, span := range h.GetLineColors(`TODO := "FIXME"`) {
+		if span.Color == h.markerColor() {
+			t.Errorf("span %v uses the marker color outside a comment", span)
+		}
+	}
+}
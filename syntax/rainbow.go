@@ -0,0 +1,43 @@
+package syntax
+
+// RainbowPalette is the sequence of colors brackets cycle through by
+// nesting depth. The editor replaces it with the configured palette.
+var RainbowPalette = []string{"#ffd700", "#da70d6", "#179fff"}
+
+// RainbowSpans colors the brackets in line by nesting depth, starting at
+// depthAtLineStart, and returns the depth at the end of the line so the
+// caller can carry it to the next one. Brackets inside literals, the
+// line's string and comment runs from Literals, are skipped. A closing
+// bracket with nothing open is left uncolored.
+func RainbowSpans(line string, literals []Literal, depthAtLineStart int) (spans []ColorSpan, depthAtLineEnd int) {
+	depth := depthAtLineStart
+	col := 0
+	for _, r := range line {
+		for len(literals) > 0 && literals[0].End <= col {
+			literals = literals[1:]
+		}
+		inLiteral := len(literals) > 0 && literals[0].Start <= col
+		switch {
+		case inLiteral:
+		case r == '(' || r == '[' || r == '{':
+			spans = append(spans, rainbowSpan(col, depth))
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			if depth > 0 {
+				depth--
+				spans = append(spans, rainbowSpan(col, depth))
+			}
+		}
+		col++
+	}
+	return spans, depth
+}
+
+// rainbowSpan colors the single bracket at col for the given depth
+func rainbowSpan(col, depth int) ColorSpan {
+	if len(RainbowPalette) == 0 {
+		return ColorSpan{Start: col, End: col + 1}
+	}
+	color := RainbowPalette[depth%len(RainbowPalette)]
+	return ColorSpan{Start: col, End: col + 1, Color: ColorConverter(color)}
+}
@@ -0,0 +1,91 @@
+package syntax
+
+import "testing"
+
+func TestRainbowSpans(t *testing.T) {
+	saved := RainbowPalette
+	defer func() { RainbowPalette = saved }()
+	RainbowPalette = []string{"#ff0000", "#00ff00", "#0000ff"}
+	color := func(depth int) string { return ColorConverter(RainbowPalette[depth]) }
+
+	tests := []struct {
+		name      string
+		line      string
+		literals  []Literal
+		depth     int
+		want      []ColorSpan
+		wantDepth int
+	}{
+		{
+			"depth cycles through the palette",
+			"((([x])))",
+			nil,
+			0,
+			[]ColorSpan{
+				{Start: 0, End: 1, Color: color(0)}, {Start: 1, End: 2, Color: color(1)}, {Start: 2, End: 3, Color: color(2)}, {Start: 3, End: 4, Color: color(0)},
+				{Start: 5, End: 6, Color: color(0)}, {Start: 6, End: 7, Color: color(2)}, {Start: 7, End: 8, Color: color(1)}, {Start: 8, End: 9, Color: color(0)},
+			},
+			0,
+		},
+		{
+			"depth carried in",
+			"x) {",
+			nil,
+			2,
+			[]ColorSpan{{Start: 1, End: 2, Color: color(1)}, {Start: 3, End: 4, Color: color(1)}},
+			2,
+		},
+		{
+			"open bracket carried out",
+			"f(a, [",
+			nil,
+			0,
+			[]ColorSpan{{Start: 1, End: 2, Color: color(0)}, {Start: 5, End: 6, Color: color(1)}},
+			2,
+		},
+		{
+			"strings and comments skipped",
+			`f("(", '[') /* { */ // (`,
+			[]Literal{{2, 5, CategoryString}, {7, 10, CategoryString}, {12, 19, CategoryComment}, {20, 24, CategoryComment}},
+			0,
+			[]ColorSpan{{Start: 1, End: 2, Color: color(0)}, {Start: 10, End: 11, Color: color(0)}},
+			0,
+		},
+		{
+			"unmatched closer stays uncolored",
+			"a)",
+			nil,
+			0,
+			nil,
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		got, depth := RainbowSpans(tt.line, tt.literals, tt.depth)
+		if depth != tt.wantDepth {
+			t.Errorf("%s: depth = %d, want %d", tt.name, depth, tt.wantDepth)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: spans = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: span %d = %v, want %v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestRainbowSpansAcrossLines(t *testing.T) {
+	lines := []string{"func f() {", "\tif (a[0]) {", "\t}", "}"}
+	wantDepths := []int{1, 2, 1, 0}
+	depth := 0
+	for i, line := range lines {
+		_, depth = RainbowSpans(line, nil, depth)
+		if depth != wantDepths[i] {
+			t.Errorf("after line %d: depth = %d, want %d", i, depth, wantDepths[i])
+		}
+	}
+}
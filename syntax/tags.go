@@ -0,0 +1,227 @@
+package syntax
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+// TagRange locates a markup tag from its "<" to its ">". Columns are rune
+// indices and EndCol is exclusive.
+type TagRange struct {
+	StartLine, StartCol int
+	EndLine, EndCol     int
+}
+
+// Contains reports whether the rune at (line, col) lies inside the tag.
+func (r TagRange) Contains(line, col int) bool {
+	if line < r.StartLine || line > r.EndLine {
+		return false
+	}
+	if line == r.StartLine && col < r.StartCol {
+		return false
+	}
+	return line != r.EndLine || col < r.EndCol
+}
+
+// TagName locates the name of a markup tag, which is on one line. Columns
+// are rune indices and EndCol is exclusive.
+type TagName struct {
+	Line, StartCol, EndCol int
+}
+
+// voidElements are HTML elements that never have a closing tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// markupTag is a tag found while scanning a document
+type markupTag struct {
+	name        string
+	closing     bool // </name>
+	selfClosing bool // <name/> or an HTML void element
+	rng         TagRange
+	nameAt      TagName
+}
+
+// IsMarkup reports whether the current lexer is for HTML or XML.
+func (h *Highlighter) IsMarkup() bool {
+	switch h.Language() {
+	case "HTML", "XML":
+		return true
+	}
+	return false
+}
+
+// MatchingTag finds the tag under (line, col) and the tag that opens or
+// closes it. Columns are rune indices. ok is false when the cursor is not
+// in a tag, the tag is self-closing or void, or its partner is missing.
+func (h *Highlighter) MatchingTag(lines []string, line, col int) (tag, match TagRange, ok bool) {
+	if !h.IsMarkup() {
+		return TagRange{}, TagRange{}, false
+	}
+	tags := h.scanTags(lines, 0)
+	cur, partner := matchTag(tags, line, col)
+	if cur < 0 || partner < 0 {
+		return TagRange{}, TagRange{}, false
+	}
+	return tags[cur].rng, tags[partner].rng, true
+}
+
+// MatchingTagNames is MatchingTag for drawing: it lexes only lines
+// [first, end), which should cover the cursor and what is on screen, and
+// returns where the names of the tag under (line, col) and its partner
+// are. When the partner is not found before the end of those lines it
+// may lie beyond them, so unless they reach the edge of the document the
+// tag's own name is returned alone. Returns nil when there is nothing to
+// mark.
+func (h *Highlighter) MatchingTagNames(lines []string, first, end, line, col int) []TagName {
+	if !h.IsMarkup() {
+		return nil
+	}
+	first, end = max(0, first), min(end, len(lines))
+	if line < first || line >= end {
+		return nil
+	}
+	tags := h.scanTags(lines[first:end], first)
+	cur, partner := matchTag(tags, line, col)
+	switch {
+	case cur < 0:
+		return nil
+	case partner >= 0:
+		return []TagName{tags[cur].nameAt, tags[partner].nameAt}
+	case tags[cur].closing && first > 0, !tags[cur].closing && end < len(lines):
+		return []TagName{tags[cur].nameAt}
+	}
+	return nil
+}
+
+// matchTag returns the index in tags of the tag under (line, col) and of
+// its partner. cur is -1 when no tag with a partner is under the cursor,
+// and partner is -1 when none was found.
+func matchTag(tags []markupTag, line, col int) (cur, partner int) {
+	cur = -1
+	for i, t := range tags {
+		if t.rng.Contains(line, col) {
+			cur = i
+			break
+		}
+	}
+	if cur < 0 || tags[cur].selfClosing {
+		return -1, -1
+	}
+
+	name := tags[cur].name
+	step := 1
+	if tags[cur].closing {
+		step = -1
+	}
+	depth := 0
+	for i := cur; i >= 0 && i < len(tags); i += step {
+		t := tags[i]
+		if t.selfClosing || t.name != name {
+			continue
+		}
+		if t.closing == tags[cur].closing {
+			depth++
+		} else {
+			depth--
+		}
+		if depth == 0 {
+			return cur, i
+		}
+	}
+	return cur, -1
+}
+
+// scanTags tokenises lines and returns their tags in document order, with
+// lines numbered from firstLine. Only "<", ">" and "/" from tag and
+// punctuation tokens count as structure, so the same characters inside
+// attribute values, comments or script are ignored.
+func (h *Highlighter) scanTags(lines []string, firstLine int) []markupTag {
+	iterator, err := h.lexer.Tokenise(nil, strings.Join(lines, "\n"))
+	if err != nil {
+		return nil
+	}
+	html := h.Language() == "HTML"
+
+	var tags []markupTag
+	var text strings.Builder // Structural text of the tag being read
+	inTag := false
+	var start TagRange
+	var name TagName
+	line, col := firstLine, 0
+	for _, token := range iterator.Tokens() {
+		structural := token.Type == chroma.Punctuation || token.Type == chroma.NameTag
+		for _, r := range token.Value {
+			switch {
+			case !structural:
+				if inTag {
+					text.WriteByte(' ')
+				}
+			case r == '<':
+				inTag = true
+				text.Reset()
+				text.WriteRune(r)
+				start = TagRange{StartLine: line, StartCol: col}
+				name = TagName{}
+			case inTag && r == '>':
+				inTag = false
+				start.EndLine, start.EndCol = line, col+1
+				if t, ok := parseTag(text.String(), html); ok {
+					t.rng = start
+					t.nameAt = name
+					tags = append(tags, t)
+				}
+			case inTag:
+				text.WriteRune(r)
+				// The name is the first run of name characters in a tag
+				// token, which may also hold the "<" or "/"
+				if token.Type == chroma.NameTag && !unicode.IsSpace(r) && r != '/' {
+					if name == (TagName{}) {
+						name = TagName{line, col, col + 1}
+					} else if name.Line == line && name.EndCol == col {
+						name.EndCol++
+					}
+				}
+			}
+			if r == '\n' {
+				line++
+				col = 0
+			} else {
+				col++
+			}
+		}
+	}
+	return tags
+}
+
+// parseTag reads the name and kind of a tag from its structural text, which
+// starts with "<" and excludes the closing ">"
+func parseTag(text string, html bool) (markupTag, bool) {
+	body := strings.TrimPrefix(text, "<")
+	var t markupTag
+	if strings.HasPrefix(body, "/") {
+		t.closing = true
+		body = body[1:]
+	}
+	body = strings.TrimSpace(body)
+	if strings.HasSuffix(body, "/") {
+		t.selfClosing = true
+		body = strings.TrimSuffix(body, "/")
+	}
+	t.name, _, _ = strings.Cut(body, " ")
+	if t.name == "" || strings.ContainsAny(t.name[:1], "!?") {
+		return markupTag{}, false
+	}
+	if html {
+		t.name = strings.ToLower(t.name)
+		if voidElements[t.name] {
+			t.selfClosing = true
+		}
+	}
+	return t, true
+}
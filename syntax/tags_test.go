@@ -0,0 +1,87 @@
+package syntax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchingTag(t *testing.T) {
+	lines := []string{
+		`<div class="outer">`,
+		`  <div title="a > b">`,
+		`    <input type="text"/><br>`,
+		`  </div>`,
+		`</div>`,
+		`<p>`,
+	}
+
+	tests := []struct {
+		name      string
+		file      string
+		line, col int
+		wantTag   TagRange
+		wantMatch TagRange
+		wantOK    bool
+	}{
+		{"outer open", "a.html", 0, 2, TagRange{0, 0, 0, 19}, TagRange{4, 0, 4, 6}, true},
+		{"outer close", "a.html", 4, 3, TagRange{4, 0, 4, 6}, TagRange{0, 0, 0, 19}, true},
+		{"inner open with > in attribute", "a.html", 1, 15, TagRange{1, 2, 1, 21}, TagRange{3, 2, 3, 8}, true},
+		{"inner close", "a.html", 3, 2, TagRange{3, 2, 3, 8}, TagRange{1, 2, 1, 21}, true},
+		{"self-closing input", "a.html", 2, 6, TagRange{}, TagRange{}, false},
+		{"void element", "a.html", 2, 24, TagRange{}, TagRange{}, false},
+		{"unclosed tag", "a.html", 5, 1, TagRange{}, TagRange{}, false},
+		{"outside any tag", "a.html", 2, 0, TagRange{}, TagRange{}, false},
+		{"xml nesting", "a.xml", 1, 3, TagRange{1, 2, 1, 21}, TagRange{3, 2, 3, 8}, true},
+		{"not markup", "a.go", 0, 2, TagRange{}, TagRange{}, false},
+	}
+
+	for _, tt := range tests {
+		h := New(tt.file)
+		tag, match, ok := h.MatchingTag(lines, tt.line, tt.col)
+		if ok != tt.wantOK || tag != tt.wantTag || match != tt.wantMatch {
+			t.Errorf("%s: MatchingTag(%d, %d) = (%v, %v, %v), want (%v, %v, %v)",
+				tt.name, tt.line, tt.col, tag, match, ok, tt.wantTag, tt.wantMatch, tt.wantOK)
+		}
+	}
+}
+
+func TestMatchingTagXMLSelfClosing(t *testing.T) {
+	lines := []string{`<a><a/></a>`}
+	h := New("a.xml")
+	_, match, ok := h.MatchingTag(lines, 0, 0)
+	if want := (TagRange{0, 7, 0, 11}); !ok || match != want {
+		t.Errorf("MatchingTag = (%v, %v), want (%v, true)", match, ok, want)
+	}
+}
+
+func TestMatchingTagNames(t *testing.T) {
+	lines := []string{
+		`<div class="outer">`,
+		`  <p>text</p>`,
+		`</div>`,
+		`<span>`,
+	}
+
+	tests := []struct {
+		name       string
+		file       string
+		first, end int
+		line, col  int
+		want       []TagName
+	}{
+		{"both names", "a.html", 0, 4, 0, 12, []TagName{{0, 1, 4}, {2, 2, 5}}},
+		{"closing tag", "a.html", 0, 4, 1, 10, []TagName{{1, 11, 12}, {1, 3, 4}}},
+		{"partner below the window", "a.html", 0, 2, 0, 1, []TagName{{0, 1, 4}}},
+		{"partner above the window", "a.html", 1, 4, 2, 3, []TagName{{2, 2, 5}}},
+		{"unclosed at the end of the document", "a.html", 0, 4, 3, 1, nil},
+		{"xml", "a.xml", 0, 4, 1, 3, []TagName{{1, 3, 4}, {1, 11, 12}}},
+		{"cursor outside the window", "a.html", 2, 4, 0, 1, nil},
+	}
+
+	for _, tt := range tests {
+		got := New(tt.file).MatchingTagNames(lines, tt.first, tt.end, tt.line, tt.col)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: MatchingTagNames = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package textio holds helpers for deciding how file contents should be
+// read and shown.
+package textio
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/encoding"
+)
+
+// SniffLen is how many leading bytes IsBinary looks at
+const SniffLen = 8 * 1024
+
+// maxBadRatio is the fraction of suspicious bytes above which data counts
+// as binary. Legacy 8-bit text such as Latin-1 stays well below it.
+const maxBadRatio = 0.3
+
+// byteOrderMarks mark text in a Unicode encoding; UTF-16 and UTF-32 text is
+// full of NUL bytes and must not be mistaken for binary
+var byteOrderMarks = [][]byte{
+	{0xEF, 0xBB, 0xBF},       // UTF-8
+	{0xFF, 0xFE, 0x00, 0x00}, // UTF-32 LE
+	{0x00, 0x00, 0xFE, 0xFF}, // UTF-32 BE
+	{0xFF, 0xFE},             // UTF-16 LE
+	{0xFE, 0xFF},             // UTF-16 BE
+}
+
+// IsBinary reports whether data looks like binary rather than text, judging
+// from its first SniffLen bytes. Data with a Unicode byte order mark, or
+// that encoding.DetectUTF16 recognises as UTF-16 without one, is text. Otherwise any NUL byte makes it binary, as does a high share of
+// invalid UTF-8 and control characters other than common whitespace.
+func IsBinary(data []byte) bool {
+	if len(data) > SniffLen {
+		data = data[:SniffLen]
+	}
+	if len(data) == 0 {
+		return false
+	}
+	for _, bom := range byteOrderMarks {
+		if bytes.HasPrefix(data, bom) {
+			return false
+		}
+	}
+	if encoding.DetectUTF16(data) != nil {
+		return false
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+
+	bad := 0
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			// A sequence cut off by the sniff limit is not an error
+			if !utf8.FullRune(data[i:]) {
+				i = len(data)
+				continue
+			}
+			bad++
+		case r < 0x20 && !isTextControl(byte(r)):
+			bad++
+		}
+		i += size
+	}
+	return float64(bad)/float64(len(data)) > maxBadRatio
+}
+
+// isTextControl reports whether the control byte c turns up in ordinary
+// text files
+func isTextControl(c byte) bool {
+	switch c {
+	case '\t', '\n', '\r', '\f', '\v', '\b', 0x1b:
+		return true
+	}
+	return false
+}
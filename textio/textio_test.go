@@ -0,0 +1,40 @@
+package textio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	// Mostly high bytes and control codes, like compressed data
+	noise := make([]byte, 512)
+	for i := range noise {
+		noise[i] = byte(0x80 + i%0x60)
+		if i%5 == 0 {
+			noise[i] = byte(1 + i%8)
+		}
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", nil, false},
+		{"ascii text", []byte("package main\n\nfunc main() {}\n"), false},
+		{"utf-8 text", []byte("héllo wörld, 日本語のテキスト\r\n"), false},
+		{"latin-1 text", []byte("caf\xe9 cr\xe8me br\xfbl\xe9e and more plain words"), false},
+		{"embedded nul", []byte("text\x00more text"), true},
+		{"utf-16 with bom", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, false},
+		{"utf-16 without bom", []byte{'h', 0, 'i', 0, '!', 0}, false},
+		{"predominantly binary", noise, true},
+		{"nul past the sniff limit", append(bytes.Repeat([]byte("a"), SniffLen), 0), false},
+		{"multibyte rune cut at the sniff limit", append(bytes.Repeat([]byte("a"), SniffLen-1), "é"...), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsBinary(tt.data); got != tt.want {
+			t.Errorf("%s: IsBinary = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -12,14 +12,30 @@ type ColumnRenderer interface {
 	Render(width, height int, state *RenderState) []string
 }
 
+// StyledRenderer is implemented by column renderers that draw with theme
+// colors and need to be told when the theme changes.
+type StyledRenderer interface {
+	SetStyles(styles Styles)
+}
+
 // Column represents a single column in the compositor layout.
 type Column struct {
+	Name     string         // Identifies the column to ColumnByName (may be empty)
 	Width    int            // Fixed width in cells (0 if flexible)
 	Flexible bool           // If true, this column takes remaining space
 	Enabled  bool           // Whether this column is currently shown
 	Renderer ColumnRenderer // The renderer for this column
 }
 
+// Names of the editor's standard columns.
+const (
+	ColumnLineNumbers = "line_numbers"
+	ColumnModified    = "modified"
+	ColumnText        = "text"
+	ColumnMinimap     = "minimap"
+	ColumnScrollbar   = "scrollbar"
+)
+
 // RenderState holds shared state passed to all column renderers.
 // This allows columns to render consistently without direct coupling.
 type RenderState struct {
@@ -30,6 +46,17 @@ type RenderState struct {
 	CursorLine int
 	CursorCol  int
 
+	// GhostText is a suggestion drawn dim from the cursor onward when the
+	// cursor is at the end of its line. It is not part of Lines.
+	GhostText string
+
+	// CursorShape is the shape the cursor and any secondary cursors are
+	// drawn in
+	CursorShape CursorStyle
+
+	// Secondary cursors for multi-cursor editing (map of line index to rune columns)
+	SecondaryCursors map[int][]int
+
 	// Scroll position
 	ScrollY int // First visible line (visual line for word wrap)
 	ScrollX int // Horizontal scroll offset
@@ -37,19 +64,107 @@ type RenderState struct {
 	// Selection state (map of line index to selection range)
 	Selection map[int]SelectionRange
 
+	// Folded line ranges, sorted; each is drawn as one summary row. Only
+	// honored without word wrap.
+	Folds []FoldedRange
+
 	// Syntax highlighting (map of line index to color spans)
 	LineColors map[int][]syntax.ColorSpan
 
+	// Lines longer than this many bytes are drawn without LineColors, so a
+	// huge line renders plainly (0 = no limit)
+	HighlightLimit int
+
+	// Lines with unsaved changes since the last save
+	ModifiedLines map[int]bool
+
 	// Display options
-	WordWrap bool
-	TabWidth int // Display width of tabs
+	WordWrap     bool
+	VirtualSpace bool // CursorCol may be past the end of its line; pad with spaces up to it
+	TabWidth     int  // Display width of tabs
+	TextWidth    int  // Width of the text column (used by gutters to follow wrapping)
+
+	// Indent guides in leading whitespace; ActiveGuide, if set, is drawn
+	// brighter than the rest
+	IndentGuides bool
+	ActiveGuide  *IndentScope
+	IndentGuide  string // Glyph drawn for each guide (empty = "│")
+
+	// Markers drawn by the text column; neither is part of Lines
+	EOLMarker       string // One-cell glyph drawn after the end of each line (empty = none)
+	HideEndOfBuffer bool   // Leave rows past the last line blank instead of marking them with ~
 
 	// Total document metrics (used by scrollbar, minimap)
 	TotalLines       int // Total buffer lines
 	TotalVisualLines int // Total visual lines (with word wrap)
+	CursorVisualLine int // Visual line of the cursor, as the viewport counts them
 
 	// Styles for rendering
 	Styles Styles
 }
 
+// lineColors returns the color spans for line i, or nil when the line is
+// past HighlightLimit.
+func (s *RenderState) lineColors(i int) []syntax.ColorSpan {
+	if s.HighlightLimit > 0 && i < len(s.Lines) && len(s.Lines[i]) > s.HighlightLimit {
+		return nil
+	}
+	return s.LineColors[i]
+}
+
+// isSecondaryCursor reports whether a secondary cursor sits at (line, col).
+func (s *RenderState) isSecondaryCursor(line, col int) bool {
+	for _, c := range s.SecondaryCursors[line] {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibleRange returns the first and last buffer lines (inclusive) with at
+// least one row on screen when the text column shows viewportHeight rows of
+// state. With word wrap, ScrollY counts visual rows, so the first line may
+// be only partly visible. Lines are wrapped at TextWidth the way
+// TextRenderer wraps them. lastLine is less than firstLine when no line is
+// visible.
+func VisibleRange(viewportHeight int, state *RenderState) (firstLine, lastLine int) {
+	if state == nil || viewportHeight <= 0 || len(state.Lines) == 0 {
+		return 0, -1
+	}
+	lines := state.Lines
+	scrollY := state.ScrollY
+	if scrollY < 0 {
+		scrollY = 0
+	}
+
+	if !state.WordWrap {
+		return scrollY, min(scrollY+viewportHeight, len(lines)) - 1
+	}
+
+	tabWidth := state.TabWidth
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	rows := func(i int) int { return countWrappedLinesLocal(lines[i], state.TextWidth, tabWidth) }
+
+	// Skip the lines scrolled entirely off the top
+	row := 0
+	for firstLine < len(lines) && row+rows(firstLine) <= scrollY {
+		row += rows(firstLine)
+		firstLine++
+	}
+	if firstLine == len(lines) {
+		return firstLine, firstLine - 1
+	}
+
+	lastLine = firstLine
+	row += rows(firstLine)
+	for lastLine+1 < len(lines) && row < scrollY+viewportHeight {
+		lastLine++
+		row += rows(lastLine)
+	}
+	return firstLine, lastLine
+}
+
 // Note: SelectionRange is defined in viewport.go
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVisibleRange(t *testing.T) {
+	long := strings.Repeat("x", 25) // Three rows at width 10
+	tests := []struct {
+		name      string
+		lines     []string
+		wrap      bool
+		scrollY   int
+		height    int
+		wantFirst int
+		wantLast  int
+	}{
+		{"unwrapped page", make([]string, 100), false, 10, 20, 10, 29},
+		{"unwrapped partial last page", make([]string, 25), false, 15, 20, 15, 24},
+		{"unwrapped scrolled past the end", make([]string, 5), false, 8, 20, 8, 4},
+		{"wrapped lines take several rows", []string{long, long, long, long}, true, 0, 5, 0, 1},
+		{"wrapped first line partly scrolled off", []string{long, long, long, long}, true, 2, 3, 0, 1},
+		{"wrapped scroll at a line boundary", []string{long, "a", long, "b"}, true, 3, 2, 1, 2},
+		{"wrapped partial last page", []string{long, "a", long, "b"}, true, 4, 20, 2, 3},
+		{"wrapped scrolled past the end", []string{"a", "b"}, true, 5, 3, 2, 1},
+	}
+
+	for _, tt := range tests {
+		state := &RenderState{
+			Lines:     tt.lines,
+			ScrollY:   tt.scrollY,
+			WordWrap:  tt.wrap,
+			TabWidth:  4,
+			TextWidth: 10,
+		}
+		first, last := VisibleRange(tt.height, state)
+		if first != tt.wantFirst || last != tt.wantLast {
+			t.Errorf("%s: VisibleRange(%d) = (%d, %d), want (%d, %d)",
+				tt.name, tt.height, first, last, tt.wantFirst, tt.wantLast)
+		}
+	}
+}
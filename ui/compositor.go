@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"os"
 	"strings"
 
 	"github.com/mattn/go-runewidth"
@@ -8,9 +9,10 @@ import (
 
 // Compositor joins multiple columns horizontally to produce the final viewport output.
 type Compositor struct {
-	columns []Column
-	width   int
-	height  int
+	columns  []Column
+	overlays []Overlay // Applied in order on top of the joined columns
+	width    int
+	height   int
 }
 
 // NewCompositor creates a new compositor with the given dimensions.
@@ -48,6 +50,26 @@ func (c *Compositor) SetColumns(cols []Column) {
 	c.columns = cols
 }
 
+// AddOverlay adds an overlay, drawn on top of those added before it.
+func (c *Compositor) AddOverlay(o Overlay) {
+	c.overlays = append(c.overlays, o)
+}
+
+// SetOverlays replaces all overlays.
+func (c *Compositor) SetOverlays(overlays []Overlay) {
+	c.overlays = overlays
+}
+
+// SetStyles passes new styles to every column renderer that draws with
+// theme colors, enabled or not, so a theme switch applies on the next Render.
+func (c *Compositor) SetStyles(styles Styles) {
+	for _, col := range c.columns {
+		if r, ok := col.Renderer.(StyledRenderer); ok {
+			r.SetStyles(styles)
+		}
+	}
+}
+
 // GetColumns returns a copy of the current columns.
 func (c *Compositor) GetColumns() []Column {
 	result := make([]Column, len(c.columns))
@@ -62,6 +84,29 @@ func (c *Compositor) EnableColumn(index int, enabled bool) {
 	}
 }
 
+// ColumnByName returns the column with the given name. The pointer refers
+// to the compositor's own column, so changes to it take effect on the next
+// Render. ok is false when no column has that name.
+func (c *Compositor) ColumnByName(name string) (col *Column, ok bool) {
+	if name == "" {
+		return nil, false
+	}
+	for i := range c.columns {
+		if c.columns[i].Name == name {
+			return &c.columns[i], true
+		}
+	}
+	return nil, false
+}
+
+// EnableColumnByName enables or disables the column with the given name.
+// An unknown name is ignored.
+func (c *Compositor) EnableColumnByName(name string, enabled bool) {
+	if col, ok := c.ColumnByName(name); ok {
+		col.Enabled = enabled
+	}
+}
+
 // calculateColumnWidths determines the actual width for each enabled column.
 // Fixed columns get their specified width; the flexible column gets the remainder.
 func (c *Compositor) calculateColumnWidths() []int {
@@ -107,6 +152,28 @@ func (c *Compositor) FlexibleColumnWidth() int {
 	return c.width // No flexible column, return full width
 }
 
+// ColumnAtX maps the absolute screen column x to the column drawn there,
+// returning its index and x relative to the column's left edge. Disabled and
+// zero-width columns take no space and are never returned. ok is false when
+// x lies outside every column.
+func (c *Compositor) ColumnAtX(x int) (index int, localX int, ok bool) {
+	if x < 0 {
+		return 0, 0, false
+	}
+	widths := c.calculateColumnWidths()
+	offset := 0
+	for i, col := range c.columns {
+		if !col.Enabled || widths[i] == 0 {
+			continue
+		}
+		if x < offset+widths[i] {
+			return i, x - offset, true
+		}
+		offset += widths[i]
+	}
+	return 0, 0, false
+}
+
 // Render renders all enabled columns and joins them horizontally.
 func (c *Compositor) Render(state *RenderState) string {
 	if len(c.columns) == 0 || c.height <= 0 {
@@ -139,20 +206,87 @@ func (c *Compositor) Render(state *RenderState) string {
 	}
 
 	// Join columns horizontally, row by row
-	var result strings.Builder
-	for row := 0; row < c.height; row++ {
-		if row > 0 {
-			result.WriteString("\n")
-		}
+	rows := make([]string, c.height)
+	for row := range rows {
+		var sb strings.Builder
 		for i, col := range c.columns {
 			if !col.Enabled || widths[i] == 0 {
 				continue
 			}
-			result.WriteString(columnOutputs[i][row])
+			sb.WriteString(closeSGR(columnOutputs[i][row]))
 		}
+		rows[row] = sb.String()
 	}
 
-	return result.String()
+	for _, o := range c.overlays {
+		rows = o.Apply(rows, state)
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// RenderANSI renders like Render but as a standalone artifact for
+// screenshots: every row ends with a reset and a newline, so the output can
+// be cat-ed back to a terminal or embedded in docs without colors bleeding.
+func (c *Compositor) RenderANSI(state *RenderState) string {
+	out := c.Render(state)
+	if out == "" {
+		return ""
+	}
+	var sb strings.Builder
+	for _, row := range strings.Split(out, "\n") {
+		sb.WriteString(row)
+		sb.WriteString(sgrReset() + "\n")
+	}
+	return sb.String()
+}
+
+// RenderToANSIFile writes RenderANSI output to path.
+func (c *Compositor) RenderToANSIFile(path string, state *RenderState) error {
+	return os.WriteFile(path, []byte(c.RenderANSI(state)), 0644)
+}
+
+// PlainTextColumn extracts the flexible (text) column from output, a
+// previous Render result for the same layout, dropping gutters such as line
+// numbers, the minimap and the scrollbar. Rows are ANSI-stripped with their
+// trailing padding removed, ready for copying as plain text.
+func (c *Compositor) PlainTextColumn(output string) []string {
+	widths := c.calculateColumnWidths()
+	offset := 0
+	for i, col := range c.columns {
+		if !col.Enabled || widths[i] == 0 {
+			continue
+		}
+		if col.Flexible {
+			return ExtractColumn(output, offset, widths[i])
+		}
+		offset += widths[i]
+	}
+	return ExtractColumn(output, 0, c.width)
+}
+
+// ExtractColumn returns the cells [offset, offset+width) of each row of
+// output, measured in visual columns, with ANSI codes and trailing spaces
+// removed. A wide character straddling either edge is dropped.
+func ExtractColumn(output string, offset, width int) []string {
+	rows := strings.Split(output, "\n")
+	result := make([]string, len(rows))
+	for i, row := range rows {
+		var sb strings.Builder
+		col := 0
+		for _, r := range stripANSI(row) {
+			w := runewidth.RuneWidth(r)
+			if col >= offset && col+w <= offset+width {
+				sb.WriteRune(r)
+			}
+			col += w
+			if col >= offset+width {
+				break
+			}
+		}
+		result[i] = strings.TrimRight(sb.String(), " ")
+	}
+	return result
 }
 
 // visualWidth calculates the visible width of a string, ignoring ANSI escape codes.
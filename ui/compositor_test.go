@@ -1,8 +1,12 @@
 package ui
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/cornish/textivus-editor/config"
 )
 
 // mockRenderer is a simple test renderer that produces fixed content.
@@ -20,14 +24,18 @@ func (m *mockRenderer) Render(width, height int, state *RenderState) []string {
 
 // mockColorRenderer produces content with ANSI color codes.
 type mockColorRenderer struct {
-	char  string
-	color string // ANSI color code
+	char    string
+	color   string // ANSI color code
+	noReset bool   // Leave the color open at the end of each row
 }
 
 func (m *mockColorRenderer) Render(width, height int, state *RenderState) []string {
 	rows := make([]string, height)
 	for i := 0; i < height; i++ {
-		rows[i] = m.color + strings.Repeat(m.char, width) + "\033[0m"
+		rows[i] = m.color + strings.Repeat(m.char, width)
+		if !m.noReset {
+			rows[i] += "\033[0m"
+		}
 	}
 	return rows
 }
@@ -225,3 +233,282 @@ func TestVisualWidth(t *testing.T) {
 		}
 	}
 }
+
+func TestCompositorSetStyles(t *testing.T) {
+	oldStyles := NewStyles(config.GetTheme("default"))
+	newStyles := NewStyles(config.GetTheme("dracula"))
+
+	lineNums := NewLineNumberRenderer(oldStyles)
+	gutter := NewModifiedGutterRenderer(oldStyles)
+	gutter.SetEnabled(true)
+	scrollbar := NewScrollbar(oldStyles)
+	scrollbar.SetEnabled(true)
+
+	c := NewCompositor(20, 3)
+	c.SetColumns([]Column{
+		{Width: 5, Enabled: true, Renderer: lineNums},
+		{Width: 1, Enabled: true, Renderer: gutter},
+		{Flexible: true, Enabled: true, Renderer: &mockRenderer{char: "T"}},
+		{Width: 1, Enabled: true, Renderer: NewScrollbarColumnAdapter(scrollbar)},
+	})
+
+	state := &RenderState{
+		Lines:         []string{"a", "b", "c", "d", "e"},
+		ModifiedLines: map[int]bool{0: true},
+		TotalLines:    5,
+	}
+
+	c.SetStyles(newStyles)
+	out := c.Render(state)
+
+	ui := newStyles.Theme.UI
+	for name, code := range map[string]string{
+		"line number":   ColorToANSIFg(ui.LineNumber),
+		"modified line": ColorToANSIFg(ui.ModifiedLine),
+		"scrollbar":     ColorToANSIFg(ui.ScrollbarThumb),
+	} {
+		if !strings.Contains(out, code) {
+			t.Errorf("%s color %q not in output after SetStyles", name, code)
+		}
+	}
+	if oldCode := ColorToANSIFg(oldStyles.Theme.UI.ModifiedLine); strings.Contains(out, oldCode) {
+		t.Errorf("old modified line color %q still in output", oldCode)
+	}
+}
+
+func TestCompositorRenderANSI(t *testing.T) {
+	c := NewCompositor(6, 2)
+	c.SetColumns([]Column{
+		{Width: 2, Enabled: true, Renderer: &mockColorRenderer{char: "L", color: "\033[31m"}},
+		{Flexible: true, Enabled: true, Renderer: &mockRenderer{char: "T"}},
+	})
+
+	out := c.RenderANSI(nil)
+	if !strings.HasSuffix(out, "\033[0m\n") {
+		t.Errorf("RenderANSI output does not end with reset and newline: %q", out)
+	}
+	if got, want := stripANSI(out), "LLTTTT\nLLTTTT\n"; got != want {
+		t.Errorf("stripped output = %q, want %q", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "shot.ans")
+	if err := c.RenderToANSIFile(path, nil); err != nil {
+		t.Fatalf("RenderToANSIFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != out {
+		t.Errorf("file content = %q, want %q", data, out)
+	}
+}
+
+func TestCompositorPlainTextColumn(t *testing.T) {
+	styles := DefaultStyles()
+	scrollbar := NewScrollbar(styles)
+	scrollbar.SetEnabled(true)
+	state := &RenderState{
+		Lines:      []string{"hello", "日本語 ok", "x"},
+		CursorLine: 9, // Keep the cursor out of view
+		TabWidth:   4,
+		TotalLines: 3,
+	}
+
+	tests := []struct {
+		name        string
+		lineNumbers bool
+		scrollbar   bool
+	}{
+		{"line numbers and scrollbar", true, true},
+		{"line numbers only", true, false},
+		{"no gutters", false, false},
+	}
+
+	for _, tt := range tests {
+		c := NewCompositor(20, 4)
+		c.SetColumns([]Column{
+			{Width: 5, Enabled: tt.lineNumbers, Renderer: NewLineNumberRenderer(styles)},
+			{Flexible: true, Enabled: true, Renderer: NewTextRenderer(styles)},
+			{Width: 1, Enabled: tt.scrollbar, Renderer: NewScrollbarColumnAdapter(scrollbar)},
+		})
+		got := c.PlainTextColumn(c.Render(state))
+		want := []string{"hello", "日本語 ok", "x", "~"}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: PlainTextColumn = %q, want %q", tt.name, got, want)
+		}
+	}
+}
+
+// wideRenderer fills each row with double-width characters.
+type wideRenderer struct{}
+
+func (wideRenderer) Render(width, height int, state *RenderState) []string {
+	rows := make([]string, height)
+	for i := range rows {
+		rows[i] = strings.Repeat("日", width/2) + strings.Repeat(" ", width%2)
+	}
+	return rows
+}
+
+// tintOverlay recolors the cell at (row, col) with code.
+type tintOverlay struct {
+	row, col int
+	code     string
+}
+
+func (o tintOverlay) Apply(rows []string, state *RenderState) []string {
+	cell := stripANSI(SliceANSI(rows[o.row], o.col, o.col+1))
+	rows[o.row] = OverlayAt(o.code+cell+"\033[0m", rows[o.row], o.col)
+	return rows
+}
+
+func TestCompositorOverlays(t *testing.T) {
+	const red, blue = "\033[31m", "\033[34m"
+	tests := []struct {
+		name     string
+		renderer ColumnRenderer
+		overlays []Overlay
+		wantText string // Plain text of the tinted row
+		wantCode string // Code expected right before the tinted cell
+	}{
+		{
+			"tints one cell",
+			&mockColorRenderer{char: "x", color: "\033[32m"},
+			[]Overlay{tintOverlay{1, 3, red}},
+			"Lxxxxxxxx",
+			red,
+		},
+		{
+			"later overlays draw on top",
+			&mockRenderer{char: "y"},
+			[]Overlay{tintOverlay{1, 3, red}, tintOverlay{1, 3, blue}},
+			"Lyyyyyyyy",
+			blue,
+		},
+		{
+			"wide character cut by the overlay becomes spaces",
+			wideRenderer{},
+			[]Overlay{tintOverlay{1, 3, red}},
+			"L日  日日",
+			red,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewCompositor(9, 3)
+		c.AddColumn(Column{Width: 1, Enabled: true, Renderer: &mockRenderer{char: "L"}})
+		c.AddColumn(Column{Flexible: true, Enabled: true, Renderer: tt.renderer})
+		c.SetOverlays(tt.overlays)
+
+		rows := strings.Split(c.Render(&RenderState{}), "\n")
+		if len(rows) != 3 {
+			t.Fatalf("%s: %d rows, want 3", tt.name, len(rows))
+		}
+		for i, row := range rows {
+			if w := visualWidth(row); w != 9 {
+				t.Errorf("%s: row %d width = %d, want 9: %q", tt.name, i, w, row)
+			}
+		}
+		if i := strings.LastIndex(rows[1], tt.wantCode); i < 0 || visualWidth(rows[1][:i]) != 3 {
+			t.Errorf("%s: tint %q not at column 3: %q", tt.name, tt.wantCode, rows[1])
+		}
+		if got := stripANSI(rows[1]); got != tt.wantText {
+			t.Errorf("%s: row 1 text = %q, want %q", tt.name, got, tt.wantText)
+		}
+	}
+
+	// Without overlays the joined columns are returned as they are
+	c := NewCompositor(9, 1)
+	c.AddColumn(Column{Flexible: true, Enabled: true, Renderer: &mockRenderer{char: "z"}})
+	if got := c.Render(&RenderState{}); got != "zzzzzzzzz" {
+		t.Errorf("without overlays: %q, want %q", got, "zzzzzzzzz")
+	}
+}
+
+func TestCompositorColumnAtX(t *testing.T) {
+	c := NewCompositor(30, 2)
+	c.SetColumns([]Column{
+		{Width: 4, Enabled: true},       // Line numbers: 0-3
+		{Width: 2, Enabled: false},      // Disabled gutter takes no space
+		{Flexible: true, Enabled: true}, // Text: 4-18
+		{Width: 10, Enabled: true},      // Minimap: 19-28
+		{Width: 0, Enabled: true},       // Zero width
+		{Width: 1, Enabled: true},       // Scrollbar: 29
+	})
+
+	tests := []struct {
+		x         int
+		wantIndex int
+		wantLocal int
+		wantOK    bool
+	}{
+		{0, 0, 0, true},
+		{3, 0, 3, true},
+		{4, 2, 0, true},
+		{18, 2, 14, true},
+		{19, 3, 0, true},
+		{28, 3, 9, true},
+		{29, 5, 0, true},
+		{30, 0, 0, false},
+		{-1, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		index, local, ok := c.ColumnAtX(tt.x)
+		if index != tt.wantIndex || local != tt.wantLocal || ok != tt.wantOK {
+			t.Errorf("ColumnAtX(%d) = (%d, %d, %v), want (%d, %d, %v)",
+				tt.x, index, local, ok, tt.wantIndex, tt.wantLocal, tt.wantOK)
+		}
+	}
+}
+
+func TestCompositorClosesOpenColumnColors(t *testing.T) {
+	c := NewCompositor(5, 2)
+	c.AddColumn(Column{Width: 2, Enabled: true, Renderer: &mockColorRenderer{char: "L", color: "\033[31m", noReset: true}})
+	c.AddColumn(Column{Flexible: true, Enabled: true, Renderer: &mockRenderer{char: "T"}})
+
+	for i, row := range strings.Split(c.Render(&RenderState{}), "\n") {
+		if want := "\033[31mLL\033[0mTTT"; row != want {
+			t.Errorf("row %d = %q, want %q", i, row, want)
+		}
+	}
+}
+
+func TestCompositorEnableColumnByName(t *testing.T) {
+	c := NewCompositor(10, 1)
+	c.SetColumns([]Column{
+		{Name: ColumnLineNumbers, Width: 3, Enabled: true, Renderer: &mockRenderer{char: "L"}},
+		{Flexible: true, Enabled: true, Renderer: &mockRenderer{char: "T"}},
+		{Name: ColumnMinimap, Width: 2, Enabled: false, Renderer: &mockRenderer{char: "M"}},
+	})
+
+	c.EnableColumnByName(ColumnMinimap, true)
+	if got, want := c.Render(nil), "LLLTTTTTMM"; got != want {
+		t.Errorf("after enabling minimap: got %q, want %q", got, want)
+	}
+
+	c.EnableColumnByName(ColumnLineNumbers, false)
+	if got, want := c.Render(nil), "TTTTTTTTMM"; got != want {
+		t.Errorf("after disabling line numbers: got %q, want %q", got, want)
+	}
+
+	// Unknown and empty names change nothing
+	c.EnableColumnByName("no-such-column", false)
+	c.EnableColumnByName("", false)
+	if got, want := c.Render(nil), "TTTTTTTTMM"; got != want {
+		t.Errorf("after unknown names: got %q, want %q", got, want)
+	}
+
+	col, ok := c.ColumnByName(ColumnMinimap)
+	if !ok || col.Width != 2 || !col.Enabled {
+		t.Fatalf("ColumnByName(%q) = %+v, %v", ColumnMinimap, col, ok)
+	}
+	col.Width = 4
+	if got, want := c.Render(nil), "TTTTTTMMMM"; got != want {
+		t.Errorf("after widening through ColumnByName: got %q, want %q", got, want)
+	}
+	if _, ok := c.ColumnByName("no-such-column"); ok {
+		t.Error("ColumnByName found a column for an unknown name")
+	}
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// CursorStyle is a cursor shape. The values are the DECSCUSR parameters,
+// so CursorDefault asks the terminal for its own default; the text
+// renderer draws it as the reverse-video block.
+type CursorStyle int
+
+const (
+	CursorDefault CursorStyle = iota
+	CursorBlinkingBlock
+	CursorBlock
+	CursorBlinkingUnderline
+	CursorUnderline
+	CursorBlinkingBar
+	CursorBar
+)
+
+// ResetCursorStyle restores the terminal's default cursor shape.
+const ResetCursorStyle = "\033[0 q"
+
+// cursorStyleNames maps config names to styles
+var cursorStyleNames = map[string]CursorStyle{
+	"default":            CursorDefault,
+	"blinking-block":     CursorBlinkingBlock,
+	"block":              CursorBlock,
+	"blinking-underline": CursorBlinkingUnderline,
+	"underline":          CursorUnderline,
+	"blinking-bar":       CursorBlinkingBar,
+	"bar":                CursorBar,
+}
+
+// ParseCursorStyle parses a cursor_style setting such as "bar" or
+// "blinking-block". Returns false for empty and unknown names.
+func ParseCursorStyle(s string) (CursorStyle, bool) {
+	style, ok := cursorStyleNames[strings.ToLower(strings.TrimSpace(s))]
+	return style, ok
+}
+
+// Sequence returns the DECSCUSR escape that selects the style for the
+// terminal's own cursor.
+func (s CursorStyle) Sequence() string {
+	return fmt.Sprintf("\033[%d q", int(s))
+}
+
+// cell draws char, the contents of the text cursor's cell, in the style's
+// shape. Bubble Tea hides the terminal's own cursor while it runs, so
+// this is what the user sees. A block is drawn in reverse video, an underline underlines the
+// cell in its own color, and a bar is a thin line at the left of a blank
+// cell. A cell can't hold a bar beside a character, so over text the bar
+// is drawn as an underline. Blinking styles blink. color is the cell's
+// color escape, or "", which an underline keeps; a block is drawn in
+// reverse video of the default colors.
+func (s CursorStyle) cell(char, color string) string {
+	blink := ""
+	if s == CursorBlinkingBlock || s == CursorBlinkingUnderline || s == CursorBlinkingBar {
+		blink = "\033[5m"
+	}
+	if !colorEnabled {
+		color = ""
+	}
+	switch s {
+	case CursorBar, CursorBlinkingBar:
+		if strings.TrimSpace(char) == "" {
+			// The rest of a wide blank or an expanded tab stays blank
+			return blink + "▏" + sgrReset() + strings.Repeat(" ", max(runewidth.StringWidth(char)-1, 0))
+		}
+		fallthrough
+	case CursorUnderline, CursorBlinkingUnderline:
+		return color + blink + "\033[4m" + char + sgrReset()
+	}
+	return blink + "\033[7m" + char + sgrReset()
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseCursorStyle(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   CursorStyle
+		wantOK bool
+	}{
+		{"default", CursorDefault, true},
+		{"blinking-block", CursorBlinkingBlock, true},
+		{"block", CursorBlock, true},
+		{"blinking-underline", CursorBlinkingUnderline, true},
+		{"underline", CursorUnderline, true},
+		{"blinking-bar", CursorBlinkingBar, true},
+		{"bar", CursorBar, true},
+		{" Bar ", CursorBar, true},
+		{"beam", CursorDefault, false},
+		{"", CursorDefault, false},
+	}
+
+	for _, tt := range tests {
+		style, ok := ParseCursorStyle(tt.name)
+		if ok != tt.wantOK || style != tt.want {
+			t.Errorf("ParseCursorStyle(%q) = (%d, %v), want (%d, %v)", tt.name, style, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCursorStyleSequence(t *testing.T) {
+	tests := []struct {
+		style CursorStyle
+		want  string
+	}{
+		{CursorDefault, "\033[0 q"},
+		{CursorBlinkingBlock, "\033[1 q"},
+		{CursorBlock, "\033[2 q"},
+		{CursorBlinkingUnderline, "\033[3 q"},
+		{CursorUnderline, "\033[4 q"},
+		{CursorBlinkingBar, "\033[5 q"},
+		{CursorBar, "\033[6 q"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.style.Sequence(); got != tt.want {
+			t.Errorf("style %d: Sequence() = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+	if ResetCursorStyle != CursorDefault.Sequence() {
+		t.Errorf("ResetCursorStyle = %q, want %q", ResetCursorStyle, CursorDefault.Sequence())
+	}
+}
+
+func TestCursorBarOverMultibyteBlank(t *testing.T) {
+	tests := []struct {
+		char string
+		want string
+	}{
+		{" ", "▏\033[0m"},
+		{"\u00a0", "▏\033[0m"},  // No-break space
+		{"\u3000", "▏\033[0m "}, // Ideographic space, two cells
+		{"    ", "▏\033[0m   "}, // Expanded tab
+	}
+
+	for _, tt := range tests {
+		got := CursorBar.cell(tt.char, "")
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("bar over %q = %q, want %q", tt.char, got, tt.want)
+		}
+	}
+}
+
+func TestTextRendererCursorShapes(t *testing.T) {
+	r := NewTextRenderer(DefaultStyles())
+	tests := []struct {
+		shape   CursorStyle
+		col     int
+		want    string // The cursor cell as drawn
+		notWant string
+	}{
+		{CursorDefault, 1, "\033[7mb\033[0m", ""},
+		{CursorBlock, 2, "\033[7m \033[0m", ""},
+		{CursorBlinkingBlock, 1, "\033[5m\033[7mb\033[0m", ""},
+		{CursorUnderline, 1, "\033[4mb\033[0m", "\033[7m"},
+		{CursorBlinkingUnderline, 1, "\033[5m\033[4mb\033[0m", "\033[7m"},
+		{CursorBar, 2, "▏\033[0m", "\033[7m"},
+		{CursorBar, 1, "\033[4mb\033[0m", "\033[7m"},
+	}
+
+	for _, tt := range tests {
+		for _, wrap := range []bool{false, true} {
+			state := &RenderState{
+				Lines:       []string{"ab"},
+				CursorCol:   tt.col,
+				CursorShape: tt.shape,
+				WordWrap:    wrap,
+				TabWidth:    4,
+			}
+			row := r.Render(6, 1, state)[0]
+			if !strings.Contains(row, tt.want) || (tt.notWant != "" && strings.Contains(row, tt.notWant)) {
+				t.Errorf("shape %d at col %d, wrap=%v: row = %q, want the cell %q", tt.shape, tt.col, wrap, row, tt.want)
+			}
+			if got := visualWidth(row); got != 6 {
+				t.Errorf("shape %d at col %d, wrap=%v: row width = %d, want 6", tt.shape, tt.col, wrap, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// FoldedRange is a run of buffer lines hidden by a fold, Start and End
+// inclusive. The text column draws it as one summary row.
+type FoldedRange struct {
+	Start int
+	End   int
+}
+
+// displayRow is what one row of the unwrapped text column shows: a buffer
+// line, or the summary of a fold starting at that line.
+type displayRow struct {
+	line   int // Buffer line; len(Lines) or more is past the end
+	folded int // Lines hidden by a fold summarized on this row (0 = none)
+}
+
+// displayRows maps the height rows starting at ScrollY to buffer lines,
+// collapsing each folded range to a single row. With folds, ScrollY counts
+// rows rather than buffer lines. Folds must be sorted; one that starts
+// inside an earlier fold is ignored.
+func (s *RenderState) displayRows(height int) []displayRow {
+	rows := make([]displayRow, 0, height)
+	fold := 0
+	for line, row := 0, 0; len(rows) < height; row++ {
+		for fold < len(s.Folds) && s.Folds[fold].Start < line {
+			fold++
+		}
+		next := displayRow{line: line}
+		if fold < len(s.Folds) && s.Folds[fold].Start == line && line < len(s.Lines) {
+			end := min(s.Folds[fold].End, len(s.Lines)-1)
+			next.folded = end - line + 1
+			line = end + 1
+			fold++
+		} else {
+			line++
+		}
+		if row >= s.ScrollY {
+			rows = append(rows, next)
+		}
+	}
+	return rows
+}
+
+// renderFoldSummary renders the row standing in for n folded lines.
+func (r *TextRenderer) renderFoldSummary(n, width int) string {
+	label := "⋯ " + itoaLocal(n) + " lines"
+	if n == 1 {
+		label = "⋯ 1 line"
+	}
+	label = runewidth.Truncate(label, width, "")
+	pad := width - runewidth.StringWidth(label)
+	return r.nonTextCode() + label + colorReset() + strings.Repeat(" ", pad)
+}
+
+// foldLabel returns the line-number gutter label for a fold starting at
+// line: the range of lines it hides when that fits in numWidth, else the
+// first line's number.
+func (r *LineNumberRenderer) foldLabel(line, folded, numWidth int) string {
+	label := r.format(line+1) + "-" + r.format(line+folded)
+	if len(label) > numWidth {
+		label = r.format(line + 1)
+	}
+	return padLeftStr(label, numWidth)
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFoldedRegionRendersOneSummaryRow(t *testing.T) {
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = "line " + itoaLocal(i+1)
+	}
+	state := &RenderState{
+		Lines:      lines,
+		CursorLine: 0,
+		Folds:      []FoldedRange{{Start: 3, End: 12}},
+		TabWidth:   4,
+	}
+
+	text := NewTextRenderer(DefaultStyles()).Render(14, 12, state)
+	want := []string{
+		"line 1", "line 2", "line 3", "⋯ 10 lines",
+		"line 14", "line 15", "line 16", "line 17", "line 18", "line 19", "line 20", "~",
+	}
+	summaries := 0
+	for i, row := range text {
+		got := strings.TrimRight(stripANSI(row), " ")
+		if i > 0 && got != want[i] {
+			t.Errorf("text row %d = %q, want %q", i, got, want[i])
+		}
+		if strings.Contains(got, "⋯") {
+			summaries++
+		}
+		if w := visualWidth(row); w != 14 {
+			t.Errorf("text row %d width = %d, want 14", i, w)
+		}
+	}
+	if summaries != 1 {
+		t.Errorf("%d summary rows, want 1", summaries)
+	}
+
+	numbers := NewLineNumberRenderer(DefaultStyles()).Render(6, 12, state)
+	for i, want := range []string{"    1 ", "    2 ", "    3 ", " 4-13 ", "   14 "} {
+		if got := stripANSI(numbers[i]); got != want {
+			t.Errorf("number row %d = %q, want %q", i, got, want)
+		}
+	}
+	if got := stripANSI(numbers[11]); got != "      " {
+		t.Errorf("number row past the end = %q, want blank", got)
+	}
+}
+
+func TestDisplayRowsScrolledPastFold(t *testing.T) {
+	state := &RenderState{
+		Lines:   make([]string, 30),
+		ScrollY: 4,
+		Folds:   []FoldedRange{{Start: 1, End: 10}, {Start: 5, End: 6}, {Start: 15, End: 16}},
+	}
+	// Rows: 0, fold 1-10, 11, 12, 13, 14, fold 15-16, 17 ...; the fold
+	// starting inside the first one is ignored
+	want := []displayRow{{13, 0}, {14, 0}, {15, 2}, {17, 0}}
+	got := state.displayRows(4)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
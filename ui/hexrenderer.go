@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HexRenderer shows raw bytes as a hex dump: an offset column, the bytes
+// in hex split into groups of 8, and an ASCII panel. Each row is one
+// display line, so state.ScrollY counts byte rows.
+type HexRenderer struct {
+	styles      Styles
+	data        []byte
+	bytesPerRow int
+}
+
+// NewHexRenderer creates a hex renderer showing 16 bytes per row.
+func NewHexRenderer(styles Styles) *HexRenderer {
+	return &HexRenderer{styles: styles, bytesPerRow: 16}
+}
+
+// SetStyles updates the styles for runtime theme changes.
+func (r *HexRenderer) SetStyles(styles Styles) {
+	r.styles = styles
+}
+
+// SetData sets the bytes to display.
+func (r *HexRenderer) SetData(data []byte) {
+	r.data = data
+}
+
+// SetBytesPerRow sets how many bytes each row shows: 8 or 16.
+// Other values are ignored.
+func (r *HexRenderer) SetBytesPerRow(n int) {
+	if n == 8 || n == 16 {
+		r.bytesPerRow = n
+	}
+}
+
+// RowCount returns the number of rows needed to show all the data.
+func (r *HexRenderer) RowCount() int {
+	return (len(r.data) + r.bytesPerRow - 1) / r.bytesPerRow
+}
+
+// Render implements ColumnRenderer.
+// Rows are cut to width; rows past the end of the data are blank.
+func (r *HexRenderer) Render(width, height int, state *RenderState) []string {
+	rows := make([]string, height)
+	if width <= 0 {
+		return rows
+	}
+
+	offsetColor := ColorToANSIFg(r.styles.Theme.UI.LineNumber)
+	resetCode := colorReset()
+
+	for row := 0; row < height; row++ {
+		offset := (state.ScrollY + row) * r.bytesPerRow
+		if offset >= len(r.data) {
+			rows[row] = strings.Repeat(" ", width)
+			continue
+		}
+
+		line := r.formatRow(offset)
+		if len(line) > width {
+			line = line[:width]
+		} else {
+			line += strings.Repeat(" ", width-len(line))
+		}
+
+		// Color the offset column when it is shown in full
+		const offsetWidth = 8
+		if width >= offsetWidth {
+			line = offsetColor + line[:offsetWidth] + resetCode + line[offsetWidth:]
+		}
+		rows[row] = line
+	}
+	return rows
+}
+
+// formatRow formats the row of bytes starting at offset, e.g.
+// "00000010  48 65 6c 6c 6f 0a 00 01  02 03 ...  |Hello...|".
+// Short final rows are padded so the ASCII panel stays aligned.
+func (r *HexRenderer) formatRow(offset int) string {
+	end := offset + r.bytesPerRow
+	if end > len(r.data) {
+		end = len(r.data)
+	}
+	chunk := r.data[offset:end]
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%08x ", offset)
+	for i := 0; i < r.bytesPerRow; i++ {
+		if i%8 == 0 {
+			sb.WriteByte(' ')
+		}
+		if i < len(chunk) {
+			fmt.Fprintf(&sb, "%02x ", chunk[i])
+		} else {
+			sb.WriteString("   ")
+		}
+	}
+
+	sb.WriteString(" |")
+	for _, b := range chunk {
+		if b >= 0x20 && b < 0x7f {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteByte('.')
+		}
+	}
+	sb.WriteByte('|')
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHexRendererRows(t *testing.T) {
+	SetColorEnabled(false)
+	defer SetColorEnabled(true)
+
+	data := append([]byte("Hello, world!\n"), 0x00, 0x01, 0x7f, 0xff, 'A')
+	tests := []struct {
+		name    string
+		perRow  int
+		scrollY int
+		want    []string
+	}{
+		{
+			"sixteen bytes per row",
+			16,
+			0,
+			[]string{
+				"00000000  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 21 0a 00 01  |Hello, world!...|",
+				"00000010  7f ff 41                                          |..A|",
+				"",
+			},
+		},
+		{
+			"eight bytes per row",
+			8,
+			0,
+			[]string{
+				"00000000  48 65 6c 6c 6f 2c 20 77  |Hello, w|",
+				"00000008  6f 72 6c 64 21 0a 00 01  |orld!...|",
+				"00000010  7f ff 41                 |..A|",
+			},
+		},
+		{
+			"scrolled by rows",
+			8,
+			2,
+			[]string{
+				"00000010  7f ff 41                 |..A|",
+				"",
+				"",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		r := NewHexRenderer(DefaultStyles())
+		r.SetData(data)
+		r.SetBytesPerRow(tt.perRow)
+		rows := r.Render(80, 3, &RenderState{ScrollY: tt.scrollY})
+		for i, row := range rows {
+			if len(row) != 80 {
+				t.Errorf("%s: row %d has width %d, want 80", tt.name, i, len(row))
+			}
+			if got := strings.TrimRight(row, " "); got != tt.want[i] {
+				t.Errorf("%s: row %d = %q, want %q", tt.name, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestHexRendererTruncatesToWidth(t *testing.T) {
+	SetColorEnabled(false)
+	defer SetColorEnabled(true)
+
+	r := NewHexRenderer(DefaultStyles())
+	r.SetData([]byte("abcdefghijklmnopq"))
+	if got := r.RowCount(); got != 2 {
+		t.Errorf("RowCount() = %d, want 2", got)
+	}
+	rows := r.Render(19, 1, &RenderState{})
+	if want := "00000000  61 62 63 "; rows[0] != want {
+		t.Errorf("row = %q, want %q", rows[0], want)
+	}
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cornish/textivus-editor/syntax"
+)
+
+// HTMLExportOptions controls ExportHTML output.
+type HTMLExportOptions struct {
+	// CSSClasses emits class="c-rrggbb" spans plus a <style> block defining
+	// them, instead of inline style attributes.
+	CSSClasses bool
+	// LineNumbers prefixes each line with its right-aligned 1-based number.
+	LineNumbers bool
+}
+
+// ExportHTML renders lines as an HTML <pre> block, coloring text by the
+// token categories of the syntax spans in lineColors (as produced by
+// Highlighter.GetLineColors). colors gives the theme color of a category,
+// such as Highlighter.CategoryColor; it is read rather than the spans' ANSI
+// escapes, so the export keeps full color whatever the terminal's color
+// depth, and with NO_COLOR. nil colors exports plain text. Text is
+// HTML-escaped.
+func ExportHTML(lines []string, lineColors map[int][]syntax.ColorSpan, colors func(syntax.TokenCategory) string, opts HTMLExportOptions) string {
+	var body strings.Builder
+	classes := make(map[string]bool)
+
+	numWidth := len(itoaLocal(len(lines)))
+	for i, line := range lines {
+		if opts.LineNumbers {
+			if opts.CSSClasses {
+				body.WriteString(`<span class="ln">`)
+			} else {
+				body.WriteString(`<span style="color:#808080">`)
+			}
+			body.WriteString(padLeftStr(itoaLocal(i+1), numWidth))
+			body.WriteString(" </span>")
+		}
+		writeHTMLLine(&body, line, lineColors[i], colors, opts, classes)
+		body.WriteString("\n")
+	}
+
+	var sb strings.Builder
+	if opts.CSSClasses {
+		sb.WriteString("<style>\n")
+		if opts.LineNumbers {
+			sb.WriteString(".ln { color: #808080; user-select: none; }\n")
+		}
+		names := make([]string, 0, len(classes))
+		for hex := range classes {
+			names = append(names, hex)
+		}
+		sort.Strings(names)
+		for _, hex := range names {
+			fmt.Fprintf(&sb, ".c-%s { color: #%s; }\n", hex, hex)
+		}
+		sb.WriteString("</style>\n")
+	}
+	sb.WriteString("<pre>")
+	sb.WriteString(body.String())
+	sb.WriteString("</pre>\n")
+	return sb.String()
+}
+
+// writeHTMLLine writes one line, grouping consecutive runes of the same
+// token category into a single span.
+func writeHTMLLine(sb *strings.Builder, line string, spans []syntax.ColorSpan, colors func(syntax.TokenCategory) string, opts HTMLExportOptions, classes map[string]bool) {
+	runes := []rune(line)
+	for start := 0; start < len(runes); {
+		category := syntax.CategoryAt(spans, start)
+		end := start + 1
+		for end < len(runes) && syntax.CategoryAt(spans, end) == category {
+			end++
+		}
+
+		text := html.EscapeString(string(runes[start:end]))
+		hex := ""
+		if category != "" && colors != nil {
+			hex = htmlColor(colors(category))
+		}
+		if hex == "" {
+			sb.WriteString(text)
+		} else {
+			if opts.CSSClasses {
+				classes[hex] = true
+				fmt.Fprintf(sb, `<span class="c-%s">%s</span>`, hex, text)
+			} else {
+				fmt.Fprintf(sb, `<span style="color:#%s">%s</span>`, hex, text)
+			}
+		}
+		start = end
+	}
+}
+
+// htmlColor returns a theme color, "#rrggbb", "#rgb" or a 256-color palette
+// index, as six hex digits, or "" if it isn't a color.
+func htmlColor(color string) string {
+	var r, g, b int
+	if strings.HasPrefix(color, "#") {
+		r, g, b = parseHexColor(color)
+	} else {
+		n, err := strconv.Atoi(color)
+		if err != nil || n < 0 || n > 255 {
+			return ""
+		}
+		r, g, b = color256ToRGB(n)
+	}
+	return fmt.Sprintf("%02x%02x%02x", r, g, b)
+}
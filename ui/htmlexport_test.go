@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cornish/textivus-editor/config"
+	"github.com/cornish/textivus-editor/syntax"
+)
+
+func goHighlighter() *syntax.Highlighter {
+	h := syntax.New("main.go")
+	h.SetColors(syntax.SyntaxColors{
+		Keyword:  "#ff0000",
+		String:   "#00ff00",
+		Comment:  "#0000ff",
+		Number:   "#ffff00",
+		Operator: "#ff00ff",
+		Function: "#00ffff",
+		Type:     "#123456",
+	})
+	return h
+}
+
+func goLineColors(t *testing.T, h *syntax.Highlighter, lines []string) map[int][]syntax.ColorSpan {
+	t.Helper()
+	colors := make(map[int][]syntax.ColorSpan)
+	for i, line := range lines {
+		colors[i] = h.GetLineColors(line)
+	}
+	return colors
+}
+
+func TestExportHTMLInlineStyles(t *testing.T) {
+	lines := []string{`func main() { s := "x" }`}
+	h := goHighlighter()
+	out := ExportHTML(lines, goLineColors(t, h, lines), h.CategoryColor, HTMLExportOptions{})
+
+	for _, want := range []string{
+		`<pre>`,
+		`<span style="color:#ff0000">func</span>`,
+		`<span style="color:#00ff00">&#34;x&#34;</span>`,
+		"</pre>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\033") {
+		t.Errorf("output contains raw escape codes:\n%q", out)
+	}
+}
+
+func TestExportHTMLCSSClasses(t *testing.T) {
+	lines := []string{"func f() {}"}
+	h := goHighlighter()
+	out := ExportHTML(lines, goLineColors(t, h, lines), h.CategoryColor, HTMLExportOptions{CSSClasses: true})
+
+	for _, want := range []string{
+		".c-ff0000 { color: #ff0000; }",
+		`<span class="c-ff0000">func</span>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "style=") {
+		t.Errorf("class output has inline styles:\n%s", out)
+	}
+}
+
+func TestExportHTMLEscapesAndLineNumbers(t *testing.T) {
+	lines := make([]string, 10)
+	lines[0] = "if a < b && c > d {"
+	out := ExportHTML(lines, nil, nil, HTMLExportOptions{LineNumbers: true})
+
+	if !strings.Contains(out, "if a &lt; b &amp;&amp; c &gt; d {") {
+		t.Errorf("special characters not escaped:\n%s", out)
+	}
+	if !strings.Contains(out, `<span style="color:#808080"> 1 </span>`) || !strings.Contains(out, `<span style="color:#808080">10 </span>`) {
+		t.Errorf("line numbers missing or misaligned:\n%s", out)
+	}
+}
+
+func TestExportHTMLCommentMarkers(t *testing.T) {
+	// Markers are bold as well as colored; the color must survive that
+	lines := []string{"x := 1 // TODO: fix"}
+	h := syntax.New("main.go")
+	h.SetColors(syntax.SyntaxColors{Comment: "#0000ff"})
+	h.SetColorOverrides(map[syntax.TokenCategory]string{syntax.CategoryMarker: "#ff8800"})
+	colors := map[int][]syntax.ColorSpan{0: h.GetLineColors(lines[0])}
+
+	out := ExportHTML(lines, colors, h.CategoryColor, HTMLExportOptions{})
+	if want := `<span style="color:#ff8800">TODO</span>`; !strings.Contains(out, want) {
+		t.Errorf("output missing %q:\n%s", want, out)
+	}
+}
+
+func TestExportHTMLIgnoresColorDepth(t *testing.T) {
+	// The terminal's color depth quantizes the span escapes; the export
+	// must still use the theme's own colors
+	defer func(depth config.ColorMode, enabled bool) {
+		ColorDepth = depth
+		SetColorEnabled(enabled)
+	}(ColorDepth, ColorEnabled())
+	defer func(convert func(string) string) { syntax.ColorConverter = convert }(syntax.ColorConverter)
+	syntax.ColorConverter = ColorToANSIFg
+
+	lines := []string{"type T int // 42"}
+	h := goHighlighter()
+	h.SetColors(syntax.SyntaxColors{Keyword: "#123456", Comment: "14"})
+	for _, mode := range []struct {
+		depth   config.ColorMode
+		enabled bool
+	}{
+		{config.Color16, true},
+		{config.Color256, true},
+		{config.ColorTrueColor, false}, // NO_COLOR
+	} {
+		ColorDepth = mode.depth
+		SetColorEnabled(mode.enabled)
+		out := ExportHTML(lines, goLineColors(t, h, lines), h.CategoryColor, HTMLExportOptions{})
+		for _, want := range []string{
+			`<span style="color:#123456">type</span>`,
+			`<span style="color:#00ffff">// 42</span>`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("depth %v, color %v: output missing %q:\n%s", mode.depth, mode.enabled, want, out)
+			}
+		}
+	}
+}
+
+func TestParseANSIToRGBChained(t *testing.T) {
+	tests := []struct {
+		ansi string
+		want [3]byte
+	}{
+		{"\033[38;2;1;2;3m", [3]byte{1, 2, 3}},
+		{"\033[1m\033[38;2;255;136;0m", [3]byte{255, 136, 0}},
+		{"\033[4m\033[31m", [3]byte{205, 0, 0}},
+		{"\033[94m", [3]byte{92, 92, 255}},
+		{"\033[38;5;244m", [3]byte{128, 128, 128}},
+		{"\033[1;38;5;196m", [3]byte{255, 0, 0}},
+		{"\033[1m", [3]byte{200, 200, 200}},
+	}
+	for _, tt := range tests {
+		if got := parseANSIToRGB(tt.ansi); got != tt.want {
+			t.Errorf("parseANSIToRGB(%q) = %v, want %v", tt.ansi, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ui
+
+import "strings"
+
+// IndentScope is the block of lines an indent guide runs through: the
+// guide at visual column Column on lines FirstLine to LastLine (inclusive).
+type IndentScope struct {
+	Column    int
+	FirstLine int
+	LastLine  int
+}
+
+// indentColumns returns the visual width of line's leading whitespace and
+// whether the line has anything else on it.
+func indentColumns(line string, tabWidth int) (width int, blank bool) {
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += tabWidth - width%tabWidth
+		default:
+			return width, false
+		}
+	}
+	return width, true
+}
+
+// ActiveIndentScope returns the indentation block containing line, whose
+// guide is drawn emphasized. On a line that opens a block (the next
+// non-blank line is indented deeper) it is that block; otherwise it is the
+// block the line itself is indented into. Blank lines take the indentation
+// of the next non-blank line. ok is false at the top level.
+func ActiveIndentScope(lines []string, line, tabWidth int) (scope IndentScope, ok bool) {
+	if line < 0 || line >= len(lines) {
+		return IndentScope{}, false
+	}
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	indent := func(i int) (int, bool) { return indentColumns(lines[i], tabWidth) }
+	nextNonBlank := func(i int) int {
+		for i < len(lines) {
+			if _, blank := indent(i); !blank {
+				return i
+			}
+			i++
+		}
+		return -1
+	}
+
+	cur := line
+	if _, blank := indent(line); blank {
+		if cur = nextNonBlank(line); cur < 0 {
+			return IndentScope{}, false
+		}
+	}
+	width, _ := indent(cur)
+
+	column, first := 0, cur
+	if next := nextNonBlank(cur + 1); next >= 0 && line == cur {
+		if w, _ := indent(next); w > width {
+			// A block header: emphasize the block it opens
+			column, first = width, next
+		}
+	}
+	if first == cur {
+		if width == 0 {
+			return IndentScope{}, false
+		}
+		column = (width - 1) / tabWidth * tabWidth
+	}
+
+	// The block is every line around first indented past the guide, with
+	// blank lines inside it but not at its ends
+	deeper := func(i int) bool {
+		w, blank := indent(i)
+		return blank || w > column
+	}
+	start, end := first, first
+	for start > 0 && deeper(start-1) {
+		start--
+	}
+	for end < len(lines)-1 && deeper(end+1) {
+		end++
+	}
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(lines[end]) == "" {
+		end--
+	}
+	return IndentScope{Column: column, FirstLine: start, LastLine: end}, true
+}
+
+// guideFunc returns a function giving the indent guide drawn at visual
+// column visualCol of line lineIdx, or "" where there is none. Guides sit
+// at every tab stop within the leading whitespace; the one for the active
+// scope is emphasized. A blank line carries the guides its neighbours share,
+// drawn over its padding, so they run unbroken through a block.
+func (r *TextRenderer) guideFunc(state *RenderState, lineIdx int, line string, tabWidth int) func(visualCol int) string {
+	limit := 0
+	if state.IndentGuides {
+		limit = guideLimit(state.Lines, lineIdx, line, tabWidth)
+	}
+	if limit == 0 {
+		return noGuide
+	}
+	glyph := state.IndentGuide
+	if glyph == "" {
+		glyph = "│"
+	}
+	dim := r.nonTextCode()
+	active := ColorToANSIFg(r.styles.Theme.UI.LineNumberActive)
+	scope := state.ActiveGuide
+	return func(visualCol int) string {
+		if visualCol >= limit || visualCol%tabWidth != 0 {
+			return ""
+		}
+		color := dim
+		if scope != nil && visualCol == scope.Column && lineIdx >= scope.FirstLine && lineIdx <= scope.LastLine {
+			color = active
+		}
+		return color + glyph + colorReset()
+	}
+}
+
+// noGuide is the guide function for lines, or parts of lines, without any.
+func noGuide(int) string { return "" }
+
+// guideLimit returns the visual column that line lineIdx's guides stop
+// before: the width of its leading whitespace, or for a blank line the
+// shallower indentation of the nearest non-blank lines above and below.
+func guideLimit(lines []string, lineIdx int, line string, tabWidth int) int {
+	width, blank := indentColumns(line, tabWidth)
+	if !blank {
+		return width
+	}
+	above, below := -1, -1
+	for i := lineIdx - 1; i >= 0 && i < len(lines); i-- {
+		if w, b := indentColumns(lines[i], tabWidth); !b {
+			above = w
+			break
+		}
+	}
+	for i := lineIdx + 1; i >= 0 && i < len(lines); i++ {
+		if w, b := indentColumns(lines[i], tabWidth); !b {
+			below = w
+			break
+		}
+	}
+	return max(min(above, below), 0)
+}
+
+// writePadding fills n cells starting at visual column visualCol with
+// spaces, drawing any guides that fall there.
+func writePadding(sb *strings.Builder, n, visualCol int, guide func(visualCol int) string) {
+	for i := 0; i < n; i++ {
+		if g := guide(visualCol + i); g != "" {
+			sb.WriteString(g)
+		} else {
+			sb.WriteByte(' ')
+		}
+	}
+}
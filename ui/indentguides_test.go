@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+var nestedBlocks = []string{
+	"func f() {",
+	"    if x {",
+	"        a()",
+	"",
+	"        b()",
+	"    }",
+	"    if y {",
+	"        c()",
+	"    }",
+	"}",
+}
+
+func TestActiveIndentScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   int
+		want   IndentScope
+		wantOK bool
+	}{
+		{"inside a nested block", 2, IndentScope{4, 2, 4}, true},
+		{"blank line inside the block", 3, IndentScope{4, 2, 4}, true},
+		{"header opens its block", 1, IndentScope{4, 2, 4}, true},
+		{"closing line belongs to the outer block", 5, IndentScope{0, 1, 8}, true},
+		{"sibling block", 7, IndentScope{4, 7, 7}, true},
+		{"function header", 0, IndentScope{0, 1, 8}, true},
+		{"top level", 9, IndentScope{}, false},
+		{"out of range", 20, IndentScope{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ActiveIndentScope(nestedBlocks, tt.line, 4)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("%s: ActiveIndentScope(%d) = (%+v, %v), want (%+v, %v)",
+				tt.name, tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestTextRendererActiveIndentGuide(t *testing.T) {
+	r := NewTextRenderer(DefaultStyles())
+	dim := r.nonTextCode() + "│" + colorReset()
+	active := ColorToANSIFg(r.styles.Theme.UI.LineNumberActive) + "│" + colorReset()
+
+	scope, _ := ActiveIndentScope(nestedBlocks, 2, 4)
+	state := &RenderState{
+		Lines:        nestedBlocks,
+		CursorLine:   2,
+		CursorCol:    11,
+		TabWidth:     4,
+		IndentGuides: true,
+		ActiveGuide:  &scope,
+	}
+
+	for _, wrap := range []bool{false, true} {
+		state.WordWrap = wrap
+		rows := r.Render(12, 10, state)
+		want := map[int]string{
+			1: dim + "   if x {  ",
+			2: dim + "   " + active + "   a()\033[7m \033[0m",
+			3: dim + "   " + active + "       ",
+			4: dim + "   " + active + "   b() ",
+			5: dim + "   }       ",
+			7: dim + "   " + dim + "   c() ",
+		}
+		for row, w := range want {
+			if rows[row] != w {
+				t.Errorf("wrap=%v: row %d = %q, want %q", wrap, row, rows[row], w)
+			}
+		}
+	}
+
+	state.WordWrap = false
+	state.IndentGuide = "|"
+	if rows := r.Render(12, 10, state); rows[3] != strings.ReplaceAll(dim+"   "+active+"       ", "│", "|") {
+		t.Errorf("ASCII guide: row 3 = %q", rows[3])
+	}
+
+	state.IndentGuides = false
+	if rows := r.Render(12, 10, state); rows[7] != "        c() " {
+		t.Errorf("guides off: row 7 = %q", rows[7])
+	}
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"encoding/base64"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/cornish/textivus-editor/syntax"
@@ -28,6 +29,7 @@ type KittyMinimapRenderer struct {
 	lineColors     func(line string) []syntax.ColorSpan // Syntax highlighter callback
 	lastStartLine  int                                  // First line shown in last render (for click handling)
 	lastLinesShown int                                  // Number of lines shown in last render
+	density        int                                  // Braille fallback: characters per span that light a dot
 }
 
 // NewKittyMinimapRenderer creates a new Kitty graphics minimap renderer.
@@ -37,9 +39,16 @@ func NewKittyMinimapRenderer(styles Styles, useKitty bool) *KittyMinimapRenderer
 		enabled:  false,
 		useKitty: useKitty,
 		imageID:  1001, // Fixed ID for minimap image
+		density:  DefaultMinimapDensity,
 	}
 }
 
+// SetDensityThreshold sets the braille fallback's dot threshold; see
+// MinimapRenderer.SetDensityThreshold. The Kitty image is not affected.
+func (r *KittyMinimapRenderer) SetDensityThreshold(n int) {
+	r.density = clampDensity(n)
+}
+
 // SetStyles updates the styles for runtime theme changes.
 func (r *KittyMinimapRenderer) SetStyles(styles Styles) {
 	r.styles = styles
@@ -382,76 +391,52 @@ func blendColor(base, overlay byte, alpha float64) byte {
 	return byte(result)
 }
 
-// parseANSIToRGB attempts to parse an ANSI color escape sequence to RGB.
+// parseANSIToRGB returns the foreground color set by an ANSI escape. The
+// escape may chain several SGR sequences, such as bold and then a color, or
+// set several attributes in one; everything but the foreground color is
+// ignored. Returns gray when no foreground color is set.
 func parseANSIToRGB(ansi string) [3]byte {
-	// Common ANSI 256-color format: \033[38;5;XXXm or \033[38;2;R;G;Bm
-	// Try to extract RGB values
-
-	// Check for true color (24-bit): \033[38;2;R;G;Bm
-	var r, g, b int
-	if n, _ := fmt.Sscanf(ansi, "\033[38;2;%d;%d;%dm", &r, &g, &b); n == 3 {
-		return [3]byte{byte(r), byte(g), byte(b)}
-	}
-
-	// Check for 256-color: \033[38;5;XXXm
-	var colorIdx int
-	if n, _ := fmt.Sscanf(ansi, "\033[38;5;%dm", &colorIdx); n == 1 {
-		return ansi256ToRGB(colorIdx)
-	}
-
-	// Basic ANSI colors (30-37, 90-97)
-	var code int
-	if n, _ := fmt.Sscanf(ansi, "\033[%dm", &code); n == 1 {
-		return ansiBasicToRGB(code)
+	rgb := [3]byte{200, 200, 200} // Default gray
+	for _, seq := range strings.Split(ansi, "\033[")[1:] {
+		body, isSGR := strings.CutSuffix(seq, "m")
+		if !isSGR {
+			continue
+		}
+		var params []int
+		for _, field := range strings.Split(body, ";") {
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				n = 0 // An empty parameter means 0
+			}
+			params = append(params, n)
+		}
+		for i := 0; i < len(params); i++ {
+			switch p := params[i]; {
+			case p == 38 && i+4 < len(params) && params[i+1] == 2:
+				// True color (24-bit): 38;2;R;G;B
+				rgb = [3]byte{byte(params[i+2]), byte(params[i+3]), byte(params[i+4])}
+				i += 4
+			case p == 38 && i+2 < len(params) && params[i+1] == 5:
+				// 256-color: 38;5;N
+				if n := params[i+2]; n >= 0 && n <= 255 {
+					rgb = paletteRGB(n)
+				}
+				i += 2
+			case p >= 30 && p <= 37:
+				rgb = paletteRGB(p - 30)
+			case p >= 90 && p <= 97:
+				rgb = paletteRGB(p - 90 + 8)
+			}
+		}
 	}
-
-	return [3]byte{200, 200, 200} // Default gray
-}
-
-// ansi256ToRGB converts a 256-color palette index to RGB.
-func ansi256ToRGB(idx int) [3]byte {
-	if idx < 16 {
-		// Standard colors
-		return ansiBasicToRGB(idx + 30)
-	}
-	if idx < 232 {
-		// 6x6x6 color cube
-		idx -= 16
-		r := (idx / 36) * 51
-		g := ((idx / 6) % 6) * 51
-		b := (idx % 6) * 51
-		return [3]byte{byte(r), byte(g), byte(b)}
-	}
-	// Grayscale
-	gray := (idx-232)*10 + 8
-	return [3]byte{byte(gray), byte(gray), byte(gray)}
+	return rgb
 }
 
-// ansiBasicToRGB converts basic ANSI color codes to RGB.
-func ansiBasicToRGB(code int) [3]byte {
-	// Map basic ANSI colors to approximate RGB
-	colors := map[int][3]byte{
-		30: {0, 0, 0},       // Black
-		31: {205, 49, 49},   // Red
-		32: {13, 188, 121},  // Green
-		33: {229, 229, 16},  // Yellow
-		34: {36, 114, 200},  // Blue
-		35: {188, 63, 188},  // Magenta
-		36: {17, 168, 205},  // Cyan
-		37: {229, 229, 229}, // White
-		90: {102, 102, 102}, // Bright Black
-		91: {241, 76, 76},   // Bright Red
-		92: {35, 209, 139},  // Bright Green
-		93: {245, 245, 67},  // Bright Yellow
-		94: {59, 142, 234},  // Bright Blue
-		95: {214, 112, 214}, // Bright Magenta
-		96: {41, 184, 219},  // Bright Cyan
-		97: {255, 255, 255}, // Bright White
-	}
-	if rgb, ok := colors[code]; ok {
-		return rgb
-	}
-	return [3]byte{200, 200, 200}
+// paletteRGB returns the RGB value of 256-color palette index n, from the
+// same palette the styles quantize to, as bytes.
+func paletteRGB(n int) [3]byte {
+	r, g, b := color256ToRGB(n)
+	return [3]byte{byte(r), byte(g), byte(b)}
 }
 
 // encodeKittyGraphics creates the Kitty graphics protocol escape sequence.
@@ -562,7 +547,7 @@ func (r *KittyMinimapRenderer) renderBrailleFallback(width, height int, state *R
 	ui := r.styles.Theme.UI
 	indicatorColor := ColorToANSIFg(ui.MinimapIndicator)
 	textColor := ColorToANSIFg(ui.MinimapText)
-	resetCode := "\033[0m"
+	resetCode := colorReset()
 
 	// Scroll offset if minimap is taller than viewport
 	minimapScrollOffset := 0
@@ -612,7 +597,7 @@ func (r *KittyMinimapRenderer) renderBrailleFallback(width, height int, state *R
 		}
 
 		sb.WriteString(textColor)
-		braille := renderBrailleChars(fourLines, brailleWidth)
+		braille := renderBrailleChars(fourLines, brailleWidth, r.density)
 		sb.WriteString(braille)
 		sb.WriteString(resetCode)
 
@@ -637,8 +622,10 @@ func hexToRGB(hex string) [3]byte {
 	return [3]byte{r, g, b}
 }
 
-// renderBrailleChars renders braille characters for 4 visual lines.
-func renderBrailleChars(fourLines [4]string, brailleWidth int) string {
+// renderBrailleChars renders braille characters for 4 visual lines. A dot
+// is lit when its 5-character span holds at least threshold non-whitespace
+// characters.
+func renderBrailleChars(fourLines [4]string, brailleWidth, threshold int) string {
 	var result strings.Builder
 	charsPerBraille := 10
 
@@ -651,7 +638,7 @@ func renderBrailleChars(fourLines [4]string, brailleWidth int) string {
 		for rowOffset := 0; rowOffset < 4; rowOffset++ {
 			lineRunes := []rune(fourLines[rowOffset])
 
-			if hasEnoughContentLocal(lineRunes, srcColStart, srcColMid, 3) {
+			if hasEnoughContentLocal(lineRunes, srcColStart, srcColMid, threshold) {
 				switch rowOffset {
 				case 0:
 					pattern |= 0x01
@@ -664,7 +651,7 @@ func renderBrailleChars(fourLines [4]string, brailleWidth int) string {
 				}
 			}
 
-			if hasEnoughContentLocal(lineRunes, srcColMid, srcColMid+5, 3) {
+			if hasEnoughContentLocal(lineRunes, srcColMid, srcColMid+5, threshold) {
 				switch rowOffset {
 				case 0:
 					pattern |= 0x08
@@ -1,14 +1,20 @@
 package ui
 
 import (
+	"strconv"
 	"strings"
-	"unicode/utf8"
 )
 
+// DefaultLineNumberWidth is the standard width of the line number column:
+// 4 digits + 1 space separator.
+const DefaultLineNumberWidth = 5
+
 // LineNumberRenderer renders line numbers in a column.
 // Standard width is 5 (4 digits + 1 space separator).
 type LineNumberRenderer struct {
-	styles Styles
+	styles          Styles
+	highlightActive bool // Paint the cursor line's gutter background
+	radix           int  // Base numbers are shown in (0 = 10)
 }
 
 // NewLineNumberRenderer creates a new line number renderer.
@@ -21,6 +27,72 @@ func (r *LineNumberRenderer) SetStyles(styles Styles) {
 	r.styles = styles
 }
 
+// SetHighlightActive sets whether the cursor line's gutter, including its
+// wrapped continuation rows, is painted with the theme's GutterActiveBg.
+func (r *LineNumberRenderer) SetHighlightActive(on bool) {
+	r.highlightActive = on
+}
+
+// SetRadix sets the base line numbers are shown in: 16 for hex, 8 for
+// octal, or 10. Any other value means 10.
+func (r *LineNumberRenderer) SetRadix(radix int) {
+	switch radix {
+	case 8, 16:
+		r.radix = radix
+	default:
+		r.radix = 10
+	}
+}
+
+// GutterWidth returns the column width that fits the numbers of a
+// lineCount-line document in the current base, plus the separator space.
+// It is never less than DefaultLineNumberWidth, so the gutter only grows
+// once the last line number needs more than 4 digits.
+func (r *LineNumberRenderer) GutterWidth(lineCount int) int {
+	return max(DefaultLineNumberWidth, len(r.format(max(lineCount, 1)))+1)
+}
+
+// format renders line number n in the current base.
+func (r *LineNumberRenderer) format(n int) string {
+	if r.radix == 0 || r.radix == 10 {
+		return itoaLocal(n)
+	}
+	return strconv.FormatInt(int64(n), r.radix)
+}
+
+// LineAtRow returns the buffer line whose number sits on gutter row row,
+// following scrolling, word wrap and folds the way the gutter is drawn: the
+// continuation rows of a wrapped line belong to it. ok is false for rows
+// past the end of the document.
+func (r *LineNumberRenderer) LineAtRow(row int, state *RenderState) (line int, ok bool) {
+	if row < 0 {
+		return 0, false
+	}
+	if line = rowBufferLines(state, row+1)[row]; line < 0 {
+		return 0, false
+	}
+	return line, true
+}
+
+// activeBackground returns the escape for the cursor line's gutter
+// background, or "" when that highlight is off.
+func (r *LineNumberRenderer) activeBackground() string {
+	bg := r.styles.Theme.UI.GutterActiveBg
+	if !r.highlightActive || bg == "" {
+		return ""
+	}
+	return ColorToANSIBg(bg)
+}
+
+// gutterCell renders one gutter row: label in color and the separator
+// space, both on bg when it is set.
+func gutterCell(bg, color, label string) string {
+	if bg == "" {
+		return color + label + colorReset() + " "
+	}
+	return bg + color + label + " " + colorReset()
+}
+
 // Render implements ColumnRenderer.
 // Returns line numbers for visible lines, with the cursor line highlighted.
 func (r *LineNumberRenderer) Render(width, height int, state *RenderState) []string {
@@ -46,30 +118,43 @@ func (r *LineNumberRenderer) renderNoWrap(rows []string, width, numWidth, height
 	ui := r.styles.Theme.UI
 	normalColor := ColorToANSIFg(ui.LineNumber)
 	activeColor := ColorToANSIFg(ui.LineNumberActive)
-	resetCode := "\033[0m"
+	activeBg := r.activeBackground()
+
+	if len(state.Folds) > 0 {
+		for row, d := range state.displayRows(height) {
+			if d.line >= len(state.Lines) {
+				rows[row] = strings.Repeat(" ", width)
+				continue
+			}
+			color, bg, label := normalColor, "", padLeftStr(r.format(d.line+1), numWidth)
+			if d.folded > 0 {
+				label = r.foldLabel(d.line, d.folded, numWidth)
+			}
+			if d.line == state.CursorLine {
+				color, bg = activeColor, activeBg
+			}
+			rows[row] = gutterCell(bg, color, label)
+		}
+		return
+	}
 
 	for row := 0; row < height; row++ {
 		lineIdx := state.ScrollY + row
 
-		var sb strings.Builder
 		if lineIdx < len(state.Lines) {
 			// Real line - show number
 			lineNum := lineIdx + 1 // 1-indexed
-			numStr := padLeftStr(itoaLocal(lineNum), numWidth)
+			numStr := padLeftStr(r.format(lineNum), numWidth)
 
 			if lineIdx == state.CursorLine {
-				sb.WriteString(activeColor)
+				rows[row] = gutterCell(activeBg, activeColor, numStr)
 			} else {
-				sb.WriteString(normalColor)
+				rows[row] = gutterCell("", normalColor, numStr)
 			}
-			sb.WriteString(numStr)
-			sb.WriteString(resetCode)
-			sb.WriteString(" ")
 		} else {
 			// Past end of file - empty gutter
-			sb.WriteString(strings.Repeat(" ", width))
+			rows[row] = strings.Repeat(" ", width)
 		}
-		rows[row] = sb.String()
 	}
 }
 
@@ -80,13 +165,18 @@ func (r *LineNumberRenderer) renderWrapped(rows []string, width, numWidth, heigh
 	ui := r.styles.Theme.UI
 	normalColor := ColorToANSIFg(ui.LineNumber)
 	activeColor := ColorToANSIFg(ui.LineNumberActive)
-	resetCode := "\033[0m"
+	activeBg := r.activeBackground()
 
-	// Calculate text width (we need this to determine wrap points)
-	// This is a bit of a hack - we don't know the text column width here.
-	// For now, estimate based on a typical layout.
-	// TODO: Pass text width through RenderState
-	textWidth := 80 // Default estimate
+	// Wrap points follow the text column, the same way rowBufferLines
+	// counts them
+	textWidth := state.TextWidth
+	if textWidth <= 0 {
+		textWidth = 80 // Estimate when the text width isn't known
+	}
+	tabWidth := state.TabWidth
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
 
 	// Find which buffer line corresponds to ScrollY visual line
 	visualLine := 0
@@ -94,8 +184,7 @@ func (r *LineNumberRenderer) renderWrapped(rows []string, width, numWidth, heigh
 	wrapOffset := 0
 
 	for bufferLine < len(state.Lines) && visualLine < state.ScrollY {
-		lineLen := utf8.RuneCountInString(state.Lines[bufferLine])
-		wrappedCount := countWrappedLinesForWidth(lineLen, textWidth)
+		wrappedCount := countWrappedLinesLocal(state.Lines[bufferLine], textWidth, tabWidth)
 
 		if visualLine+wrappedCount > state.ScrollY {
 			// Start partway through this line
@@ -117,22 +206,21 @@ func (r *LineNumberRenderer) renderWrapped(rows []string, width, numWidth, heigh
 			continue
 		}
 
-		lineLen := utf8.RuneCountInString(state.Lines[bufferLine])
-		wrappedCount := countWrappedLinesForWidth(lineLen, textWidth)
+		wrappedCount := countWrappedLinesLocal(state.Lines[bufferLine], textWidth, tabWidth)
 
 		if wrapOffset == 0 {
 			// First visual line of buffer line - show number
 			lineNum := bufferLine + 1
-			numStr := padLeftStr(itoaLocal(lineNum), numWidth)
+			numStr := padLeftStr(r.format(lineNum), numWidth)
 
 			if bufferLine == state.CursorLine {
-				sb.WriteString(activeColor)
+				sb.WriteString(gutterCell(activeBg, activeColor, numStr))
 			} else {
-				sb.WriteString(normalColor)
+				sb.WriteString(gutterCell("", normalColor, numStr))
 			}
-			sb.WriteString(numStr)
-			sb.WriteString(resetCode)
-			sb.WriteString(" ")
+		} else if bufferLine == state.CursorLine && activeBg != "" {
+			// Continuation of the cursor line - keep its background
+			sb.WriteString(activeBg + strings.Repeat(" ", width) + colorReset())
 		} else {
 			// Continuation line - empty gutter
 			sb.WriteString(strings.Repeat(" ", width))
@@ -149,17 +237,6 @@ func (r *LineNumberRenderer) renderWrapped(rows []string, width, numWidth, heigh
 	}
 }
 
-// countWrappedLinesForWidth returns how many visual lines a buffer line takes.
-func countWrappedLinesForWidth(lineLen, textWidth int) int {
-	if textWidth <= 0 {
-		return 1
-	}
-	if lineLen == 0 {
-		return 1
-	}
-	return (lineLen + textWidth - 1) / textWidth
-}
-
 // padLeftStr pads a string with spaces on the left to reach the target width.
 func padLeftStr(s string, width int) string {
 	if len(s) >= width {
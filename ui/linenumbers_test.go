@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLineNumberActiveBackground(t *testing.T) {
+	styles := DefaultStyles()
+	bg := ColorToANSIBg(styles.Theme.UI.GutterActiveBg)
+	long := strings.Repeat("x", 100) // Wraps onto two rows at the 80-column estimate
+
+	// paintedRows returns the indices of rows carrying the active background.
+	paintedRows := func(rows []string) []int {
+		var painted []int
+		for i, row := range rows {
+			if strings.Contains(row, bg) {
+				painted = append(painted, i)
+			}
+		}
+		return painted
+	}
+
+	tests := []struct {
+		name      string
+		lines     []string
+		cursor    int
+		wordWrap  bool
+		highlight bool
+		want      []int
+	}{
+		{"cursor line painted", []string{"a", "b", "c"}, 1, false, true, []int{1}},
+		{"off by default", []string{"a", "b", "c"}, 1, false, false, nil},
+		{"wrapped cursor line painted on every row", []string{"a", long, "c"}, 1, true, true, []int{1, 2}},
+		{"wrapped line without the cursor", []string{"a", long, "c"}, 2, true, true, []int{3}},
+	}
+
+	for _, tt := range tests {
+		r := NewLineNumberRenderer(styles)
+		r.SetHighlightActive(tt.highlight)
+		state := &RenderState{Lines: tt.lines, CursorLine: tt.cursor, WordWrap: tt.wordWrap}
+		rows := r.Render(5, 5, state)
+		if got := paintedRows(rows); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: painted rows = %v, want %v", tt.name, got, tt.want)
+		}
+		for i, row := range rows {
+			if got := visualWidth(row); got != 5 {
+				t.Errorf("%s: row %d is %d cells wide, want 5", tt.name, i, got)
+			}
+		}
+	}
+}
+
+func TestLineNumberRadix(t *testing.T) {
+	defer SetColorEnabled(true)
+	SetColorEnabled(false)
+
+	lines := make([]string, 300)
+	tests := []struct {
+		name  string
+		radix int
+		want  string
+	}{
+		{"decimal by default", 0, "  255 "},
+		{"decimal", 10, "  255 "},
+		{"hex", 16, "   ff "},
+		{"octal", 8, "  377 "},
+		{"unsupported base is decimal", 7, "  255 "},
+	}
+
+	for _, tt := range tests {
+		r := NewLineNumberRenderer(DefaultStyles())
+		r.SetRadix(tt.radix)
+		state := &RenderState{Lines: lines, ScrollY: 254, CursorLine: -1}
+		if got := r.Render(6, 1, state)[0]; got != tt.want {
+			t.Errorf("%s: line 255 = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLineNumberGutterWidth(t *testing.T) {
+	tests := []struct {
+		radix     int
+		lineCount int
+		want      int
+	}{
+		{10, 0, DefaultLineNumberWidth},
+		{10, 9999, DefaultLineNumberWidth},
+		{10, 10000, 6},
+		{10, 1234567, 8},
+		{16, 0xffff, DefaultLineNumberWidth},
+		{16, 0x10000, 6},
+		{8, 010000, 6},
+	}
+
+	for _, tt := range tests {
+		r := NewLineNumberRenderer(DefaultStyles())
+		r.SetRadix(tt.radix)
+		if got := r.GutterWidth(tt.lineCount); got != tt.want {
+			t.Errorf("radix %d: GutterWidth(%d) = %d, want %d", tt.radix, tt.lineCount, got, tt.want)
+		}
+	}
+}
+
+func TestLineNumberLineAtRow(t *testing.T) {
+	// At text width 10 line 1 wraps onto 3 rows and line 3 onto 2
+	lines := []string{"a", strings.Repeat("b", 25), "c", strings.Repeat("d", 15), "e"}
+
+	tests := []struct {
+		name     string
+		scrollY  int
+		wordWrap bool
+		folds    []FoldedRange
+		row      int
+		want     int
+		wantOK   bool
+	}{
+		{"unwrapped first row", 0, false, nil, 0, 0, true},
+		{"unwrapped scrolled", 2, false, nil, 1, 3, true},
+		{"unwrapped past the end", 2, false, nil, 3, 0, false},
+		{"negative row", 0, false, nil, -1, 0, false},
+		{"wrapped number row", 0, true, nil, 1, 1, true},
+		{"wrapped continuation row", 0, true, nil, 3, 1, true},
+		{"wrapped line after a wrapped line", 0, true, nil, 4, 2, true},
+		{"wrapped scrolled into a line", 2, true, nil, 0, 1, true},
+		{"wrapped scrolled", 2, true, nil, 4, 3, true},
+		{"wrapped last line", 0, true, nil, 7, 4, true},
+		{"wrapped past the end", 0, true, nil, 8, 0, false},
+		{"row below a fold", 0, false, []FoldedRange{{Start: 1, End: 3}}, 2, 4, true},
+	}
+
+	for _, tt := range tests {
+		r := NewLineNumberRenderer(DefaultStyles())
+		state := &RenderState{
+			Lines:     lines,
+			ScrollY:   tt.scrollY,
+			WordWrap:  tt.wordWrap,
+			Folds:     tt.folds,
+			TabWidth:  4,
+			TextWidth: 10,
+		}
+		line, ok := r.LineAtRow(tt.row, state)
+		if line != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: LineAtRow(%d) = %d, %v, want %d, %v", tt.name, tt.row, line, ok, tt.want, tt.wantOK)
+		}
+
+		// The mapping agrees with where the gutter draws each number
+		if ok && tt.row >= 0 {
+			rows := r.Render(5, tt.row+1, state)
+			if num := strings.TrimSpace(stripANSI(rows[tt.row])); num != "" && num != itoaLocal(line+1) && !strings.HasPrefix(num, itoaLocal(line+1)+"-") {
+				t.Errorf("%s: row %d shows %q but maps to line %d", tt.name, tt.row, num, line+1)
+			}
+		}
+	}
+}
@@ -31,6 +31,10 @@ const (
 	ActionCopy
 	ActionPaste
 	ActionCutLine
+	ActionDuplicate
+	ActionConvertIndent // Converts indentation to match tabs-to-spaces
+	ActionSortLines     // Sorts the selected lines
+	ActionUniqueLines   // Removes repeated lines from the selection
 	ActionSelectAll
 	// Search menu
 	ActionFind
@@ -131,6 +135,10 @@ func NewMenuBar(styles Styles) *MenuBar {
 					{Label: "Copy", Shortcut: "Ctrl+C", HotKey: 'C', Action: ActionCopy},
 					{Label: "Paste", Shortcut: "Ctrl+V", HotKey: 'P', Action: ActionPaste},
 					{Label: "Cut Line", Shortcut: "Ctrl+K", HotKey: 'K', Action: ActionCutLine},
+					{Label: "Duplicate", Shortcut: "Ctrl+D", HotKey: 'D', Action: ActionDuplicate},
+					{Label: "Convert Indentation", Shortcut: "", HotKey: 'I', Action: ActionConvertIndent},
+					{Label: "Sort Lines", Shortcut: "", HotKey: 'S', Action: ActionSortLines},
+					{Label: "Unique Lines", Shortcut: "", HotKey: 'Q', Action: ActionUniqueLines},
 					{Label: "Select All", Shortcut: "Ctrl+A", HotKey: 'L', Action: ActionSelectAll},
 				},
 			},
@@ -195,6 +203,7 @@ func (m *MenuBar) UpdateShortcuts(kb *config.KeybindingsConfig) {
 		ActionCopy:      kb.Copy,
 		ActionPaste:     kb.Paste,
 		ActionCutLine:   kb.CutLine,
+		ActionDuplicate: kb.Duplicate,
 		ActionSelectAll: kb.SelectAll,
 		// Search menu
 		ActionFind:     kb.Find,
@@ -545,8 +554,8 @@ func (m *MenuBar) View() string {
 
 	// Get theme colors
 	ui := m.styles.Theme.UI
-	normalColor := ColorToANSI(ui.MenuFg, ui.MenuBg)
-	highlightColor := ColorToANSI(ui.MenuHighlightFg, ui.MenuHighlightBg)
+	normalColor := barColor(ui.MenuFg, ui.MenuBg)
+	highlightColor := barHighlight(ui.MenuHighlightFg, ui.MenuHighlightBg)
 
 	// Build the menu bar
 	var sb strings.Builder
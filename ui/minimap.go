@@ -16,6 +16,20 @@ type MinimapController interface {
 	RowToVisualLine(row int, metrics MinimapMetrics) int
 	ClearImage() string                                                              // Returns escape sequence to clear graphics (Kitty only, empty for braille)
 	GetKittySequence(width, height, xOffset, yOffset int, state *RenderState) string // Kitty graphics overlay
+	SetDensityThreshold(n int)                                                       // Non-blank characters per 5-column span that light a dot
+}
+
+// DefaultMinimapDensity is the number of non-whitespace characters in a
+// 5-column span that lights a braille dot unless configured otherwise.
+const DefaultMinimapDensity = 3
+
+// clampDensity limits a density threshold to what a 5-column span can
+// hold, with 0 or less meaning DefaultMinimapDensity.
+func clampDensity(n int) int {
+	if n <= 0 {
+		return DefaultMinimapDensity
+	}
+	return min(n, 5)
 }
 
 // MinimapRenderer renders a braille-based minimap of the document.
@@ -36,8 +50,8 @@ type MinimapController interface {
 //   - Lines longer than 60 chars are truncated (not scaled)
 //
 // Fill logic:
-//   - A dot is ON if there are >= 3 non-whitespace characters in that
-//     5-character span (i.e., less than 2 char widths of whitespace)
+//   - A dot is ON if there are at least the density threshold (default 3)
+//     non-whitespace characters in that 5-character span
 //
 // Viewport indicator:
 //   - Option A: Current vertical bar │ on left side
@@ -48,6 +62,7 @@ type MinimapController interface {
 type MinimapRenderer struct {
 	styles  Styles
 	enabled bool
+	density int // Non-whitespace characters per 5-column span that light a dot
 }
 
 // NewMinimapRenderer creates a new minimap renderer.
@@ -55,9 +70,17 @@ func NewMinimapRenderer(styles Styles) *MinimapRenderer {
 	return &MinimapRenderer{
 		styles:  styles,
 		enabled: false, // Disabled by default
+		density: DefaultMinimapDensity,
 	}
 }
 
+// SetDensityThreshold sets how many non-whitespace characters a 5-column
+// span needs to light its dot: 1 marks any text, 5 only solid runs. Values
+// are clamped to 1-5, and 0 or less restores DefaultMinimapDensity.
+func (r *MinimapRenderer) SetDensityThreshold(n int) {
+	r.density = clampDensity(n)
+}
+
 // SetStyles updates the styles for runtime theme changes.
 func (r *MinimapRenderer) SetStyles(styles Styles) {
 	r.styles = styles
@@ -121,7 +144,7 @@ func (r *MinimapRenderer) Render(width, height int, state *RenderState) []string
 	ui := r.styles.Theme.UI
 	indicatorColor := ColorToANSIFg(ui.MinimapIndicator)
 	textColor := ColorToANSIFg(ui.MinimapText)
-	resetCode := "\033[0m"
+	resetCode := colorReset()
 
 	rows := make([]string, height)
 
@@ -231,7 +254,8 @@ func (r *MinimapRenderer) generateVisualLines(lines []string, wordWrap bool, tex
 
 // renderBrailleChar renders braille characters for 4 visual lines.
 // Each braille char represents 4 rows × 2 columns, where each dot column = 5 visual columns.
-// A dot is ON if >= 3 non-whitespace chars in that 5-column span.
+// A dot is ON if the 5-column span holds at least the density threshold of
+// non-whitespace chars.
 func (r *MinimapRenderer) renderBrailleChar(fourLines [4]string, brailleWidth, tabWidth int) string {
 	var result strings.Builder
 
@@ -254,7 +278,7 @@ func (r *MinimapRenderer) renderBrailleChar(fourLines [4]string, brailleWidth, t
 			line := fourLines[rowOffset]
 
 			// Left dot column (dots 1,2,3,7) - visual columns [visualColStart, visualColMid)
-			if hasEnoughContentVisual(line, visualColStart, visualColMid, 3, tabWidth) {
+			if hasEnoughContentVisual(line, visualColStart, visualColMid, r.density, tabWidth) {
 				switch rowOffset {
 				case 0:
 					pattern |= 0x01 // dot 1
@@ -268,7 +292,7 @@ func (r *MinimapRenderer) renderBrailleChar(fourLines [4]string, brailleWidth, t
 			}
 
 			// Right dot column (dots 4,5,6,8) - visual columns [visualColMid, visualColMid+5)
-			if hasEnoughContentVisual(line, visualColMid, visualColMid+5, 3, tabWidth) {
+			if hasEnoughContentVisual(line, visualColMid, visualColMid+5, r.density, tabWidth) {
 				switch rowOffset {
 				case 0:
 					pattern |= 0x08 // dot 4
@@ -0,0 +1,34 @@
+package ui
+
+import "testing"
+
+func TestMinimapDensityThreshold(t *testing.T) {
+	// Two non-blank characters in the left dot's span, four in the right's
+	lines := [4]string{"x x   xxxx"}
+
+	tests := []struct {
+		name      string
+		threshold int
+		want      rune
+	}{
+		{"any text lights a dot", 1, 0x2809},
+		{"default needs three characters", 3, 0x2808},
+		{"zero means the default", 0, 0x2808},
+		{"solid runs only", 5, 0x2800},
+		{"clamped to the span width", 9, 0x2800},
+	}
+
+	for _, tt := range tests {
+		r := NewMinimapRenderer(DefaultStyles())
+		r.SetDensityThreshold(tt.threshold)
+		if got := r.renderBrailleChar(lines, 1, 4); got != string(tt.want) {
+			t.Errorf("%s: braille = %U, want %U", tt.name, []rune(got)[0], tt.want)
+		}
+
+		k := NewKittyMinimapRenderer(DefaultStyles(), false)
+		k.SetDensityThreshold(tt.threshold)
+		if got := renderBrailleChars(lines, 1, k.density); got != string(tt.want) {
+			t.Errorf("%s: fallback braille = %U, want %U", tt.name, []rune(got)[0], tt.want)
+		}
+	}
+}
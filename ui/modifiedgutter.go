@@ -0,0 +1,104 @@
+package ui
+
+import "strings"
+
+// ModifiedGutterRenderer draws a bar beside lines that have unsaved changes.
+// Dirty lines come from RenderState.ModifiedLines.
+type ModifiedGutterRenderer struct {
+	styles  Styles
+	enabled bool
+}
+
+// NewModifiedGutterRenderer creates a new modified-lines gutter renderer.
+func NewModifiedGutterRenderer(styles Styles) *ModifiedGutterRenderer {
+	return &ModifiedGutterRenderer{styles: styles}
+}
+
+// SetStyles updates the styles for runtime theme changes.
+func (r *ModifiedGutterRenderer) SetStyles(styles Styles) {
+	r.styles = styles
+}
+
+// SetEnabled enables or disables the gutter.
+func (r *ModifiedGutterRenderer) SetEnabled(enabled bool) {
+	r.enabled = enabled
+}
+
+// IsEnabled returns whether the gutter is enabled.
+func (r *ModifiedGutterRenderer) IsEnabled() bool {
+	return r.enabled
+}
+
+// Render implements ColumnRenderer.
+// Every visual row of a modified line gets the bar, so wrapped lines are
+// marked along their full height.
+func (r *ModifiedGutterRenderer) Render(width, height int, state *RenderState) []string {
+	rows := make([]string, height)
+	if width <= 0 {
+		return rows
+	}
+
+	barColor := ColorToANSIFg(r.styles.Theme.UI.ModifiedLine)
+	resetCode := colorReset()
+	blank := strings.Repeat(" ", width)
+	bar := barColor + "▎" + resetCode + strings.Repeat(" ", width-1)
+
+	for row, lineIdx := range rowBufferLines(state, height) {
+		if lineIdx >= 0 && state.ModifiedLines[lineIdx] {
+			rows[row] = bar
+		} else {
+			rows[row] = blank
+		}
+	}
+	return rows
+}
+
+// rowBufferLines maps each visible row to the buffer line shown on it,
+// or -1 past the end of the document. Wrapping matches the text renderer.
+func rowBufferLines(state *RenderState, height int) []int {
+	result := make([]int, height)
+	for i := range result {
+		result[i] = -1
+	}
+
+	if !state.WordWrap && len(state.Folds) > 0 {
+		// A fold's summary row stands for the line it starts at
+		for row, d := range state.displayRows(height) {
+			if d.line < len(state.Lines) {
+				result[row] = d.line
+			}
+		}
+		return result
+	}
+	if !state.WordWrap {
+		for row := 0; row < height; row++ {
+			if lineIdx := state.ScrollY + row; lineIdx < len(state.Lines) {
+				result[row] = lineIdx
+			}
+		}
+		return result
+	}
+
+	textWidth := state.TextWidth
+	if textWidth <= 0 {
+		textWidth = 80
+	}
+	tabWidth := state.TabWidth
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+
+	visual := 0
+	row := 0
+	for lineIdx := 0; lineIdx < len(state.Lines) && row < height; lineIdx++ {
+		count := countWrappedLinesLocal(state.Lines[lineIdx], textWidth, tabWidth)
+		for seg := 0; seg < count && row < height; seg++ {
+			if visual >= state.ScrollY {
+				result[row] = lineIdx
+				row++
+			}
+			visual++
+		}
+	}
+	return result
+}
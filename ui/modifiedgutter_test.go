@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// markedRows returns the indices of rows that carry the modified bar.
+func markedRows(rows []string) []int {
+	var marked []int
+	for i, row := range rows {
+		if strings.Contains(row, "▎") {
+			marked = append(marked, i)
+		}
+	}
+	return marked
+}
+
+func TestModifiedGutterRender(t *testing.T) {
+	lines := []string{"a", "b", "c", "d", "e", "f"}
+	dirty := map[int]bool{1: true, 4: true}
+
+	tests := []struct {
+		name     string
+		scrollY  int
+		wordWrap bool
+		lines    []string
+		want     []int
+	}{
+		{"marks dirty lines", 0, false, lines, []int{1, 4}},
+		{"follows vertical scroll", 2, false, lines, []int{2}},
+		{"scrolled past all dirty lines", 5, false, lines, nil},
+		// Line 1 wraps onto three rows at width 4
+		{"wrapped line marked on every row", 0, true, []string{"a", "bbbbbbbbbb", "c", "d", "e"}, []int{1, 2, 3}},
+		{"wrapped and scrolled", 2, true, []string{"a", "bbbbbbbbbb", "c", "d", "e"}, []int{0, 1, 4}},
+	}
+
+	for _, tt := range tests {
+		r := NewModifiedGutterRenderer(DefaultStyles())
+		state := &RenderState{
+			Lines:         tt.lines,
+			ScrollY:       tt.scrollY,
+			WordWrap:      tt.wordWrap,
+			TabWidth:      4,
+			TextWidth:     4,
+			ModifiedLines: dirty,
+		}
+		rows := r.Render(1, 5, state)
+		if len(rows) != 5 {
+			t.Fatalf("%s: got %d rows, want 5", tt.name, len(rows))
+		}
+		got := markedRows(rows)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: marked rows = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
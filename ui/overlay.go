@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Overlay draws on top of the composited viewport, after the columns have
+// been joined: inline errors, ghost text and the like, without changes to
+// the column renderers. rows holds one ANSI-coded string per screen row,
+// each as wide as the compositor. Apply returns the rows to display and
+// must keep each one's visual width; OverlayAt does that for text placed at
+// a column.
+type Overlay interface {
+	Apply(rows []string, state *RenderState) []string
+}
+
+// OverlayAt draws dropLine on top of viewportLine starting at visual column
+// offset, preserving the viewport content on both sides of it (including
+// ANSI color codes). Wide characters cut by either edge become spaces, so
+// the row keeps its visual width.
+func OverlayAt(dropLine, viewportLine string, offset int) string {
+	// Calculate the visual width of the dropdown line (strip ANSI codes)
+	dropWidth := visualWidth(dropLine)
+
+	// Extract prefix and suffix from viewport line, preserving ANSI codes
+	prefix := SliceANSI(viewportLine, 0, offset)
+	suffix := SliceANSI(viewportLine, offset+dropWidth, -1)
+
+	// Build the result: prefix + dropdown + suffix
+	var result strings.Builder
+
+	// Prefix: viewport content before the dropdown (or spaces if line is short)
+	prefixWidth := visualWidth(prefix)
+	result.WriteString(prefix)
+	if prefixWidth < offset {
+		// Viewport line is shorter than offset - add padding
+		result.WriteString(strings.Repeat(" ", offset-prefixWidth))
+	}
+
+	// The dropdown itself
+	result.WriteString(dropLine)
+
+	// Suffix: viewport content after the dropdown (with ANSI codes preserved)
+	if suffix != "" {
+		result.WriteString(suffix)
+	}
+
+	return result.String()
+}
+
+// SliceANSI extracts a substring from an ANSI-coded string based on visual positions.
+// start and end are visual column positions (0-indexed). Use end=-1 for "to the end".
+// ANSI escape codes are preserved and passed through correctly.
+// Wide characters that would be split are excluded and replaced with spaces to maintain exact width.
+func SliceANSI(s string, start, end int) string {
+	var result strings.Builder
+	visualPos := 0
+	outputWidth := 0
+	inEscape := false
+	var escapeSeq strings.Builder
+
+	for _, r := range s {
+		if r == '\033' {
+			inEscape = true
+			escapeSeq.Reset()
+			escapeSeq.WriteRune(r)
+			continue
+		}
+
+		if inEscape {
+			escapeSeq.WriteRune(r)
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+				inEscape = false
+				// Include escape sequences that appear within our range
+				// or at the boundary (to preserve color state)
+				if end == -1 || visualPos < end {
+					result.WriteString(escapeSeq.String())
+				}
+			}
+			continue
+		}
+
+		// Regular character - check if it's in our range
+		charWidth := runewidth.RuneWidth(r)
+		charEnd := visualPos + charWidth
+
+		if end != -1 && visualPos < end && charEnd > end {
+			// Character would extend past end boundary - skip it but pad with spaces
+			// (This handles wide chars that would be split)
+			spacesNeeded := end - visualPos
+			result.WriteString(strings.Repeat(" ", spacesNeeded))
+			outputWidth += spacesNeeded
+			visualPos = charEnd
+			continue
+		}
+
+		if visualPos >= start && (end == -1 || charEnd <= end) {
+			// Character fully within range
+			if visualPos > start && outputWidth == 0 {
+				// We're starting mid-string, might have skipped a wide char
+				// Pad with spaces to maintain alignment
+				result.WriteString(strings.Repeat(" ", visualPos-start))
+				outputWidth += visualPos - start
+			}
+			result.WriteRune(r)
+			outputWidth += charWidth
+		} else if visualPos < start && charEnd > start {
+			// Wide character straddles the start boundary - skip it, pad with space
+			spacesNeeded := charEnd - start
+			if end != -1 && start+spacesNeeded > end {
+				spacesNeeded = end - start
+			}
+			result.WriteString(strings.Repeat(" ", spacesNeeded))
+			outputWidth += spacesNeeded
+		}
+
+		visualPos = charEnd
+	}
+
+	return result.String()
+}
@@ -2,6 +2,14 @@ package ui
 
 import (
 	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Default scrollbar glyphs, used when none (or an invalid one) is configured
+const (
+	DefaultScrollbarTrack = "░"
+	DefaultScrollbarThumb = "█"
 )
 
 // Scrollbar represents a vertical scrollbar displayed on the right side of the editor
@@ -9,6 +17,8 @@ type Scrollbar struct {
 	height  int
 	enabled bool
 	styles  Styles
+	track   string
+	thumb   string
 }
 
 // NewScrollbar creates a new scrollbar instance
@@ -17,9 +27,33 @@ func NewScrollbar(styles Styles) *Scrollbar {
 		height:  24,
 		enabled: false,
 		styles:  styles,
+		track:   DefaultScrollbarTrack,
+		thumb:   DefaultScrollbarThumb,
 	}
 }
 
+// SetGlyphs sets the characters drawn for the track and the thumb. An empty
+// glyph selects the default. Each must take exactly one display cell; a wider
+// glyph is rejected and the default used instead. Reports whether both
+// glyphs were accepted.
+func (s *Scrollbar) SetGlyphs(track, thumb string) bool {
+	trackOK := track == "" || validScrollbarGlyph(track)
+	thumbOK := thumb == "" || validScrollbarGlyph(thumb)
+	s.track, s.thumb = DefaultScrollbarTrack, DefaultScrollbarThumb
+	if track != "" && trackOK {
+		s.track = track
+	}
+	if thumb != "" && thumbOK {
+		s.thumb = thumb
+	}
+	return trackOK && thumbOK
+}
+
+// validScrollbarGlyph reports whether g is a single character one cell wide.
+func validScrollbarGlyph(g string) bool {
+	return len([]rune(g)) == 1 && runewidth.StringWidth(g) == 1
+}
+
 // Width returns the scrollbar width (1 character, or 0 if disabled)
 func (s *Scrollbar) Width() int {
 	if !s.enabled {
@@ -145,13 +179,13 @@ func (s *Scrollbar) Render(viewportStart, viewportHeight, totalLines int) []stri
 
 		if row >= thumbStart && row < thumbEnd {
 			sb.WriteString(thumbColor)
-			sb.WriteString("┃")
+			sb.WriteString(s.thumb)
 		} else {
 			sb.WriteString(trackColor)
-			sb.WriteString("│")
+			sb.WriteString(s.track)
 		}
 
-		sb.WriteString("\033[0m")
+		sb.WriteString(colorReset())
 		result[row] = sb.String()
 	}
 
@@ -168,6 +202,11 @@ func NewScrollbarColumnAdapter(sb *Scrollbar) *ScrollbarColumnAdapter {
 	return &ScrollbarColumnAdapter{scrollbar: sb}
 }
 
+// SetStyles forwards theme changes to the wrapped scrollbar.
+func (a *ScrollbarColumnAdapter) SetStyles(styles Styles) {
+	a.scrollbar.SetStyles(styles)
+}
+
 // Render implements ColumnRenderer interface.
 func (a *ScrollbarColumnAdapter) Render(width, height int, state *RenderState) []string {
 	if !a.scrollbar.enabled || width <= 0 || height <= 0 {
@@ -0,0 +1,40 @@
+package ui
+
+import "testing"
+
+func TestScrollbarGlyphs(t *testing.T) {
+	tests := []struct {
+		name      string
+		track     string
+		thumb     string
+		wantOK    bool
+		wantTrack string
+		wantThumb string
+	}{
+		{"unset uses defaults", "", "", true, DefaultScrollbarTrack, DefaultScrollbarThumb},
+		{"custom", "|", "#", true, "|", "#"},
+		{"multi-character track rejected", "::", "#", false, DefaultScrollbarTrack, "#"},
+		{"double-width thumb rejected", "|", "全", false, "|", DefaultScrollbarThumb},
+	}
+
+	for _, tt := range tests {
+		s := NewScrollbar(DefaultStyles())
+		s.SetEnabled(true)
+		s.SetHeight(4)
+		if ok := s.SetGlyphs(tt.track, tt.thumb); ok != tt.wantOK {
+			t.Errorf("%s: SetGlyphs(%q, %q) = %v, want %v", tt.name, tt.track, tt.thumb, ok, tt.wantOK)
+		}
+
+		// One screen of a two-screen document: thumb on the top half
+		rows := s.Render(0, 2, 4)
+		for i, row := range rows {
+			want := tt.wantTrack
+			if i < 2 {
+				want = tt.wantThumb
+			}
+			if got := stripANSI(row); got != want {
+				t.Errorf("%s: row %d = %q, want %q", tt.name, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// selectionPainter draws the cells of one buffer line with the selection
+// colors over exactly the selected ones. Both of the text renderer's paths
+// draw through it, wrapped or not, as does PaintSelection.
+type selectionPainter struct {
+	sel      SelectionRange
+	active   bool // Whether the line has a selection at all
+	tabWidth int
+	on       string // Escapes that start a selected cell
+}
+
+// newSelectionPainter returns a painter for a line selected over sel, or
+// with no selection when active is false.
+func newSelectionPainter(sel SelectionRange, active bool, tabWidth int, styles Styles) selectionPainter {
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	bg, fg := selectionCodes(styles.Theme.UI.SelectionBg, styles.Theme.UI.SelectionFg)
+	return selectionPainter{sel: sel, active: active, tabWidth: tabWidth, on: bg + fg}
+}
+
+// covers reports whether rune column col of the line is selected. When
+// sel.End is -1 the selection runs through the newline, at column len.
+func (p selectionPainter) covers(col int) bool {
+	return p.active && col >= p.sel.Start && (p.sel.End == -1 || col < p.sel.End)
+}
+
+// paintCell draws text as one selected cell.
+func (p selectionPainter) paintCell(sb *strings.Builder, text string) {
+	sb.WriteString(p.on)
+	sb.WriteString(text)
+	sb.WriteString(sgrReset())
+}
+
+// cellDrawer draws a cell the painter would otherwise draw itself: char
+// at rune column col and visual column cell of its row, w cells wide with
+// tabs already expanded. It returns "" to leave the cell to the painter.
+type cellDrawer func(col, cell int, char string, w int, selected bool) string
+
+// paintRow draws row into sb: a whole buffer line or one wrapped segment
+// of it, whose first rune is rune column col of the line. Tabs run to tab
+// stops counted from the start of row. Clusters starting left of visual
+// column from are skipped, and drawing stops before one that would end
+// past from+width. draw, when not nil, gets the first chance at every
+// cell. Returns the rune and visual columns after the last cluster
+// skipped or drawn, and the cells drawn.
+func (p selectionPainter) paintRow(sb *strings.Builder, row string, col, from, width int, draw cellDrawer) (endCol, endCell, drawn int) {
+	_, cells := expandTabs(row, p.tabWidth)
+	i := 0 // Rune index in row
+	for rest, graphemes := row, -1; rest != ""; {
+		// Whole grapheme clusters are drawn together, so a flag or a
+		// letter with combining marks is one character
+		char, next, _, nextState := uniseg.FirstGraphemeClusterInString(rest, graphemes)
+		runes := utf8.RuneCountInString(char)
+		cell := cells[i]
+		cw := cells[i+runes] - cell
+		if cell >= from {
+			if drawn+cw > width {
+				break
+			}
+			if char == "\t" {
+				char = strings.Repeat(" ", cw)
+			}
+			selected := p.covers(col + i)
+			s := ""
+			if draw != nil {
+				s = draw(col+i, cell, char, cw, selected)
+			}
+			switch {
+			case s != "":
+				sb.WriteString(s)
+			case selected:
+				p.paintCell(sb, char)
+			default:
+				sb.WriteString(char)
+			}
+			drawn += cw
+		}
+		rest, graphemes = next, nextState
+		i += runes
+	}
+	return col + i, cells[i], drawn
+}
+
+// PaintSelection renders the wrapped rows of one buffer line with the
+// selection background over exactly the selected cells. segments are the
+// line's wrap segments in order (as from wrapLineLocal); sel is in rune
+// columns of the whole line. Tabs expand to tab stops measured from each
+// segment's start, matching the wrapping, and a selected tab highlights
+// every cell it covers. When sel.End is -1 the selection runs through the
+// newline, drawn as one highlighted cell after the last segment.
+func PaintSelection(segments []string, tabWidth int, sel SelectionRange, styles Styles) []string {
+	p := newSelectionPainter(sel, true, tabWidth, styles)
+	rows := make([]string, len(segments))
+	col := 0 // Rune column in the whole line
+	for i, segment := range segments {
+		var sb strings.Builder
+		col, _, _ = p.paintRow(&sb, segment, col, 0, calculateVisualWidth(segment, tabWidth), nil)
+		if i == len(segments)-1 && p.covers(col) {
+			p.paintCell(&sb, " ") // The selected newline
+		}
+		rows[i] = sb.String()
+	}
+	return rows
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaintSelection(t *testing.T) {
+	styles := DefaultStyles()
+	bg, fg := selectionCodes(styles.Theme.UI.SelectionBg, styles.Theme.UI.SelectionFg)
+	on := bg + fg
+	off := "\033[0m"
+
+	tests := []struct {
+		name     string
+		segments []string
+		sel      SelectionRange
+		want     []string
+	}{
+		{
+			"spans a wrap boundary",
+			[]string{"abcd", "efgh"},
+			SelectionRange{Start: 2, End: 6},
+			[]string{"ab" + on + "c" + off + on + "d" + off, on + "e" + off + on + "f" + off + "gh"},
+		},
+		{
+			"crosses a tab",
+			[]string{"a\tbc"},
+			SelectionRange{Start: 1, End: 3},
+			[]string{"a" + on + "   " + off + on + "b" + off + "c"},
+		},
+		{
+			"tab stop restarts on each wrapped row",
+			[]string{"abcd", "\tx"},
+			SelectionRange{Start: 4, End: 5},
+			[]string{"abcd", on + "    " + off + "x"},
+		},
+		{
+			"through end of line includes the newline cell",
+			[]string{"abc", "de"},
+			SelectionRange{Start: 4, End: -1},
+			[]string{"abc", "d" + on + "e" + off + on + " " + off},
+		},
+		{
+			"wide characters",
+			[]string{"日本", "語x"},
+			SelectionRange{Start: 1, End: 3},
+			[]string{"日" + on + "本" + off, on + "語" + off + "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := PaintSelection(tt.segments, 4, tt.sel, styles)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: PaintSelection = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTextRendererSelectionAcrossWrap(t *testing.T) {
+	r := NewTextRenderer(DefaultStyles())
+	sel := SelectionRange{Start: 2, End: -1}
+	state := &RenderState{
+		Lines:      []string{"ab\tcdefgh", "x"},
+		CursorLine: 9,
+		Selection:  map[int]SelectionRange{0: sel},
+		TabWidth:   4,
+		WordWrap:   true,
+		TextWidth:  6,
+	}
+	segments := wrapLineLocal(state.Lines[0], 6, 4)
+	want := PaintSelection(segments, 4, sel, r.styles)
+
+	rows := r.Render(6, 3, state)
+	for i, w := range want {
+		// The renderer pads each row to the full width
+		if got := rows[i][:len(w)]; got != w {
+			t.Errorf("row %d = %q, want prefix %q", i, rows[i], w)
+		}
+		if n := visualWidth(rows[i]); n != 6 {
+			t.Errorf("row %d width = %d, want 6", i, n)
+		}
+	}
+}
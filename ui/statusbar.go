@@ -10,9 +10,11 @@ import (
 type StatusBar struct {
 	filename          string
 	modified          bool
+	readOnly          bool
 	line              int
 	col               int
 	totalLines        int
+	position          string // "Top", "Bot" or a percentage, from StatusData
 	encoding          string
 	encodingSupported bool // Whether the encoding is fully supported
 	wordCount         int
@@ -49,15 +51,18 @@ func (s *StatusBar) SetModified(modified bool) {
 	s.modified = modified
 }
 
-// SetPosition sets the cursor position (1-indexed for display)
-func (s *StatusBar) SetPosition(line, col int) {
-	s.line = line + 1 // Convert from 0-indexed to 1-indexed
-	s.col = col + 1
+// SetReadOnly sets whether the buffer is read-only
+func (s *StatusBar) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
 }
 
-// SetTotalLines sets the total number of lines
-func (s *StatusBar) SetTotalLines(total int) {
-	s.totalLines = total
+// SetStatus sets the cursor position, line count and document position
+// from a StatusInfo summary.
+func (s *StatusBar) SetStatus(d StatusData) {
+	s.line = d.Line
+	s.col = d.Col
+	s.totalLines = d.TotalLines
+	s.position = d.Position
 }
 
 // SetEncoding sets the file encoding and whether it's supported
@@ -106,7 +111,7 @@ func (s *StatusBar) View() string {
 
 	// Get theme colors
 	ui := s.styles.Theme.UI
-	normalColor := ColorToANSI(ui.StatusFg, ui.StatusBg)
+	normalColor := barColor(ui.StatusFg, ui.StatusBg)
 	accentColor := ColorToANSIFg(ui.StatusAccent) + "\033[1m" // Bold
 	errorColor := ColorToANSIFg(ui.ErrorFg) + "\033[1m"       // Bold
 	resetToNormal := ColorToANSIFg(ui.StatusFg) + "\033[22m"  // Not bold
@@ -128,6 +133,13 @@ func (s *StatusBar) View() string {
 	}
 	sb.WriteString(filename)
 
+	// Read-only indicator
+	readOnlyIndicator := ""
+	if s.readOnly {
+		readOnlyIndicator = " [RO]"
+		sb.WriteString(accentColor + readOnlyIndicator + resetToNormal)
+	}
+
 	// Buffer indicator (only show if multiple buffers)
 	bufferIndicator := ""
 	if s.bufferCount > 1 {
@@ -139,10 +151,13 @@ func (s *StatusBar) View() string {
 	// Build encoding display (may need color)
 	encodingDisplay := s.encoding
 	rightBase := fmt.Sprintf("W:%d C:%d | Ln %d, Col %d | ", s.wordCount, s.charCount, s.line, s.col)
+	if s.position != "" {
+		rightBase += s.position + " | "
+	}
 	right := rightBase + encodingDisplay
 
 	// Calculate spacing
-	leftLen := len(filename) + len(bufferIndicator)
+	leftLen := len(filename) + len(readOnlyIndicator) + len(bufferIndicator)
 	if s.modified {
 		leftLen++
 	}
@@ -0,0 +1,46 @@
+package ui
+
+import "fmt"
+
+// StatusData is the cursor summary shown in the status bar, derived from a
+// RenderState so every consumer agrees with what is on screen.
+type StatusData struct {
+	Line       int    // Cursor line, 1-based
+	Col        int    // Cursor column, 1-based
+	TotalLines int    // Buffer lines in the document
+	Percent    int    // How far through the document the cursor is, 0-100
+	Position   string // "Top", "Bot" or a percentage such as "42%"
+}
+
+// StatusInfo computes the status summary for state. With word wrap on the
+// percentage counts visual lines, so a long wrapped line near the top
+// moves it as far as the rows it actually occupies.
+func StatusInfo(state *RenderState) StatusData {
+	d := StatusData{
+		Line:       state.CursorLine + 1,
+		Col:        state.CursorCol + 1,
+		TotalLines: state.TotalLines,
+	}
+	if d.TotalLines == 0 {
+		d.TotalLines = len(state.Lines)
+	}
+
+	total, line := d.TotalLines, state.CursorLine
+	if state.WordWrap && state.TotalVisualLines > 0 {
+		total, line = state.TotalVisualLines, state.CursorVisualLine
+	}
+	if total > 1 {
+		d.Percent = line * 100 / (total - 1)
+		d.Percent = max(0, min(d.Percent, 100))
+	}
+
+	switch d.Percent {
+	case 0:
+		d.Position = "Top"
+	case 100:
+		d.Position = "Bot"
+	default:
+		d.Position = fmt.Sprintf("%d%%", d.Percent)
+	}
+	return d
+}
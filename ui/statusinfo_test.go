@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatusInfo(t *testing.T) {
+	lines := make([]string, 101)
+	for i := range lines {
+		lines[i] = "line"
+	}
+	// At width 10 these wrap to 1+3+1+1+1 = 7 visual lines
+	wrapped := []string{"a", strings.Repeat("b", 25), "c", "d", "e"}
+
+	tests := []struct {
+		name         string
+		lines        []string
+		line, col    int
+		wordWrap     bool
+		wantLine     int
+		wantCol      int
+		wantPercent  int
+		wantPosition string
+	}{
+		{"top", lines, 0, 0, false, 1, 1, 0, "Top"},
+		{"bottom", lines, 100, 3, false, 101, 4, 100, "Bot"},
+		{"middle", lines, 50, 2, false, 51, 3, 50, "50%"},
+		{"quarter", lines, 25, 0, false, 26, 1, 25, "25%"},
+		{"single line document", []string{"x"}, 0, 1, false, 1, 2, 0, "Top"},
+		{"wrapped: last segment of a long line", wrapped, 1, 22, true, 2, 23, 50, "50%"},
+		{"wrapped: line after a long line", wrapped, 2, 0, true, 3, 1, 66, "66%"},
+		{"unwrapped counts buffer lines", wrapped, 2, 0, false, 3, 1, 50, "50%"},
+	}
+
+	for _, tt := range tests {
+		v := NewViewport(DefaultStyles())
+		v.SetSize(10, 5)
+		v.SetWordWrap(tt.wordWrap)
+		state := &RenderState{
+			Lines:            tt.lines,
+			CursorLine:       tt.line,
+			CursorCol:        tt.col,
+			WordWrap:         tt.wordWrap,
+			TabWidth:         4,
+			TextWidth:        10,
+			TotalLines:       len(tt.lines),
+			TotalVisualLines: v.CountVisualLines(tt.lines),
+			CursorVisualLine: v.CursorVisualLine(tt.lines, tt.line, tt.col),
+		}
+		got := StatusInfo(state)
+		want := StatusData{tt.wantLine, tt.wantCol, len(tt.lines), tt.wantPercent, tt.wantPosition}
+		if got != want {
+			t.Errorf("%s: StatusInfo = %+v, want %+v", tt.name, got, want)
+		}
+	}
+}
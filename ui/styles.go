@@ -10,58 +10,161 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-// UseTrueColor controls whether hex colors use true color (24-bit) or
-// fall back to the nearest 256-color. Set to false for older terminals.
-var UseTrueColor = true
+// ColorDepth is the terminal color capability that ColorToANSIFg and
+// ColorToANSIBg target. Hex colors are sent as 24-bit escapes, degraded to
+// the nearest 256-color entry, or degraded to the nearest of the 16 basic
+// colors; indexed colors above 15 are degraded the same way on 16-color
+// terminals. The editor sets this at startup from detection or config.
+var ColorDepth = config.ColorTrueColor
+
+// colorEnabled controls whether renderers emit color escapes at all.
+// Cleared for NO_COLOR and non-terminal output.
+var colorEnabled = true
+
+// SetColorEnabled turns color output on or off. With color off,
+// ColorToANSIFg/Bg return empty strings and column renderers emit no color
+// or reset codes; text attributes that carry meaning (reverse video for the
+// cursor and selection) are kept so the editor stays usable.
+func SetColorEnabled(enabled bool) {
+	colorEnabled = enabled
+}
+
+// ColorEnabled reports whether color output is on.
+func ColorEnabled() bool {
+	return colorEnabled
+}
+
+// colorReset returns the SGR reset that ends a colored run, or "" when
+// color is disabled and no color was started.
+func colorReset() string {
+	if !colorEnabled {
+		return ""
+	}
+	return sgrReset()
+}
+
+// sgrReset returns the SGR reset that clears every attribute. Unlike
+// colorReset it is always emitted, since reverse video is used with color
+// disabled too.
+func sgrReset() string {
+	return "\033[0m"
+}
+
+// sgrOpen reports whether row sets an SGR attribute that is not reset
+// before the row ends.
+func sgrOpen(row string) bool {
+	open := false
+	for {
+		i := strings.Index(row, "\033[")
+		if i < 0 {
+			return open
+		}
+		row = row[i+2:]
+		end := strings.IndexFunc(row, func(r rune) bool { return r >= '@' && r <= '~' })
+		if end < 0 {
+			return open
+		}
+		if row[end] == 'm' {
+			params := row[:end]
+			open = params != "" && params != "0"
+		}
+		row = row[end+1:]
+	}
+}
+
+// closeSGR appends a reset to row when it leaves an attribute set, so one
+// column's colors never bleed into the next column or row.
+func closeSGR(row string) string {
+	if sgrOpen(row) {
+		return row + sgrReset()
+	}
+	return row
+}
 
 // ColorToANSIFg converts a theme color string to an ANSI foreground escape sequence
 // Supports: "0"-"255" for indexed colors, "#RGB" or "#RRGGBB" for hex colors
-// Hex colors use true color if UseTrueColor is true, otherwise nearest 256-color
 func ColorToANSIFg(color string) string {
+	return colorToANSI(color, 38, 30, 90, "\033[37m") // Default to white on error
+}
+
+// ColorToANSIBg converts a theme color string to an ANSI background escape sequence
+func ColorToANSIBg(color string) string {
+	return colorToANSI(color, 48, 40, 100, "\033[40m") // Default to black on error
+}
+
+// colorToANSI builds the escape for color at the current ColorDepth.
+// extended is 38/48 for 256-color and 24-bit codes; base and bright are the
+// first codes of the normal and bright 16-color ranges.
+func colorToANSI(color string, extended, base, bright int, fallback string) string {
+	if !colorEnabled {
+		return ""
+	}
+	n := -1
 	if strings.HasPrefix(color, "#") {
 		r, g, b := parseHexColor(color)
-		if UseTrueColor {
-			return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+		switch ColorDepth {
+		case config.ColorTrueColor:
+			return fmt.Sprintf("\033[%d;2;%d;%d;%dm", extended, r, g, b)
+		case config.Color256:
+			return fmt.Sprintf("\033[%d;5;%dm", extended, rgbTo256Color(r, g, b))
+		default:
+			n = rgbTo16Color(r, g, b)
+		}
+	} else {
+		var err error
+		n, err = strconv.Atoi(color)
+		if err != nil || n < 0 || n > 255 {
+			return fallback
+		}
+		if n >= 16 && ColorDepth == config.Color16 {
+			n = rgbTo16Color(color256ToRGB(n))
 		}
-		// Fall back to nearest 256-color
-		return fmt.Sprintf("\033[38;5;%dm", rgbTo256Color(r, g, b))
-	}
-	n, err := strconv.Atoi(color)
-	if err != nil {
-		return "\033[37m" // Default to white on error
 	}
 	if n < 16 {
 		// Standard colors: use traditional codes for better compatibility
 		if n < 8 {
-			return fmt.Sprintf("\033[%dm", 30+n)
+			return fmt.Sprintf("\033[%dm", base+n)
 		}
-		return fmt.Sprintf("\033[%dm", 90+(n-8))
+		return fmt.Sprintf("\033[%dm", bright+(n-8))
 	}
-	return fmt.Sprintf("\033[38;5;%dm", n)
+	return fmt.Sprintf("\033[%d;5;%dm", extended, n)
 }
 
-// ColorToANSIBg converts a theme color string to an ANSI background escape sequence
-func ColorToANSIBg(color string) string {
-	if strings.HasPrefix(color, "#") {
-		r, g, b := parseHexColor(color)
-		if UseTrueColor {
-			return fmt.Sprintf("\033[48;2;%d;%d;%dm", r, g, b)
+// ansi16Palette holds typical RGB values of the 16 basic colors (xterm defaults)
+var ansi16Palette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// rgbTo16Color returns the index of the basic color nearest to r, g, b
+func rgbTo16Color(r, g, b int) int {
+	best, bestDist := 0, -1
+	for i, c := range ansi16Palette {
+		dr, dg, db := r-c[0], g-c[1], b-c[2]
+		dist := dr*dr + dg*dg + db*db
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
 		}
-		// Fall back to nearest 256-color
-		return fmt.Sprintf("\033[48;5;%dm", rgbTo256Color(r, g, b))
-	}
-	n, err := strconv.Atoi(color)
-	if err != nil {
-		return "\033[40m" // Default to black on error
 	}
-	if n < 16 {
-		// Standard colors: use traditional codes for better compatibility
-		if n < 8 {
-			return fmt.Sprintf("\033[%dm", 40+n)
-		}
-		return fmt.Sprintf("\033[%dm", 100+(n-8))
+	return best
+}
+
+// color256ToRGB returns the RGB value of a 256-color palette index
+func color256ToRGB(n int) (int, int, int) {
+	switch {
+	case n < 16:
+		c := ansi16Palette[n]
+		return c[0], c[1], c[2]
+	case n < 232:
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return levels[n/36], levels[(n/6)%6], levels[n%6]
+	default:
+		gray := 8 + (n-232)*10
+		return gray, gray, gray
 	}
-	return fmt.Sprintf("\033[48;5;%dm", n)
 }
 
 // rgbTo256Color converts RGB values to the nearest 256-color palette index
@@ -192,11 +295,13 @@ type Styles struct {
 	Error  lipgloss.Style
 }
 
-// NewStyles creates a Styles configuration from a theme
+// NewStyles creates a Styles configuration from a theme. With color
+// disabled the menu and status bar styles drop their colors and mark the
+// bars and highlighted items with reverse video instead.
 func NewStyles(theme config.Theme) Styles {
 	ui := theme.UI
 
-	return Styles{
+	styles := Styles{
 		Theme: theme,
 
 		// Menu bar
@@ -319,6 +424,45 @@ func NewStyles(theme config.Theme) Styles {
 			Foreground(lipgloss.Color(ui.ErrorFg)).
 			Bold(true),
 	}
+	if !colorEnabled {
+		styles.uncolorBars()
+	}
+	return styles
+}
+
+// uncolorBars strips the colors from the menu and status bar styles,
+// keeping them distinct from the text with reverse video.
+func (s *Styles) uncolorBars() {
+	plain := func(style lipgloss.Style) lipgloss.Style {
+		return style.UnsetForeground().UnsetBackground().UnsetBorderForeground()
+	}
+	s.MenuBar = plain(s.MenuBar).Reverse(true)
+	s.MenuItem = plain(s.MenuItem).Reverse(true)
+	s.MenuItemActive = plain(s.MenuItemActive)
+	s.MenuDropdown = plain(s.MenuDropdown)
+	s.MenuOption = plain(s.MenuOption)
+	s.MenuOptionActive = plain(s.MenuOptionActive).Reverse(true)
+	s.MenuOptionDisabled = plain(s.MenuOptionDisabled).Faint(true)
+	s.StatusBar = plain(s.StatusBar).Reverse(true)
+	s.StatusModified = plain(s.StatusModified).Reverse(true)
+}
+
+// barColor returns the escape that starts a run of menu or status bar text
+// in fg on bg. With color disabled bars are drawn in reverse video instead.
+func barColor(fg, bg string) string {
+	if !colorEnabled {
+		return "\033[7m"
+	}
+	return ColorToANSI(fg, bg)
+}
+
+// barHighlight returns the escape for a highlighted item on a bar drawn
+// with barColor: fg on bg, or normal video where the bar is reversed.
+func barHighlight(fg, bg string) string {
+	if !colorEnabled {
+		return "\033[27m"
+	}
+	return ColorToANSI(fg, bg)
 }
 
 // DefaultStyles returns the default style configuration (DOS EDIT theme)
@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cornish/textivus-editor/config"
+	"github.com/cornish/textivus-editor/syntax"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestColorToANSIDepth(t *testing.T) {
+	defer func(d config.ColorMode) { ColorDepth = d }(ColorDepth)
+
+	tests := []struct {
+		name   string
+		depth  config.ColorMode
+		color  string
+		wantFg string
+		wantBg string
+	}{
+		{"truecolor hex", config.ColorTrueColor, "#FF8700", "\033[38;2;255;135;0m", "\033[48;2;255;135;0m"},
+		{"256 hex", config.Color256, "#FF8700", "\033[38;5;208m", "\033[48;5;208m"},
+		{"256 gray hex", config.Color256, "#808080", "\033[38;5;244m", "\033[48;5;244m"},
+		{"16 hex nearest red", config.Color16, "#E01010", "\033[31m", "\033[41m"},
+		{"16 hex nearest bright blue", config.Color16, "#5F5FFF", "\033[94m", "\033[104m"},
+		{"16 hex white", config.Color16, "#FAFAFA", "\033[97m", "\033[107m"},
+		{"16 indexed cube color", config.Color16, "46", "\033[92m", "\033[102m"},
+		{"16 indexed gray", config.Color16, "240", "\033[90m", "\033[100m"},
+		{"basic color unchanged", config.Color16, "3", "\033[33m", "\033[43m"},
+		{"indexed color kept at 256", config.Color256, "208", "\033[38;5;208m", "\033[48;5;208m"},
+		{"invalid color", config.ColorTrueColor, "bogus", "\033[37m", "\033[40m"},
+	}
+
+	for _, tt := range tests {
+		ColorDepth = tt.depth
+		if got := ColorToANSIFg(tt.color); got != tt.wantFg {
+			t.Errorf("%s: ColorToANSIFg(%q) = %q, want %q", tt.name, tt.color, got, tt.wantFg)
+		}
+		if got := ColorToANSIBg(tt.color); got != tt.wantBg {
+			t.Errorf("%s: ColorToANSIBg(%q) = %q, want %q", tt.name, tt.color, got, tt.wantBg)
+		}
+	}
+}
+
+func TestColorDisabledRendersPlainRows(t *testing.T) {
+	defer SetColorEnabled(true)
+
+	state := &RenderState{
+		Lines:         []string{"package main", "func main() {}", "x"},
+		CursorLine:    5, // Off screen so no cursor attribute is drawn
+		ModifiedLines: map[int]bool{1: true},
+		LineColors: map[int][]syntax.ColorSpan{
+			0: {{Start: 0, End: 7, Color: "\033[35m"}},
+		},
+		TabWidth:   4,
+		TotalLines: 3,
+	}
+	scrollbar := NewScrollbar(DefaultStyles())
+	scrollbar.SetEnabled(true)
+	renderers := map[string]ColumnRenderer{
+		"text":      NewTextRenderer(DefaultStyles()),
+		"line":      NewLineNumberRenderer(DefaultStyles()),
+		"modified":  NewModifiedGutterRenderer(DefaultStyles()),
+		"minimap":   NewMinimapRenderer(DefaultStyles()),
+		"scrollbar": NewScrollbarColumnAdapter(scrollbar),
+	}
+
+	for name, r := range renderers {
+		SetColorEnabled(true)
+		colored := r.Render(8, 5, state)
+		SetColorEnabled(false)
+		plain := r.Render(8, 5, state)
+
+		for i, row := range plain {
+			if strings.Contains(row, "\033") {
+				t.Errorf("%s row %d contains an escape with color disabled: %q", name, i, row)
+			}
+			if got, want := row, stripANSI(colored[i]); got != want {
+				t.Errorf("%s row %d = %q, want %q", name, i, got, want)
+			}
+		}
+	}
+}
+
+func TestColorDisabledSelectionUsesReverseVideo(t *testing.T) {
+	defer SetColorEnabled(true)
+	SetColorEnabled(false)
+
+	r := NewTextRenderer(DefaultStyles())
+	rows := r.Render(8, 1, &RenderState{
+		Lines:      []string{"abcd"},
+		CursorLine: 5,
+		Selection:  map[int]SelectionRange{0: {Start: 1, End: 3}},
+	})
+	if !strings.Contains(rows[0], "\033[7m") {
+		t.Errorf("selected text not shown in reverse video: %q", rows[0])
+	}
+	if got := stripANSI(rows[0]); got != "abcd    " {
+		t.Errorf("content = %q, want %q", got, "abcd    ")
+	}
+}
+
+func TestColorDisabledBarsUseReverseVideo(t *testing.T) {
+	defer SetColorEnabled(true)
+	SetColorEnabled(false)
+
+	styles := DefaultStyles()
+	if !styles.StatusBar.GetReverse() || !styles.MenuOptionActive.GetReverse() {
+		t.Error("status bar and active menu option styles are not reversed")
+	}
+	if _, ok := styles.MenuBar.GetBackground().(lipgloss.NoColor); !ok {
+		t.Errorf("menu bar background = %v, want none", styles.MenuBar.GetBackground())
+	}
+
+	menu := NewMenuBar(styles)
+	menu.SetWidth(40)
+	menu.OpenMenu(0)
+	status := NewStatusBar(styles)
+	status.SetWidth(40)
+	for name, view := range map[string]string{"menu bar": menu.View(), "status bar": status.View()} {
+		if !strings.HasPrefix(view, "\033[7m") {
+			t.Errorf("%s does not start in reverse video: %q", name, view)
+		}
+		if strings.Contains(view, "38;") || strings.Contains(view, "48;") {
+			t.Errorf("%s has color escapes with color disabled: %q", name, view)
+		}
+	}
+	if !strings.Contains(menu.View(), "\033[27m") {
+		t.Error("open menu title is not set off from the reversed bar")
+	}
+}
+
+func TestRenderersCloseSGR(t *testing.T) {
+	lines := []string{"package main", "", "\tfunc main() {}", strings.Repeat("x", 30)}
+	scrollbar := NewScrollbar(DefaultStyles())
+	scrollbar.SetEnabled(true)
+	minimap := NewMinimapRenderer(DefaultStyles())
+	minimap.SetEnabled(true)
+	renderers := map[string]ColumnRenderer{
+		"text":      NewTextRenderer(DefaultStyles()),
+		"line":      NewLineNumberRenderer(DefaultStyles()),
+		"modified":  NewModifiedGutterRenderer(DefaultStyles()),
+		"minimap":   minimap,
+		"scrollbar": NewScrollbarColumnAdapter(scrollbar),
+	}
+
+	for _, wrap := range []bool{false, true} {
+		state := &RenderState{
+			Lines:         lines,
+			CursorLine:    1,
+			GhostText:     "suggestion",
+			Selection:     map[int]SelectionRange{0: {Start: 3, End: -1}, 2: {Start: 0, End: 4}},
+			ModifiedLines: map[int]bool{0: true, 2: true},
+			LineColors: map[int][]syntax.ColorSpan{
+				0: {{Start: 0, End: 7, Color: "\033[35m"}},
+				2: {{Start: 1, End: 5, Color: "\033[1;34m"}},
+			},
+			WordWrap:         wrap,
+			TabWidth:         4,
+			TextWidth:        12,
+			EOLMarker:        "¶",
+			TotalLines:       len(lines),
+			TotalVisualLines: len(lines) + 2,
+			Styles:           DefaultStyles(),
+		}
+		for name, r := range renderers {
+			// Taller than the document so rows past the end are drawn too
+			for i, row := range r.Render(12, 10, state) {
+				if sgrOpen(row) {
+					t.Errorf("wrap=%v %s row %d leaves an attribute open: %q", wrap, name, i, row)
+				}
+			}
+		}
+	}
+}
+
+func TestCloseSGR(t *testing.T) {
+	tests := []struct {
+		name string
+		row  string
+		want string
+	}{
+		{"plain", "abc", "abc"},
+		{"closed", "\033[31mab\033[0m ", "\033[31mab\033[0m "},
+		{"left open", "\033[31mab  ", "\033[31mab  \033[0m"},
+		{"short reset", "\033[7mx\033[m", "\033[7mx\033[m"},
+		{"reopened after a reset", "\033[1ma\033[0m\033[2mb", "\033[1ma\033[0m\033[2mb\033[0m"},
+		{"non-SGR escapes ignored", "\033[2Kab", "\033[2Kab"},
+	}
+
+	for _, tt := range tests {
+		if got := closeSGR(tt.row); got != tt.want {
+			t.Errorf("%s: closeSGR(%q) = %q, want %q", tt.name, tt.row, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// tabStopWidth returns how many cells a tab occupies when it starts at
+// visual column col: it advances to the next multiple of tabWidth.
+func tabStopWidth(col, tabWidth int) int {
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	return tabWidth - col%tabWidth
+}
+
+// expandTabs replaces each tab in line with spaces up to the next tab stop.
+// colMap[i] is the visual column where rune i starts; it has one extra
+// entry holding the total width, which is where a cursor after the last
+// rune is drawn. Wide characters advance the column by two. The runes of a
+// grapheme cluster all start where the cluster does, so a flag or a letter
+// with combining marks takes the cells the terminal draws for it.
+func expandTabs(line string, tabWidth int) (expanded string, colMap []int) {
+	var sb strings.Builder
+	colMap = make([]int, 0, len(line)+1)
+	col := 0
+	for rest, graphemes := line, -1; rest != ""; {
+		var cluster string
+		var w int
+		cluster, rest, w, graphemes = uniseg.FirstGraphemeClusterInString(rest, graphemes)
+		for n := utf8.RuneCountInString(cluster); n > 0; n-- {
+			colMap = append(colMap, col)
+		}
+		if cluster == "\t" {
+			w = tabStopWidth(col, tabWidth)
+			sb.WriteString(strings.Repeat(" ", w))
+		} else {
+			sb.WriteString(cluster)
+		}
+		col += w
+	}
+	colMap = append(colMap, col)
+	return sb.String(), colMap
+}
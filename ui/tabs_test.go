@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		line       string
+		tabWidth   int
+		want       string
+		wantColMap []int
+	}{
+		{"\tx", 4, "    x", []int{0, 4, 5}},
+		{"ab\tc", 4, "ab  c", []int{0, 1, 2, 4, 5}},
+		{"ab\tc", 2, "ab  c", []int{0, 1, 2, 4, 5}},
+		{"abc\td", 2, "abc d", []int{0, 1, 2, 3, 4, 5}},
+		{"日\tx", 4, "日  x", []int{0, 2, 4, 5}},
+		{"日本\t語", 4, "日本    語", []int{0, 2, 4, 8, 10}},
+		{"日本語\tx", 8, "日本語  x", []int{0, 2, 4, 6, 8, 9}},
+		{"a\t日\tb", 8, "a       日      b", []int{0, 1, 8, 10, 16, 17}},
+		{"a\t日\tb", 2, "a 日  b", []int{0, 1, 2, 4, 6, 7}},
+		{"e\u0301\tx", 4, "e\u0301   x", []int{0, 0, 1, 4, 5}},
+		{"", 4, "", []int{0}},
+	}
+
+	for _, tt := range tests {
+		got, colMap := expandTabs(tt.line, tt.tabWidth)
+		if got != tt.want || !reflect.DeepEqual(colMap, tt.wantColMap) {
+			t.Errorf("expandTabs(%q, %d) = (%q, %v), want (%q, %v)",
+				tt.line, tt.tabWidth, got, colMap, tt.want, tt.wantColMap)
+		}
+	}
+}
+
+func TestTabStopWidth(t *testing.T) {
+	tests := []struct {
+		col, tabWidth, want int
+	}{
+		{0, 4, 4},
+		{2, 4, 2},
+		{4, 4, 4},
+		{3, 2, 1},
+		{5, 8, 3},
+		{1, 0, 3}, // Unset width falls back to 4
+	}
+
+	for _, tt := range tests {
+		if got := tabStopWidth(tt.col, tt.tabWidth); got != tt.want {
+			t.Errorf("tabStopWidth(%d, %d) = %d, want %d", tt.col, tt.tabWidth, got, tt.want)
+		}
+	}
+}
+
+func TestTextRendererTabStops(t *testing.T) {
+	r := NewTextRenderer(DefaultStyles())
+	tests := []struct {
+		tabWidth int
+		want     []string
+	}{
+		{2, []string{"ab  c           ", "  d             ", "日  x           ", "a 日  b         "}},
+		{4, []string{"ab  c           ", "    d           ", "日  x           ", "a   日  b       "}},
+		{8, []string{"ab      c       ", "        d       ", "日      x       ", "a       日      "}}, // b is past the edge
+	}
+
+	for _, tt := range tests {
+		state := &RenderState{
+			Lines:      []string{"ab\tc", "\td", "日\tx", "a\t日\tb"},
+			CursorLine: 9,
+			TabWidth:   tt.tabWidth,
+		}
+		rows := r.Render(16, len(tt.want), state)
+		for i, want := range tt.want {
+			if got := stripANSI(rows[i]); got != want {
+				t.Errorf("tab width %d: row %d = %q, want %q", tt.tabWidth, i, got, want)
+			}
+		}
+	}
+}
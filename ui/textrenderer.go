@@ -6,6 +6,7 @@ import (
 
 	"github.com/cornish/textivus-editor/syntax"
 	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
 )
 
 // TextRenderer renders the main text content column.
@@ -42,10 +43,44 @@ func (r *TextRenderer) Render(width, height int, state *RenderState) []string {
 	return r.renderNoWrap(width, height, state)
 }
 
+// RenderLine renders one line on its own, without the compositor: the
+// output Render would give for it with syntax colors, selection sel (nil
+// for none), no cursor and horizontal scroll scrollX. The result is
+// exactly width cells wide.
+func (r *TextRenderer) RenderLine(line string, colors []syntax.ColorSpan, sel *SelectionRange, tabWidth, width, scrollX int) string {
+	if width <= 0 {
+		return ""
+	}
+	state := &RenderState{
+		Lines:      []string{line},
+		CursorLine: -1,
+		ScrollX:    scrollX,
+		TabWidth:   tabWidth,
+	}
+	if sel != nil {
+		state.Selection = map[int]SelectionRange{0: *sel}
+	}
+	return r.renderLineContent(line, 0, width, state, colors)
+}
+
 // renderNoWrap renders without word wrap.
 func (r *TextRenderer) renderNoWrap(width, height int, state *RenderState) []string {
 	rows := make([]string, height)
 
+	if len(state.Folds) > 0 {
+		for row, d := range state.displayRows(height) {
+			switch {
+			case d.line >= len(state.Lines):
+				rows[row] = r.renderEmptyLine(width, state)
+			case d.folded > 0:
+				rows[row] = r.renderFoldSummary(d.folded, width)
+			default:
+				rows[row] = r.renderLineContent(state.Lines[d.line], d.line, width, state, state.lineColors(d.line))
+			}
+		}
+		return rows
+	}
+
 	endLine := state.ScrollY + height
 	if endLine > len(state.Lines) {
 		endLine = len(state.Lines)
@@ -57,17 +92,11 @@ func (r *TextRenderer) renderNoWrap(width, height int, state *RenderState) []str
 		if lineIdx < len(state.Lines) {
 			line := state.Lines[lineIdx]
 
-			// Get syntax colors for this line
-			var colors []syntax.ColorSpan
-			if state.LineColors != nil {
-				colors = state.LineColors[lineIdx]
-			}
-
-			// Render line content with selection and cursor
-			rows[row] = r.renderLineContent(line, lineIdx, width, state, colors)
+			// Render line content with syntax colors, selection and cursor
+			rows[row] = r.renderLineContent(line, lineIdx, width, state, state.lineColors(lineIdx))
 		} else {
 			// Past end of file - render empty line marker
-			rows[row] = r.renderEmptyLine(width)
+			rows[row] = r.renderEmptyLine(width, state)
 		}
 	}
 
@@ -105,13 +134,11 @@ func (r *TextRenderer) renderWrapped(width, height int, state *RenderState) []st
 	// Render visible lines
 	for visualLineCount < height && logicalLine < len(state.Lines) {
 		line := state.Lines[logicalLine]
-		sel := state.Selection[logicalLine]
+		sel, hasSelection := state.Selection[logicalLine]
+		p := newSelectionPainter(sel, hasSelection, tabWidth, r.styles)
 		wrappedLines := wrapLineLocal(line, width, tabWidth)
 
-		var colors []syntax.ColorSpan
-		if state.LineColors != nil {
-			colors = state.LineColors[logicalLine]
-		}
+		colors := state.lineColors(logicalLine)
 
 		// Track starting column for each wrapped segment
 		segmentStartCol := 0
@@ -123,9 +150,23 @@ func (r *TextRenderer) renderWrapped(width, height int, state *RenderState) []st
 				}
 			}
 
+			ghost := ""
+			if logicalLine == state.CursorLine {
+				ghost = state.GhostText
+			}
+			last := wrapIdx == len(wrappedLines)-1
+			eol := ""
+			if last {
+				eol = eolMarker(state)
+			}
+			// Leading whitespace, and so any guide, is in the first segment
+			guide := noGuide
+			if wrapIdx == 0 {
+				guide = r.guideFunc(state, logicalLine, line, tabWidth)
+			}
 			rows[visualLineCount] = r.renderWrappedSegment(
 				wrappedLines[wrapIdx], logicalLine, segmentStartCol,
-				state.CursorLine, state.CursorCol, sel, width, tabWidth, colors,
+				state.CursorLine, state.CursorCol, state.SecondaryCursors[logicalLine], state.CursorShape, p, width, colors, ghost, eol, guide, last,
 			)
 			visualLineCount++
 			segmentStartCol += utf8.RuneCountInString(wrappedLines[wrapIdx])
@@ -137,7 +178,7 @@ func (r *TextRenderer) renderWrapped(width, height int, state *RenderState) []st
 
 	// Fill remaining lines with empty markers
 	for visualLineCount < height {
-		rows[visualLineCount] = r.renderEmptyLine(width)
+		rows[visualLineCount] = r.renderEmptyLine(width, state)
 		visualLineCount++
 	}
 
@@ -146,185 +187,240 @@ func (r *TextRenderer) renderWrapped(width, height int, state *RenderState) []st
 
 // renderLineContent renders a single line's content with selection and cursor (no wrap).
 func (r *TextRenderer) renderLineContent(line string, lineIdx, width int, state *RenderState, colors []syntax.ColorSpan) string {
-	runes := []rune(line)
+	lineLen := utf8.RuneCountInString(line)
 	var sb strings.Builder
 
-	// Get ANSI codes for cursor and selection
-	ui := r.styles.Theme.UI
-	cursorCode := "\033[7m" // Reverse video for cursor
-	selectionBg := ColorToANSIBg(ui.SelectionBg)
-	selectionFg := ColorToANSIFg(ui.SelectionFg)
-	resetCode := "\033[0m"
-
-	// Apply horizontal scroll
-	visibleStart := state.ScrollX
-	visualCol := 0
-	runeIdx := 0
-
-	// Skip to scroll position
 	tabWidth := state.TabWidth
 	if tabWidth <= 0 {
 		tabWidth = 4
 	}
-	for runeIdx < len(runes) && visualCol < visibleStart {
-		ru := runes[runeIdx]
-		if ru == '\t' {
-			visualCol += tabWidth
-		} else {
-			visualCol += runewidth.RuneWidth(ru)
-		}
-		runeIdx++
-	}
+	visibleStart := state.ScrollX
 
-	// Get selection range for this line
 	sel, hasSelection := state.Selection[lineIdx]
+	p := newSelectionPainter(sel, hasSelection, tabWidth, r.styles)
+	guide := r.guideFunc(state, lineIdx, line, tabWidth)
+	isCursor := func(col int) bool {
+		return (lineIdx == state.CursorLine && col == state.CursorCol) || state.isSecondaryCursor(lineIdx, col)
+	}
 
-	// Render visible portion
-	outputCol := 0
-	for runeIdx < len(runes) && outputCol < width {
-		ru := runes[runeIdx]
-		rw := runewidth.RuneWidth(ru)
+	// Render the visible portion, after what is scrolled off to the left
+	runeIdx, visualCol, outputCol := p.paintRow(&sb, line, 0, visibleStart, width, textCells(isCursor, state.CursorShape, colors, guide))
+	// Cells from here on are past the text, at a fixed offset from outputCol
+	padOffset := max(visualCol, visibleStart) - outputCol
 
-		char := string(ru)
-		if ru == '\t' {
-			char = strings.Repeat(" ", tabWidth) // Render tab as spaces
-			rw = tabWidth
-		}
+	// The end-of-line marker takes the cell after the last character, when
+	// that cell is on screen. A cursor or selection there is drawn over it.
+	eol := ""
+	if runeIdx == lineLen && visualCol >= visibleStart && outputCol < width {
+		eol = eolMarker(state)
+	}
+	cell := " "
+	if eol != "" {
+		cell = eol
+	}
 
-		if outputCol+rw > width {
-			break
+	// In virtual space the cursor can sit past the end of the line: pad with
+	// spaces up to it so it is drawn in its column
+	if state.VirtualSpace && lineIdx == state.CursorLine && runeIdx == lineLen && state.CursorCol > runeIdx {
+		pad := state.CursorCol - runeIdx
+		if skip := visibleStart - visualCol; skip > 0 {
+			pad -= skip // Part of the virtual space is scrolled off to the left
 		}
-
-		isCursor := lineIdx == state.CursorLine && runeIdx == state.CursorCol
-		isSelected := hasSelection && runeIdx >= sel.Start && (sel.End == -1 || runeIdx < sel.End)
-
-		if isCursor {
-			sb.WriteString(cursorCode)
-			sb.WriteString(char)
-			sb.WriteString(resetCode)
-		} else if isSelected {
-			sb.WriteString(selectionBg)
-			sb.WriteString(selectionFg)
-			sb.WriteString(char)
-			sb.WriteString(resetCode)
-		} else {
-			syntaxColor := syntax.ColorAt(colors, runeIdx)
-			if syntaxColor != "" {
-				sb.WriteString(syntaxColor)
-				sb.WriteString(char)
-				sb.WriteString(resetCode)
-			} else {
-				sb.WriteString(char)
+		if pad >= 0 && outputCol+pad < width {
+			if eol != "" {
+				// The marker keeps its cell at the end of the line
+				sb.WriteString(r.nonTextCode() + eol + colorReset())
+				outputCol++
+				pad--
+				eol, cell = "", " "
 			}
+			sb.WriteString(strings.Repeat(" ", pad))
+			outputCol += pad
+			runeIdx = state.CursorCol
 		}
-
-		visualCol += rw
-		outputCol += rw
-		runeIdx++
 	}
 
 	// Render cursor at end of line if needed
-	if lineIdx == state.CursorLine && runeIdx == state.CursorCol {
-		sb.WriteString(cursorCode)
-		sb.WriteString(" ")
-		sb.WriteString(resetCode)
+	atCursor := lineIdx == state.CursorLine && runeIdx == state.CursorCol
+	switch {
+	case outputCol >= width:
+		// The line was cut off at the right edge; there is no cell left
+	case atCursor && state.GhostText != "":
+		outputCol += renderGhostText(&sb, state.GhostText, width-outputCol, state.CursorShape)
+	case atCursor || state.isSecondaryCursor(lineIdx, runeIdx):
+		sb.WriteString(state.CursorShape.cell(cell, ""))
+		outputCol++
+	case runeIdx == lineLen && p.covers(runeIdx):
+		// Selection running on past the end of the line
+		p.paintCell(&sb, cell)
 		outputCol++
-	} else if hasSelection && runeIdx >= sel.Start && (sel.End == -1 || runeIdx < sel.End) {
-		sb.WriteString(selectionBg)
-		sb.WriteString(selectionFg)
-		sb.WriteString(" ")
-		sb.WriteString(resetCode)
+	case eol != "":
+		sb.WriteString(r.nonTextCode() + eol + colorReset())
 		outputCol++
 	}
 
 	// Pad to full width
 	if outputCol < width {
-		padding := width - outputCol
-		sb.WriteString(strings.Repeat(" ", padding))
+		writePadding(&sb, width-outputCol, outputCol+padOffset, guide)
 	}
 
 	return sb.String()
 }
 
 // renderWrappedSegment renders a single wrapped segment of a line.
-func (r *TextRenderer) renderWrappedSegment(segment string, lineIdx, segmentStartCol, cursorLine, cursorCol int, sel SelectionRange, width, tabWidth int, colors []syntax.ColorSpan) string {
+// secondaryCols holds the columns of any secondary cursors on this line,
+// p paints the line's selection, ghost is any ghost text to draw at the
+// cursor, eol the end-of-line marker and guide the line's indent guides.
+// last reports whether this is the line's last segment.
+func (r *TextRenderer) renderWrappedSegment(segment string, lineIdx, segmentStartCol, cursorLine, cursorCol int, secondaryCols []int, shape CursorStyle, p selectionPainter, width int, colors []syntax.ColorSpan, ghost, eol string, guide func(visualCol int) string, last bool) string {
 	var sb strings.Builder
-	runes := []rune(segment)
+	segmentLen := utf8.RuneCountInString(segment)
 
-	// Get ANSI codes for cursor and selection
-	ui := r.styles.Theme.UI
-	cursorCode := "\033[7m" // Reverse video for cursor
-	selectionBg := ColorToANSIBg(ui.SelectionBg)
-	selectionFg := ColorToANSIFg(ui.SelectionFg)
-	resetCode := "\033[0m"
+	isCursor := func(col int) bool {
+		return (lineIdx == cursorLine && col == cursorCol) || containsInt(secondaryCols, col)
+	}
+	// Tab stops are measured from the start of the segment, as in wrapLineLocal
+	_, _, outputCol := p.paintRow(&sb, segment, segmentStartCol, 0, width, textCells(isCursor, shape, colors, guide))
+
+	// Cursor at end of line. On an earlier segment the column after it
+	// starts the next segment, which draws the cursor there
+	segmentEndCol := segmentStartCol + segmentLen
+	cursorAtEnd := last && isCursor(segmentEndCol)
+	if cursorAtEnd && segmentEndCol%width == 0 && segmentLen == width {
+		// Cursor is at wrap point, don't show here
+	} else if last && ghost != "" && lineIdx == cursorLine && cursorCol == segmentEndCol && outputCol < width {
+		outputCol += renderGhostText(&sb, ghost, width-outputCol, shape)
+	} else if cursorAtEnd && outputCol < width {
+		cell := " "
+		if eol != "" {
+			cell = eol
+		}
+		sb.WriteString(shape.cell(cell, ""))
+		outputCol++
+	} else if last && p.covers(segmentEndCol) && outputCol < width {
+		// Selection running on past the end of the line
+		cell := " "
+		if eol != "" {
+			cell = eol
+		}
+		p.paintCell(&sb, cell)
+		outputCol++
+	} else if eol != "" && outputCol < width {
+		sb.WriteString(r.nonTextCode() + eol + colorReset())
+		outputCol++
+	}
 
-	if tabWidth <= 0 {
-		tabWidth = 4
+	// Pad to full width
+	if outputCol < width {
+		writePadding(&sb, width-outputCol, outputCol, guide)
 	}
 
-	outputCol := 0
-	for i, ru := range runes {
-		col := segmentStartCol + i
-		isCursor := lineIdx == cursorLine && col == cursorCol
-		isSelected := sel.Start <= col && (sel.End == -1 || col < sel.End)
+	return sb.String()
+}
 
-		char := string(ru)
-		charWidth := runewidth.RuneWidth(ru)
-		if ru == '\t' {
-			char = strings.Repeat(" ", tabWidth)
-			charWidth = tabWidth
+// textCells returns the drawer for the cells of one line: the cursor,
+// where isCursor says, in shape over everything, then for cells outside
+// the selection the indent guides and syntax colors.
+func textCells(isCursor func(col int) bool, shape CursorStyle, colors []syntax.ColorSpan, guide func(visualCol int) string) cellDrawer {
+	return func(col, cell int, char string, w int, selected bool) string {
+		switch {
+		case isCursor(col):
+			return shape.cell(char, syntax.ColorAt(colors, col))
+		case selected:
+			return ""
 		}
+		if g := guide(cell); g != "" {
+			return g + strings.Repeat(" ", w-1)
+		}
+		if c := syntax.ColorAt(colors, col); c != "" && colorEnabled {
+			return c + char + sgrReset()
+		}
+		return ""
+	}
+}
 
-		if isCursor {
-			sb.WriteString(cursorCode)
-			sb.WriteString(char)
-			sb.WriteString(resetCode)
-		} else if isSelected {
-			sb.WriteString(selectionBg)
-			sb.WriteString(selectionFg)
-			sb.WriteString(char)
-			sb.WriteString(resetCode)
+// renderGhostText draws ghost text dim at an end-of-line cursor, the first
+// character inside the cursor drawn in shape, and returns the columns used.
+// It stops at the first newline and draws only what fits in room, but
+// always at least the cursor cell.
+func renderGhostText(sb *strings.Builder, ghost string, room int, shape CursorStyle) int {
+	if i := strings.IndexByte(ghost, '\n'); i >= 0 {
+		ghost = ghost[:i]
+	}
+	used := 0
+	for _, ru := range ghost {
+		if ru == '\t' {
+			ru = ' '
+		}
+		w := runewidth.RuneWidth(ru)
+		if w == 0 || used+w > room {
+			break
+		}
+		if used == 0 {
+			sb.WriteString("\033[2m" + shape.cell(string(ru), "") + "\033[2m")
 		} else {
-			syntaxColor := syntax.ColorAt(colors, col)
-			if syntaxColor != "" {
-				sb.WriteString(syntaxColor)
-				sb.WriteString(char)
-				sb.WriteString(resetCode)
-			} else {
-				sb.WriteString(char)
-			}
+			sb.WriteRune(ru)
 		}
-		outputCol += charWidth
+		used += w
+	}
+	if used == 0 {
+		sb.WriteString(shape.cell(" ", ""))
+		return 1
 	}
+	sb.WriteString(sgrReset())
+	return used
+}
 
-	// Cursor at end of segment
-	segmentEndCol := segmentStartCol + len(runes)
-	if lineIdx == cursorLine && cursorCol == segmentEndCol && segmentEndCol%width == 0 && len(runes) == width {
-		// Cursor is at wrap point, don't show here
-	} else if lineIdx == cursorLine && cursorCol >= segmentStartCol && cursorCol <= segmentEndCol && outputCol < width {
-		if cursorCol == segmentEndCol {
-			sb.WriteString(cursorCode)
-			sb.WriteString(" ")
-			sb.WriteString(resetCode)
-			outputCol++
+// selectionCodes returns the escapes that start selected text: the theme's
+// selection colors, or reverse video when color is disabled.
+func selectionCodes(bg, fg string) (string, string) {
+	if !colorEnabled {
+		return "\033[7m", ""
+	}
+	return ColorToANSIBg(bg), ColorToANSIFg(fg)
+}
+
+// containsInt reports whether v is in list.
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
 		}
 	}
+	return false
+}
 
-	// Pad to full width
-	if outputCol < width {
-		sb.WriteString(strings.Repeat(" ", width-outputCol))
+// eolMarker returns the end-of-line glyph to draw, or "" when there is none
+// or it would not fit in one cell.
+func eolMarker(state *RenderState) string {
+	if runewidth.StringWidth(state.EOLMarker) != 1 {
+		return ""
 	}
+	return state.EOLMarker
+}
 
-	return sb.String()
+// nonTextCode returns the escape that starts a marker that is not part of
+// the text: the theme's non-text color, or dim gray when it has none.
+func (r *TextRenderer) nonTextCode() string {
+	if !colorEnabled {
+		return ""
+	}
+	if c := r.styles.Theme.UI.NonText; c != "" {
+		return ColorToANSIFg(c)
+	}
+	return "\033[90m" // Dim gray
 }
 
-// renderEmptyLine renders an empty line marker (~).
-func (r *TextRenderer) renderEmptyLine(width int) string {
+// renderEmptyLine renders a row past the end of the buffer: the
+// end-of-buffer marker (~), or blank when the marker is hidden.
+func (r *TextRenderer) renderEmptyLine(width int, state *RenderState) string {
+	if state.HideEndOfBuffer {
+		return strings.Repeat(" ", width)
+	}
 	var sb strings.Builder
-	// Use dim gray for empty line marker
-	sb.WriteString("\033[90m") // Dim gray
+	sb.WriteString(r.nonTextCode())
 	sb.WriteString("~")
-	sb.WriteString("\033[0m")
+	sb.WriteString(colorReset())
 	if width > 1 {
 		sb.WriteString(strings.Repeat(" ", width-1))
 	}
@@ -334,52 +430,32 @@ func (r *TextRenderer) renderEmptyLine(width int) string {
 // Helper functions (local copies to avoid dependency issues)
 
 // countWrappedLinesLocal counts how many visual lines a buffer line takes.
-// Accounts for tabs and wide characters.
+// Accounts for tabs and wide characters, breaking where wrapLineLocal does.
 func countWrappedLinesLocal(line string, width, tabWidth int) int {
-	if width <= 0 {
-		return 1
-	}
-	visualWidth := calculateVisualWidth(line, tabWidth)
-	if visualWidth == 0 {
-		return 1
-	}
-	return (visualWidth + width - 1) / width
+	count := 0
+	wrapClusters(line, width, tabWidth, func(cluster string, newSegment bool) {
+		if newSegment {
+			count++
+		}
+	})
+	return max(count, 1)
 }
 
 // wrapLineLocal splits a line into segments that fit within width visual columns.
-// Accounts for tabs and wide characters.
+// Accounts for tabs and wide characters, and never splits a grapheme cluster.
 func wrapLineLocal(line string, width, tabWidth int) []string {
 	if width <= 0 {
 		return []string{line}
 	}
-	if tabWidth <= 0 {
-		tabWidth = 4
-	}
-	runes := []rune(line)
-	if len(runes) == 0 {
-		return []string{""}
-	}
-
 	var segments []string
 	var currentSegment strings.Builder
-	currentWidth := 0
-
-	for _, r := range runes {
-		charWidth := runewidth.RuneWidth(r)
-		if r == '\t' {
-			charWidth = tabWidth
-		}
-
-		if currentWidth+charWidth > width {
-			// Start a new segment
+	wrapClusters(line, width, tabWidth, func(cluster string, newSegment bool) {
+		if newSegment && currentSegment.Len() > 0 {
 			segments = append(segments, currentSegment.String())
 			currentSegment.Reset()
-			currentWidth = 0
 		}
-
-		currentSegment.WriteRune(r)
-		currentWidth += charWidth
-	}
+		currentSegment.WriteString(cluster)
+	})
 
 	// Don't forget the last segment
 	if currentSegment.Len() > 0 {
@@ -392,19 +468,60 @@ func wrapLineLocal(line string, width, tabWidth int) []string {
 	return segments
 }
 
+// wrapClusters walks the grapheme clusters of line, calling fn with each
+// and whether it starts a new segment of at most width visual columns.
+// Tab stops are measured from the start of each segment.
+func wrapClusters(line string, width, tabWidth int, fn func(cluster string, newSegment bool)) {
+	if width <= 0 {
+		return
+	}
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	currentWidth := 0
+	first := true
+	for rest, graphemes := line, -1; rest != ""; {
+		var cluster string
+		var w int
+		cluster, rest, w, graphemes = uniseg.FirstGraphemeClusterInString(rest, graphemes)
+		charWidth := clusterWidth(cluster, w, currentWidth, tabWidth)
+		newSegment := first
+		if !first && currentWidth+charWidth > width {
+			// Start a new segment
+			newSegment = true
+			currentWidth = 0
+			charWidth = clusterWidth(cluster, w, 0, tabWidth)
+		}
+		first = false
+		fn(cluster, newSegment)
+		currentWidth += charWidth
+	}
+}
+
+// clusterWidth returns the cells a grapheme cluster of display width w
+// takes at visual column col: a tab runs to the next tab stop.
+func clusterWidth(cluster string, w, col, tabWidth int) int {
+	if cluster == "\t" {
+		return tabStopWidth(col, tabWidth)
+	}
+	return w
+}
+
 // calculateVisualWidth returns the visual width of a string,
 // accounting for tabs and wide characters.
 func calculateVisualWidth(s string, tabWidth int) int {
 	if tabWidth <= 0 {
 		tabWidth = 4
 	}
+	// Measure whole grapheme clusters so flags, ZWJ emoji sequences and
+	// combining marks count as the single cell (or two) the terminal draws
 	width := 0
-	for _, r := range s {
-		if r == '\t' {
-			width += tabWidth
-		} else {
-			width += runewidth.RuneWidth(r)
-		}
+	state := -1
+	for len(s) > 0 {
+		var cluster string
+		var w int
+		cluster, s, w, state = uniseg.FirstGraphemeClusterInString(s, state)
+		width += clusterWidth(cluster, w, width, tabWidth)
 	}
 	return width
 }
@@ -0,0 +1,422 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cornish/textivus-editor/syntax"
+)
+
+func TestTextRendererDrawsSecondaryCursors(t *testing.T) {
+	const cursor = "\033[7m"
+	r := NewTextRenderer(DefaultStyles())
+	state := &RenderState{
+		Lines:            []string{"abcd", "ef"},
+		CursorLine:       0,
+		CursorCol:        0,
+		SecondaryCursors: map[int][]int{0: {2}, 1: {2}},
+		TabWidth:         4,
+	}
+
+	for _, wrap := range []bool{false, true} {
+		state.WordWrap = wrap
+		rows := r.Render(10, 2, state)
+		if got := strings.Count(rows[0], cursor); got != 2 {
+			t.Errorf("wrap=%v: row 0 has %d cursors, want 2: %q", wrap, got, rows[0])
+		}
+		// Secondary cursor past the end of the line
+		if got := strings.Count(rows[1], cursor); got != 1 {
+			t.Errorf("wrap=%v: row 1 has %d cursors, want 1: %q", wrap, got, rows[1])
+		}
+	}
+}
+
+func TestCalculateVisualWidthGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want int
+	}{
+		{"ascii", "abc", 3},
+		{"flag", "\U0001F1EF\U0001F1F5", 2},
+		{"family emoji", "\U0001F468‍\U0001F469‍\U0001F467", 2},
+		{"combining accent", "é", 1},
+		{"tab after wide cluster", "\U0001F1EF\U0001F1F5\tx", 5},
+	}
+
+	for _, tt := range tests {
+		if got := calculateVisualWidth(tt.s, 4); got != tt.want {
+			t.Errorf("%s: calculateVisualWidth(%q) = %d, want %d", tt.name, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestGraphemeClustersWrapAndRender(t *testing.T) {
+	const flag = "\U0001F1EF\U0001F1F5" // Two regional indicators, two cells
+	const accented = "e\u0301"          // e and a combining acute accent, one cell
+
+	if got := wrapLineLocal("ab"+flag+"c", 3, 4); !reflect.DeepEqual(got, []string{"ab", flag + "c"}) {
+		t.Errorf("wrapLineLocal split = %q, want the flag kept whole", got)
+	}
+	if got := countWrappedLinesLocal("ab"+flag+"c", 3, 4); got != 2 {
+		t.Errorf("countWrappedLinesLocal = %d, want 2", got)
+	}
+
+	r := NewTextRenderer(DefaultStyles())
+	for _, wrap := range []bool{false, true} {
+		state := &RenderState{
+			Lines:     []string{accented + flag + "x"},
+			CursorCol: 0,
+			WordWrap:  wrap,
+			TabWidth:  4,
+		}
+		row := r.Render(8, 1, state)[0]
+		if !strings.HasPrefix(row, "\033[7m"+accented+"\033[0m") {
+			t.Errorf("wrap=%v: row = %q, want the cursor over the whole accented letter", wrap, row)
+		}
+		if !strings.HasSuffix(row, flag+"x    ") {
+			t.Errorf("wrap=%v: row = %q, want the flag drawn whole and padded to 8 cells", wrap, row)
+		}
+
+		// Rune column 4, past the accent and the flag's two runes, is the x
+		state.CursorCol = 4
+		row = r.Render(8, 1, state)[0]
+		if !strings.Contains(row, flag+"\033[7mx\033[0m") {
+			t.Errorf("wrap=%v: row = %q, want the cursor on x", wrap, row)
+		}
+	}
+
+	v := NewViewport(DefaultStyles())
+	if got := v.VisualColumn(accented+flag+"x", 4); got != 3 {
+		t.Errorf("VisualColumn after the flag = %d, want 3", got)
+	}
+}
+
+func TestTextRendererCursorAtEarlyWrap(t *testing.T) {
+	const cursor = "\033[7m"
+	r := NewTextRenderer(DefaultStyles())
+	// The wide character doesn't fit after "abcd" at width 5, so the first
+	// segment wraps a column early and leaves a cell free
+	state := &RenderState{
+		Lines:      []string{"abcd日e"},
+		CursorLine: 0,
+		CursorCol:  4,
+		TabWidth:   4,
+		WordWrap:   true,
+		TextWidth:  5,
+	}
+
+	rows := r.Render(5, 2, state)
+	if got := strings.Count(rows[0], cursor); got != 0 {
+		t.Errorf("row 0 has %d cursors, want 0: %q", got, rows[0])
+	}
+	if want := cursor + "日"; !strings.HasPrefix(rows[1], want) {
+		t.Errorf("row 1 = %q, want prefix %q", rows[1], want)
+	}
+}
+
+func TestTextRendererVirtualSpace(t *testing.T) {
+	const cursor = "\033[7m"
+	r := NewTextRenderer(DefaultStyles())
+	state := &RenderState{
+		Lines:      []string{"ab", "cd"},
+		CursorLine: 0,
+		CursorCol:  5,
+		TabWidth:   4,
+	}
+
+	rows := r.Render(10, 2, state)
+	if strings.Contains(rows[0], cursor) {
+		t.Errorf("without virtual space a cursor past EOL was drawn: %q", rows[0])
+	}
+
+	state.VirtualSpace = true
+	rows = r.Render(10, 2, state)
+	if want := "ab   " + cursor + " "; !strings.HasPrefix(rows[0], want) {
+		t.Errorf("row 0 = %q, want prefix %q", rows[0], want)
+	}
+	if got := visualWidth(rows[0]); got != 10 {
+		t.Errorf("row 0 width = %d, want 10", got)
+	}
+}
+
+func TestTextRendererGhostText(t *testing.T) {
+	tests := []struct {
+		name  string
+		ghost string
+		width int
+		want  string // Row 0 with ANSI codes
+	}{
+		{"faint from the cursor column", "llo", 10, "he\033[2m\033[7ml\033[0m\033[2mlo\033[0m     "},
+		{"clipped at the viewport edge", "llo world", 6, "he\033[2m\033[7ml\033[0m\033[2mlo \033[0m"},
+		{"only the first line of a suggestion", "y\nnext", 6, "he\033[2m\033[7my\033[0m\033[2m\033[0m   "},
+		{"wide character that does not fit", "日", 3, "he\033[7m \033[0m"},
+		{"empty ghost text draws the plain cursor", "", 6, "he\033[7m \033[0m   "},
+	}
+
+	r := NewTextRenderer(DefaultStyles())
+	for _, wrap := range []bool{false, true} {
+		for _, tt := range tests {
+			state := &RenderState{
+				Lines:      []string{"he", "x"},
+				CursorLine: 0,
+				CursorCol:  2,
+				GhostText:  tt.ghost,
+				TabWidth:   4,
+				WordWrap:   wrap,
+				TextWidth:  tt.width,
+			}
+			rows := r.Render(tt.width, 2, state)
+			if rows[0] != tt.want {
+				t.Errorf("wrap=%v %s: row 0 = %q, want %q", wrap, tt.name, rows[0], tt.want)
+			}
+			if w := visualWidth(rows[0]); w != tt.width {
+				t.Errorf("wrap=%v %s: row 0 width = %d, want %d", wrap, tt.name, w, tt.width)
+			}
+			if strings.Contains(rows[1], "\033[2m") {
+				t.Errorf("wrap=%v %s: ghost text drawn on another line: %q", wrap, tt.name, rows[1])
+			}
+		}
+	}
+}
+
+func TestTextRendererSyntaxUnderSelection(t *testing.T) {
+	const (
+		kw    = "\033[31m"
+		reset = "\033[0m"
+	)
+	r := NewTextRenderer(DefaultStyles())
+	bg, fg := selectionCodes(r.styles.Theme.UI.SelectionBg, r.styles.Theme.UI.SelectionFg)
+	sel := bg + fg
+
+	tests := []struct {
+		name    string
+		scrollX int
+		wrap    bool
+		width   int
+		want    []string
+	}{
+		{
+			"selection overrides syntax color",
+			0, false, 12,
+			[]string{kw + "f" + reset + kw + "u" + reset + sel + "n" + reset + sel + "c" + reset +
+				sel + "    " + reset + sel + "a" + reset + "b  "},
+		},
+		{
+			"horizontal scroll drops the highlighted prefix",
+			2, false, 8,
+			[]string{sel + "n" + reset + sel + "c" + reset + sel + "    " + reset + sel + "a" + reset + "b"},
+		},
+		{
+			"wrapped segments keep their colors",
+			0, true, 6,
+			[]string{kw + "f" + reset + kw + "u" + reset + sel + "n" + reset + sel + "c" + reset + "  ",
+				sel + "    " + reset + sel + "a" + reset + "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		state := &RenderState{
+			Lines:      []string{"func\tab"},
+			CursorLine: 5,
+			ScrollX:    tt.scrollX,
+			Selection:  map[int]SelectionRange{0: {Start: 2, End: 6}},
+			LineColors: map[int][]syntax.ColorSpan{0: {{Start: 0, End: 4, Color: kw}}},
+			TabWidth:   4,
+			WordWrap:   tt.wrap,
+			TextWidth:  tt.width,
+		}
+		rows := r.Render(tt.width, len(tt.want), state)
+		for i, want := range tt.want {
+			if rows[i] != want {
+				t.Errorf("%s: row %d = %q, want %q", tt.name, i, rows[i], want)
+			}
+			if w := visualWidth(rows[i]); w != tt.width {
+				t.Errorf("%s: row %d width = %d, want %d", tt.name, i, w, tt.width)
+			}
+		}
+	}
+}
+
+func TestTextRendererEOLMarker(t *testing.T) {
+	defer SetColorEnabled(true)
+	SetColorEnabled(false)
+
+	tests := []struct {
+		name  string
+		state RenderState
+		want  []string
+	}{
+		{
+			"marker at each line end",
+			RenderState{Lines: []string{"ab", "", "cd"}, CursorLine: 9, EOLMarker: "¶"},
+			[]string{"ab¶   ", "¶     ", "cd¶   "},
+		},
+		{
+			"marker drawn in the cursor cell",
+			RenderState{Lines: []string{"ab"}, CursorLine: 0, CursorCol: 2, EOLMarker: "$"},
+			[]string{"ab\033[7m$\033[0m   "},
+		},
+		{
+			"marker before virtual space",
+			RenderState{Lines: []string{"ab"}, CursorLine: 0, CursorCol: 4, VirtualSpace: true, EOLMarker: "$"},
+			[]string{"ab$ \033[7m \033[0m "},
+		},
+		{
+			"scrolled past the line end",
+			RenderState{Lines: []string{"ab", "abcdefgh"}, CursorLine: 9, ScrollX: 3, EOLMarker: "$"},
+			[]string{"      ", "defgh$"},
+		},
+		{
+			"wide glyph is ignored",
+			RenderState{Lines: []string{"ab"}, CursorLine: 9, EOLMarker: "日"},
+			[]string{"ab    "},
+		},
+		{
+			"wrapped line marks only its last segment",
+			RenderState{Lines: []string{"abcdefgh"}, CursorLine: 9, WordWrap: true, EOLMarker: "$"},
+			[]string{"abcdef", "gh$   "},
+		},
+	}
+
+	r := NewTextRenderer(DefaultStyles())
+	for _, tt := range tests {
+		state := tt.state
+		state.TabWidth = 4
+		rows := r.Render(6, len(tt.want), &state)
+		for i, want := range tt.want {
+			if rows[i] != want {
+				t.Errorf("%s: row %d = %q, want %q", tt.name, i, rows[i], want)
+			}
+		}
+	}
+}
+
+func TestTextRendererEndOfBuffer(t *testing.T) {
+	r := NewTextRenderer(DefaultStyles())
+	state := &RenderState{Lines: []string{"ab"}, CursorLine: 9, TabWidth: 4}
+
+	for _, wrap := range []bool{false, true} {
+		state.WordWrap = wrap
+		state.HideEndOfBuffer = false
+		rows := r.Render(4, 3, state)
+		for i, row := range rows[1:] {
+			if got := stripANSI(row); got != "~   " {
+				t.Errorf("wrap=%v: row %d = %q, want %q", wrap, i+1, got, "~   ")
+			}
+		}
+		if strings.Contains(rows[0], "~") {
+			t.Errorf("wrap=%v: end-of-buffer marker on a text row: %q", wrap, rows[0])
+		}
+
+		state.HideEndOfBuffer = true
+		rows = r.Render(4, 3, state)
+		for i, row := range rows[1:] {
+			if row != "    " {
+				t.Errorf("wrap=%v hidden: row %d = %q, want blank", wrap, i+1, row)
+			}
+		}
+	}
+}
+
+func TestTextRendererHighlightLimit(t *testing.T) {
+	const kw = "\033[31m"
+	r := NewTextRenderer(DefaultStyles())
+	long := strings.Repeat("x", 30)
+
+	tests := []struct {
+		name   string
+		line   string
+		wrap   bool
+		colors bool
+	}{
+		{"under the limit keeps colors", "func", false, true},
+		{"over the limit renders plainly", long, false, false},
+		{"over the limit renders plainly when wrapped", long, true, false},
+	}
+
+	for _, tt := range tests {
+		state := &RenderState{
+			Lines:          []string{tt.line},
+			CursorLine:     0,
+			CursorCol:      2,
+			LineColors:     map[int][]syntax.ColorSpan{0: {{Start: 0, End: 4, Color: kw}}},
+			HighlightLimit: 20,
+			TabWidth:       4,
+			WordWrap:       tt.wrap,
+			TextWidth:      40,
+		}
+		row := r.Render(40, 1, state)[0]
+		if got := strings.Contains(row, kw); got != tt.colors {
+			t.Errorf("%s: row %q has syntax color = %v, want %v", tt.name, row, got, tt.colors)
+		}
+		// The cursor stays on the third character either way
+		if i := strings.Index(row, "\033[7m"); i < 0 || visualWidth(row[:i]) != 2 {
+			t.Errorf("%s: cursor not at column 2 in %q", tt.name, row)
+		}
+	}
+}
+
+func TestTextRendererRenderLine(t *testing.T) {
+	const (
+		kw    = "\033[31m"
+		reset = "\033[0m"
+	)
+	r := NewTextRenderer(DefaultStyles())
+	bg, fg := selectionCodes(r.styles.Theme.UI.SelectionBg, r.styles.Theme.UI.SelectionFg)
+	sel := bg + fg
+	keyword := []syntax.ColorSpan{{Start: 0, End: 4, Color: kw}}
+
+	tests := []struct {
+		name    string
+		line    string
+		colors  []syntax.ColorSpan
+		sel     *SelectionRange
+		width   int
+		scrollX int
+		want    string
+	}{
+		{
+			"highlighting only",
+			"func\tab", keyword, nil, 12, 0,
+			kw + "f" + reset + kw + "u" + reset + kw + "n" + reset + kw + "c" + reset + "    ab  ",
+		},
+		{
+			"selection only",
+			"func\tab", nil, &SelectionRange{Start: 1, End: 3}, 12, 0,
+			"f" + sel + "u" + reset + sel + "n" + reset + "c    ab  ",
+		},
+		{
+			"selection over highlighting",
+			"func\tab", keyword, &SelectionRange{Start: 2, End: 6}, 12, 0,
+			kw + "f" + reset + kw + "u" + reset + sel + "n" + reset + sel + "c" + reset +
+				sel + "    " + reset + sel + "a" + reset + "b  ",
+		},
+		{
+			"clamped to width",
+			"func\tab", keyword, nil, 3, 0,
+			kw + "f" + reset + kw + "u" + reset + kw + "n" + reset,
+		},
+		{
+			"horizontal scroll",
+			"func\tab", keyword, &SelectionRange{Start: 5, End: -1}, 6, 3,
+			kw + "c" + reset + "    " + sel + "a" + reset,
+		},
+		{
+			"wide character that doesn't fit is padded",
+			"日本語", nil, nil, 3, 0,
+			"日 ",
+		},
+	}
+
+	for _, tt := range tests {
+		got := r.RenderLine(tt.line, tt.colors, tt.sel, 4, tt.width, tt.scrollX)
+		if got != tt.want {
+			t.Errorf("%s: RenderLine = %q, want %q", tt.name, got, tt.want)
+		}
+		if w := visualWidth(got); w != tt.width {
+			t.Errorf("%s: width = %d, want %d", tt.name, w, tt.width)
+		}
+	}
+}
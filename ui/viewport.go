@@ -18,20 +18,24 @@ type Viewport struct {
 	showLineNum    bool
 	wordWrap       bool
 	scrollbarWidth int // Width reserved for scrollbar (0 if disabled)
+	gutterWidth    int // Width of extra gutters between line numbers and text
+	lineNumWidth   int // Width of the line numbers when shown
 	tabWidth       int // Display width of tabs
+	scrollOff      int // Lines of context kept above/below the cursor
 	styles         Styles
 }
 
 // NewViewport creates a new viewport
 func NewViewport(styles Styles) *Viewport {
 	return &Viewport{
-		width:       80,
-		height:      24,
-		scrollY:     0,
-		scrollX:     0,
-		showLineNum: false,
-		tabWidth:    4,
-		styles:      styles,
+		width:        80,
+		height:       24,
+		scrollY:      0,
+		scrollX:      0,
+		showLineNum:  false,
+		lineNumWidth: DefaultLineNumberWidth,
+		tabWidth:     4,
+		styles:       styles,
 	}
 }
 
@@ -50,6 +54,19 @@ func (v *Viewport) TabWidth() int {
 	return v.tabWidth
 }
 
+// SetScrollOff sets the number of context lines kept around the cursor
+func (v *Viewport) SetScrollOff(lines int) {
+	if lines < 0 {
+		lines = 0
+	}
+	v.scrollOff = lines
+}
+
+// ScrollOff returns the number of context lines kept around the cursor
+func (v *Viewport) ScrollOff() int {
+	return v.scrollOff
+}
+
 // SetSize sets the viewport dimensions
 func (v *Viewport) SetSize(width, height int) {
 	v.width = width
@@ -107,6 +124,26 @@ func (v *Viewport) SetWordWrap(wrap bool) {
 	v.wordWrap = wrap
 }
 
+// SetWordWrapKeepingRow switches word wrap like SetWordWrap, then moves
+// scrollY so the cursor stays on roughly the same screen row. Without this
+// a cursor deep in a file of long lines would be many visual lines further
+// down once wrapped, and the view would jump. cursorCol is a rune column.
+func (v *Viewport) SetWordWrapKeepingRow(wrap bool, lines []string, cursorLine, cursorCol int) {
+	row := v.CursorVisualLine(lines, cursorLine, cursorCol) - v.scrollY
+	v.wordWrap = wrap
+	if wrap {
+		v.scrollX = 0
+	}
+	if row >= v.height {
+		row = v.height - 1
+	}
+	if row < 0 {
+		row = 0
+	}
+	v.scrollY = v.CursorVisualLine(lines, cursorLine, cursorCol) - row
+	v.ClampScroll(v.CountVisualLines(lines), v.height)
+}
+
 // WordWrap returns whether word wrap is enabled
 func (v *Viewport) WordWrap() bool {
 	return v.wordWrap
@@ -221,45 +258,58 @@ func (v *Viewport) MoveUpVisual(lines []string, line, col int) (newLine, newCol
 // EnsureCursorVisible scrolls the viewport to ensure the cursor is visible
 func (v *Viewport) EnsureCursorVisible(cursorLine, cursorCol int) {
 	// Vertical scrolling - word wrap uses visual lines
-	if cursorLine < v.scrollY {
-		v.scrollY = cursorLine
-	}
-	if cursorLine >= v.scrollY+v.height {
-		v.scrollY = cursorLine - v.height + 1
-	}
+	v.EnsureVisible(cursorLine, v.height, v.scrollOff)
 
 	// Horizontal scrolling (only when word wrap is off)
 	if !v.wordWrap {
-		textWidth := v.width
-		if v.showLineNum {
-			textWidth -= 5 // Line number width
-		}
-
-		if cursorCol < v.scrollX {
-			v.scrollX = cursorCol
-		}
-		if cursorCol >= v.scrollX+textWidth {
-			v.scrollX = cursorCol - textWidth + 1
-		}
+		v.EnsureColumnVisible(cursorCol, v.TextWidth(), 0)
 	} else {
 		v.scrollX = 0 // No horizontal scroll with word wrap
 	}
 }
 
 // EnsureCursorVisibleWrapped scrolls the viewport to ensure cursor is visible (word-wrap aware)
-// lines parameter is needed to calculate visual line positions
+// lines parameter is needed to calculate visual line positions. cursorCol
+// is a byte offset into the cursor's line, as the editor's cursor keeps
+// it; columns past the end of the line are virtual space.
 func (v *Viewport) EnsureCursorVisibleWrapped(lines []string, cursorLine, cursorCol int) {
+	if cursorLine >= 0 && cursorLine < len(lines) {
+		cursorCol = byteToRuneColumn(lines[cursorLine], cursorCol)
+	}
 	if !v.wordWrap {
-		v.EnsureCursorVisible(cursorLine, cursorCol)
+		// scrollX is in visual columns, so convert the rune column first
+		visualCol := cursorCol
+		if cursorLine >= 0 && cursorLine < len(lines) {
+			visualCol = v.VisualColumn(lines[cursorLine], cursorCol)
+		}
+		v.EnsureCursorVisible(cursorLine, visualCol)
+		if v.scrollOff > 0 {
+			v.clampScrollY(len(lines))
+		}
 		return
 	}
 
+	// Scroll to show cursor
+	visualLine := v.CursorVisualLine(lines, cursorLine, cursorCol)
+	v.EnsureVisible(visualLine, v.height, v.scrollOff)
+	if v.scrollOff > 0 {
+		v.clampScrollY(v.totalVisualLines(lines))
+	}
+
+	v.scrollX = 0 // No horizontal scroll with word wrap
+}
+
+// CursorVisualLine returns the visual line the cursor is on: the buffer
+// line without word wrap, or the count of wrapped rows above it with wrap.
+func (v *Viewport) CursorVisualLine(lines []string, cursorLine, cursorCol int) int {
+	if !v.wordWrap {
+		return cursorLine
+	}
 	textWidth := v.TextWidth()
 	if textWidth <= 0 {
 		textWidth = 1
 	}
 
-	// Calculate visual line position of cursor
 	visualLine := 0
 	for i := 0; i < cursorLine && i < len(lines); i++ {
 		visualLine += v.countWrappedLines(lines[i], textWidth)
@@ -272,26 +322,127 @@ func (v *Viewport) EnsureCursorVisibleWrapped(lines []string, cursorLine, cursor
 			visualLine += cursorCol / textWidth
 		}
 	}
+	return visualLine
+}
 
-	// Scroll to show cursor
-	if visualLine < v.scrollY {
-		v.scrollY = visualLine
+// CenterOn scrolls so the cursor's visual line sits in the middle of the
+// viewport, clamped so neither end of the document overscrolls.
+func (v *Viewport) CenterOn(cursorVisualLine, viewportHeight, totalVisualLines int) {
+	v.scrollToClamped(cursorVisualLine-(viewportHeight-1)/2, viewportHeight, totalVisualLines)
+}
+
+// CursorToTop scrolls so the cursor's visual line is the first row,
+// as far as the end of the document allows.
+func (v *Viewport) CursorToTop(cursorVisualLine, viewportHeight, totalVisualLines int) {
+	v.scrollToClamped(cursorVisualLine, viewportHeight, totalVisualLines)
+}
+
+// CursorToBottom scrolls so the cursor's visual line is the last row,
+// as far as the start of the document allows.
+func (v *Viewport) CursorToBottom(cursorVisualLine, viewportHeight, totalVisualLines int) {
+	v.scrollToClamped(cursorVisualLine-viewportHeight+1, viewportHeight, totalVisualLines)
+}
+
+// scrollToClamped sets scrollY to target, kept within the document
+func (v *Viewport) scrollToClamped(target, viewportHeight, totalVisualLines int) {
+	v.scrollY = 0
+	v.scrollWithin(target, totalVisualLines-viewportHeight)
+}
+
+// EnsureVisible scrolls vertically so the cursor keeps at least margin lines
+// of context above and below it. The margin shrinks when the viewport is too
+// small to honor it, and scrollY never goes above the start of the document.
+func (v *Viewport) EnsureVisible(cursorVisualLine, viewportHeight, margin int) {
+	if viewportHeight <= 0 {
+		return
+	}
+	if margin < 0 {
+		margin = 0
 	}
-	if visualLine >= v.scrollY+v.height {
-		v.scrollY = visualLine - v.height + 1
+	if maxMargin := (viewportHeight - 1) / 2; margin > maxMargin {
+		margin = maxMargin
 	}
 
-	v.scrollX = 0 // No horizontal scroll with word wrap
+	if cursorVisualLine-margin < v.scrollY {
+		v.scrollY = cursorVisualLine - margin
+	}
+	if cursorVisualLine+margin >= v.scrollY+viewportHeight {
+		v.scrollY = cursorVisualLine + margin - viewportHeight + 1
+	}
+	if v.scrollY < 0 {
+		v.scrollY = 0
+	}
+}
+
+// EnsureColumnVisible scrolls horizontally so the cursor's visual column stays
+// inside the text area, keeping margin columns of context on either side.
+func (v *Viewport) EnsureColumnVisible(cursorVisualCol, textWidth, margin int) {
+	if textWidth <= 0 {
+		return
+	}
+	if margin < 0 {
+		margin = 0
+	}
+	if maxMargin := (textWidth - 1) / 2; margin > maxMargin {
+		margin = maxMargin
+	}
+
+	if cursorVisualCol-margin < v.scrollX {
+		v.scrollX = cursorVisualCol - margin
+	}
+	if cursorVisualCol+margin >= v.scrollX+textWidth {
+		v.scrollX = cursorVisualCol + margin - textWidth + 1
+	}
+	if v.scrollX < 0 {
+		v.scrollX = 0
+	}
+}
+
+// byteToRuneColumn converts byte offset col in line to a rune column.
+// Offsets past the end of the line count one column per byte, as virtual
+// space does.
+func byteToRuneColumn(line string, col int) int {
+	n := min(max(col, 0), len(line))
+	return utf8.RuneCountInString(line[:n]) + col - n
+}
+
+// VisualColumn converts a rune column in line to a visual column,
+// accounting for tabs and wide characters
+func (v *Viewport) VisualColumn(line string, col int) int {
+	_, colMap := expandTabs(line, v.TabWidth())
+	if col < len(colMap) {
+		return colMap[max(col, 0)]
+	}
+	// Columns past the end of the line (virtual cursor) are one cell each
+	last := len(colMap) - 1
+	return colMap[last] + col - last
+}
+
+// clampScrollY keeps scrollY from scrolling past the last page of the document
+func (v *Viewport) clampScrollY(totalVisualLines int) {
+	maxScroll := totalVisualLines - v.height
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if v.scrollY > maxScroll {
+		v.scrollY = maxScroll
+	}
 }
 
 // LineNumberWidth returns the width of the line number column
 func (v *Viewport) LineNumberWidth() int {
 	if v.showLineNum {
-		return 5
+		return v.lineNumWidth
 	}
 	return 0
 }
 
+// SetLineNumberWidth sets the width taken by line numbers when they are
+// shown, never less than DefaultLineNumberWidth
+func (v *Viewport) SetLineNumberWidth(width int) {
+	v.lineNumWidth = max(width, DefaultLineNumberWidth)
+}
+
 // SetScrollbarWidth sets the width reserved for the scrollbar
 func (v *Viewport) SetScrollbarWidth(width int) {
 	if width < 0 {
@@ -305,9 +456,22 @@ func (v *Viewport) ScrollbarWidth() int {
 	return v.scrollbarWidth
 }
 
-// TextWidth returns the width available for text (viewport width minus line numbers and scrollbar)
+// SetGutterWidth sets the width of gutters drawn between the line numbers and the text
+func (v *Viewport) SetGutterWidth(width int) {
+	if width < 0 {
+		width = 0
+	}
+	v.gutterWidth = width
+}
+
+// GutterWidth returns the total width to the left of the text (line numbers plus gutters)
+func (v *Viewport) GutterWidth() int {
+	return v.LineNumberWidth() + v.gutterWidth
+}
+
+// TextWidth returns the width available for text (viewport width minus gutters and scrollbar)
 func (v *Viewport) TextWidth() int {
-	return v.width - v.LineNumberWidth() - v.scrollbarWidth
+	return v.width - v.GutterWidth() - v.scrollbarWidth
 }
 
 // CountVisualLines returns the total number of visual lines when word wrap is enabled
@@ -755,36 +919,94 @@ func (v *Viewport) ScrollDownWrapped(lines []string) {
 	}
 }
 
-// PageUp scrolls up by one page
-func (v *Viewport) PageUp() {
-	v.scrollY -= v.height
-	if v.scrollY < 0 {
-		v.scrollY = 0
+// ScrollByVisual scrolls by delta visual lines (negative scrolls up), so
+// with word wrap on a wrapped line takes several steps to pass. The result
+// is clamped to [0, totalVisualLines-1], so the last visual line can still
+// reach the top row; a document that fits in the viewport doesn't scroll.
+func (v *Viewport) ScrollByVisual(delta, totalVisualLines, viewportHeight int) {
+	maxScroll := totalVisualLines - 1
+	if totalVisualLines <= viewportHeight {
+		maxScroll = 0
 	}
+	v.scrollWithin(delta, maxScroll)
 }
 
-// PageDown scrolls down by one page
-func (v *Viewport) PageDown(totalLines int) {
-	v.scrollY += v.height
-	maxScroll := totalLines - v.height
+// scrollWithin scrolls by delta visual lines, clamped to [0, maxScroll]
+func (v *Viewport) scrollWithin(delta, maxScroll int) {
 	if maxScroll < 0 {
 		maxScroll = 0
 	}
+	v.scrollY += delta
 	if v.scrollY > maxScroll {
 		v.scrollY = maxScroll
 	}
+	if v.scrollY < 0 {
+		v.scrollY = 0
+	}
 }
 
-// PageDownWrapped scrolls down by one page (word-wrap aware)
-func (v *Viewport) PageDownWrapped(lines []string) {
-	v.scrollY += v.height
-	maxScroll := v.totalVisualLines(lines) - v.height
+// ClampScroll pulls scrollY back within the document, e.g. after a resize
+// left it past the point where the last line sits on the bottom row.
+func (v *Viewport) ClampScroll(totalVisualLines, viewportHeight int) {
+	v.scrollWithin(0, totalVisualLines-viewportHeight)
+}
+
+// PageStep returns how many lines a page scroll moves: a full viewport
+// less one line, which stays on screen for context.
+func PageStep(viewportHeight int) int {
+	if viewportHeight <= 1 {
+		return 1
+	}
+	return viewportHeight - 1
+}
+
+// HalfPageStep returns how many lines a half-page scroll moves, rounding
+// down for odd heights.
+func HalfPageStep(viewportHeight int) int {
+	if viewportHeight <= 1 {
+		return 1
+	}
+	return viewportHeight / 2
+}
+
+// PageUp scrolls up by one page
+func (v *Viewport) PageUp(viewportHeight, totalVisualLines int) {
+	v.scrollWithin(-PageStep(viewportHeight), totalVisualLines-viewportHeight)
+}
+
+// PageDown scrolls down by one page, stopping at the last page
+func (v *Viewport) PageDown(viewportHeight, totalVisualLines int) {
+	v.scrollWithin(PageStep(viewportHeight), totalVisualLines-viewportHeight)
+}
+
+// HalfPageUp scrolls up by half a page
+func (v *Viewport) HalfPageUp(viewportHeight, totalVisualLines int) {
+	v.scrollWithin(-HalfPageStep(viewportHeight), totalVisualLines-viewportHeight)
+}
+
+// HalfPageDown scrolls down by half a page, stopping at the last page
+func (v *Viewport) HalfPageDown(viewportHeight, totalVisualLines int) {
+	v.scrollWithin(HalfPageStep(viewportHeight), totalVisualLines-viewportHeight)
+}
+
+// ScrollToPercent scrolls so that pct percent (0-100) of the document is at the top.
+// The result is clamped so the last page never overscrolls.
+func (v *Viewport) ScrollToPercent(pct float64, totalVisualLines, viewportHeight int) {
+	if pct < 0 {
+		pct = 0
+	}
+	if pct > 100 {
+		pct = 100
+	}
+	maxScroll := totalVisualLines - viewportHeight
 	if maxScroll < 0 {
 		maxScroll = 0
 	}
-	if v.scrollY > maxScroll {
-		v.scrollY = maxScroll
+	target := int(pct / 100 * float64(totalVisualLines))
+	if target > maxScroll {
+		target = maxScroll
 	}
+	v.scrollY = target
 }
 
 // totalVisualLines calculates the total number of visual lines with word wrap
@@ -838,7 +1060,7 @@ func itoa(n int) string {
 // PositionFromClick converts a click position to buffer line and column
 func (v *Viewport) PositionFromClick(x, y int) (line, col int) {
 	line = v.scrollY + y
-	col = v.scrollX + x - v.LineNumberWidth()
+	col = v.scrollX + x - v.GutterWidth()
 	if col < 0 {
 		col = 0
 	}
@@ -869,7 +1091,7 @@ func (v *Viewport) PositionFromClickWrapped(lines []string, x, y int) (line, col
 			line = logicalLine
 			// Calculate which wrapped segment and column
 			segmentIndex := targetVisualLine - visualLine
-			col = segmentIndex*textWidth + (x - v.LineNumberWidth())
+			col = segmentIndex*textWidth + (x - v.GutterWidth())
 			if col < 0 {
 				col = 0
 			}
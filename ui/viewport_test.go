@@ -0,0 +1,280 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScrollToPercent(t *testing.T) {
+	tests := []struct {
+		name   string
+		pct    float64
+		total  int
+		height int
+		want   int
+	}{
+		{"zero percent", 0, 100, 20, 0},
+		{"fifty percent", 50, 100, 20, 50},
+		{"hundred percent clamps to last page", 100, 100, 20, 80},
+		{"eighty five percent clamps", 85, 100, 20, 80},
+		{"short document stays at top", 50, 10, 20, 0},
+		{"negative percent", -10, 100, 20, 0},
+	}
+
+	for _, tt := range tests {
+		v := NewViewport(DefaultStyles())
+		v.SetScrollY(37)
+		v.ScrollToPercent(tt.pct, tt.total, tt.height)
+		if got := v.ScrollY(); got != tt.want {
+			t.Errorf("%s: ScrollToPercent(%v, %d, %d) scrollY = %d, want %d",
+				tt.name, tt.pct, tt.total, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestEnsureVisibleMargin(t *testing.T) {
+	tests := []struct {
+		name    string
+		scrollY int
+		cursor  int
+		height  int
+		margin  int
+		want    int
+	}{
+		{"cursor near top keeps margin", 10, 11, 20, 3, 8},
+		{"cursor near bottom keeps margin", 10, 28, 20, 3, 12},
+		{"cursor in middle does not scroll", 10, 20, 20, 3, 10},
+		{"document too short to honor margin", 0, 1, 20, 3, 0},
+		{"margin larger than half the viewport", 10, 12, 5, 10, 10},
+		{"zero margin", 10, 9, 20, 0, 9},
+	}
+
+	for _, tt := range tests {
+		v := NewViewport(DefaultStyles())
+		v.SetScrollY(tt.scrollY)
+		v.EnsureVisible(tt.cursor, tt.height, tt.margin)
+		if got := v.ScrollY(); got != tt.want {
+			t.Errorf("%s: EnsureVisible(%d, %d, %d) scrollY = %d, want %d",
+				tt.name, tt.cursor, tt.height, tt.margin, got, tt.want)
+		}
+	}
+}
+
+func TestEnsureCursorVisibleScrollOffClampsAtEnd(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	v.SetSize(80, 10)
+	v.SetScrollOff(3)
+	lines := make([]string, 20)
+	v.EnsureCursorVisibleWrapped(lines, 19, 0)
+	if got := v.ScrollY(); got != 10 {
+		t.Errorf("scrollY at last line = %d, want 10", got)
+	}
+}
+
+func TestEnsureColumnVisible(t *testing.T) {
+	tests := []struct {
+		name    string
+		scrollX int
+		col     int
+		width   int
+		margin  int
+		want    int
+	}{
+		{"cursor past right edge", 0, 45, 40, 0, 6},
+		{"cursor past right edge with margin", 0, 45, 40, 5, 11},
+		{"cursor back to the left", 20, 5, 40, 0, 5},
+		{"cursor back to the left with margin", 20, 5, 40, 3, 2},
+		{"short line stays unscrolled", 0, 10, 40, 5, 0},
+		{"cursor already visible", 10, 30, 40, 0, 10},
+	}
+
+	for _, tt := range tests {
+		v := NewViewport(DefaultStyles())
+		v.SetScrollX(tt.scrollX)
+		v.EnsureColumnVisible(tt.col, tt.width, tt.margin)
+		if got := v.ScrollX(); got != tt.want {
+			t.Errorf("%s: EnsureColumnVisible(%d, %d, %d) scrollX = %d, want %d",
+				tt.name, tt.col, tt.width, tt.margin, got, tt.want)
+		}
+	}
+}
+
+func TestVisualColumn(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	tests := []struct {
+		line string
+		col  int
+		want int
+	}{
+		{"hello", 3, 3},
+		{"日本語", 2, 4},
+		{"\tx", 1, 4},
+		{"ab", 4, 4},
+	}
+
+	for _, tt := range tests {
+		if got := v.VisualColumn(tt.line, tt.col); got != tt.want {
+			t.Errorf("VisualColumn(%q, %d) = %d, want %d", tt.line, tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestEnsureCursorVisibleWideChars(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	v.SetSize(10, 5)
+	lines := []string{"日本語日本語日本語"}
+	// Byte offset 18 is rune column 6, visual column 12, past the
+	// 10-column text area
+	v.EnsureCursorVisibleWrapped(lines, 0, 18)
+	if got := v.ScrollX(); got != 3 {
+		t.Errorf("scrollX = %d, want 3", got)
+	}
+}
+
+func TestEnsureCursorVisibleUsesTextWidth(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	v.SetSize(20, 5)
+	v.ShowLineNumbers(true)
+	v.SetLineNumberWidth(6)
+	v.SetScrollbarWidth(1)
+	// 20 columns less a 6-column gutter and the scrollbar leave 13
+	v.EnsureCursorVisible(0, 13)
+	if got := v.ScrollX(); got != 1 {
+		t.Errorf("scrollX = %d, want 1 for a 13-column text area", got)
+	}
+}
+
+func TestScrollByVisual(t *testing.T) {
+	// At width 10 these 4 buffer lines wrap to 1+3+1+3 = 8 visual lines
+	lines := []string{"a", strings.Repeat("b", 25), "c", strings.Repeat("d", 30)}
+
+	tests := []struct {
+		name    string
+		scrollY int
+		delta   int
+		height  int
+		want    int
+	}{
+		{"one visual line into a wrapped line", 1, 1, 3, 2},
+		{"several visual lines", 0, 4, 3, 4},
+		{"clamped so the last line reaches the top row", 4, 10, 3, 7},
+		{"back up from the last line", 7, -2, 3, 5},
+		{"up past the top", 2, -5, 3, 0},
+		{"document shorter than the viewport", 0, 2, 20, 0},
+	}
+
+	for _, tt := range tests {
+		v := NewViewport(DefaultStyles())
+		v.SetSize(10, tt.height)
+		v.SetWordWrap(true)
+		total := v.CountVisualLines(lines)
+		if total != 8 {
+			t.Fatalf("CountVisualLines = %d, want 8", total)
+		}
+		v.SetScrollY(tt.scrollY)
+		v.ScrollByVisual(tt.delta, total, tt.height)
+		if got := v.ScrollY(); got != tt.want {
+			t.Errorf("%s: ScrollByVisual(%d) from %d: scrollY = %d, want %d",
+				tt.name, tt.delta, tt.scrollY, got, tt.want)
+		}
+	}
+}
+
+func TestPageScroll(t *testing.T) {
+	tests := []struct {
+		name    string
+		scroll  func(v *Viewport, height, total int)
+		scrollY int
+		height  int
+		total   int
+		want    int
+	}{
+		{"page down keeps a line of overlap", (*Viewport).PageDown, 0, 10, 100, 9},
+		{"page down near the bottom clamps", (*Viewport).PageDown, 85, 10, 100, 90},
+		{"page up clamps at the top", (*Viewport).PageUp, 5, 10, 100, 0},
+		{"page up", (*Viewport).PageUp, 50, 10, 100, 41},
+		{"half page down, even height", (*Viewport).HalfPageDown, 0, 10, 100, 5},
+		{"half page down, odd height", (*Viewport).HalfPageDown, 0, 11, 100, 5},
+		{"half page up, odd height", (*Viewport).HalfPageUp, 20, 7, 100, 17},
+		{"half page down clamps", (*Viewport).HalfPageDown, 88, 10, 100, 90},
+		{"one-line viewport still moves", (*Viewport).HalfPageDown, 0, 1, 100, 1},
+	}
+
+	for _, tt := range tests {
+		v := NewViewport(DefaultStyles())
+		v.SetScrollY(tt.scrollY)
+		tt.scroll(v, tt.height, tt.total)
+		if got := v.ScrollY(); got != tt.want {
+			t.Errorf("%s: scrollY = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCursorScrollPlacement(t *testing.T) {
+	tests := []struct {
+		name   string
+		place  func(v *Viewport, cursor, height, total int)
+		cursor int
+		height int
+		total  int
+		want   int
+	}{
+		{"center in a long document", (*Viewport).CenterOn, 50, 11, 100, 45},
+		{"center with even height", (*Viewport).CenterOn, 50, 10, 100, 46},
+		{"center near the top clamps", (*Viewport).CenterOn, 2, 10, 100, 0},
+		{"center near the bottom clamps", (*Viewport).CenterOn, 97, 10, 100, 90},
+		{"center in a short document", (*Viewport).CenterOn, 3, 10, 5, 0},
+		{"to top", (*Viewport).CursorToTop, 40, 10, 100, 40},
+		{"to top near the bottom clamps", (*Viewport).CursorToTop, 95, 10, 100, 90},
+		{"to bottom", (*Viewport).CursorToBottom, 40, 10, 100, 31},
+		{"to bottom near the top clamps", (*Viewport).CursorToBottom, 3, 10, 100, 0},
+	}
+
+	for _, tt := range tests {
+		v := NewViewport(DefaultStyles())
+		v.SetScrollY(20)
+		tt.place(v, tt.cursor, tt.height, tt.total)
+		if got := v.ScrollY(); got != tt.want {
+			t.Errorf("%s: scrollY = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetWordWrapKeepingRow(t *testing.T) {
+	// At width 10 each long line wraps to 3 visual lines
+	lines := make([]string, 40)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", 25)
+	}
+
+	tests := []struct {
+		name    string
+		wrap    bool
+		scrollY int
+		cursor  int
+		col     int
+		want    int
+	}{
+		{"wrap on keeps the row", true, 10, 15, 0, 40},
+		{"wrap on keeps the row mid-line", true, 10, 15, 12, 41},
+		{"wrap off keeps the row", false, 40, 15, 0, 10},
+		{"wrap off near the top clamps", false, 0, 1, 0, 0},
+		{"wrap on near the end clamps", true, 35, 39, 0, 112},
+		{"cursor below the view comes back on screen", true, 0, 20, 0, 53},
+	}
+
+	for _, tt := range tests {
+		v := NewViewport(DefaultStyles())
+		v.SetSize(10, 8)
+		v.SetWordWrap(!tt.wrap)
+		v.SetScrollY(tt.scrollY)
+		v.SetWordWrapKeepingRow(tt.wrap, lines, tt.cursor, tt.col)
+		if got := v.ScrollY(); got != tt.want {
+			t.Errorf("%s: scrollY = %d, want %d", tt.name, got, tt.want)
+		}
+		row := v.CursorVisualLine(lines, tt.cursor, tt.col) - v.ScrollY()
+		if row < 0 || row >= 8 {
+			t.Errorf("%s: cursor row %d is off screen", tt.name, row)
+		}
+	}
+}